@@ -0,0 +1,48 @@
+// Command webhook-receiver is a local endpoint for trying out webhook subscriptions. It logs every
+// delivery, checks its signature when given the subscription's secret and answers with -status.
+package main
+
+import (
+	"crypto/hmac"
+	"flag"
+	"io"
+	"log"
+	"net/http"
+
+	"kasir-api/events"
+)
+
+func main() {
+	addr := flag.String("addr", ":9000", "listen address")
+	secret := flag.String("secret", "", "subscription secret, signatures are not checked when empty")
+	status := flag.Int("status", http.StatusOK, "status code to answer, non-2xx makes the API retry")
+	flag.Parse()
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		verdict := "unchecked"
+		if *secret != "" {
+			want := events.SignPayload(*secret, r.Header.Get(events.HeaderWebhookTimestamp), body)
+			verdict = "valid"
+			if !hmac.Equal([]byte(want), []byte(r.Header.Get(events.HeaderWebhookSignature))) {
+				verdict = "INVALID"
+			}
+		}
+		log.Printf("%s delivery %s, signature %s: %s", r.Header.Get(events.HeaderWebhookEvent),
+			r.Header.Get(events.HeaderWebhookDelivery), verdict, body)
+
+		if verdict == "INVALID" {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(*status)
+	})
+
+	log.Printf("webhook receiver listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
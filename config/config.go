@@ -2,6 +2,8 @@ package config
 
 import (
 	"log"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/viper"
 )
@@ -9,6 +11,61 @@ import (
 type Config struct {
 	Port   string `mapstructure:"PORT"`
 	DBConn string `mapstructure:"DB_CONN"`
+	// ExpiredSalePolicy is "block" to refuse selling expired batches or "warn" to allow it with a warning
+	ExpiredSalePolicy string `mapstructure:"EXPIRED_SALE_POLICY"`
+	// LowStockWebhookURL receives stock.low events as JSON POSTs signed with LowStockWebhookSecret,
+	// it is only posted to when both are set
+	LowStockWebhookURL    string `mapstructure:"LOW_STOCK_WEBHOOK_URL"`
+	LowStockWebhookSecret string `mapstructure:"LOW_STOCK_WEBHOOK_SECRET"`
+	// Defaults for reorder suggestions, in days
+	ReorderWindowDays   int `mapstructure:"REORDER_WINDOW_DAYS"`
+	ReorderLeadTimeDays int `mapstructure:"REORDER_LEAD_TIME_DAYS"`
+	ReorderSafetyDays   int `mapstructure:"REORDER_SAFETY_DAYS"`
+	ReorderCoverDays    int `mapstructure:"REORDER_COVER_DAYS"`
+	// OutletID is the outlet this instance serves when a request has no X-Outlet-ID header, 0 for none
+	OutletID int `mapstructure:"OUTLET_ID"`
+	// MultiTenant serves many merchants from one deployment, each authenticated by its tenant API key
+	MultiTenant bool `mapstructure:"MULTI_TENANT"`
+	// AdminAPIKey authorizes tenant provisioning, tenant routes are disabled without it
+	AdminAPIKey string `mapstructure:"ADMIN_API_KEY"`
+	// TenantPoolConns sizes the connection pool shared by all tenants, TenantMaxConns caps how many
+	// of its connections one tenant uses at once
+	TenantPoolConns int `mapstructure:"TENANT_POOL_CONNS"`
+	TenantMaxConns  int `mapstructure:"TENANT_MAX_CONNS"`
+	// Loyalty points: Rupiah spent per point earned, rounding of earned points (down, nearest or up),
+	// Rupiah a redeemed point is worth, days until points expire and categories that earn no points
+	LoyaltySpendPerPoint      int    `mapstructure:"LOYALTY_SPEND_PER_POINT"`
+	LoyaltyRounding           string `mapstructure:"LOYALTY_ROUNDING"`
+	LoyaltyPointValue         int    `mapstructure:"LOYALTY_POINT_VALUE"`
+	LoyaltyExpiryDays         int    `mapstructure:"LOYALTY_EXPIRY_DAYS"`
+	LoyaltyExcludedCategories []int  `mapstructure:"LOYALTY_EXCLUDED_CATEGORIES"`
+	// GiftCardExpiryDays is how long sold gift cards and vouchers stay valid, 0 for no expiry
+	GiftCardExpiryDays int `mapstructure:"GIFT_CARD_EXPIRY_DAYS"`
+	// DraftOrderExpiryMinutes is how long a parked cart is kept after it was last saved
+	DraftOrderExpiryMinutes int `mapstructure:"DRAFT_ORDER_EXPIRY_MINUTES"`
+	// Webhook deliveries are attempted up to WebhookMaxAttempts times, the first retry after
+	// WebhookRetrySeconds and each later one after twice the previous wait
+	WebhookMaxAttempts  int `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookRetrySeconds int `mapstructure:"WEBHOOK_RETRY_SECONDS"`
+	// WebhookAllowPrivateURLs lets webhook subscriptions reach loopback, link-local and private
+	// addresses, which are refused otherwise
+	WebhookAllowPrivateURLs bool `mapstructure:"WEBHOOK_ALLOW_PRIVATE_URLS"`
+	// DashboardOrigins are the browser origins, besides the server's own, allowed to open the live
+	// dashboard WebSocket
+	DashboardOrigins []string `mapstructure:"DASHBOARD_ORIGINS"`
+	// OutboxPollSeconds is how often the event outbox is checked for events to retry or left behind
+	OutboxPollSeconds int `mapstructure:"OUTBOX_POLL_SECONDS"`
+	// PaymentGateway takes QRIS payments, only "mock" for now. The QRIS merchant is the merchant
+	// registered for QRIS, its category a merchant category code.
+	PaymentGateway       string `mapstructure:"PAYMENT_GATEWAY"`
+	QRISMerchantID       string `mapstructure:"QRIS_MERCHANT_ID"`
+	QRISMerchantName     string `mapstructure:"QRIS_MERCHANT_NAME"`
+	QRISMerchantCity     string `mapstructure:"QRIS_MERCHANT_CITY"`
+	QRISMerchantCategory string `mapstructure:"QRIS_MERCHANT_CATEGORY"`
+	// QRISExpiryMinutes is how long a QRIS can be paid before its sale is given back
+	QRISExpiryMinutes int `mapstructure:"QRIS_EXPIRY_MINUTES"`
+	// PaymentCallbackSecret signs the mock gateway's callbacks, which are refused while it is empty
+	PaymentCallbackSecret string `mapstructure:"PAYMENT_CALLBACK_SECRET"`
 }
 
 var AppConfig *Config
@@ -19,13 +76,94 @@ func LoadConfig() {
 
 	// Set defaults
 	viper.SetDefault("PORT", "8080")
+	viper.SetDefault("EXPIRED_SALE_POLICY", "block")
+	viper.SetDefault("REORDER_WINDOW_DAYS", 30)
+	viper.SetDefault("REORDER_LEAD_TIME_DAYS", 7)
+	viper.SetDefault("REORDER_SAFETY_DAYS", 3)
+	viper.SetDefault("REORDER_COVER_DAYS", 14)
+	viper.SetDefault("TENANT_POOL_CONNS", 25)
+	viper.SetDefault("TENANT_MAX_CONNS", 5)
+	viper.SetDefault("LOYALTY_SPEND_PER_POINT", 10000)
+	viper.SetDefault("LOYALTY_ROUNDING", "down")
+	viper.SetDefault("LOYALTY_POINT_VALUE", 100)
+	viper.SetDefault("LOYALTY_EXPIRY_DAYS", 365)
+	viper.SetDefault("GIFT_CARD_EXPIRY_DAYS", 365)
+	viper.SetDefault("DRAFT_ORDER_EXPIRY_MINUTES", 120)
+	viper.SetDefault("WEBHOOK_MAX_ATTEMPTS", 6)
+	viper.SetDefault("WEBHOOK_RETRY_SECONDS", 30)
+	viper.SetDefault("OUTBOX_POLL_SECONDS", 5)
+	viper.SetDefault("PAYMENT_GATEWAY", "mock")
+	viper.SetDefault("QRIS_MERCHANT_ID", "ID0000000000000")
+	viper.SetDefault("QRIS_MERCHANT_NAME", "Kasir")
+	viper.SetDefault("QRIS_MERCHANT_CITY", "Jakarta")
+	viper.SetDefault("QRIS_MERCHANT_CATEGORY", "5411")
+	viper.SetDefault("QRIS_EXPIRY_MINUTES", 15)
 
 	if err := viper.ReadInConfig(); err != nil {
 		log.Println("No .env file found, using environment variables")
 	}
 
 	AppConfig = &Config{
-		Port:   viper.GetString("PORT"),
-		DBConn: viper.GetString("DB_CONN"),
+		Port:                  viper.GetString("PORT"),
+		DBConn:                viper.GetString("DB_CONN"),
+		ExpiredSalePolicy:     viper.GetString("EXPIRED_SALE_POLICY"),
+		LowStockWebhookURL:    viper.GetString("LOW_STOCK_WEBHOOK_URL"),
+		LowStockWebhookSecret: viper.GetString("LOW_STOCK_WEBHOOK_SECRET"),
+		ReorderWindowDays:     viper.GetInt("REORDER_WINDOW_DAYS"),
+		ReorderLeadTimeDays:   viper.GetInt("REORDER_LEAD_TIME_DAYS"),
+		ReorderSafetyDays:     viper.GetInt("REORDER_SAFETY_DAYS"),
+		ReorderCoverDays:      viper.GetInt("REORDER_COVER_DAYS"),
+		OutletID:              viper.GetInt("OUTLET_ID"),
+		MultiTenant:           viper.GetBool("MULTI_TENANT"),
+		AdminAPIKey:           viper.GetString("ADMIN_API_KEY"),
+		TenantPoolConns:       viper.GetInt("TENANT_POOL_CONNS"),
+		TenantMaxConns:        viper.GetInt("TENANT_MAX_CONNS"),
+
+		LoyaltySpendPerPoint:      viper.GetInt("LOYALTY_SPEND_PER_POINT"),
+		LoyaltyRounding:           viper.GetString("LOYALTY_ROUNDING"),
+		LoyaltyPointValue:         viper.GetInt("LOYALTY_POINT_VALUE"),
+		LoyaltyExpiryDays:         viper.GetInt("LOYALTY_EXPIRY_DAYS"),
+		LoyaltyExcludedCategories: parseIDList(viper.GetString("LOYALTY_EXCLUDED_CATEGORIES")),
+		GiftCardExpiryDays:        viper.GetInt("GIFT_CARD_EXPIRY_DAYS"),
+		DraftOrderExpiryMinutes:   viper.GetInt("DRAFT_ORDER_EXPIRY_MINUTES"),
+		WebhookMaxAttempts:        viper.GetInt("WEBHOOK_MAX_ATTEMPTS"),
+		WebhookRetrySeconds:       viper.GetInt("WEBHOOK_RETRY_SECONDS"),
+		WebhookAllowPrivateURLs:   viper.GetBool("WEBHOOK_ALLOW_PRIVATE_URLS"),
+		DashboardOrigins:          parseList(viper.GetString("DASHBOARD_ORIGINS")),
+		OutboxPollSeconds:         viper.GetInt("OUTBOX_POLL_SECONDS"),
+		PaymentGateway:            viper.GetString("PAYMENT_GATEWAY"),
+		QRISMerchantID:            viper.GetString("QRIS_MERCHANT_ID"),
+		QRISMerchantName:          viper.GetString("QRIS_MERCHANT_NAME"),
+		QRISMerchantCity:          viper.GetString("QRIS_MERCHANT_CITY"),
+		QRISMerchantCategory:      viper.GetString("QRIS_MERCHANT_CATEGORY"),
+		QRISExpiryMinutes:         viper.GetInt("QRIS_EXPIRY_MINUTES"),
+		PaymentCallbackSecret:     viper.GetString("PAYMENT_CALLBACK_SECRET"),
+	}
+}
+
+// parseIDList reads a comma-separated list of IDs such as "3,7"
+func parseList(s string) []string {
+	var items []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			items = append(items, part)
+		}
+	}
+	return items
+}
+
+func parseIDList(s string) []int {
+	var ids []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.Atoi(part)
+		if err != nil {
+			log.Fatalf("Invalid ID %q in list %q", part, s)
+		}
+		ids = append(ids, id)
 	}
+	return ids
 }
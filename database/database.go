@@ -1,17 +1,34 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"log"
+	"strconv"
+	"sync"
 	"time"
 
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
 
 	"kasir-api/config"
 )
 
+// DefaultTenantID is the tenant a single-tenant deployment runs as
+const DefaultTenantID = 1
+
 var DB *sql.DB
 
+var (
+	// tenantPool holds the connections of all tenants, lent to a tenant's sessions one use at a time
+	tenantPool *pgxpool.Pool
+	tenantMu   sync.Mutex
+	tenantDBs  = make(map[int]*sql.DB)
+)
+
 func InitDB() {
 	var err error
 
@@ -20,7 +37,13 @@ func InitDB() {
 		return
 	}
 
-	DB, err = sql.Open("pgx", config.AppConfig.DBConn)
+	// In multi-tenant mode DB is only used for the tenant registry and
+	// cannot see tenant data, tenants are served through TenantDB
+	tenantID := DefaultTenantID
+	if config.AppConfig.MultiTenant {
+		tenantID = 0
+	}
+	DB, err = open(tenantID)
 	if err != nil {
 		log.Fatalf("Failed to open database connection: %v", err)
 	}
@@ -35,11 +58,98 @@ func InitDB() {
 		log.Fatalf("Failed to ping database: %v", err)
 	}
 
+	if config.AppConfig.MultiTenant {
+		// Superusers and BYPASSRLS roles ignore row-level security, which would expose every tenant's data
+		var bypass bool
+		err = DB.QueryRow("SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user").Scan(&bypass)
+		if err != nil {
+			log.Fatalf("Failed to check database role: %v", err)
+		}
+		if bypass {
+			log.Fatalf("Multi-tenant mode needs a database role that is subject to row-level security")
+		}
+	}
+
+	if config.AppConfig.MultiTenant {
+		poolConfig, err := pgxpool.ParseConfig(config.AppConfig.DBConn)
+		if err != nil {
+			log.Fatalf("Failed to parse database connection: %v", err)
+		}
+		poolConfig.MaxConns = int32(config.AppConfig.TenantPoolConns)
+		poolConfig.MaxConnIdleTime = 5 * time.Minute
+		poolConfig.MaxConnLifetime = 5 * time.Minute
+		tenantPool, err = pgxpool.NewWithConfig(context.Background(), poolConfig)
+		if err != nil {
+			log.Fatalf("Failed to open tenant connection pool: %v", err)
+		}
+	}
+
 	log.Println("Database connected successfully")
 }
 
+// TenantDB returns the database of a tenant. Its sessions carry the tenant in the app.tenant_id
+// setting, so row-level security only shows and accepts that tenant's rows. All tenants share the
+// connections of one pool: a connection is bound to the tenant each time it is lent, and given back
+// to the pool once used rather than kept idle, so connections do not grow with the number of tenants.
+func TenantDB(tenantID int) (*sql.DB, error) {
+	tenantMu.Lock()
+	defer tenantMu.Unlock()
+
+	if tenantPool == nil {
+		return nil, errors.New("tenant databases need multi-tenant mode")
+	}
+	if db, ok := tenantDBs[tenantID]; ok {
+		return db, nil
+	}
+	db := sql.OpenDB(tenantConnector{Connector: stdlib.GetPoolConnector(tenantPool), tenantID: tenantID})
+	db.SetMaxOpenConns(config.AppConfig.TenantMaxConns)
+	db.SetMaxIdleConns(0)
+	tenantDBs[tenantID] = db
+	return db, nil
+}
+
+// tenantConnector lends the connections of the shared pool bound to one tenant
+type tenantConnector struct {
+	driver.Connector
+	tenantID int
+}
+
+func (c tenantConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, err = conn.(*stdlib.Conn).Conn().Exec(ctx, "SELECT set_config('app.tenant_id', $1, false)", strconv.Itoa(c.tenantID))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// open opens a pool whose sessions are bound to tenantID, or to no tenant when it is 0
+func open(tenantID int) (*sql.DB, error) {
+	connConfig, err := pgx.ParseConfig(config.AppConfig.DBConn)
+	if err != nil {
+		return nil, err
+	}
+	if tenantID != 0 {
+		connConfig.RuntimeParams["app.tenant_id"] = strconv.Itoa(tenantID)
+	}
+	return stdlib.OpenDB(*connConfig), nil
+}
+
 func CloseDB() {
 	if DB != nil {
 		DB.Close()
 	}
+
+	tenantMu.Lock()
+	defer tenantMu.Unlock()
+	for _, db := range tenantDBs {
+		db.Close()
+	}
+	if tenantPool != nil {
+		tenantPool.Close()
+	}
 }
@@ -194,41 +194,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/health": {
-            "get": {
-                "description": "Check if the API is running properly",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Health"
-                ],
-                "summary": "Health check",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/main.HealthResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/products": {
+        "/customers": {
             "get": {
-                "description": "Get all products from the database, optionally filtered by name",
+                "description": "Get customers by name, optionally searching name, phone and email",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Customers"
                 ],
-                "summary": "Get all products",
+                "summary": "Get all customers",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Search by product name",
-                        "name": "name",
+                        "description": "Search text",
+                        "name": "q",
                         "in": "query"
                     }
                 ],
@@ -238,14 +218,14 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Product"
+                                "$ref": "#/definitions/models.Customer"
                             }
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a new product",
+                "description": "Create a new customer. Phone numbers are unique and stored without formatting",
                 "consumes": [
                     "application/json"
                 ],
@@ -253,17 +233,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Customers"
                 ],
-                "summary": "Create product",
+                "summary": "Create customer",
                 "parameters": [
                     {
-                        "description": "Product data",
-                        "name": "product",
+                        "description": "Customer data",
+                        "name": "customer",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.ProductRequest"
+                            "$ref": "#/definitions/models.CustomerRequest"
                         }
                     }
                 ],
@@ -271,26 +251,61 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "$ref": "#/definitions/models.Customer"
                         }
                     }
                 }
             }
         },
-        "/products/{id}": {
+        "/customers/lookup": {
             "get": {
-                "description": "Get a product by its ID",
+                "description": "Find a customer by phone number, written with or without country code and formatting",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Customers"
                 ],
-                "summary": "Get product by ID",
+                "summary": "Find customer by phone",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Phone number",
+                        "name": "phone",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Customer"
+                        }
+                    },
+                    "404": {
+                        "description": "Customer not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}": {
+            "get": {
+                "description": "Get a customer by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "Get customer by ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Product ID",
+                        "description": "Customer ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -300,11 +315,11 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "$ref": "#/definitions/models.Customer"
                         }
                     },
                     "404": {
-                        "description": "Product not found",
+                        "description": "Customer not found",
                         "schema": {
                             "type": "string"
                         }
@@ -312,7 +327,7 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "Update a product by its ID",
+                "description": "Update a customer by its ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -320,24 +335,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Customers"
                 ],
-                "summary": "Update product",
+                "summary": "Update customer",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Product ID",
+                        "description": "Customer ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Product data",
-                        "name": "product",
+                        "description": "Customer data",
+                        "name": "customer",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.ProductRequest"
+                            "$ref": "#/definitions/models.CustomerRequest"
                         }
                     }
                 ],
@@ -345,24 +360,24 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "$ref": "#/definitions/models.Customer"
                         }
                     }
                 }
             },
             "delete": {
-                "description": "Delete a product by its ID",
+                "description": "Delete a customer, their past transactions are kept as anonymous sales.\nA customer who still owes on account cannot be deleted.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Customers"
                 ],
-                "summary": "Delete product",
+                "summary": "Delete customer",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Product ID",
+                        "description": "Customer ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -381,17 +396,182 @@ const docTemplate = `{
                 }
             }
         },
-        "/reports": {
+        "/customers/{id}/account": {
             "get": {
-                "description": "Get sales report filtered by start_date and end_date (YYYY-MM-DD)",
+                "description": "Get a customer's credit limit, outstanding balance, available credit and the open\non-account transactions with their age in days, oldest first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Reports"
+                    "Customers"
                 ],
-                "summary": "Get sales report with custom date range",
+                "summary": "Get customer account",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CustomerAccount"
+                        }
+                    },
+                    "404": {
+                        "description": "Customer not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}/payments": {
+            "post": {
+                "description": "Record a full or partial repayment of a customer's account. It settles the oldest\nopen transactions first and cannot exceed the outstanding balance.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "Record account payment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payment data",
+                        "name": "payment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CustomerPaymentRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Who receives the payment",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.CustomerPayment"
+                        }
+                    },
+                    "404": {
+                        "description": "Customer not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}/points": {
+            "get": {
+                "description": "Get a customer's points balance, its value, the next points to expire and the points ledger.\nPoints past their expiry date are expired first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "Get customer loyalty points",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LoyaltyAccount"
+                        }
+                    },
+                    "404": {
+                        "description": "Customer not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}/profile": {
+            "get": {
+                "description": "Get a customer with lifetime spend, visit count, first and last visit and the full purchase history",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "Get customer profile",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CustomerProfile"
+                        }
+                    },
+                    "404": {
+                        "description": "Customer not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}/statement": {
+            "get": {
+                "description": "Get a customer's on-account purchases, payments and refunds between start_date and end_date (YYYY-MM-DD)\nwith the opening balance, a running balance and the closing balance",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "Get customer statement",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "string",
                         "description": "Start Date (YYYY-MM-DD)",
@@ -411,35 +591,56 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SalesReport"
+                            "$ref": "#/definitions/models.CustomerStatement"
+                        }
+                    },
+                    "404": {
+                        "description": "Customer not found",
+                        "schema": {
+                            "type": "string"
                         }
                     }
                 }
             }
         },
-        "/reports/today": {
+        "/dine-in-orders": {
             "get": {
-                "description": "Get total revenue, total transactions, and best seller for today",
+                "description": "Get dine-in orders, newest first. Lists the orders occupying tables unless a status is given.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Reports"
+                    "Dine-In Orders"
+                ],
+                "summary": "Get dine-in orders",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Status (open, sent_to_kitchen, served, billed, paid, cancelled, merged)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default all outlets)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    }
                 ],
-                "summary": "Get sales report for today",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SalesReport"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DineInOrder"
+                            }
                         }
                     }
                 }
-            }
-        },
-        "/transactions": {
+            },
             "post": {
-                "description": "Create a new transaction with multiple items",
+                "description": "Seat a new order at one or more free tables of an outlet",
                 "consumes": [
                     "application/json"
                 ],
@@ -447,69 +648,7112 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Transactions"
+                    "Dine-In Orders"
                 ],
-                "summary": "Create transaction (checkout)",
+                "summary": "Open dine-in order",
                 "parameters": [
                     {
-                        "description": "Checkout data",
-                        "name": "checkout",
+                        "description": "Tables and guests",
+                        "name": "order",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CheckoutRequest"
+                            "$ref": "#/definitions/models.DineInOrderRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Waiter opening the order",
+                        "name": "X-Actor",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Transaction"
+                            "$ref": "#/definitions/models.DineInOrder"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "main.HealthResponse": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string"
-                },
-                "status": {
-                    "type": "string"
+        },
+        "/dine-in-orders/{id}": {
+            "get": {
+                "description": "Get a dine-in order with its tables, items by round, bills and payments",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dine-In Orders"
+                ],
+                "summary": "Get dine-in order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DineInOrder"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/dine-in-orders/{id}/bill": {
+            "post": {
+                "description": "Check out the order's items as transactions, taking them out of stock. Without bills all items\ngo on one bill, otherwise every item must be on one bill to split it by item. Each bill takes\nthe customer, price list, points, gift card and on-account options of a regular checkout.\nBills are pending, left out of the reports, until payments cover them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dine-In Orders"
+                ],
+                "summary": "Bill order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Bill split",
+                        "name": "bill",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.DineInBillRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cashier billing the order",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DineInOrder"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/dine-in-orders/{id}/cancel": {
+            "post": {
+                "description": "Cancel an order and free its tables. A billed order that is not paid is voided: its bills are\ngiven back like a refund, returning their stock, points and gift card balances. Payments taken\ntowards them stay listed on the order, to be handed back.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dine-In Orders"
+                ],
+                "summary": "Cancel order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Who cancels the order",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DineInOrder"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/dine-in-orders/{id}/items": {
+            "post": {
+                "description": "Add items to an order that is not billed yet. The order is open until they are sent to the kitchen.\nNo stock is taken until the order is billed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dine-In Orders"
+                ],
+                "summary": "Add a round of items",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Items",
+                        "name": "items",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DineInItemsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DineInOrder"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/dine-in-orders/{id}/merge": {
+            "post": {
+                "description": "Merge another order that is not billed yet, with its items and tables, into this order",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dine-In Orders"
+                ],
+                "summary": "Merge orders",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Order to merge",
+                        "name": "merge",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DineInMergeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DineInOrder"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/dine-in-orders/{id}/move": {
+            "post": {
+                "description": "Move an order that is not billed yet to other free tables of its outlet",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dine-In Orders"
+                ],
+                "summary": "Move order to other tables",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New tables",
+                        "name": "tables",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DineInTablesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DineInOrder"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/dine-in-orders/{id}/payments": {
+            "post": {
+                "description": "Pay part or all of what is left of a billed order, so guests can split the bill by amount.\nThe order and its bills are paid once the balance is 0.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dine-In Orders"
+                ],
+                "summary": "Pay order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payment",
+                        "name": "payment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DineInPaymentRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cashier taking the payment",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DineInOrder"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/dine-in-orders/{id}/send": {
+            "post": {
+                "description": "Send the items of an open order that were not sent yet to the kitchen",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dine-In Orders"
+                ],
+                "summary": "Send order to the kitchen",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DineInOrder"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/dine-in-orders/{id}/serve": {
+            "post": {
+                "description": "Mark the items sent to the kitchen as served",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dine-In Orders"
+                ],
+                "summary": "Mark order served",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DineInOrder"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/draft-orders": {
+            "get": {
+                "description": "Get draft orders, most recently saved first. Lists open drafts unless another status is given.\nDrafts past their expiry time are expired first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Draft Orders"
+                ],
+                "summary": "Get parked carts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Status (open, converted, expired)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default all outlets)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DraftOrder"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Save a cart as a draft order without taking its items out of stock.\nItem units default to the product's sale unit.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Draft Orders"
+                ],
+                "summary": "Park a cart",
+                "parameters": [
+                    {
+                        "description": "Draft order data",
+                        "name": "draft_order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DraftOrderRequest"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default the default outlet)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cashier parking the cart",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.DraftOrder"
+                        }
+                    }
+                }
+            }
+        },
+        "/draft-orders/{id}": {
+            "get": {
+                "description": "Get a draft order with its items to resume it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Draft Orders"
+                ],
+                "summary": "Get parked cart",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Draft order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DraftOrder"
+                        }
+                    },
+                    "404": {
+                        "description": "Draft order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the items and details of an open draft order, which restarts its expiry",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Draft Orders"
+                ],
+                "summary": "Update parked cart",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Draft order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Draft order data",
+                        "name": "draft_order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DraftOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DraftOrder"
+                        }
+                    },
+                    "404": {
+                        "description": "Draft order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a draft order that has not been checked out",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Draft Orders"
+                ],
+                "summary": "Discard parked cart",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Draft order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Draft order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/draft-orders/{id}/checkout": {
+            "post": {
+                "description": "Convert an open draft order into a transaction at its outlet. Stock is taken now, and the\nbody gives the payment options of a regular checkout. Items come from the draft, the draft's\ncustomer and price list apply unless the body gives others.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Draft Orders"
+                ],
+                "summary": "Check out parked cart",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Draft order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payment options",
+                        "name": "checkout",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.CheckoutRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cashier checking out",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    },
+                    "404": {
+                        "description": "Draft order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/events": {
+            "get": {
+                "description": "Stream events such as stock.low as Server-Sent Events. Each message has the event ID,\nthe event type and the JSON encoded event as data.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Events"
+                ],
+                "summary": "Stream events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma separated event types to receive, * matches one token and a final \u003e the rest, e.g. transaction.* (default all)",
+                        "name": "type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/events.Event"
+                        }
+                    }
+                }
+            }
+        },
+        "/gift-cards": {
+            "get": {
+                "description": "Get gift cards and vouchers, newest first, optionally filtered by status. They are sold at checkout",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Gift Cards"
+                ],
+                "summary": "Get all gift cards",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Status (active, redeemed, void)",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.GiftCard"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/gift-cards/{code}": {
+            "get": {
+                "description": "Get a gift card or voucher with its balance ledger",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Gift Cards"
+                ],
+                "summary": "Get gift card by code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Gift card code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GiftCard"
+                        }
+                    },
+                    "404": {
+                        "description": "Gift card not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/gift-cards/{code}/balance": {
+            "get": {
+                "description": "Get the balance and expiry of a gift card or voucher, and whether it can pay a checkout now",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Gift Cards"
+                ],
+                "summary": "Check gift card balance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Gift card code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GiftCardBalance"
+                        }
+                    },
+                    "404": {
+                        "description": "Gift card not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/goods-receipts": {
+            "get": {
+                "description": "Get the receipts history, newest first, optionally filtered by supplier or product",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Goods Receipts"
+                ],
+                "summary": "Get all goods receipts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Supplier ID",
+                        "name": "supplier_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "product_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.GoodsReceipt"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Record a delivery from a supplier, optionally against a sent purchase order. Stock is\nincremented through the stock ledger and each product's cost becomes the weighted\naverage of the stock on hand and the delivery. Lines with a batch_number are tracked as a\nbatch with an optional expiry_date.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Goods Receipts"
+                ],
+                "summary": "Receive goods",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User receiving the goods",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Outlet receiving the goods (default the default outlet)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Goods receipt data",
+                        "name": "goods_receipt",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.GoodsReceiptRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.GoodsReceipt"
+                        }
+                    }
+                }
+            }
+        },
+        "/goods-receipts/{id}": {
+            "get": {
+                "description": "Get a goods receipt with its lines and the resulting average costs",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Goods Receipts"
+                ],
+                "summary": "Get goods receipt by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Goods receipt ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GoodsReceipt"
+                        }
+                    },
+                    "404": {
+                        "description": "Goods receipt not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Check if the API is running properly",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/kitchen-items/{id}/bump": {
+            "post": {
+                "description": "Take a kitchen item off the station's screen once it has been picked up",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Kitchen"
+                ],
+                "summary": "Bump item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Kitchen item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.KitchenItem"
+                        }
+                    },
+                    "404": {
+                        "description": "Kitchen item not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/kitchen-items/{id}/ready": {
+            "post": {
+                "description": "Mark a kitchen item ready to be picked up, which stops its timers",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Kitchen"
+                ],
+                "summary": "Mark item ready",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Kitchen item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.KitchenItem"
+                        }
+                    },
+                    "404": {
+                        "description": "Kitchen item not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/kitchen-items/{id}/recall": {
+            "post": {
+                "description": "Bring a bumped kitchen item back on the station's screen as ready",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Kitchen"
+                ],
+                "summary": "Recall item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Kitchen item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.KitchenItem"
+                        }
+                    },
+                    "404": {
+                        "description": "Kitchen item not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/kitchen-items/{id}/start": {
+            "post": {
+                "description": "Mark a new kitchen item as being prepared, which starts its preparation timer",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Kitchen"
+                ],
+                "summary": "Start preparing item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Kitchen item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.KitchenItem"
+                        }
+                    },
+                    "404": {
+                        "description": "Kitchen item not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/kitchen-stations": {
+            "get": {
+                "description": "Get kitchen stations with the categories they prepare",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Kitchen"
+                ],
+                "summary": "Get kitchen stations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.KitchenStation"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a station preparing the products of some categories, such as a bar for drinks.\nEvery category goes to at most one station, products of other categories skip the kitchen.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Kitchen"
+                ],
+                "summary": "Create kitchen station",
+                "parameters": [
+                    {
+                        "description": "Station data",
+                        "name": "station",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.KitchenStationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.KitchenStation"
+                        }
+                    }
+                }
+            }
+        },
+        "/kitchen-stations/{id}": {
+            "get": {
+                "description": "Get a kitchen station with the categories it prepares",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Kitchen"
+                ],
+                "summary": "Get kitchen station",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Station ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.KitchenStation"
+                        }
+                    },
+                    "404": {
+                        "description": "Station not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Rename a station and replace the categories it prepares. Items already on its queue stay there.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Kitchen"
+                ],
+                "summary": "Update kitchen station",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Station ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Station data",
+                        "name": "station",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.KitchenStationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.KitchenStation"
+                        }
+                    },
+                    "404": {
+                        "description": "Station not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a station with nothing left on its queue",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Kitchen"
+                ],
+                "summary": "Delete kitchen station",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Station ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Station not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/kitchen-stations/{id}/queue": {
+            "get": {
+                "description": "Get the items a station has to prepare, oldest first, with how long they have waited and\nbeen in preparation. Lists the items not bumped yet unless a status is given.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Kitchen"
+                ],
+                "summary": "Get station queue",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Station ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Status (new, preparing, ready, bumped)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default all outlets)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.KitchenItem"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Station not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/kitchen-stations/{id}/stream": {
+            "get": {
+                "description": "Stream a station's queue as Server-Sent Events for a kitchen display. The first event,\nkitchen.queue, carries the items not bumped yet, then a kitchen.item event follows whenever\nan item reaches the station or changes status.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Kitchen"
+                ],
+                "summary": "Stream station queue",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Station ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default all outlets)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/events.Event"
+                        }
+                    },
+                    "404": {
+                        "description": "Station not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/outlets": {
+            "get": {
+                "description": "Get all outlets",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Outlets"
+                ],
+                "summary": "Get all outlets",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Outlet"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new outlet. Marking it as default takes the flag from the current default outlet",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Outlets"
+                ],
+                "summary": "Create outlet",
+                "parameters": [
+                    {
+                        "description": "Outlet data",
+                        "name": "outlet",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OutletRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Outlet"
+                        }
+                    }
+                }
+            }
+        },
+        "/outlets/{id}": {
+            "get": {
+                "description": "Get an outlet by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Outlets"
+                ],
+                "summary": "Get outlet by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Outlet"
+                        }
+                    },
+                    "404": {
+                        "description": "Outlet not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update an outlet by its ID. The default outlet stays the default until another outlet is made the default",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Outlets"
+                ],
+                "summary": "Update outlet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Outlet data",
+                        "name": "outlet",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OutletRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Outlet"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete an outlet that holds no stock. The default outlet cannot be deleted",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Outlets"
+                ],
+                "summary": "Delete outlet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/outlets/{id}/prices": {
+            "put": {
+                "description": "Override product prices at an outlet. A null price removes the override so the product price applies again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Outlets"
+                ],
+                "summary": "Set outlet prices",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Price overrides",
+                        "name": "prices",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.OutletPriceRequest"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.OutletStock"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/outlets/{id}/stock": {
+            "get": {
+                "description": "Get the stock and selling price of every product at an outlet",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Outlets"
+                ],
+                "summary": "Get outlet stock",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.OutletStock"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/payments": {
+            "get": {
+                "description": "Get gateway payments, newest first, with the sales they pay",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Get payments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Status (pending, paid, expired, cancelled, failed)",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Payment"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/callback": {
+            "post": {
+                "description": "Notification posted by the payment gateway. A paid payment completes its sale, an expired\nor failed one gives it back. Repeated notifications are harmless.\nThe mock gateway takes a payments.MockNotification signed in the X-Callback-Signature header,\nand refuses every notification while PAYMENT_CALLBACK_SECRET is not set.\nIn multi-tenant mode gateways post to /payments/callback/{tenant_id} without an API key.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Payment gateway callback",
+                "parameters": [
+                    {
+                        "description": "Notification (mock gateway)",
+                        "name": "notification",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payments.MockNotification"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "sha256= and the hex HMAC-SHA256 of the body with PAYMENT_CALLBACK_SECRET (mock gateway)",
+                        "name": "X-Callback-Signature",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Payment"
+                        }
+                    },
+                    "401": {
+                        "description": "Notification not authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Payment not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "PAYMENT_CALLBACK_SECRET is not set",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/qris": {
+            "post": {
+                "description": "Record a sale like POST /transactions and create a dynamic QRIS for what is left to pay.\nThe sale is pending: its stock is reserved, but it is left out of the reports and sent to\nthe kitchen only once the gateway notifies the payment. Unpaid, it is given back when the\nQRIS expires. Sales on account and gift cards cannot be paid by QRIS.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Check out with a QRIS payment",
+                "parameters": [
+                    {
+                        "description": "Checkout data",
+                        "name": "checkout",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CheckoutRequest"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default the default outlet)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Who records the sale",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Payment"
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/{id}": {
+            "get": {
+                "description": "Get a payment with its QRIS and the sale it pays, for polling its status",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Get payment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Payment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Payment"
+                        }
+                    },
+                    "404": {
+                        "description": "Payment not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/{id}/cancel": {
+            "post": {
+                "description": "Cancel a pending payment at the gateway. The sale is given back and its stock released.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Cancel payment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Payment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Who cancels the payment",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Payment"
+                        }
+                    },
+                    "404": {
+                        "description": "Payment not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/{id}/qr.png": {
+            "get": {
+                "description": "Get the QRIS of a pending payment as a PNG image for the customer to scan",
+                "produces": [
+                    "image/png"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Get payment QR code",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Payment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Pixels per QR code module, 1 to 32 (default 8)",
+                        "name": "scale",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Payment not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/price-lists": {
+            "get": {
+                "description": "Get all price lists without their prices",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Price Lists"
+                ],
+                "summary": "Get all price lists",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PriceList"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a price list with per-product prices and quantity-break tiers.\nItem units default to the product's sale unit and min_quantity to 1",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Price Lists"
+                ],
+                "summary": "Create price list",
+                "parameters": [
+                    {
+                        "description": "Price list data",
+                        "name": "price_list",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PriceListRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.PriceList"
+                        }
+                    }
+                }
+            }
+        },
+        "/price-lists/{id}": {
+            "get": {
+                "description": "Get a price list with its prices",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Price Lists"
+                ],
+                "summary": "Get price list by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Price list ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PriceList"
+                        }
+                    },
+                    "404": {
+                        "description": "Price list not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update a price list, its items replace all its prices",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Price Lists"
+                ],
+                "summary": "Update price list",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Price list ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Price list data",
+                        "name": "price_list",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PriceListRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PriceList"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a price list, transactions it priced keep their prices",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Price Lists"
+                ],
+                "summary": "Delete price list",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Price list ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products": {
+            "get": {
+                "description": "Get all products from the database, optionally filtered by name.\nstock is the stock at the selected outlet, or the total over all outlets without one.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Get all products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search by product name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default all outlets)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Product"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new product, or a bundle when components are given",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Create product",
+                "parameters": [
+                    {
+                        "description": "Product data",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductRequest"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default the default outlet)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/low-stock": {
+            "get": {
+                "description": "Get products whose stock is at or below their min_stock, with the quantity to reorder",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Get low-stock products",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default all outlets)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LowStockItem"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}": {
+            "get": {
+                "description": "Get a product by its ID. stock is the stock at the selected outlet, or the total over all outlets without one.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Get product by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default all outlets)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update a product by its ID. Sending components turns it into a bundle, once no outlet holds its stock; components left out are kept and an empty list un-bundles it.\nstock can be left out; when sent it has to match the stock at the selected outlet, stock changes go through /products/{id}/adjustments.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Update product",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Product data",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductRequest"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default all outlets)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "stock differs from the current stock",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a product by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Delete product",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/adjustments": {
+            "post": {
+                "description": "Record a stock adjustment (signed quantity) or waste (positive quantity lost) with a reason. The actor is taken from the X-Actor header",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Adjust product stock",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User performing the adjustment",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default the default outlet)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Adjustment data",
+                        "name": "adjustment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.StockAdjustmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.StockMovement"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/batches": {
+            "get": {
+                "description": "Get the batches of a product with their expiry dates, first-expired-first-out.\nUsed up batches are only listed with all=true.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Get product batches",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include used up batches",
+                        "name": "all",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default all outlets)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ProductBatch"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/cost-history": {
+            "get": {
+                "description": "Get the cost price history of a product, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Get product cost history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ProductCost"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/movements": {
+            "get": {
+                "description": "Get the stock ledger of a product with the running balance after each movement, optionally filtered by start_date and end_date (YYYY-MM-DD).\nbalance_after is the selected outlet's balance, or without an outlet the running total over all outlets.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Get product stock movements",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start Date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End Date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default all outlets)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.StockMovement"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/purchase-orders": {
+            "get": {
+                "description": "Get all purchase orders, newest first, optionally filtered by status",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Purchase Orders"
+                ],
+                "summary": "Get all purchase orders",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Status (draft, sent, partially_received, received, cancelled)",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PurchaseOrder"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a draft purchase order. Line units default to the product's purchase unit",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Purchase Orders"
+                ],
+                "summary": "Create purchase order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User creating the order",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Purchase order data",
+                        "name": "purchase_order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PurchaseOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.PurchaseOrder"
+                        }
+                    }
+                }
+            }
+        },
+        "/purchase-orders/{id}": {
+            "get": {
+                "description": "Get a purchase order with its lines and received quantities",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Purchase Orders"
+                ],
+                "summary": "Get purchase order by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Purchase order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PurchaseOrder"
+                        }
+                    },
+                    "404": {
+                        "description": "Purchase order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the supplier, notes and lines of a draft purchase order",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Purchase Orders"
+                ],
+                "summary": "Update purchase order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Purchase order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Purchase order data",
+                        "name": "purchase_order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PurchaseOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PurchaseOrder"
+                        }
+                    }
+                }
+            }
+        },
+        "/purchase-orders/{id}/cancel": {
+            "post": {
+                "description": "Cancel a draft or sent purchase order that has not received any goods",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Purchase Orders"
+                ],
+                "summary": "Cancel purchase order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Purchase order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PurchaseOrder"
+                        }
+                    }
+                }
+            }
+        },
+        "/purchase-orders/{id}/pdf": {
+            "get": {
+                "description": "Download a printable PDF of the purchase order",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "Purchase Orders"
+                ],
+                "summary": "Export purchase order as PDF",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Purchase order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
+        "/purchase-orders/{id}/send": {
+            "post": {
+                "description": "Mark a draft purchase order as sent to the supplier",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Purchase Orders"
+                ],
+                "summary": "Send purchase order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Purchase order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PurchaseOrder"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports": {
+            "get": {
+                "description": "Get sales report filtered by start_date and end_date (YYYY-MM-DD)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Get sales report with custom date range",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start Date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End Date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default all outlets)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SalesReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/categories": {
+            "get": {
+                "description": "Get revenue, COGS, gross profit and margin per category between start_date and end_date (YYYY-MM-DD)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Get sales per category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start Date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End Date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default all outlets)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CategorySales"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/expiring": {
+            "get": {
+                "description": "Get batches with stock left that expire within the given number of days (default 30),\nincluding batches already past their expiry date, valued at cost",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Get expiring stock",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Days ahead (default 30)",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default all outlets)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ExpiringStock"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/live": {
+            "get": {
+                "description": "Stream today's revenue, transaction count, average basket and best sellers as Server-Sent Events.\nThe first dashboard.metrics event is sent on connect, another follows every sale and refund\nand one at midnight for the new day.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Stream live sales dashboard",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default all outlets)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DashboardMetrics"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/live/ws": {
+            "get": {
+                "description": "Upgrade to a WebSocket receiving the same dashboard.metrics events as /reports/live, one JSON\nencoded event per text message. Messages sent by the client are ignored. Browsers may only\nconnect from the server's own origin or one listed in DASHBOARD_ORIGINS.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Live sales dashboard over WebSocket",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default all outlets)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "$ref": "#/definitions/models.DashboardMetrics"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/outlets": {
+            "get": {
+                "description": "Get revenue, transactions, COGS, gross profit and margin per outlet between start_date and end_date (YYYY-MM-DD)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Get sales per outlet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start Date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End Date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.OutletSales"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/products": {
+            "get": {
+                "description": "Get quantity sold, revenue, COGS, gross profit and margin per product between start_date and end_date (YYYY-MM-DD). Bundles are reported as their components with allocated revenue",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Get sales per product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start Date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End Date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default all outlets)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ProductSales"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/receivables": {
+            "get": {
+                "description": "Get what customers owe on account split by the age of the open transactions:\n0-30, 31-60 and over 60 days, customers owing the most first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Get receivables aging report",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReceivablesAging"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/reorder": {
+            "get": {
+                "description": "Suggest what to order from the average daily sales over a window: days of cover left,\nsafety stock, reorder point and suggested quantity. Parameters left out use the configured defaults.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Get reorder suggestions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Days of sales history",
+                        "name": "window_days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Supplier lead time in days",
+                        "name": "lead_time_days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Days of sales kept as safety stock",
+                        "name": "safety_days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Days of sales an order should last",
+                        "name": "cover_days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include products that need no order",
+                        "name": "all",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default) or csv",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default all outlets)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ReorderSuggestion"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/today": {
+            "get": {
+                "description": "Get total revenue, total transactions, COGS, gross profit, margin and best seller for today",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Get sales report for today",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default all outlets)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SalesReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-takes": {
+            "get": {
+                "description": "Get all stock take (stock opname) sessions, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stock Takes"
+                ],
+                "summary": "Get all stock takes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.StockTake"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Open a count session for all products or only the given categories. Only one stock take can be open per outlet at a time",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stock Takes"
+                ],
+                "summary": "Open stock take",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User opening the count",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Outlet to count (default the default outlet)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Stock take scope",
+                        "name": "stock_take",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.StockTakeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.StockTake"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-takes/{id}": {
+            "get": {
+                "description": "Get a stock take with its lines and variances valued at cost",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stock Takes"
+                ],
+                "summary": "Get stock take by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Stock take ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StockTake"
+                        }
+                    },
+                    "404": {
+                        "description": "Stock take not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-takes/{id}/approve": {
+            "post": {
+                "description": "Post the variance of every counted product to the stock ledger as adjustments, atomically",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stock Takes"
+                ],
+                "summary": "Approve stock take",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Stock take ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User approving the count",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StockTake"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-takes/{id}/cancel": {
+            "post": {
+                "description": "Cancel an open stock take without changing stock",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stock Takes"
+                ],
+                "summary": "Cancel stock take",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Stock take ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StockTake"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-takes/{id}/counts": {
+            "post": {
+                "description": "Submit counts by product ID or barcode. With add the quantity is added to the previous count (barcode scans), otherwise it replaces it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stock Takes"
+                ],
+                "summary": "Submit counted quantities",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Stock take ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Counted quantities",
+                        "name": "counts",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.StockCountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StockTake"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-transfers": {
+            "get": {
+                "description": "Get stock transfers newest first, optionally filtered by status, outlet or product.\nWith a product_id each transfer only lists that product's line, giving its transfer history.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stock Transfers"
+                ],
+                "summary": "Get all stock transfers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "requested, in_transit, received or cancelled",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "product_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only transfers from or to this outlet",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.StockTransfer"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Request stock from a source outlet (e.g. the warehouse) for a destination outlet. Quantities are in the product's base unit",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stock Transfers"
+                ],
+                "summary": "Request stock transfer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User requesting the transfer",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Transfer request",
+                        "name": "stock_transfer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.StockTransferRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.StockTransfer"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-transfers/in-transit": {
+            "get": {
+                "description": "Get the stock dispatched but not yet received, per product and destination outlet",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stock Transfers"
+                ],
+                "summary": "Get stock in transit",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only transfers from or to this outlet",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.InTransitStock"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-transfers/{id}": {
+            "get": {
+                "description": "Get a stock transfer with requested, dispatched and received quantities and the dispatched batches",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stock Transfers"
+                ],
+                "summary": "Get stock transfer by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Stock transfer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StockTransfer"
+                        }
+                    },
+                    "404": {
+                        "description": "Stock transfer not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-transfers/{id}/cancel": {
+            "post": {
+                "description": "Cancel a transfer that has not been dispatched yet",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stock Transfers"
+                ],
+                "summary": "Cancel stock transfer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Stock transfer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StockTransfer"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-transfers/{id}/dispatch": {
+            "post": {
+                "description": "Take the stock out of the source outlet and put it in transit. Lines left out ship the\nrequested quantity, a line may ship less when the source does not have it all",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stock Transfers"
+                ],
+                "summary": "Dispatch stock transfer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Stock transfer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User dispatching the goods",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Dispatched quantities",
+                        "name": "dispatch",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.TransferDispatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StockTransfer"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-transfers/{id}/receive": {
+            "post": {
+                "description": "Book the arrived stock into the destination outlet. Lines left out are received as\ndispatched; receiving a different quantity records a discrepancy and needs a reason",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stock Transfers"
+                ],
+                "summary": "Receive stock transfer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Stock transfer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User receiving the goods",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Received quantities",
+                        "name": "receive",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.TransferReceiveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StockTransfer"
+                        }
+                    }
+                }
+            }
+        },
+        "/suppliers": {
+            "get": {
+                "description": "Get all suppliers",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Suppliers"
+                ],
+                "summary": "Get all suppliers",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Supplier"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new supplier",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Suppliers"
+                ],
+                "summary": "Create supplier",
+                "parameters": [
+                    {
+                        "description": "Supplier data",
+                        "name": "supplier",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SupplierRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Supplier"
+                        }
+                    }
+                }
+            }
+        },
+        "/suppliers/{id}": {
+            "get": {
+                "description": "Get a supplier by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Suppliers"
+                ],
+                "summary": "Get supplier by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Supplier ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Supplier"
+                        }
+                    },
+                    "404": {
+                        "description": "Supplier not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update a supplier by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Suppliers"
+                ],
+                "summary": "Update supplier",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Supplier ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Supplier data",
+                        "name": "supplier",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SupplierRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Supplier"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a supplier by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Suppliers"
+                ],
+                "summary": "Delete supplier",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Supplier ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/tables": {
+            "get": {
+                "description": "Get dining tables by area and name with whether an order occupies them",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tables"
+                ],
+                "summary": "Get all tables",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Area",
+                        "name": "area",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default all outlets)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Table"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a dining table to the outlet. Table names are unique per outlet",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tables"
+                ],
+                "summary": "Create table",
+                "parameters": [
+                    {
+                        "description": "Table data",
+                        "name": "table",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TableRequest"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default the default outlet)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Table"
+                        }
+                    }
+                }
+            }
+        },
+        "/tables/{id}": {
+            "get": {
+                "description": "Get a dining table with the order occupying it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tables"
+                ],
+                "summary": "Get table by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Table ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Table"
+                        }
+                    },
+                    "404": {
+                        "description": "Table not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update a dining table's name, area, seats and whether it is in use",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tables"
+                ],
+                "summary": "Update table",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Table ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Table data",
+                        "name": "table",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TableRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Table"
+                        }
+                    },
+                    "404": {
+                        "description": "Table not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a dining table that no order occupies",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tables"
+                ],
+                "summary": "Delete table",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Table ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Table not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tenants": {
+            "get": {
+                "description": "Get all tenants of a multi-tenant deployment. Requires the admin API key",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tenants"
+                ],
+                "summary": "Get all tenants",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Tenant"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a tenant with a default outlet. The response carries the tenant's API key,\nwhich is only shown once. Requires the admin API key",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tenants"
+                ],
+                "summary": "Provision tenant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Tenant data",
+                        "name": "tenant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TenantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tenant"
+                        }
+                    }
+                }
+            }
+        },
+        "/tenants/{id}": {
+            "get": {
+                "description": "Get a tenant with its settings. Requires the admin API key",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tenants"
+                ],
+                "summary": "Get tenant by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Tenant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tenant"
+                        }
+                    },
+                    "404": {
+                        "description": "Tenant not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update a tenant's name, status and settings. Suspended tenants are refused. Requires the admin API key",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tenants"
+                ],
+                "summary": "Update tenant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Tenant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tenant data",
+                        "name": "tenant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TenantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tenant"
+                        }
+                    }
+                }
+            }
+        },
+        "/tenants/{id}/api-key": {
+            "post": {
+                "description": "Issue a new API key for the tenant, the previous key stops working. Requires the admin API key",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tenants"
+                ],
+                "summary": "Rotate tenant API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Tenant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tenant"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions": {
+            "post": {
+                "description": "Create a new transaction with multiple items. Attach a customer with customer_id or customer_phone,\nthe customer earns loyalty points and can pay part of the total with redeem_points\nItems are priced from the price list chosen by price_list_id, customer_group or the customer's group\ngift_cards sells gift cards and vouchers, gift_card_payments pays with them\non_account charges what is left to pay to the customer's account within their credit limit",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Create transaction (checkout)",
+                "parameters": [
+                    {
+                        "description": "Checkout data",
+                        "name": "checkout",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CheckoutRequest"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID (default the default outlet)",
+                        "name": "X-Outlet-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "A quantity or amount is out of range",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{id}/refund": {
+            "post": {
+                "description": "Refund a whole transaction. The stock returns to the outlet and its batches, loyalty points\nearned with the sale are reversed and points paid with are given back.\nThe unpaid part of an on-account sale is written off the customer's account.\nRefunded transactions are left out of the reports.\nSales awaiting a QRIS payment cannot be refunded, their payment is cancelled instead.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Refund transaction",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Refund reason",
+                        "name": "refund",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.RefundRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Who refunds the transaction",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    },
+                    "404": {
+                        "description": "Transaction not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhook-deliveries/{id}": {
+            "get": {
+                "description": "Get a webhook delivery with its payload and the outcome of its last attempt",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get webhook delivery",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDelivery"
+                        }
+                    },
+                    "404": {
+                        "description": "Delivery not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhook-deliveries/{id}/redeliver": {
+            "post": {
+                "description": "Send a delivery again now, whatever its status, with the same payload and a fresh signature.\nA failed delivery gets one more attempt, a pending one carries on with its retries.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Redeliver webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDelivery"
+                        }
+                    },
+                    "404": {
+                        "description": "Delivery not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "description": "Get webhook subscriptions, without their secrets",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get webhook subscriptions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookSubscription"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Subscribe a URL to events (transaction.created, transaction.refunded, product.created,\nproduct.updated, product.deleted, stock.low), all of them when event_types is empty.\nEach delivery is a POST of the JSON event signed in the X-Webhook-Signature header.\nThe secret is generated unless given and is only returned here.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Create webhook subscription",
+                "parameters": [
+                    {
+                        "description": "Subscription data",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookSubscription"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "description": "Get a webhook subscription, without its secret",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get webhook subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookSubscription"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update a webhook subscription. The secret is kept unless a new one is given.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Update webhook subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Subscription data",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookSubscription"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a webhook subscription with its delivery log",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Delete webhook subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "Get the 100 most recent deliveries of a subscription with their payloads and attempt outcomes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get webhook delivery log",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Status (pending, delivered, failed)",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/test": {
+            "post": {
+                "description": "Send a webhook.test event to the subscription, even when inactive, and return the delivery",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Test webhook subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDelivery"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "events.Event": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "id": {
+                    "type": "string"
+                },
+                "occurred_at": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.WelcomeResponse": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "docs": {
+                    "type": "string"
+                },
+                "endpoints": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BatchAllocation": {
+            "type": "object",
+            "properties": {
+                "batch_id": {
+                    "type": "integer"
+                },
+                "batch_number": {
+                    "type": "string"
+                },
+                "expired": {
+                    "type": "boolean"
+                },
+                "expiry_date": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.BestSeller": {
+            "type": "object",
+            "properties": {
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.BundleComponent": {
+            "type": "object",
+            "properties": {
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.Category": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CategoryRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CategorySales": {
+            "type": "object",
+            "properties": {
+                "category_id": {
+                    "type": "integer"
+                },
+                "category_name": {
+                    "type": "string"
+                },
+                "cogs": {
+                    "type": "integer"
+                },
+                "gross_profit": {
+                    "type": "integer"
+                },
+                "margin_percent": {
+                    "type": "number"
+                },
+                "revenue": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CheckoutItem": {
+            "type": "object",
+            "properties": {
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CheckoutRequest": {
+            "type": "object",
+            "properties": {
+                "customer_group": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "integer"
+                },
+                "customer_phone": {
+                    "type": "string"
+                },
+                "gift_card_payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GiftCardPayment"
+                    }
+                },
+                "gift_cards": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GiftCardIssueRequest"
+                    }
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CheckoutItem"
+                    }
+                },
+                "on_account": {
+                    "type": "boolean"
+                },
+                "price_list_id": {
+                    "type": "integer"
+                },
+                "redeem_points": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Customer": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "credit_limit": {
+                    "type": "integer"
+                },
+                "customer_group": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CustomerAccount": {
+            "type": "object",
+            "properties": {
+                "available_credit": {
+                    "type": "integer"
+                },
+                "credit_limit": {
+                    "type": "integer"
+                },
+                "customer_id": {
+                    "type": "integer"
+                },
+                "customer_name": {
+                    "type": "string"
+                },
+                "open_invoices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OpenInvoice"
+                    }
+                },
+                "outstanding": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CustomerAging": {
+            "type": "object",
+            "properties": {
+                "credit_limit": {
+                    "type": "integer"
+                },
+                "customer_id": {
+                    "type": "integer"
+                },
+                "customer_name": {
+                    "type": "string"
+                },
+                "days_0_30": {
+                    "type": "integer"
+                },
+                "days_31_60": {
+                    "type": "integer"
+                },
+                "days_over_60": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CustomerPayment": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "allocations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PaymentAllocation"
+                    }
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "outstanding": {
+                    "description": "Outstanding is what the customer still owes after the payment",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CustomerPaymentRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CustomerProfile": {
+            "type": "object",
+            "properties": {
+                "average_spend": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "credit_limit": {
+                    "type": "integer"
+                },
+                "customer_group": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "first_visit": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_visit": {
+                    "type": "string"
+                },
+                "lifetime_spend": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "purchases": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Transaction"
+                    }
+                },
+                "visit_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CustomerRequest": {
+            "type": "object",
+            "properties": {
+                "credit_limit": {
+                    "type": "integer"
+                },
+                "customer_group": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CustomerStatement": {
+            "type": "object",
+            "properties": {
+                "closing_balance": {
+                    "type": "integer"
+                },
+                "customer_id": {
+                    "type": "integer"
+                },
+                "customer_name": {
+                    "type": "string"
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StatementLine"
+                    }
+                },
+                "opening_balance": {
+                    "type": "integer"
+                },
+                "start_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DashboardMetrics": {
+            "type": "object",
+            "properties": {
+                "average_basket": {
+                    "type": "integer"
+                },
+                "best_sellers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductSales"
+                    }
+                },
+                "date": {
+                    "type": "string"
+                },
+                "gross_profit": {
+                    "type": "integer"
+                },
+                "margin_percent": {
+                    "type": "number"
+                },
+                "outlet_id": {
+                    "type": "integer"
+                },
+                "revenue": {
+                    "type": "integer"
+                },
+                "transactions": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DineInBill": {
+            "type": "object",
+            "properties": {
+                "customer_group": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "integer"
+                },
+                "customer_phone": {
+                    "type": "string"
+                },
+                "gift_card_payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GiftCardPayment"
+                    }
+                },
+                "item_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "on_account": {
+                    "type": "boolean"
+                },
+                "price_list_id": {
+                    "type": "integer"
+                },
+                "redeem_points": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.DineInBillRequest": {
+            "type": "object",
+            "properties": {
+                "bills": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DineInBill"
+                    }
+                }
+            }
+        },
+        "models.DineInItemRequest": {
+            "type": "object",
+            "properties": {
+                "notes": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DineInItemsRequest": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DineInItemRequest"
+                    }
+                }
+            }
+        },
+        "models.DineInMergeRequest": {
+            "type": "object",
+            "properties": {
+                "order_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.DineInOrder": {
+            "type": "object",
+            "properties": {
+                "amount_due": {
+                    "type": "integer"
+                },
+                "amount_paid": {
+                    "type": "integer"
+                },
+                "balance": {
+                    "type": "integer"
+                },
+                "billed_at": {
+                    "type": "string"
+                },
+                "bills": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Transaction"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "guests": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DineInOrderItem"
+                    }
+                },
+                "merged_into": {
+                    "type": "integer"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "opened_by": {
+                    "type": "string"
+                },
+                "outlet_id": {
+                    "type": "integer"
+                },
+                "paid_at": {
+                    "type": "string"
+                },
+                "payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DineInPayment"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                },
+                "tables": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DineInTable"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DineInOrderItem": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "round": {
+                    "type": "integer"
+                },
+                "sent_at": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DineInOrderRequest": {
+            "type": "object",
+            "properties": {
+                "guests": {
+                    "type": "integer"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "table_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "models.DineInPayment": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DineInPaymentRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DineInTable": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "table_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.DineInTablesRequest": {
+            "type": "object",
+            "properties": {
+                "table_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "models.DraftOrder": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "customer_group": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DraftOrderItem"
+                    }
+                },
+                "label": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "outlet_id": {
+                    "type": "integer"
+                },
+                "price_list_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DraftOrderItem": {
+            "type": "object",
+            "properties": {
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DraftOrderRequest": {
+            "type": "object",
+            "properties": {
+                "customer_group": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "integer"
+                },
+                "customer_phone": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CheckoutItem"
+                    }
+                },
+                "label": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "price_list_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ExpiringStock": {
+            "type": "object",
+            "properties": {
+                "batch_id": {
+                    "type": "integer"
+                },
+                "batch_number": {
+                    "type": "string"
+                },
+                "cost_value": {
+                    "type": "integer"
+                },
+                "days_to_expiry": {
+                    "type": "integer"
+                },
+                "expiry_date": {
+                    "type": "string"
+                },
+                "outlet_id": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.GiftCard": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "integer"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GiftCardEntry"
+                    }
+                },
+                "expired": {
+                    "type": "boolean"
+                },
+                "expiry_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "initial_amount": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.GiftCardBalance": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "integer"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "expired": {
+                    "type": "boolean"
+                },
+                "expiry_date": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "usable": {
+                    "description": "Usable is true when the card can pay a checkout now",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.GiftCardEntry": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "balance_after": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.GiftCardIssueRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "expiry_date": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.GiftCardPayment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.GiftCardRedemption": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "balance_after": {
+                    "type": "integer"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "gift_card_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GoodsReceipt": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GoodsReceiptLine"
+                    }
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "outlet_id": {
+                    "type": "integer"
+                },
+                "purchase_order_id": {
+                    "type": "integer"
+                },
+                "received_at": {
+                    "type": "string"
+                },
+                "received_by": {
+                    "type": "string"
+                },
+                "reference": {
+                    "type": "string"
+                },
+                "supplier_id": {
+                    "type": "integer"
+                },
+                "supplier_name": {
+                    "type": "string"
+                },
+                "total_cost": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GoodsReceiptLine": {
+            "type": "object",
+            "properties": {
+                "average_cost_after": {
+                    "type": "integer"
+                },
+                "base_quantity": {
+                    "type": "number"
+                },
+                "batch_id": {
+                    "type": "integer"
+                },
+                "batch_number": {
+                    "type": "string"
+                },
+                "expiry_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "purchase_order_line_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "subtotal": {
+                    "type": "integer"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "unit_cost": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GoodsReceiptLineRequest": {
+            "type": "object",
+            "properties": {
+                "batch_number": {
+                    "type": "string"
+                },
+                "expiry_date": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "purchase_order_line_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "unit_cost": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GoodsReceiptRequest": {
+            "type": "object",
+            "properties": {
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GoodsReceiptLineRequest"
+                    }
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "purchase_order_id": {
+                    "type": "integer"
+                },
+                "reference": {
+                    "type": "string"
+                },
+                "supplier_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.InTransitStock": {
+            "type": "object",
+            "properties": {
+                "destination_outlet_id": {
+                    "type": "integer"
+                },
+                "destination_outlet_name": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "source_outlet_id": {
+                    "type": "integer"
+                },
+                "transfers": {
+                    "type": "integer"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.KitchenItem": {
+            "type": "object",
+            "properties": {
+                "age_seconds": {
+                    "type": "integer"
+                },
+                "bumped_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "dine_in_order_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "outlet_id": {
+                    "type": "integer"
+                },
+                "prep_seconds": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "ready_at": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "station_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "tables": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.KitchenStation": {
+            "type": "object",
+            "properties": {
+                "category_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.KitchenStationRequest": {
+            "type": "object",
+            "properties": {
+                "category_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LowStockItem": {
+            "type": "object",
+            "properties": {
+                "min_stock": {
+                    "type": "number"
+                },
+                "outlet_id": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "reorder_quantity": {
+                    "type": "number"
+                },
+                "stock": {
+                    "type": "number"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LoyaltyAccount": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "integer"
+                },
+                "balance_value": {
+                    "type": "integer"
+                },
+                "customer_id": {
+                    "type": "integer"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LoyaltyEntry"
+                    }
+                },
+                "next_expiry": {
+                    "type": "string"
+                },
+                "next_expiry_points": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LoyaltyEntry": {
+            "type": "object",
+            "properties": {
+                "balance_after": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "points": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OpenInvoice": {
+            "type": "object",
+            "properties": {
+                "age_days": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "outstanding": {
+                    "type": "integer"
+                },
+                "paid": {
+                    "type": "integer"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Outlet": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_default": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OutletPriceRequest": {
+            "type": "object",
+            "properties": {
+                "price": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OutletRequest": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "is_default": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OutletSales": {
+            "type": "object",
+            "properties": {
+                "gross_profit": {
+                    "type": "integer"
+                },
+                "margin_percent": {
+                    "type": "number"
+                },
+                "outlet_id": {
+                    "type": "integer"
+                },
+                "outlet_name": {
+                    "type": "string"
+                },
+                "total_cogs": {
+                    "type": "integer"
+                },
+                "total_revenue": {
+                    "type": "integer"
+                },
+                "total_transactions": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OutletStock": {
+            "type": "object",
+            "properties": {
+                "effective_price": {
+                    "type": "integer"
+                },
+                "outlet_id": {
+                    "type": "integer"
+                },
+                "outlet_name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "stock": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Payment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "gateway": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "paid_at": {
+                    "type": "string"
+                },
+                "qris_payload": {
+                    "type": "string"
+                },
+                "reference": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "transaction": {
+                    "description": "Transaction is the sale being paid, as recorded at checkout",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    ]
+                },
+                "transaction_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PaymentAllocation": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PriceList": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "customer_group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PriceListItem"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PriceListItem": {
+            "type": "object",
+            "properties": {
+                "min_quantity": {
+                    "type": "number"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PriceListItemRequest": {
+            "type": "object",
+            "properties": {
+                "min_quantity": {
+                    "type": "number"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PriceListRequest": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "customer_group": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PriceListItemRequest"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Product": {
+            "type": "object",
+            "properties": {
+                "allow_fraction": {
+                    "type": "boolean"
+                },
+                "barcode": {
+                    "type": "string"
+                },
+                "category_id": {
+                    "type": "integer"
+                },
+                "category_name": {
+                    "type": "string"
+                },
+                "components": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BundleComponent"
+                    }
+                },
+                "cost_price": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_bundle": {
+                    "type": "boolean"
+                },
+                "min_stock": {
+                    "description": "MinStock is the reorder point, 0 disables low-stock alerts. ReorderQuantity is how much to order then.",
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "outlets": {
+                    "description": "Stock is the total over all outlets, Outlets breaks it down with each outlet's price",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OutletStock"
+                    }
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "purchase_unit": {
+                    "type": "string"
+                },
+                "reorder_quantity": {
+                    "type": "number"
+                },
+                "sale_unit": {
+                    "type": "string"
+                },
+                "stock": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "units": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductUnit"
+                    }
+                }
+            }
+        },
+        "models.ProductBatch": {
+            "type": "object",
+            "properties": {
+                "batch_number": {
+                    "type": "string"
+                },
+                "expired": {
+                    "type": "boolean"
+                },
+                "expiry_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "outlet_id": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "received_at": {
+                    "type": "string"
+                },
+                "received_quantity": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.ProductCost": {
+            "type": "object",
+            "properties": {
+                "cost_price": {
+                    "type": "integer"
+                },
+                "effective_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProductRequest": {
+            "type": "object",
+            "properties": {
+                "allow_fraction": {
+                    "type": "boolean"
+                },
+                "barcode": {
+                    "type": "string"
+                },
+                "category_id": {
+                    "type": "integer"
+                },
+                "components": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BundleComponent"
+                    }
+                },
+                "cost_price": {
+                    "type": "integer"
+                },
+                "min_stock": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "purchase_unit": {
+                    "type": "string"
+                },
+                "reorder_quantity": {
+                    "type": "number"
+                },
+                "sale_unit": {
+                    "type": "string"
+                },
+                "stock": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "units": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductUnit"
+                    }
+                }
+            }
+        },
+        "models.ProductSales": {
+            "type": "object",
+            "properties": {
+                "cogs": {
+                    "type": "integer"
+                },
+                "gross_profit": {
+                    "type": "integer"
+                },
+                "margin_percent": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "revenue": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProductUnit": {
+            "type": "object",
+            "properties": {
+                "factor": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PurchaseOrder": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expected_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PurchaseOrderLine"
+                    }
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "supplier_id": {
+                    "type": "integer"
+                },
+                "supplier_name": {
+                    "type": "string"
+                },
+                "total_amount": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PurchaseOrderLine": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "received_quantity": {
+                    "type": "number"
+                },
+                "subtotal": {
+                    "type": "integer"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "unit_cost": {
+                    "type": "integer"
+                },
+                "unit_factor": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.PurchaseOrderLineRequest": {
+            "type": "object",
+            "properties": {
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "unit_cost": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PurchaseOrderRequest": {
+            "type": "object",
+            "properties": {
+                "expected_date": {
+                    "type": "string"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PurchaseOrderLineRequest"
+                    }
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "supplier_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReceivablesAging": {
+            "type": "object",
+            "properties": {
+                "as_of": {
+                    "type": "string"
+                },
+                "customers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CustomerAging"
+                    }
+                },
+                "days_0_30": {
+                    "type": "integer"
+                },
+                "days_31_60": {
+                    "type": "integer"
+                },
+                "days_over_60": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RefundRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReorderSuggestion": {
+            "type": "object",
+            "properties": {
+                "average_daily_sales": {
+                    "type": "number"
+                },
+                "days_of_cover": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "reorder_point": {
+                    "type": "number"
+                },
+                "safety_stock": {
+                    "type": "number"
+                },
+                "sold_quantity": {
+                    "type": "number"
+                },
+                "stock": {
+                    "type": "number"
+                },
+                "suggested_quantity": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SalesReport": {
+            "type": "object",
+            "properties": {
+                "best_seller": {
+                    "$ref": "#/definitions/models.BestSeller"
+                },
+                "gross_profit": {
+                    "type": "integer"
+                },
+                "margin_percent": {
+                    "type": "number"
+                },
+                "outlet_id": {
+                    "type": "integer"
+                },
+                "total_cogs": {
+                    "type": "integer"
+                },
+                "total_revenue": {
+                    "type": "integer"
+                },
+                "total_transactions": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.StatementLine": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "integer"
+                },
+                "credit": {
+                    "type": "integer"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "debit": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "reference_id": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.StockAdjustmentRequest": {
+            "type": "object",
+            "properties": {
+                "quantity": {
+                    "type": "number"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.StockCountItem": {
+            "type": "object",
+            "properties": {
+                "add": {
+                    "type": "boolean"
+                },
+                "barcode": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.StockCountRequest": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StockCountItem"
+                    }
+                }
+            }
+        },
+        "models.StockMovement": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "balance_after": {
+                    "type": "number"
+                },
+                "batches": {
+                    "description": "Batches lists the batches a decrease was taken from, first-expired-first-out",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BatchAllocation"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "outlet_id": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reference_id": {
+                    "type": "integer"
+                },
+                "reference_type": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.StockTake": {
+            "type": "object",
+            "properties": {
+                "approved_at": {
+                    "type": "string"
+                },
+                "approved_by": {
+                    "type": "string"
+                },
+                "counted_lines": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StockTakeLine"
+                    }
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "outlet_id": {
+                    "type": "integer"
+                },
+                "outlet_name": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "total_variance_value": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.StockTakeLine": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "type": "string"
+                },
+                "counted_at": {
+                    "type": "string"
+                },
+                "counted_quantity": {
+                    "type": "number"
+                },
+                "expected_quantity": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "unit_cost": {
+                    "type": "integer"
+                },
+                "variance": {
+                    "type": "number"
+                },
+                "variance_value": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.StockTakeRequest": {
+            "type": "object",
+            "properties": {
+                "category_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "notes": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.StockTransfer": {
+            "type": "object",
+            "properties": {
+                "destination_outlet_id": {
+                    "type": "integer"
+                },
+                "destination_outlet_name": {
+                    "type": "string"
+                },
+                "dispatched_at": {
+                    "type": "string"
+                },
+                "dispatched_by": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StockTransferLine"
+                    }
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "received_at": {
+                    "type": "string"
+                },
+                "received_by": {
+                    "type": "string"
+                },
+                "requested_at": {
+                    "type": "string"
+                },
+                "requested_by": {
+                    "type": "string"
+                },
+                "source_outlet_id": {
+                    "type": "integer"
+                },
+                "source_outlet_name": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.StockTransferLine": {
+            "type": "object",
+            "properties": {
+                "batches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BatchAllocation"
+                    }
+                },
+                "discrepancy": {
+                    "type": "number"
+                },
+                "discrepancy_reason": {
+                    "type": "string"
+                },
+                "dispatched_quantity": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "received_quantity": {
+                    "type": "number"
+                },
+                "requested_quantity": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.StockTransferLineRequest": {
+            "type": "object",
+            "properties": {
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.StockTransferRequest": {
+            "type": "object",
+            "properties": {
+                "destination_outlet_id": {
+                    "type": "integer"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StockTransferLineRequest"
+                    }
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "source_outlet_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Supplier": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "contact_name": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SupplierRequest": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "contact_name": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Table": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "area": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "outlet_id": {
+                    "type": "integer"
+                },
+                "seats": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TableRequest": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "area": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "seats": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Tenant": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "api_key": {
+                    "description": "APIKey is only returned when the tenant is created or its key is rotated",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "settings": {
+                    "$ref": "#/definitions/models.TenantSettings"
+                },
+                "slug": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "main.WelcomeResponse": {
+        "models.TenantRequest": {
             "type": "object",
             "properties": {
-                "description": {
+                "active": {
+                    "type": "boolean"
+                },
+                "name": {
                     "type": "string"
                 },
-                "docs": {
+                "settings": {
+                    "$ref": "#/definitions/models.TenantSettings"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TenantSettings": {
+            "type": "object",
+            "properties": {
+                "draft_order_expiry_minutes": {
+                    "type": "integer"
+                },
+                "expired_sale_policy": {
                     "type": "string"
                 },
-                "endpoints": {
+                "gift_card_expiry_days": {
+                    "type": "integer"
+                },
+                "low_stock_webhook_secret": {
+                    "description": "LowStockWebhookSecret signs the posts to the tenant's LowStockWebhookURL, the deployment's\nsecret is never sent to a tenant's receiver",
+                    "type": "string"
+                },
+                "low_stock_webhook_url": {
+                    "type": "string"
+                },
+                "loyalty_excluded_categories": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "type": "integer"
                     }
                 },
-                "name": {
+                "loyalty_expiry_days": {
+                    "type": "integer"
+                },
+                "loyalty_point_value": {
+                    "type": "integer"
+                },
+                "loyalty_rounding": {
                     "type": "string"
                 },
-                "version": {
+                "loyalty_spend_per_point": {
+                    "description": "Loyalty settings, LoyaltyExcludedCategories replaces the configured list when given",
+                    "type": "integer"
+                },
+                "reorder_cover_days": {
+                    "type": "integer"
+                },
+                "reorder_lead_time_days": {
+                    "type": "integer"
+                },
+                "reorder_safety_days": {
+                    "type": "integer"
+                },
+                "reorder_window_days": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Transaction": {
+            "type": "object",
+            "properties": {
+                "amount_due": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "integer"
+                },
+                "details": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TransactionDetail"
+                    }
+                },
+                "gift_card_amount": {
+                    "type": "integer"
+                },
+                "gift_card_payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GiftCardRedemption"
+                    }
+                },
+                "gift_cards_sold": {
+                    "description": "GiftCardsSold is the part of the total spent on the IssuedGiftCards",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "issued_gift_cards": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GiftCard"
+                    }
+                },
+                "kitchen_items": {
+                    "description": "KitchenItems are the items sent to kitchen stations to prepare",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.KitchenItem"
+                    }
+                },
+                "low_stock": {
+                    "description": "LowStock lists products whose stock fell to or below their minimum with this sale",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LowStockItem"
+                    }
+                },
+                "on_account_amount": {
+                    "type": "integer"
+                },
+                "outlet_id": {
+                    "type": "integer"
+                },
+                "payment_status": {
+                    "description": "PaymentStatus is paid unless the sale waits for a QRIS payment or, as the bill of a dine-in\norder, for the table to pay (pending), or its items were put back when a QRIS payment expired,\nwas cancelled or failed",
                     "type": "string"
+                },
+                "points_amount": {
+                    "type": "integer"
+                },
+                "points_earned": {
+                    "type": "integer"
+                },
+                "points_redeemed": {
+                    "description": "PointsRedeemed paid PointsAmount of the total and GiftCardPayments paid GiftCardAmount,\nOnAccountAmount was charged to the customer's account and AmountDue is left for other tenders.\nPointsEarned are credited to the customer for this sale once it is paid.",
+                    "type": "integer"
+                },
+                "price_list_id": {
+                    "type": "integer"
+                },
+                "refund_reason": {
+                    "type": "string"
+                },
+                "refunded_at": {
+                    "description": "RefundedAt is set once the transaction is refunded",
+                    "type": "string"
+                },
+                "total_amount": {
+                    "type": "integer"
+                },
+                "warnings": {
+                    "description": "Warnings lists expired batches that were sold when the expired sale policy is \"warn\"",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "models.BestSeller": {
+        "models.TransactionDetail": {
             "type": "object",
             "properties": {
+                "base_quantity": {
+                    "type": "number"
+                },
+                "batches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BatchAllocation"
+                    }
+                },
+                "cogs": {
+                    "type": "integer"
+                },
+                "components": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TransactionDetailComponent"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "price_list_id": {
+                    "type": "integer"
+                },
                 "product_id": {
                     "type": "integer"
                 },
@@ -517,151 +7761,196 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "quantity": {
+                    "type": "number"
+                },
+                "subtotal": {
+                    "type": "integer"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "unit_cost": {
+                    "type": "integer"
+                },
+                "unit_price": {
                     "type": "integer"
                 }
             }
         },
-        "models.Category": {
+        "models.TransactionDetailComponent": {
             "type": "object",
             "properties": {
-                "description": {
-                    "type": "string"
+                "allocated_amount": {
+                    "type": "integer"
                 },
-                "id": {
+                "batches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BatchAllocation"
+                    }
+                },
+                "cogs": {
                     "type": "integer"
                 },
-                "name": {
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_name": {
                     "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "unit_cost": {
+                    "type": "integer"
                 }
             }
         },
-        "models.CategoryRequest": {
+        "models.TransferDispatchRequest": {
             "type": "object",
             "properties": {
-                "description": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StockTransferLineRequest"
+                    }
                 }
             }
         },
-        "models.CheckoutItem": {
+        "models.TransferReceiveLineRequest": {
             "type": "object",
             "properties": {
                 "product_id": {
                     "type": "integer"
                 },
                 "quantity": {
-                    "type": "integer"
+                    "type": "number"
+                },
+                "reason": {
+                    "type": "string"
                 }
             }
         },
-        "models.CheckoutRequest": {
+        "models.TransferReceiveRequest": {
             "type": "object",
             "properties": {
-                "items": {
+                "lines": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.CheckoutItem"
+                        "$ref": "#/definitions/models.TransferReceiveLineRequest"
                     }
                 }
             }
         },
-        "models.Product": {
+        "models.WebhookDelivery": {
             "type": "object",
             "properties": {
-                "category_id": {
+                "attempts": {
                     "type": "integer"
                 },
-                "category_name": {
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "event_type": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "name": {
+                "last_error": {
                     "type": "string"
                 },
-                "price": {
+                "last_status_code": {
                     "type": "integer"
                 },
-                "stock": {
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "subscription_id": {
                     "type": "integer"
                 }
             }
         },
-        "models.ProductRequest": {
+        "models.WebhookSubscription": {
             "type": "object",
             "properties": {
-                "category_id": {
-                    "type": "integer"
+                "active": {
+                    "type": "boolean"
                 },
-                "name": {
+                "created_at": {
                     "type": "string"
                 },
-                "price": {
-                    "type": "integer"
+                "description": {
+                    "type": "string"
                 },
-                "stock": {
-                    "type": "integer"
-                }
-            }
-        },
-        "models.SalesReport": {
-            "type": "object",
-            "properties": {
-                "best_seller": {
-                    "$ref": "#/definitions/models.BestSeller"
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "total_revenue": {
+                "id": {
                     "type": "integer"
                 },
-                "total_transactions": {
-                    "type": "integer"
+                "secret": {
+                    "description": "Secret is only returned when the subscription is created",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
                 }
             }
         },
-        "models.Transaction": {
+        "models.WebhookSubscriptionRequest": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "active": {
+                    "type": "boolean"
+                },
+                "description": {
                     "type": "string"
                 },
-                "details": {
+                "event_types": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.TransactionDetail"
+                        "type": "string"
                     }
                 },
-                "id": {
-                    "type": "integer"
+                "secret": {
+                    "type": "string"
                 },
-                "total_amount": {
-                    "type": "integer"
+                "url": {
+                    "type": "string"
                 }
             }
         },
-        "models.TransactionDetail": {
+        "payments.MockNotification": {
             "type": "object",
             "properties": {
-                "id": {
-                    "type": "integer"
-                },
-                "product_id": {
+                "amount": {
                     "type": "integer"
                 },
-                "product_name": {
+                "reference": {
                     "type": "string"
                 },
-                "quantity": {
-                    "type": "integer"
-                },
-                "subtotal": {
-                    "type": "integer"
-                },
-                "transaction_id": {
-                    "type": "integer"
+                "status": {
+                    "type": "string",
+                    "example": "paid"
                 }
             }
         }
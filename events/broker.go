@@ -0,0 +1,164 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// brokerQueue is how many events a subscription holds while its consumer is busy
+const brokerQueue = 256
+
+// brokerSeen is how many recent event IDs are remembered per subscription or queue group, so an
+// event relayed again is not queued twice for those that took it already
+const brokerSeen = 1024
+
+// Broker is a local message broker in the manner of NATS. An event is published on its type as the
+// subject and handed to every subscription whose pattern matches it, with the wildcards of Match.
+// Subscriptions in the same queue group share the events, each going to one member of the group.
+// Every subscription has a queue of its own consumed in the background, so a slow or failing
+// subscriber holds up neither the publisher nor the other subscribers; a consumer retries its own
+// failures. Publish only fails for the subscriptions whose queue is full, and relayed from the outbox
+// the event is then published again later to those alone. Events still queued when the server stops
+// are lost, the webhook subscriptions are the durable way to receive them.
+type Broker struct {
+	mu     sync.Mutex
+	subs   map[int]*subscription
+	nextID int
+	// turn rotates the events of a queue group over its members
+	turn map[string]int
+	// seen holds the events recently queued per subscription or queue group
+	seen map[string]*recentIDs
+}
+
+type subscription struct {
+	pattern string
+	queue   string
+	events  chan Event
+	done    chan struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{subs: make(map[int]*subscription), turn: make(map[string]int), seen: make(map[string]*recentIDs)}
+}
+
+// Subscribe hands the events whose type matches pattern to consume until unsubscribe is called
+func (b *Broker) Subscribe(pattern string, consume Consumer) (unsubscribe func()) {
+	return b.QueueSubscribe(pattern, "", consume)
+}
+
+// QueueSubscribe subscribes consume as a member of queue, the members of a queue group taking turns
+// at the events they match. An empty queue subscribes on its own. consume is called from a goroutine
+// of the subscription, one event at a time; an error it returns is logged.
+func (b *Broker) QueueSubscribe(pattern, queue string, consume Consumer) (unsubscribe func()) {
+	sub := &subscription{pattern: pattern, queue: queue, events: make(chan Event, brokerQueue), done: make(chan struct{})}
+
+	b.mu.Lock()
+	b.nextID++
+	id := b.nextID
+	b.subs[id] = sub
+	b.mu.Unlock()
+
+	go sub.run(consume)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, id)
+			delete(b.seen, "#"+strconv.Itoa(id))
+			b.mu.Unlock()
+			close(sub.done)
+		})
+	}
+}
+
+// Publish queues an event for its subscribers, one member per queue group, skipping those that
+// queued it already. It fails for the subscriptions whose queue is full. It is a Consumer, so the
+// broker can be a sink of the outbox dispatcher.
+func (b *Broker) Publish(e Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	targets := make(map[string]*subscription)
+	groups := make(map[string][]int)
+	for _, id := range slices.Sorted(maps.Keys(b.subs)) {
+		sub := b.subs[id]
+		if !Match(sub.pattern, e.Type) {
+			continue
+		}
+		if sub.queue == "" {
+			targets["#"+strconv.Itoa(id)] = sub
+			continue
+		}
+		groups[sub.queue] = append(groups[sub.queue], id)
+	}
+	for queue, members := range groups {
+		if b.seen[queue].has(e.ID) {
+			continue
+		}
+		turn := b.turn[queue]
+		b.turn[queue] = turn + 1
+		targets[queue] = b.subs[members[turn%len(members)]]
+	}
+
+	var errs []error
+	for _, key := range slices.Sorted(maps.Keys(targets)) {
+		seen := b.seen[key]
+		if seen.has(e.ID) {
+			continue
+		}
+		sub := targets[key]
+		select {
+		case sub.events <- e:
+			if seen == nil {
+				seen = &recentIDs{ids: make(map[string]bool)}
+				b.seen[key] = seen
+			}
+			seen.add(e.ID)
+		default:
+			errs = append(errs, fmt.Errorf("subscription to %s is %d events behind", sub.pattern, brokerQueue))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// run hands the queued events to consume until the subscription ends
+func (s *subscription) run(consume Consumer) {
+	for {
+		select {
+		case <-s.done:
+			return
+		case e := <-s.events:
+			if err := consume(e); err != nil {
+				log.Printf("broker: %s: event %s %s: %v", s.pattern, e.Type, e.ID, err)
+			}
+		}
+	}
+}
+
+// recentIDs remembers the last brokerSeen event IDs added
+type recentIDs struct {
+	ids   map[string]bool
+	order []string
+}
+
+func (r *recentIDs) has(id string) bool {
+	return r != nil && id != "" && r.ids[id]
+}
+
+func (r *recentIDs) add(id string) {
+	if id == "" {
+		return
+	}
+	if len(r.order) == brokerSeen {
+		delete(r.ids, r.order[0])
+		r.order = r.order[1:]
+	}
+	r.ids[id] = true
+	r.order = append(r.order, id)
+}
@@ -0,0 +1,189 @@
+package events
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		eventType string
+		want      bool
+	}{
+		{"stock.low", "stock.low", true},
+		{"stock.low", "stock.high", false},
+		{"stock", "stock.low", false},
+		{"transaction.*", "transaction.created", true},
+		{"transaction.*", "transaction", false},
+		{"transaction.*", "transaction.created.late", false},
+		{"*.created", "product.created", true},
+		{"*.created", "product.deleted", false},
+		{">", "stock.low", true},
+		{"product.>", "product.created", true},
+		{"product.>", "product.a.b", true},
+		{"product.>", "product", false},
+		{"product.>.x", "product.a.x", false},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.eventType); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.eventType, got, tt.want)
+		}
+	}
+}
+
+// collect subscribes a consumer that passes the events it gets to a channel
+func collect(t *testing.T, subscribe func(Consumer) func()) <-chan Event {
+	ch := make(chan Event, 2*brokerQueue)
+	t.Cleanup(subscribe(func(e Event) error {
+		ch <- e
+		return nil
+	}))
+	return ch
+}
+
+// receive waits for n events on ch and then checks that no more arrive
+func receive(t *testing.T, name string, ch <-chan Event, n int) []Event {
+	t.Helper()
+	var got []Event
+	for len(got) < n {
+		select {
+		case e := <-ch:
+			got = append(got, e)
+		case <-time.After(time.Second):
+			t.Fatalf("%s: got %d events, want %d", name, len(got), n)
+		}
+	}
+	select {
+	case e := <-ch:
+		t.Fatalf("%s: got %d events and then %s, want %d", name, len(got), e.Type, n)
+	case <-time.After(20 * time.Millisecond):
+	}
+	return got
+}
+
+func TestBrokerPatterns(t *testing.T) {
+	b := NewBroker()
+	transactions := collect(t, func(c Consumer) func() { return b.Subscribe("transaction.*", c) })
+	all := collect(t, func(c Consumer) func() { return b.Subscribe(">", c) })
+	stock := collect(t, func(c Consumer) func() { return b.Subscribe(TypeStockLow, c) })
+
+	for _, eventType := range []string{TypeTransactionCreated, TypeProductCreated, TypeStockLow, TypeTransactionRefunded} {
+		if err := b.Publish(New(eventType, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := receive(t, "transaction.*", transactions, 2)
+	if got[0].Type != TypeTransactionCreated || got[1].Type != TypeTransactionRefunded {
+		t.Errorf("transaction.* got %s and %s", got[0].Type, got[1].Type)
+	}
+	receive(t, ">", all, 4)
+	if got := receive(t, TypeStockLow, stock, 1); got[0].Type != TypeStockLow {
+		t.Errorf("stock.low got %s", got[0].Type)
+	}
+}
+
+func TestBrokerQueueGroup(t *testing.T) {
+	b := NewBroker()
+	first := collect(t, func(c Consumer) func() { return b.QueueSubscribe("product.*", "indexer", c) })
+	second := collect(t, func(c Consumer) func() { return b.QueueSubscribe("product.*", "indexer", c) })
+	alone := collect(t, func(c Consumer) func() { return b.Subscribe("product.*", c) })
+
+	for range 4 {
+		if err := b.Publish(New(TypeProductUpdated, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The members take turns, the subscription on its own gets every event
+	receive(t, "first member", first, 2)
+	receive(t, "second member", second, 2)
+	receive(t, "own subscription", alone, 4)
+}
+
+func TestBrokerQueueGroupTakesRepeatOnce(t *testing.T) {
+	b := NewBroker()
+	first := collect(t, func(c Consumer) func() { return b.QueueSubscribe(">", "workers", c) })
+	second := collect(t, func(c Consumer) func() { return b.QueueSubscribe(">", "workers", c) })
+
+	e := New(TypeStockLow, nil)
+	for range 2 {
+		if err := b.Publish(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := len(receive(t, "first member", first, 1)) + len(receive(t, "second member", second, 0))
+	if got != 1 {
+		t.Fatalf("group got the event %d times, want once", got)
+	}
+}
+
+func TestBrokerIsolatesSubscribers(t *testing.T) {
+	b := NewBroker()
+	release := make(chan struct{})
+	defer close(release)
+
+	// A receiver that is down and one that hangs do not hold up the publisher or the others
+	t.Cleanup(b.Subscribe(TypeStockLow, func(Event) error { return errors.New("receiver is down") }))
+	t.Cleanup(b.Subscribe(TypeStockLow, func(Event) error {
+		<-release
+		return nil
+	}))
+	healthy := collect(t, func(c Consumer) func() { return b.Subscribe(TypeStockLow, c) })
+
+	for i := range 3 {
+		if err := b.Publish(New(TypeStockLow, i)); err != nil {
+			t.Fatalf("publish %d: %v", i, err)
+		}
+	}
+	receive(t, "healthy subscriber", healthy, 3)
+}
+
+func TestBrokerFullQueue(t *testing.T) {
+	b := NewBroker()
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	t.Cleanup(b.Subscribe(TypeStockLow, func(Event) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		return nil
+	}))
+	healthy := collect(t, func(c Consumer) func() { return b.Subscribe(TypeStockLow, c) })
+
+	// The hanging subscriber holds the first event and queues brokerQueue more, the next one fails
+	// for it alone. The healthy one takes each event before the next is published.
+	if err := b.Publish(New(TypeStockLow, 0)); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	<-healthy
+	for i := 1; i <= brokerQueue; i++ {
+		if err := b.Publish(New(TypeStockLow, i)); err != nil {
+			t.Fatalf("publish %d: %v", i, err)
+		}
+		<-healthy
+	}
+	overflow := New(TypeStockLow, brokerQueue+1)
+	err := b.Publish(overflow)
+	if err == nil {
+		t.Fatal("publish to the full queue did not fail")
+	}
+	receive(t, "healthy subscriber", healthy, 1)
+	close(release)
+
+	// Relayed again once the queue drains, the event only goes to the subscription that missed it
+	deadline := time.Now().Add(time.Second)
+	for err != nil && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		err = b.Publish(overflow)
+	}
+	if err != nil {
+		t.Fatalf("publish after the queue drained: %v", err)
+	}
+	receive(t, "healthy subscriber", healthy, 0)
+}
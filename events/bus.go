@@ -0,0 +1,156 @@
+// Package events is an in-process publish/subscribe bus for things that happen in the store,
+// such as a product running low on stock. Sinks (the log) receive every event, streams such as
+// Server-Sent Events subscribe with a channel. Events of sales and catalog changes are recorded in
+// the outbox with the change and reach the bus and the broker through its dispatcher.
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event types
+const (
+	TypeStockLow = "stock.low"
+	// TypeTransactionCreated and TypeTransactionRefunded carry the transaction once it is committed
+	TypeTransactionCreated  = "transaction.created"
+	TypeTransactionRefunded = "transaction.refunded"
+	// Product events carry the product, product.deleted only its ID
+	TypeProductCreated = "product.created"
+	TypeProductUpdated = "product.updated"
+	TypeProductDeleted = "product.deleted"
+	// TypeKitchenItem is published when a kitchen item reaches its station and whenever its status changes
+	TypeKitchenItem = "kitchen.item"
+	// TypeKitchenQueue opens a kitchen station's stream with the items on its queue
+	TypeKitchenQueue = "kitchen.queue"
+	// TypeDashboardMetrics carries today's sales figures on the live dashboard
+	TypeDashboardMetrics = "dashboard.metrics"
+	// TypeWebhookTest is sent to a webhook subscription on request to check the receiver
+	TypeWebhookTest = "webhook.test"
+)
+
+// WebhookTypes are the event types webhook subscriptions can receive
+var WebhookTypes = []string{
+	TypeTransactionCreated, TypeTransactionRefunded,
+	TypeProductCreated, TypeProductUpdated, TypeProductDeleted,
+	TypeStockLow,
+}
+
+// Event is something that happened, Data is the event specific payload
+type Event struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// New stamps an event with an ID and the current time
+func New(eventType string, data interface{}) Event {
+	return Event{
+		ID:         newID(),
+		Type:       eventType,
+		OccurredAt: time.Now(),
+		Data:       data,
+	}
+}
+
+// Sink receives every published event
+type Sink func(Event)
+
+// Consumer receives the events relayed from the outbox. An event it fails is relayed to it again
+// later, so a consumer may see an event more than once and can tell repeats by the event ID.
+type Consumer func(Event) error
+
+// subscriberBuffer is how many events a slow subscriber may fall behind before missing events
+const subscriberBuffer = 64
+
+// Bus delivers published events to its sinks and subscribers
+type Bus struct {
+	mu          sync.RWMutex
+	sinks       []Sink
+	subscribers map[chan Event]struct{}
+}
+
+func NewBus() *Bus {
+	return &Bus{subscribers: make(map[chan Event]struct{})}
+}
+
+// AddSink registers a sink. Sinks run in their own goroutine so a slow webhook never
+// holds up the request that published the event.
+func (b *Bus) AddSink(s Sink) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sinks = append(b.sinks, s)
+}
+
+// Publish delivers a new event
+func (b *Bus) Publish(eventType string, data interface{}) Event {
+	e := New(eventType, data)
+	b.deliver(e)
+	return e
+}
+
+// Consume delivers an event relayed from the outbox as it is, keeping its ID. It never fails,
+// subscribers that fall behind miss the event as they would a published one.
+func (b *Bus) Consume(e Event) error {
+	b.deliver(e)
+	return nil
+}
+
+func (b *Bus) deliver(e Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, s := range b.sinks {
+		go s(e)
+	}
+	for ch := range b.subscribers {
+		select {
+		case ch <- e:
+		default:
+			// The subscriber is not keeping up, drop rather than block the publisher
+		}
+	}
+}
+
+// Subscribe returns a channel receiving events published from now on,
+// and a function that ends the subscription
+func (b *Bus) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+		})
+	}
+}
+
+// Match reports whether an event type matches pattern, NATS style: the dot separated tokens must be
+// equal, "*" stands for any one token and a final ">" for one or more tokens. "transaction.*" matches
+// transaction.created and transaction.refunded, ">" matches every type.
+func Match(pattern, eventType string) bool {
+	p, t := strings.Split(pattern, "."), strings.Split(eventType, ".")
+	for i, token := range p {
+		if token == ">" && i == len(p)-1 {
+			return len(t) > i
+		}
+		if i >= len(t) || (token != "*" && token != t[i]) {
+			return false
+		}
+	}
+	return len(p) == len(t)
+}
+
+func newID() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,38 @@
+package events
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+)
+
+// LogSink writes every event to the standard logger as JSON
+func LogSink(e Event) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		log.Printf("event %s %s: %v", e.Type, e.ID, err)
+		return
+	}
+	log.Printf("event %s", data)
+}
+
+// Headers of signed webhook requests
+const (
+	HeaderWebhookEvent     = "X-Webhook-Event"
+	HeaderWebhookDelivery  = "X-Webhook-Delivery"
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+// SignPayload signs a webhook body sent at timestamp, in Unix seconds, with secret. The signature is
+// "sha256=" and the hex HMAC-SHA256 of the timestamp, a dot and the body, so a receiver can reject
+// old requests replayed with their signature.
+func SignPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
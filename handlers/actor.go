@@ -0,0 +1,13 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ActorHeader names the user or device performing a request, recorded in the stock ledger
+const ActorHeader = "X-Actor"
+
+func actorFromRequest(r *http.Request) string {
+	return strings.TrimSpace(r.Header.Get(ActorHeader))
+}
@@ -0,0 +1,425 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"kasir-api/models"
+	"kasir-api/services"
+)
+
+type CustomerHandler struct {
+	service           *services.CustomerService
+	loyaltyService    *services.LoyaltyService
+	receivableService *services.ReceivableService
+}
+
+func NewCustomerHandler(service *services.CustomerService, loyaltyService *services.LoyaltyService,
+	receivableService *services.ReceivableService) *CustomerHandler {
+	return &CustomerHandler{service: service, loyaltyService: loyaltyService, receivableService: receivableService}
+}
+
+// GetAll godoc
+// @Summary Get all customers
+// @Description Get customers by name, optionally searching name, phone and email
+// @Tags Customers
+// @Produce json
+// @Param q query string false "Search text"
+// @Success 200 {array} models.Customer
+// @Router /customers [get]
+func (h *CustomerHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	customers, err := h.service.GetAll(strings.TrimSpace(r.URL.Query().Get("q")))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(customers)
+}
+
+// Create godoc
+// @Summary Create customer
+// @Description Create a new customer. Phone numbers are unique and stored without formatting
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param customer body models.CustomerRequest true "Customer data"
+// @Success 201 {object} models.Customer
+// @Router /customers [post]
+func (h *CustomerHandler) Create(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeCustomerRequest(w, r)
+	if !ok {
+		return
+	}
+
+	customer, err := h.service.Create(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(customer)
+}
+
+// GetByID godoc
+// @Summary Get customer by ID
+// @Description Get a customer by its ID
+// @Tags Customers
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Success 200 {object} models.Customer
+// @Failure 404 {string} string "Customer not found"
+// @Router /customers/{id} [get]
+func (h *CustomerHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	customer, err := h.service.GetByID(id)
+	writeCustomerResult(w, customer, err)
+}
+
+// Lookup godoc
+// @Summary Find customer by phone
+// @Description Find a customer by phone number, written with or without country code and formatting
+// @Tags Customers
+// @Produce json
+// @Param phone query string true "Phone number"
+// @Success 200 {object} models.Customer
+// @Failure 404 {string} string "Customer not found"
+// @Router /customers/lookup [get]
+func (h *CustomerHandler) Lookup(w http.ResponseWriter, r *http.Request) {
+	phone := r.URL.Query().Get("phone")
+	if phone == "" {
+		http.Error(w, "phone is required", http.StatusBadRequest)
+		return
+	}
+
+	customer, err := h.service.GetByPhone(phone)
+	writeCustomerResult(w, customer, err)
+}
+
+// Update godoc
+// @Summary Update customer
+// @Description Update a customer by its ID
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Param customer body models.CustomerRequest true "Customer data"
+// @Success 200 {object} models.Customer
+// @Router /customers/{id} [put]
+func (h *CustomerHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	req, ok := decodeCustomerRequest(w, r)
+	if !ok {
+		return
+	}
+
+	customer, err := h.service.Update(id, req)
+	writeCustomerResult(w, customer, err)
+}
+
+// Delete godoc
+// @Summary Delete customer
+// @Description Delete a customer, their past transactions are kept as anonymous sales.
+// @Description A customer who still owes on account cannot be deleted.
+// @Tags Customers
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Success 200 {object} map[string]string
+// @Router /customers/{id} [delete]
+func (h *CustomerHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		writeCustomerResult(w, nil, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": fmt.Sprintf("Customer with ID %d deleted successfully", id),
+	})
+}
+
+// GetProfile godoc
+// @Summary Get customer profile
+// @Description Get a customer with lifetime spend, visit count, first and last visit and the full purchase history
+// @Tags Customers
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Success 200 {object} models.CustomerProfile
+// @Failure 404 {string} string "Customer not found"
+// @Router /customers/{id}/profile [get]
+func (h *CustomerHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	profile, err := h.service.GetProfile(id)
+	writeCustomerResult(w, profile, err)
+}
+
+// GetPoints godoc
+// @Summary Get customer loyalty points
+// @Description Get a customer's points balance, its value, the next points to expire and the points ledger.
+// @Description Points past their expiry date are expired first.
+// @Tags Customers
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Success 200 {object} models.LoyaltyAccount
+// @Failure 404 {string} string "Customer not found"
+// @Router /customers/{id}/points [get]
+func (h *CustomerHandler) GetPoints(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	account, err := h.loyaltyService.GetAccount(id)
+	writeCustomerResult(w, account, err)
+}
+
+// GetAccount godoc
+// @Summary Get customer account
+// @Description Get a customer's credit limit, outstanding balance, available credit and the open
+// @Description on-account transactions with their age in days, oldest first
+// @Tags Customers
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Success 200 {object} models.CustomerAccount
+// @Failure 404 {string} string "Customer not found"
+// @Router /customers/{id}/account [get]
+func (h *CustomerHandler) GetAccount(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	account, err := h.receivableService.GetAccount(id)
+	writeCustomerResult(w, account, err)
+}
+
+// RecordPayment godoc
+// @Summary Record account payment
+// @Description Record a full or partial repayment of a customer's account. It settles the oldest
+// @Description open transactions first and cannot exceed the outstanding balance.
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Param payment body models.CustomerPaymentRequest true "Payment data"
+// @Param X-Actor header string false "Who receives the payment"
+// @Success 201 {object} models.CustomerPayment
+// @Failure 404 {string} string "Customer not found"
+// @Router /customers/{id}/payments [post]
+func (h *CustomerHandler) RecordPayment(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.CustomerPaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Amount <= 0 {
+		http.Error(w, "amount must be positive", http.StatusBadRequest)
+		return
+	}
+	req.Method = strings.TrimSpace(req.Method)
+	req.Actor = actorFromRequest(r)
+
+	payment, err := h.receivableService.RecordPayment(id, req)
+	if err != nil {
+		writeCustomerResult(w, nil, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(payment)
+}
+
+// GetStatement godoc
+// @Summary Get customer statement
+// @Description Get a customer's on-account purchases, payments and refunds between start_date and end_date (YYYY-MM-DD)
+// @Description with the opening balance, a running balance and the closing balance
+// @Tags Customers
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Param start_date query string true "Start Date (YYYY-MM-DD)"
+// @Param end_date query string true "End Date (YYYY-MM-DD)"
+// @Success 200 {object} models.CustomerStatement
+// @Failure 404 {string} string "Customer not found"
+// @Router /customers/{id}/statement [get]
+func (h *CustomerHandler) GetStatement(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	startDate, endDate, ok := parseDateRange(w, r)
+	if !ok {
+		return
+	}
+
+	statement, err := h.receivableService.GetStatement(id, startDate, endDate)
+	writeCustomerResult(w, statement, err)
+}
+
+// GetReceivablesAging godoc
+// @Summary Get receivables aging report
+// @Description Get what customers owe on account split by the age of the open transactions:
+// @Description 0-30, 31-60 and over 60 days, customers owing the most first
+// @Tags Reports
+// @Produce json
+// @Success 200 {object} models.ReceivablesAging
+// @Router /reports/receivables [get]
+func (h *CustomerHandler) GetReceivablesAging(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	report, err := h.receivableService.GetAging()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// Handler routes requests to appropriate method handlers
+func (h *CustomerHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(r.URL.Path, "/")
+
+	switch {
+	case len(pathParts) == 2 || (len(pathParts) == 3 && pathParts[2] == ""):
+		switch r.Method {
+		case http.MethodGet:
+			h.GetAll(w, r)
+		case http.MethodPost:
+			h.Create(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	case len(pathParts) == 3 && pathParts[2] == "lookup":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.Lookup(w, r)
+	case len(pathParts) == 3:
+		switch r.Method {
+		case http.MethodGet:
+			h.GetByID(w, r)
+		case http.MethodPut:
+			h.Update(w, r)
+		case http.MethodDelete:
+			h.Delete(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	case len(pathParts) == 4 && pathParts[3] == "profile":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetProfile(w, r)
+	case len(pathParts) == 4 && pathParts[3] == "points":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetPoints(w, r)
+	case len(pathParts) == 4 && pathParts[3] == "account":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetAccount(w, r)
+	case len(pathParts) == 4 && pathParts[3] == "payments":
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.RecordPayment(w, r)
+	case len(pathParts) == 4 && pathParts[3] == "statement":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetStatement(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// decodeCustomerRequest reads and validates a customer body.
+// On failure it writes the error response and returns ok = false.
+func decodeCustomerRequest(w http.ResponseWriter, r *http.Request) (models.CustomerRequest, bool) {
+	var req models.CustomerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return req, false
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return req, false
+	}
+	if req.Email != "" {
+		if _, err := mail.ParseAddress(req.Email); err != nil {
+			http.Error(w, "Invalid email", http.StatusBadRequest)
+			return req, false
+		}
+	}
+	if req.CreditLimit < 0 {
+		http.Error(w, "credit_limit cannot be negative", http.StatusBadRequest)
+		return req, false
+	}
+	return req, true
+}
+
+func writeCustomerResult(w http.ResponseWriter, result interface{}, err error) {
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Customer not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
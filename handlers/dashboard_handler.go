@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"kasir-api/events"
+	"kasir-api/models"
+	"kasir-api/services"
+
+	"golang.org/x/net/websocket"
+)
+
+type DashboardHandler struct {
+	service *services.ReportService
+	bus     *events.Bus
+	// origins are the browser origins, besides the server's own, allowed to open the WebSocket
+	origins []string
+}
+
+func NewDashboardHandler(service *services.ReportService, bus *events.Bus, origins []string) *DashboardHandler {
+	return &DashboardHandler{service: service, bus: bus, origins: origins}
+}
+
+// Stream godoc
+// @Summary Stream live sales dashboard
+// @Description Stream today's revenue, transaction count, average basket and best sellers as Server-Sent Events.
+// @Description The first dashboard.metrics event is sent on connect, another follows every sale and refund
+// @Description and one at midnight for the new day.
+// @Tags Reports
+// @Produce text/event-stream
+// @Param X-Outlet-ID header int false "Outlet ID (default all outlets)"
+// @Success 200 {object} models.DashboardMetrics
+// @Router /reports/live [get]
+func (h *DashboardHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	snapshot, updates, err := h.live(r.Context(), outletID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	streamEvents(w, r, updates, func(events.Event) bool { return true }, snapshot)
+}
+
+// WebSocket godoc
+// @Summary Live sales dashboard over WebSocket
+// @Description Upgrade to a WebSocket receiving the same dashboard.metrics events as /reports/live, one JSON
+// @Description encoded event per text message. Messages sent by the client are ignored. Browsers may only
+// @Description connect from the server's own origin or one listed in DASHBOARD_ORIGINS.
+// @Tags Reports
+// @Produce json
+// @Param X-Outlet-ID header int false "Outlet ID (default all outlets)"
+// @Success 101 {object} models.DashboardMetrics
+// @Router /reports/live/ws [get]
+func (h *DashboardHandler) WebSocket(w http.ResponseWriter, r *http.Request) {
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// The connection is hijacked on upgrade, so its end is noticed by reading rather than through r
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	snapshot, updates, err := h.live(ctx, outletID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	server := websocket.Server{Handshake: h.checkOrigin, Handler: func(ws *websocket.Conn) {
+		go func() {
+			var msg string
+			for websocket.Message.Receive(ws, &msg) == nil {
+			}
+			cancel()
+		}()
+
+		if websocket.JSON.Send(ws, snapshot) != nil {
+			return
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e := <-updates:
+				if websocket.JSON.Send(ws, e) != nil {
+					return
+				}
+			}
+		}
+	}}
+	server.ServeHTTP(w, r)
+}
+
+// checkOrigin lets browsers open the WebSocket only from the server's own origin or an allowed one, so
+// other sites cannot read the dashboard through their visitors. Clients that are not browsers send no
+// Origin and are let through.
+func (h *DashboardHandler) checkOrigin(config *websocket.Config, r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return err
+	}
+	if u.Host != r.Host && !slices.Contains(h.origins, origin) {
+		return fmt.Errorf("origin %s is not allowed", origin)
+	}
+	config.Origin = u
+	return nil
+}
+
+// live returns today's metrics now and a channel receiving them again after every sale or refund
+// at the outlet and at midnight, when today becomes a new day, until ctx is done
+func (h *DashboardHandler) live(ctx context.Context, outletID int) (events.Event, <-chan events.Event, error) {
+	// Subscribe before the snapshot so that no sale goes missing in between
+	ch, unsubscribe := h.bus.Subscribe()
+	metrics, err := h.service.GetDashboard(outletID)
+	if err != nil {
+		unsubscribe()
+		return events.Event{}, nil, err
+	}
+
+	updates := make(chan events.Event, 1)
+	go func() {
+		defer unsubscribe()
+		midnight := time.NewTimer(untilMidnight(time.Now()))
+		defer midnight.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-midnight.C:
+				midnight.Reset(untilMidnight(time.Now()))
+			case e := <-ch:
+				if !saleAt(e, outletID) {
+					continue
+				}
+				// Let a burst of sales settle, the figures worked out next include all of them
+				time.Sleep(dashboardSettle)
+				for len(ch) > 0 {
+					<-ch
+				}
+			}
+
+			metrics, err := h.service.GetDashboard(outletID)
+			if err != nil {
+				log.Printf("dashboard: %v", err)
+				continue
+			}
+			select {
+			case updates <- events.New(events.TypeDashboardMetrics, *metrics):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events.New(events.TypeDashboardMetrics, *metrics), updates, nil
+}
+
+// untilMidnight is the wait from now until the next day starts in local time, when today's figures start over
+func untilMidnight(now time.Time) time.Duration {
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location()).Sub(now)
+}
+
+// dashboardSettle lets a burst of sales, such as the bills of a dine-in order, land in one update
+const dashboardSettle = 250 * time.Millisecond
+
+// saleAt reports whether e is a sale or refund at the outlet, any outlet when outletID is 0
+func saleAt(e events.Event, outletID int) bool {
+	if e.Type != events.TypeTransactionCreated && e.Type != events.TypeTransactionRefunded {
+		return false
+	}
+	t, ok := e.Data.(models.Transaction)
+	return ok && (outletID == 0 || t.OutletID == outletID)
+}
@@ -0,0 +1,421 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"kasir-api/models"
+	"kasir-api/services"
+)
+
+type DineInOrderHandler struct {
+	service *services.DineInOrderService
+}
+
+func NewDineInOrderHandler(service *services.DineInOrderService) *DineInOrderHandler {
+	return &DineInOrderHandler{service: service}
+}
+
+// GetAll godoc
+// @Summary Get dine-in orders
+// @Description Get dine-in orders, newest first. Lists the orders occupying tables unless a status is given.
+// @Tags Dine-In Orders
+// @Produce json
+// @Param status query string false "Status (open, sent_to_kitchen, served, billed, paid, cancelled, merged)"
+// @Param X-Outlet-ID header int false "Outlet ID (default all outlets)"
+// @Success 200 {array} models.DineInOrder
+// @Router /dine-in-orders [get]
+func (h *DineInOrderHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.DineInOpen, models.DineInSent, models.DineInServed, models.DineInBilled, models.DineInPaid,
+		models.DineInCancelled, models.DineInMerged:
+	default:
+		http.Error(w, "Invalid status", http.StatusBadRequest)
+		return
+	}
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	orders, err := h.service.GetAll(status, outletID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(orders)
+}
+
+// Open godoc
+// @Summary Open dine-in order
+// @Description Seat a new order at one or more free tables of an outlet
+// @Tags Dine-In Orders
+// @Accept json
+// @Produce json
+// @Param order body models.DineInOrderRequest true "Tables and guests"
+// @Param X-Actor header string false "Waiter opening the order"
+// @Success 201 {object} models.DineInOrder
+// @Router /dine-in-orders [post]
+func (h *DineInOrderHandler) Open(w http.ResponseWriter, r *http.Request) {
+	var req models.DineInOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.TableIDs) == 0 {
+		http.Error(w, "table_ids is required", http.StatusBadRequest)
+		return
+	}
+	if req.Guests < 0 {
+		http.Error(w, "guests cannot be negative", http.StatusBadRequest)
+		return
+	}
+	req.Actor = actorFromRequest(r)
+
+	order, err := h.service.Open(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(order)
+}
+
+// GetByID godoc
+// @Summary Get dine-in order
+// @Description Get a dine-in order with its tables, items by round, bills and payments
+// @Tags Dine-In Orders
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} models.DineInOrder
+// @Failure 404 {string} string "Order not found"
+// @Router /dine-in-orders/{id} [get]
+func (h *DineInOrderHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.service.GetByID(id)
+	writeDineInOrderResult(w, order, err)
+}
+
+// AddItems godoc
+// @Summary Add a round of items
+// @Description Add items to an order that is not billed yet. The order is open until they are sent to the kitchen.
+// @Description No stock is taken until the order is billed.
+// @Tags Dine-In Orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param items body models.DineInItemsRequest true "Items"
+// @Success 200 {object} models.DineInOrder
+// @Failure 404 {string} string "Order not found"
+// @Router /dine-in-orders/{id}/items [post]
+func (h *DineInOrderHandler) AddItems(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.DineInItemsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.Items) == 0 {
+		http.Error(w, "A round requires at least one item", http.StatusBadRequest)
+		return
+	}
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			http.Error(w, fmt.Sprintf("Invalid quantity for product %d", item.ProductID), http.StatusBadRequest)
+			return
+		}
+	}
+
+	order, err := h.service.AddItems(id, req)
+	writeDineInOrderResult(w, order, err)
+}
+
+// SendToKitchen godoc
+// @Summary Send order to the kitchen
+// @Description Send the items of an open order that were not sent yet to the kitchen
+// @Tags Dine-In Orders
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} models.DineInOrder
+// @Failure 404 {string} string "Order not found"
+// @Router /dine-in-orders/{id}/send [post]
+func (h *DineInOrderHandler) SendToKitchen(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.service.SendToKitchen(id)
+	writeDineInOrderResult(w, order, err)
+}
+
+// Serve godoc
+// @Summary Mark order served
+// @Description Mark the items sent to the kitchen as served
+// @Tags Dine-In Orders
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} models.DineInOrder
+// @Failure 404 {string} string "Order not found"
+// @Router /dine-in-orders/{id}/serve [post]
+func (h *DineInOrderHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.service.Serve(id)
+	writeDineInOrderResult(w, order, err)
+}
+
+// MoveTables godoc
+// @Summary Move order to other tables
+// @Description Move an order that is not billed yet to other free tables of its outlet
+// @Tags Dine-In Orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param tables body models.DineInTablesRequest true "New tables"
+// @Success 200 {object} models.DineInOrder
+// @Failure 404 {string} string "Order not found"
+// @Router /dine-in-orders/{id}/move [post]
+func (h *DineInOrderHandler) MoveTables(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.DineInTablesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.TableIDs) == 0 {
+		http.Error(w, "table_ids is required", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.service.MoveTables(id, req)
+	writeDineInOrderResult(w, order, err)
+}
+
+// Merge godoc
+// @Summary Merge orders
+// @Description Merge another order that is not billed yet, with its items and tables, into this order
+// @Tags Dine-In Orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param merge body models.DineInMergeRequest true "Order to merge"
+// @Success 200 {object} models.DineInOrder
+// @Failure 404 {string} string "Order not found"
+// @Router /dine-in-orders/{id}/merge [post]
+func (h *DineInOrderHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.DineInMergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.OrderID <= 0 {
+		http.Error(w, "order_id is required", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.service.Merge(id, req)
+	writeDineInOrderResult(w, order, err)
+}
+
+// Bill godoc
+// @Summary Bill order
+// @Description Check out the order's items as transactions, taking them out of stock. Without bills all items
+// @Description go on one bill, otherwise every item must be on one bill to split it by item. Each bill takes
+// @Description the customer, price list, points, gift card and on-account options of a regular checkout.
+// @Description Bills are pending, left out of the reports, until payments cover them.
+// @Tags Dine-In Orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param bill body models.DineInBillRequest false "Bill split"
+// @Param X-Actor header string false "Cashier billing the order"
+// @Success 200 {object} models.DineInOrder
+// @Failure 404 {string} string "Order not found"
+// @Router /dine-in-orders/{id}/bill [post]
+func (h *DineInOrderHandler) Bill(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.DineInBillRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	for _, bill := range req.Bills {
+		if len(bill.ItemIDs) == 0 {
+			http.Error(w, "Every bill requires item_ids", http.StatusBadRequest)
+			return
+		}
+		if bill.RedeemPoints < 0 {
+			http.Error(w, "redeem_points cannot be negative", http.StatusBadRequest)
+			return
+		}
+		if msg := validateGiftCards(nil, bill.GiftCardPayments); msg != "" {
+			http.Error(w, msg, http.StatusBadRequest)
+			return
+		}
+	}
+	req.Actor = actorFromRequest(r)
+
+	order, err := h.service.Bill(id, req)
+	writeDineInOrderResult(w, order, err)
+}
+
+// Pay godoc
+// @Summary Pay order
+// @Description Pay part or all of what is left of a billed order, so guests can split the bill by amount.
+// @Description The order and its bills are paid once the balance is 0.
+// @Tags Dine-In Orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param payment body models.DineInPaymentRequest true "Payment"
+// @Param X-Actor header string false "Cashier taking the payment"
+// @Success 200 {object} models.DineInOrder
+// @Failure 404 {string} string "Order not found"
+// @Router /dine-in-orders/{id}/payments [post]
+func (h *DineInOrderHandler) Pay(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.DineInPaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Amount <= 0 {
+		http.Error(w, "amount must be positive", http.StatusBadRequest)
+		return
+	}
+	req.Method = strings.TrimSpace(req.Method)
+	req.Actor = actorFromRequest(r)
+
+	order, err := h.service.Pay(id, req)
+	writeDineInOrderResult(w, order, err)
+}
+
+// Cancel godoc
+// @Summary Cancel order
+// @Description Cancel an order and free its tables. A billed order that is not paid is voided: its bills are
+// @Description given back like a refund, returning their stock, points and gift card balances. Payments taken
+// @Description towards them stay listed on the order, to be handed back.
+// @Tags Dine-In Orders
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param X-Actor header string false "Who cancels the order"
+// @Success 200 {object} models.DineInOrder
+// @Failure 404 {string} string "Order not found"
+// @Router /dine-in-orders/{id}/cancel [post]
+func (h *DineInOrderHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.service.Cancel(id, actorFromRequest(r))
+	writeDineInOrderResult(w, order, err)
+}
+
+// Handler routes requests to appropriate method handlers
+func (h *DineInOrderHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(r.URL.Path, "/")
+
+	switch {
+	case len(pathParts) == 2 || (len(pathParts) == 3 && pathParts[2] == ""):
+		switch r.Method {
+		case http.MethodGet:
+			h.GetAll(w, r)
+		case http.MethodPost:
+			h.Open(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	case len(pathParts) == 3:
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetByID(w, r)
+	case len(pathParts) == 4:
+		routes := map[string]http.HandlerFunc{
+			"items":    h.AddItems,
+			"send":     h.SendToKitchen,
+			"serve":    h.Serve,
+			"move":     h.MoveTables,
+			"merge":    h.Merge,
+			"bill":     h.Bill,
+			"payments": h.Pay,
+			"cancel":   h.Cancel,
+		}
+		route, ok := routes[pathParts[3]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		route(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeDineInOrderResult(w http.ResponseWriter, order *models.DineInOrder, err error) {
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Order not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
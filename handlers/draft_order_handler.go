@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"kasir-api/models"
+	"kasir-api/services"
+)
+
+type DraftOrderHandler struct {
+	service *services.DraftOrderService
+}
+
+func NewDraftOrderHandler(service *services.DraftOrderService) *DraftOrderHandler {
+	return &DraftOrderHandler{service: service}
+}
+
+// GetAll godoc
+// @Summary Get parked carts
+// @Description Get draft orders, most recently saved first. Lists open drafts unless another status is given.
+// @Description Drafts past their expiry time are expired first.
+// @Tags Draft Orders
+// @Produce json
+// @Param status query string false "Status (open, converted, expired)"
+// @Param X-Outlet-ID header int false "Outlet ID (default all outlets)"
+// @Success 200 {array} models.DraftOrder
+// @Router /draft-orders [get]
+func (h *DraftOrderHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = models.DraftOrderOpen
+	case models.DraftOrderOpen, models.DraftOrderConverted, models.DraftOrderExpired:
+	default:
+		http.Error(w, "Invalid status", http.StatusBadRequest)
+		return
+	}
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	orders, err := h.service.GetAll(status, outletID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(orders)
+}
+
+// Create godoc
+// @Summary Park a cart
+// @Description Save a cart as a draft order without taking its items out of stock.
+// @Description Item units default to the product's sale unit.
+// @Tags Draft Orders
+// @Accept json
+// @Produce json
+// @Param draft_order body models.DraftOrderRequest true "Draft order data"
+// @Param X-Outlet-ID header int false "Outlet ID (default the default outlet)"
+// @Param X-Actor header string false "Cashier parking the cart"
+// @Success 201 {object} models.DraftOrder
+// @Router /draft-orders [post]
+func (h *DraftOrderHandler) Create(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeDraftOrderRequest(w, r)
+	if !ok {
+		return
+	}
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
+	req.OutletID = outletID
+
+	order, err := h.service.Create(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(order)
+}
+
+// GetByID godoc
+// @Summary Get parked cart
+// @Description Get a draft order with its items to resume it
+// @Tags Draft Orders
+// @Produce json
+// @Param id path int true "Draft order ID"
+// @Success 200 {object} models.DraftOrder
+// @Failure 404 {string} string "Draft order not found"
+// @Router /draft-orders/{id} [get]
+func (h *DraftOrderHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.service.GetByID(id)
+	writeDraftOrderResult(w, order, err)
+}
+
+// Update godoc
+// @Summary Update parked cart
+// @Description Replace the items and details of an open draft order, which restarts its expiry
+// @Tags Draft Orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Draft order ID"
+// @Param draft_order body models.DraftOrderRequest true "Draft order data"
+// @Success 200 {object} models.DraftOrder
+// @Failure 404 {string} string "Draft order not found"
+// @Router /draft-orders/{id} [put]
+func (h *DraftOrderHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	req, ok := decodeDraftOrderRequest(w, r)
+	if !ok {
+		return
+	}
+
+	order, err := h.service.Update(id, req)
+	writeDraftOrderResult(w, order, err)
+}
+
+// Delete godoc
+// @Summary Discard parked cart
+// @Description Delete a draft order that has not been checked out
+// @Tags Draft Orders
+// @Produce json
+// @Param id path int true "Draft order ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {string} string "Draft order not found"
+// @Router /draft-orders/{id} [delete]
+func (h *DraftOrderHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		writeDraftOrderResult(w, nil, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": fmt.Sprintf("Draft order with ID %d deleted successfully", id),
+	})
+}
+
+// Checkout godoc
+// @Summary Check out parked cart
+// @Description Convert an open draft order into a transaction at its outlet. Stock is taken now, and the
+// @Description body gives the payment options of a regular checkout. Items come from the draft, the draft's
+// @Description customer and price list apply unless the body gives others.
+// @Tags Draft Orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Draft order ID"
+// @Param checkout body models.CheckoutRequest false "Payment options"
+// @Param X-Actor header string false "Cashier checking out"
+// @Success 201 {object} models.Transaction
+// @Failure 404 {string} string "Draft order not found"
+// @Router /draft-orders/{id}/checkout [post]
+func (h *DraftOrderHandler) Checkout(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.CheckoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.Items) > 0 {
+		http.Error(w, "Items come from the draft order, update the draft to change them", http.StatusBadRequest)
+		return
+	}
+	if req.RedeemPoints < 0 {
+		http.Error(w, "redeem_points cannot be negative", http.StatusBadRequest)
+		return
+	}
+	if msg := validateGiftCards(req.GiftCards, req.GiftCardPayments); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	req.Actor = actorFromRequest(r)
+
+	transaction, err := h.service.Checkout(id, req)
+	if err != nil {
+		writeDraftOrderResult(w, nil, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(transaction)
+}
+
+// Handler routes requests to appropriate method handlers
+func (h *DraftOrderHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(r.URL.Path, "/")
+
+	switch {
+	case len(pathParts) == 2 || (len(pathParts) == 3 && pathParts[2] == ""):
+		switch r.Method {
+		case http.MethodGet:
+			h.GetAll(w, r)
+		case http.MethodPost:
+			h.Create(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	case len(pathParts) == 3:
+		switch r.Method {
+		case http.MethodGet:
+			h.GetByID(w, r)
+		case http.MethodPut:
+			h.Update(w, r)
+		case http.MethodDelete:
+			h.Delete(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	case len(pathParts) == 4 && pathParts[3] == "checkout":
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.Checkout(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// decodeDraftOrderRequest reads and validates a draft order body.
+// On failure it writes the error response and returns ok = false.
+func decodeDraftOrderRequest(w http.ResponseWriter, r *http.Request) (models.DraftOrderRequest, bool) {
+	var req models.DraftOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return req, false
+	}
+	req.Label = strings.TrimSpace(req.Label)
+	if len(req.Items) == 0 {
+		http.Error(w, "Draft order requires at least one item", http.StatusBadRequest)
+		return req, false
+	}
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			http.Error(w, fmt.Sprintf("Invalid quantity for product %d", item.ProductID), http.StatusBadRequest)
+			return req, false
+		}
+	}
+	req.Actor = actorFromRequest(r)
+	return req, true
+}
+
+func writeDraftOrderResult(w http.ResponseWriter, result interface{}, err error) {
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Draft order not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
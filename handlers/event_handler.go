@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"kasir-api/events"
+)
+
+// heartbeatInterval keeps idle event streams open through proxies
+const heartbeatInterval = 30 * time.Second
+
+type EventHandler struct {
+	bus *events.Bus
+}
+
+func NewEventHandler(bus *events.Bus) *EventHandler {
+	return &EventHandler{bus: bus}
+}
+
+// Stream godoc
+// @Summary Stream events
+// @Description Stream events such as stock.low as Server-Sent Events. Each message has the event ID,
+// @Description the event type and the JSON encoded event as data.
+// @Tags Events
+// @Produce text/event-stream
+// @Param type query string false "Comma separated event types to receive, * matches one token and a final > the rest, e.g. transaction.* (default all)"
+// @Success 200 {object} events.Event
+// @Router /events [get]
+func (h *EventHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var patterns []string
+	if v := r.URL.Query().Get("type"); v != "" {
+		for _, t := range strings.Split(v, ",") {
+			patterns = append(patterns, strings.TrimSpace(t))
+		}
+	}
+
+	ch, unsubscribe := h.bus.Subscribe()
+	defer unsubscribe()
+
+	streamEvents(w, r, ch, func(e events.Event) bool {
+		if len(patterns) == 0 {
+			return true
+		}
+		for _, pattern := range patterns {
+			if events.Match(pattern, e.Type) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// streamEvents writes the events from ch that match to the client as Server-Sent Events until it
+// disconnects. The first events are written before any from ch, subscribe before reading them
+// so that nothing published in between is missed.
+func streamEvents(w http.ResponseWriter, r *http.Request, ch <-chan events.Event, match func(events.Event) bool, first ...events.Event) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	fmt.Fprint(w, ": connected\n\n")
+	for _, e := range first {
+		writeEvent(w, e)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case e := <-ch:
+			if !match(e) {
+				continue
+			}
+			writeEvent(w, e)
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, e events.Event) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"kasir-api/models"
+	"kasir-api/services"
+)
+
+type GiftCardHandler struct {
+	service *services.GiftCardService
+}
+
+func NewGiftCardHandler(service *services.GiftCardService) *GiftCardHandler {
+	return &GiftCardHandler{service: service}
+}
+
+// GetAll godoc
+// @Summary Get all gift cards
+// @Description Get gift cards and vouchers, newest first, optionally filtered by status. They are sold at checkout
+// @Tags Gift Cards
+// @Produce json
+// @Param status query string false "Status (active, redeemed, void)"
+// @Success 200 {array} models.GiftCard
+// @Router /gift-cards [get]
+func (h *GiftCardHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	cards, err := h.service.GetAll(r.URL.Query().Get("status"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cards)
+}
+
+// GetByCode godoc
+// @Summary Get gift card by code
+// @Description Get a gift card or voucher with its balance ledger
+// @Tags Gift Cards
+// @Produce json
+// @Param code path string true "Gift card code"
+// @Success 200 {object} models.GiftCard
+// @Failure 404 {string} string "Gift card not found"
+// @Router /gift-cards/{code} [get]
+func (h *GiftCardHandler) GetByCode(w http.ResponseWriter, r *http.Request) {
+	card, err := h.service.GetByCode(giftCardCode(r.URL.Path))
+	writeGiftCardResult(w, card, err)
+}
+
+// GetBalance godoc
+// @Summary Check gift card balance
+// @Description Get the balance and expiry of a gift card or voucher, and whether it can pay a checkout now
+// @Tags Gift Cards
+// @Produce json
+// @Param code path string true "Gift card code"
+// @Success 200 {object} models.GiftCardBalance
+// @Failure 404 {string} string "Gift card not found"
+// @Router /gift-cards/{code}/balance [get]
+func (h *GiftCardHandler) GetBalance(w http.ResponseWriter, r *http.Request) {
+	balance, err := h.service.GetBalance(giftCardCode(r.URL.Path))
+	writeGiftCardResult(w, balance, err)
+}
+
+// Handler routes requests to appropriate method handlers
+func (h *GiftCardHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	pathParts := strings.Split(r.URL.Path, "/")
+
+	switch {
+	case len(pathParts) == 2 || (len(pathParts) == 3 && pathParts[2] == ""):
+		h.GetAll(w, r)
+	case len(pathParts) == 3:
+		h.GetByCode(w, r)
+	case len(pathParts) == 4 && pathParts[3] == "balance":
+		h.GetBalance(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// giftCardCode extracts the code from /gift-cards/{code}
+func giftCardCode(path string) string {
+	parts := strings.Split(path, "/")
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[2]
+}
+
+func writeGiftCardResult(w http.ResponseWriter, result interface{}, err error) {
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Gift card not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// validateGiftCards checks the gift cards sold and paid with at checkout
+func validateGiftCards(issue []models.GiftCardIssueRequest, payments []models.GiftCardPayment) string {
+	for _, card := range issue {
+		if card.Type != models.GiftCardTypeCard && card.Type != models.GiftCardTypeVoucher {
+			return "gift card type must be gift_card or voucher"
+		}
+		if card.Amount <= 0 {
+			return "gift card amount must be positive"
+		}
+		if card.ExpiryDate != "" {
+			if _, err := time.Parse("2006-01-02", card.ExpiryDate); err != nil {
+				return "gift card expiry_date must use the YYYY-MM-DD format"
+			}
+		}
+	}
+	for _, payment := range payments {
+		if strings.TrimSpace(payment.Code) == "" {
+			return "gift card payments require a code"
+		}
+		if payment.Amount < 0 {
+			return "gift card payment amount cannot be negative"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"kasir-api/models"
+	"kasir-api/services"
+)
+
+type GoodsReceiptHandler struct {
+	service *services.GoodsReceiptService
+}
+
+func NewGoodsReceiptHandler(service *services.GoodsReceiptService) *GoodsReceiptHandler {
+	return &GoodsReceiptHandler{service: service}
+}
+
+// GetAll godoc
+// @Summary Get all goods receipts
+// @Description Get the receipts history, newest first, optionally filtered by supplier or product
+// @Tags Goods Receipts
+// @Produce json
+// @Param supplier_id query int false "Supplier ID"
+// @Param product_id query int false "Product ID"
+// @Success 200 {array} models.GoodsReceipt
+// @Router /goods-receipts [get]
+func (h *GoodsReceiptHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var supplierID, productID int
+	if v := query.Get("supplier_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Invalid supplier_id", http.StatusBadRequest)
+			return
+		}
+		supplierID = id
+	}
+	if v := query.Get("product_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Invalid product_id", http.StatusBadRequest)
+			return
+		}
+		productID = id
+	}
+
+	receipts, err := h.service.GetAll(supplierID, productID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(receipts)
+}
+
+// Create godoc
+// @Summary Receive goods
+// @Description Record a delivery from a supplier, optionally against a sent purchase order. Stock is
+// @Description incremented through the stock ledger and each product's cost becomes the weighted
+// @Description average of the stock on hand and the delivery. Lines with a batch_number are tracked as a
+// @Description batch with an optional expiry_date.
+// @Tags Goods Receipts
+// @Accept json
+// @Produce json
+// @Param X-Actor header string false "User receiving the goods"
+// @Param X-Outlet-ID header int false "Outlet receiving the goods (default the default outlet)"
+// @Param goods_receipt body models.GoodsReceiptRequest true "Goods receipt data"
+// @Success 201 {object} models.GoodsReceipt
+// @Router /goods-receipts [post]
+func (h *GoodsReceiptHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.GoodsReceiptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.SupplierID == 0 && req.PurchaseOrderID == 0 {
+		http.Error(w, "supplier_id or purchase_order_id is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Lines) == 0 {
+		http.Error(w, "Goods receipt requires at least one line", http.StatusBadRequest)
+		return
+	}
+	for _, line := range req.Lines {
+		if line.ProductID == 0 && line.PurchaseOrderLineID == 0 {
+			http.Error(w, "Each line requires product_id or purchase_order_line_id", http.StatusBadRequest)
+			return
+		}
+		if line.Quantity <= 0 || line.UnitCost < 0 {
+			http.Error(w, fmt.Sprintf("Invalid quantity or unit_cost for product %d", line.ProductID), http.StatusBadRequest)
+			return
+		}
+		if line.ExpiryDate != "" {
+			if line.BatchNumber == "" {
+				http.Error(w, "expiry_date requires a batch_number", http.StatusBadRequest)
+				return
+			}
+			if _, err := time.Parse("2006-01-02", line.ExpiryDate); err != nil {
+				http.Error(w, "Invalid expiry_date format (use YYYY-MM-DD)", http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	req.Actor = actorFromRequest(r)
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
+	req.OutletID = outletID
+
+	receipt, err := h.service.Create(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(receipt)
+}
+
+// GetByID godoc
+// @Summary Get goods receipt by ID
+// @Description Get a goods receipt with its lines and the resulting average costs
+// @Tags Goods Receipts
+// @Produce json
+// @Param id path int true "Goods receipt ID"
+// @Success 200 {object} models.GoodsReceipt
+// @Failure 404 {string} string "Goods receipt not found"
+// @Router /goods-receipts/{id} [get]
+func (h *GoodsReceiptHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	receipt, err := h.service.GetByID(id)
+	if err != nil {
+		http.Error(w, "Goods receipt not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(receipt)
+}
+
+// Handler routes requests to appropriate method handlers
+func (h *GoodsReceiptHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(r.URL.Path, "/")
+
+	switch {
+	case len(pathParts) == 2 || (len(pathParts) == 3 && pathParts[2] == ""):
+		switch r.Method {
+		case http.MethodGet:
+			h.GetAll(w, r)
+		case http.MethodPost:
+			h.Create(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	case len(pathParts) == 3:
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetByID(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
@@ -0,0 +1,395 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"kasir-api/events"
+	"kasir-api/models"
+	"kasir-api/services"
+)
+
+type KitchenHandler struct {
+	service *services.KitchenService
+	bus     *events.Bus
+}
+
+func NewKitchenHandler(service *services.KitchenService, bus *events.Bus) *KitchenHandler {
+	return &KitchenHandler{service: service, bus: bus}
+}
+
+// GetStations godoc
+// @Summary Get kitchen stations
+// @Description Get kitchen stations with the categories they prepare
+// @Tags Kitchen
+// @Produce json
+// @Success 200 {array} models.KitchenStation
+// @Router /kitchen-stations [get]
+func (h *KitchenHandler) GetStations(w http.ResponseWriter, r *http.Request) {
+	stations, err := h.service.GetStations()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stations)
+}
+
+// CreateStation godoc
+// @Summary Create kitchen station
+// @Description Create a station preparing the products of some categories, such as a bar for drinks.
+// @Description Every category goes to at most one station, products of other categories skip the kitchen.
+// @Tags Kitchen
+// @Accept json
+// @Produce json
+// @Param station body models.KitchenStationRequest true "Station data"
+// @Success 201 {object} models.KitchenStation
+// @Router /kitchen-stations [post]
+func (h *KitchenHandler) CreateStation(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeKitchenStationRequest(w, r)
+	if !ok {
+		return
+	}
+
+	station, err := h.service.CreateStation(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(station)
+}
+
+// GetStation godoc
+// @Summary Get kitchen station
+// @Description Get a kitchen station with the categories it prepares
+// @Tags Kitchen
+// @Produce json
+// @Param id path int true "Station ID"
+// @Success 200 {object} models.KitchenStation
+// @Failure 404 {string} string "Station not found"
+// @Router /kitchen-stations/{id} [get]
+func (h *KitchenHandler) GetStation(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	station, err := h.service.GetStation(id)
+	writeKitchenResult(w, station, err, "Station not found")
+}
+
+// UpdateStation godoc
+// @Summary Update kitchen station
+// @Description Rename a station and replace the categories it prepares. Items already on its queue stay there.
+// @Tags Kitchen
+// @Accept json
+// @Produce json
+// @Param id path int true "Station ID"
+// @Param station body models.KitchenStationRequest true "Station data"
+// @Success 200 {object} models.KitchenStation
+// @Failure 404 {string} string "Station not found"
+// @Router /kitchen-stations/{id} [put]
+func (h *KitchenHandler) UpdateStation(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	req, ok := decodeKitchenStationRequest(w, r)
+	if !ok {
+		return
+	}
+
+	station, err := h.service.UpdateStation(id, req)
+	writeKitchenResult(w, station, err, "Station not found")
+}
+
+// DeleteStation godoc
+// @Summary Delete kitchen station
+// @Description Delete a station with nothing left on its queue
+// @Tags Kitchen
+// @Produce json
+// @Param id path int true "Station ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {string} string "Station not found"
+// @Router /kitchen-stations/{id} [delete]
+func (h *KitchenHandler) DeleteStation(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.DeleteStation(id); err != nil {
+		writeKitchenResult(w, nil, err, "Station not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": fmt.Sprintf("Kitchen station with ID %d deleted successfully", id),
+	})
+}
+
+// GetQueue godoc
+// @Summary Get station queue
+// @Description Get the items a station has to prepare, oldest first, with how long they have waited and
+// @Description been in preparation. Lists the items not bumped yet unless a status is given.
+// @Tags Kitchen
+// @Produce json
+// @Param id path int true "Station ID"
+// @Param status query string false "Status (new, preparing, ready, bumped)"
+// @Param X-Outlet-ID header int false "Outlet ID (default all outlets)"
+// @Success 200 {array} models.KitchenItem
+// @Failure 404 {string} string "Station not found"
+// @Router /kitchen-stations/{id}/queue [get]
+func (h *KitchenHandler) GetQueue(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	status, ok := kitchenStatusFromRequest(w, r)
+	if !ok {
+		return
+	}
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := h.service.GetQueue(id, outletID, status)
+	writeKitchenResult(w, items, err, "Station not found")
+}
+
+// Stream godoc
+// @Summary Stream station queue
+// @Description Stream a station's queue as Server-Sent Events for a kitchen display. The first event,
+// @Description kitchen.queue, carries the items not bumped yet, then a kitchen.item event follows whenever
+// @Description an item reaches the station or changes status.
+// @Tags Kitchen
+// @Produce text/event-stream
+// @Param id path int true "Station ID"
+// @Param X-Outlet-ID header int false "Outlet ID (default all outlets)"
+// @Success 200 {object} events.Event
+// @Failure 404 {string} string "Station not found"
+// @Router /kitchen-stations/{id}/stream [get]
+func (h *KitchenHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	ch, unsubscribe := h.bus.Subscribe()
+	defer unsubscribe()
+
+	items, err := h.service.GetQueue(id, outletID, "")
+	if err != nil {
+		writeKitchenResult(w, nil, err, "Station not found")
+		return
+	}
+
+	streamEvents(w, r, ch, func(e events.Event) bool {
+		item, ok := e.Data.(models.KitchenItem)
+		return ok && item.StationID == id && (outletID == 0 || item.OutletID == outletID)
+	}, events.New(events.TypeKitchenQueue, items))
+}
+
+// StartItem godoc
+// @Summary Start preparing item
+// @Description Mark a new kitchen item as being prepared, which starts its preparation timer
+// @Tags Kitchen
+// @Produce json
+// @Param id path int true "Kitchen item ID"
+// @Success 200 {object} models.KitchenItem
+// @Failure 404 {string} string "Kitchen item not found"
+// @Router /kitchen-items/{id}/start [post]
+func (h *KitchenHandler) StartItem(w http.ResponseWriter, r *http.Request) {
+	h.setItemStatus(w, r, h.service.Start)
+}
+
+// ReadyItem godoc
+// @Summary Mark item ready
+// @Description Mark a kitchen item ready to be picked up, which stops its timers
+// @Tags Kitchen
+// @Produce json
+// @Param id path int true "Kitchen item ID"
+// @Success 200 {object} models.KitchenItem
+// @Failure 404 {string} string "Kitchen item not found"
+// @Router /kitchen-items/{id}/ready [post]
+func (h *KitchenHandler) ReadyItem(w http.ResponseWriter, r *http.Request) {
+	h.setItemStatus(w, r, h.service.Ready)
+}
+
+// BumpItem godoc
+// @Summary Bump item
+// @Description Take a kitchen item off the station's screen once it has been picked up
+// @Tags Kitchen
+// @Produce json
+// @Param id path int true "Kitchen item ID"
+// @Success 200 {object} models.KitchenItem
+// @Failure 404 {string} string "Kitchen item not found"
+// @Router /kitchen-items/{id}/bump [post]
+func (h *KitchenHandler) BumpItem(w http.ResponseWriter, r *http.Request) {
+	h.setItemStatus(w, r, h.service.Bump)
+}
+
+// RecallItem godoc
+// @Summary Recall item
+// @Description Bring a bumped kitchen item back on the station's screen as ready
+// @Tags Kitchen
+// @Produce json
+// @Param id path int true "Kitchen item ID"
+// @Success 200 {object} models.KitchenItem
+// @Failure 404 {string} string "Kitchen item not found"
+// @Router /kitchen-items/{id}/recall [post]
+func (h *KitchenHandler) RecallItem(w http.ResponseWriter, r *http.Request) {
+	h.setItemStatus(w, r, h.service.Recall)
+}
+
+func (h *KitchenHandler) setItemStatus(w http.ResponseWriter, r *http.Request, set func(int) (*models.KitchenItem, error)) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	item, err := set(id)
+	writeKitchenResult(w, item, err, "Kitchen item not found")
+}
+
+// Handler routes /kitchen-stations requests to appropriate method handlers
+func (h *KitchenHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(r.URL.Path, "/")
+
+	switch {
+	case len(pathParts) == 2 || (len(pathParts) == 3 && pathParts[2] == ""):
+		switch r.Method {
+		case http.MethodGet:
+			h.GetStations(w, r)
+		case http.MethodPost:
+			h.CreateStation(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	case len(pathParts) == 3:
+		switch r.Method {
+		case http.MethodGet:
+			h.GetStation(w, r)
+		case http.MethodPut:
+			h.UpdateStation(w, r)
+		case http.MethodDelete:
+			h.DeleteStation(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	case len(pathParts) == 4:
+		routes := map[string]http.HandlerFunc{
+			"queue":  h.GetQueue,
+			"stream": h.Stream,
+		}
+		route, ok := routes[pathParts[3]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		route(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// ItemHandler routes /kitchen-items requests to appropriate method handlers
+func (h *KitchenHandler) ItemHandler(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) != 4 {
+		http.NotFound(w, r)
+		return
+	}
+
+	routes := map[string]http.HandlerFunc{
+		"start":  h.StartItem,
+		"ready":  h.ReadyItem,
+		"bump":   h.BumpItem,
+		"recall": h.RecallItem,
+	}
+	route, ok := routes[pathParts[3]]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	route(w, r)
+}
+
+// decodeKitchenStationRequest reads and validates a kitchen station body.
+// On failure it writes the error response and returns ok = false.
+func decodeKitchenStationRequest(w http.ResponseWriter, r *http.Request) (models.KitchenStationRequest, bool) {
+	var req models.KitchenStationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return req, false
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return req, false
+	}
+	seen := make(map[int]bool)
+	for _, id := range req.CategoryIDs {
+		if seen[id] {
+			http.Error(w, fmt.Sprintf("Duplicate category_id %d", id), http.StatusBadRequest)
+			return req, false
+		}
+		seen[id] = true
+	}
+	return req, true
+}
+
+// kitchenStatusFromRequest reads the optional status filter.
+// On failure it writes the error response and returns ok = false.
+func kitchenStatusFromRequest(w http.ResponseWriter, r *http.Request) (string, bool) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.KitchenItemNew, models.KitchenItemPreparing, models.KitchenItemReady, models.KitchenItemBumped:
+		return status, true
+	}
+	http.Error(w, "Invalid status", http.StatusBadRequest)
+	return "", false
+}
+
+func writeKitchenResult(w http.ResponseWriter, result interface{}, err error, notFound string) {
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, notFound, http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// OutletHeader selects the outlet a request works on. Without it checkout and stock changes
+// use the default outlet, and reports and listings cover all outlets.
+const OutletHeader = "X-Outlet-ID"
+
+// outletFromRequest reads the outlet from the X-Outlet-ID header or the outlet_id query
+// parameter, 0 when neither is given. On failure it writes the error response and returns ok = false.
+func outletFromRequest(w http.ResponseWriter, r *http.Request) (int, bool) {
+	v := strings.TrimSpace(r.Header.Get(OutletHeader))
+	if v == "" {
+		v = r.URL.Query().Get("outlet_id")
+	}
+	if v == "" {
+		return 0, true
+	}
+	id, err := strconv.Atoi(v)
+	if err != nil || id <= 0 {
+		http.Error(w, "Invalid outlet ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
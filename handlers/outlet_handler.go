@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"kasir-api/models"
+	"kasir-api/services"
+)
+
+type OutletHandler struct {
+	service *services.OutletService
+}
+
+func NewOutletHandler(service *services.OutletService) *OutletHandler {
+	return &OutletHandler{service: service}
+}
+
+// GetAll godoc
+// @Summary Get all outlets
+// @Description Get all outlets
+// @Tags Outlets
+// @Produce json
+// @Success 200 {array} models.Outlet
+// @Router /outlets [get]
+func (h *OutletHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	outlets, err := h.service.GetAll()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(outlets)
+}
+
+// Create godoc
+// @Summary Create outlet
+// @Description Create a new outlet. Marking it as default takes the flag from the current default outlet
+// @Tags Outlets
+// @Accept json
+// @Produce json
+// @Param outlet body models.OutletRequest true "Outlet data"
+// @Success 201 {object} models.Outlet
+// @Router /outlets [post]
+func (h *OutletHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.OutletRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Code == "" || req.Name == "" {
+		http.Error(w, "code and name are required", http.StatusBadRequest)
+		return
+	}
+
+	outlet, err := h.service.Create(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(outlet)
+}
+
+// GetByID godoc
+// @Summary Get outlet by ID
+// @Description Get an outlet by its ID
+// @Tags Outlets
+// @Produce json
+// @Param id path int true "Outlet ID"
+// @Success 200 {object} models.Outlet
+// @Failure 404 {string} string "Outlet not found"
+// @Router /outlets/{id} [get]
+func (h *OutletHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	outlet, err := h.service.GetByID(id)
+	writeOutletResult(w, outlet, err)
+}
+
+// Update godoc
+// @Summary Update outlet
+// @Description Update an outlet by its ID. The default outlet stays the default until another outlet is made the default
+// @Tags Outlets
+// @Accept json
+// @Produce json
+// @Param id path int true "Outlet ID"
+// @Param outlet body models.OutletRequest true "Outlet data"
+// @Success 200 {object} models.Outlet
+// @Router /outlets/{id} [put]
+func (h *OutletHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.OutletRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Code == "" || req.Name == "" {
+		http.Error(w, "code and name are required", http.StatusBadRequest)
+		return
+	}
+
+	outlet, err := h.service.Update(id, req)
+	writeOutletResult(w, outlet, err)
+}
+
+// Delete godoc
+// @Summary Delete outlet
+// @Description Delete an outlet that holds no stock. The default outlet cannot be deleted
+// @Tags Outlets
+// @Produce json
+// @Param id path int true "Outlet ID"
+// @Success 200 {object} map[string]string
+// @Router /outlets/{id} [delete]
+func (h *OutletHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		writeOutletResult(w, nil, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": fmt.Sprintf("Outlet with ID %d deleted successfully", id),
+	})
+}
+
+// GetStock godoc
+// @Summary Get outlet stock
+// @Description Get the stock and selling price of every product at an outlet
+// @Tags Outlets
+// @Produce json
+// @Param id path int true "Outlet ID"
+// @Success 200 {array} models.OutletStock
+// @Router /outlets/{id}/stock [get]
+func (h *OutletHandler) GetStock(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	stock, err := h.service.GetStock(id)
+	writeOutletResult(w, stock, err)
+}
+
+// SetPrices godoc
+// @Summary Set outlet prices
+// @Description Override product prices at an outlet. A null price removes the override so the product price applies again
+// @Tags Outlets
+// @Accept json
+// @Produce json
+// @Param id path int true "Outlet ID"
+// @Param prices body []models.OutletPriceRequest true "Price overrides"
+// @Success 200 {array} models.OutletStock
+// @Router /outlets/{id}/prices [put]
+func (h *OutletHandler) SetPrices(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var prices []models.OutletPriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&prices); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	for _, p := range prices {
+		if p.ProductID == 0 {
+			http.Error(w, "product_id is required", http.StatusBadRequest)
+			return
+		}
+		if p.Price != nil && *p.Price < 0 {
+			http.Error(w, "price cannot be negative", http.StatusBadRequest)
+			return
+		}
+	}
+
+	stock, err := h.service.SetPrices(id, prices)
+	writeOutletResult(w, stock, err)
+}
+
+// GetSales godoc
+// @Summary Get sales per outlet
+// @Description Get revenue, transactions, COGS, gross profit and margin per outlet between start_date and end_date (YYYY-MM-DD)
+// @Tags Reports
+// @Produce json
+// @Param start_date query string true "Start Date (YYYY-MM-DD)"
+// @Param end_date query string true "End Date (YYYY-MM-DD)"
+// @Success 200 {array} models.OutletSales
+// @Router /reports/outlets [get]
+func (h *OutletHandler) GetSales(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	startDate, endDate, ok := parseDateRange(w, r)
+	if !ok {
+		return
+	}
+
+	sales, err := h.service.GetSales(startDate, endDate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sales)
+}
+
+// Handler routes requests to appropriate method handlers
+func (h *OutletHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(r.URL.Path, "/")
+
+	switch {
+	case len(pathParts) == 2 || (len(pathParts) == 3 && pathParts[2] == ""):
+		switch r.Method {
+		case http.MethodGet:
+			h.GetAll(w, r)
+		case http.MethodPost:
+			h.Create(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	case len(pathParts) == 3:
+		switch r.Method {
+		case http.MethodGet:
+			h.GetByID(w, r)
+		case http.MethodPut:
+			h.Update(w, r)
+		case http.MethodDelete:
+			h.Delete(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	case len(pathParts) == 4:
+		routes := map[string]struct {
+			method  string
+			handler http.HandlerFunc
+		}{
+			"stock":  {http.MethodGet, h.GetStock},
+			"prices": {http.MethodPut, h.SetPrices},
+		}
+		route, ok := routes[pathParts[3]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != route.method {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		route.handler(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeOutletResult(w http.ResponseWriter, result interface{}, err error) {
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Outlet not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
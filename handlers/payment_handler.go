@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"kasir-api/models"
+	"kasir-api/payments"
+	"kasir-api/services"
+)
+
+// defaultQRScale is the size in pixels of a QR code module, unless the request asks for another
+const defaultQRScale = 8
+
+type PaymentHandler struct {
+	service *services.PaymentService
+}
+
+func NewPaymentHandler(service *services.PaymentService) *PaymentHandler {
+	return &PaymentHandler{service: service}
+}
+
+// GetAll godoc
+// @Summary Get payments
+// @Description Get gateway payments, newest first, with the sales they pay
+// @Tags Payments
+// @Produce json
+// @Param status query string false "Status (pending, paid, expired, cancelled, failed)"
+// @Success 200 {array} models.Payment
+// @Router /payments [get]
+func (h *PaymentHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.PaymentPending, models.PaymentPaid, models.PaymentExpired, models.PaymentCancelled, models.PaymentFailed:
+	default:
+		http.Error(w, "Invalid status", http.StatusBadRequest)
+		return
+	}
+
+	list, err := h.service.GetAll(status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// CreateQRIS godoc
+// @Summary Check out with a QRIS payment
+// @Description Record a sale like POST /transactions and create a dynamic QRIS for what is left to pay.
+// @Description The sale is pending: its stock is reserved, but it is left out of the reports and sent to
+// @Description the kitchen only once the gateway notifies the payment. Unpaid, it is given back when the
+// @Description QRIS expires. Sales on account and gift cards cannot be paid by QRIS.
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param checkout body models.CheckoutRequest true "Checkout data"
+// @Param X-Outlet-ID header int false "Outlet ID (default the default outlet)"
+// @Param X-Actor header string false "Who records the sale"
+// @Success 201 {object} models.Payment
+// @Router /payments/qris [post]
+func (h *PaymentHandler) CreateQRIS(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeCheckoutRequest(w, r)
+	if !ok {
+		return
+	}
+
+	payment, err := h.service.CreateQRIS(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(payment)
+}
+
+// GetByID godoc
+// @Summary Get payment
+// @Description Get a payment with its QRIS and the sale it pays, for polling its status
+// @Tags Payments
+// @Produce json
+// @Param id path int true "Payment ID"
+// @Success 200 {object} models.Payment
+// @Failure 404 {string} string "Payment not found"
+// @Router /payments/{id} [get]
+func (h *PaymentHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	payment, err := h.service.GetByID(id)
+	writePaymentResult(w, payment, err)
+}
+
+// QRCode godoc
+// @Summary Get payment QR code
+// @Description Get the QRIS of a pending payment as a PNG image for the customer to scan
+// @Tags Payments
+// @Produce png
+// @Param id path int true "Payment ID"
+// @Param scale query int false "Pixels per QR code module, 1 to 32 (default 8)"
+// @Success 200 {file} binary
+// @Failure 404 {string} string "Payment not found"
+// @Router /payments/{id}/qr.png [get]
+func (h *PaymentHandler) QRCode(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	scale := defaultQRScale
+	if v := r.URL.Query().Get("scale"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 32 {
+			http.Error(w, "Invalid scale", http.StatusBadRequest)
+			return
+		}
+		scale = n
+	}
+
+	png, err := h.service.QRCode(id, scale)
+	if err != nil {
+		writePaymentResult(w, nil, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(png)
+}
+
+// Cancel godoc
+// @Summary Cancel payment
+// @Description Cancel a pending payment at the gateway. The sale is given back and its stock released.
+// @Tags Payments
+// @Produce json
+// @Param id path int true "Payment ID"
+// @Param X-Actor header string false "Who cancels the payment"
+// @Success 200 {object} models.Payment
+// @Failure 404 {string} string "Payment not found"
+// @Router /payments/{id}/cancel [post]
+func (h *PaymentHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	payment, err := h.service.Cancel(id, actorFromRequest(r))
+	writePaymentResult(w, payment, err)
+}
+
+// Callback godoc
+// @Summary Payment gateway callback
+// @Description Notification posted by the payment gateway. A paid payment completes its sale, an expired
+// @Description or failed one gives it back. Repeated notifications are harmless.
+// @Description The mock gateway takes a payments.MockNotification signed in the X-Callback-Signature header,
+// @Description and refuses every notification while PAYMENT_CALLBACK_SECRET is not set.
+// @Description In multi-tenant mode gateways post to /payments/callback/{tenant_id} without an API key.
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param notification body payments.MockNotification true "Notification (mock gateway)"
+// @Param X-Callback-Signature header string true "sha256= and the hex HMAC-SHA256 of the body with PAYMENT_CALLBACK_SECRET (mock gateway)"
+// @Success 200 {object} models.Payment
+// @Failure 401 {string} string "Notification not authenticated"
+// @Failure 503 {string} string "PAYMENT_CALLBACK_SECRET is not set"
+// @Failure 404 {string} string "Payment not found"
+// @Router /payments/callback [post]
+func (h *PaymentHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	notification, err := h.service.ParseNotification(r)
+	if err != nil {
+		if errors.Is(err, payments.ErrUnauthorized) {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if errors.Is(err, payments.ErrNotConfigured) {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payment, err := h.service.Notify(notification)
+	writePaymentResult(w, payment, err)
+}
+
+// Handler routes /payments requests to appropriate method handlers
+func (h *PaymentHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(r.URL.Path, "/")
+
+	switch {
+	case len(pathParts) == 2 || (len(pathParts) == 3 && pathParts[2] == ""):
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetAll(w, r)
+	case len(pathParts) == 3 && (pathParts[2] == "qris" || pathParts[2] == "callback"):
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if pathParts[2] == "qris" {
+			h.CreateQRIS(w, r)
+		} else {
+			h.Callback(w, r)
+		}
+	case len(pathParts) == 3:
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetByID(w, r)
+	case len(pathParts) == 4:
+		routes := map[string]struct {
+			method  string
+			handler http.HandlerFunc
+		}{
+			"qr.png": {http.MethodGet, h.QRCode},
+			"cancel": {http.MethodPost, h.Cancel},
+		}
+		route, ok := routes[pathParts[3]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != route.method {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		route.handler(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writePaymentResult(w http.ResponseWriter, result interface{}, err error) {
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Payment not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
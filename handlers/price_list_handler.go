@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"kasir-api/models"
+	"kasir-api/services"
+)
+
+type PriceListHandler struct {
+	service *services.PriceListService
+}
+
+func NewPriceListHandler(service *services.PriceListService) *PriceListHandler {
+	return &PriceListHandler{service: service}
+}
+
+// GetAll godoc
+// @Summary Get all price lists
+// @Description Get all price lists without their prices
+// @Tags Price Lists
+// @Produce json
+// @Success 200 {array} models.PriceList
+// @Router /price-lists [get]
+func (h *PriceListHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	lists, err := h.service.GetAll()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lists)
+}
+
+// Create godoc
+// @Summary Create price list
+// @Description Create a price list with per-product prices and quantity-break tiers.
+// @Description Item units default to the product's sale unit and min_quantity to 1
+// @Tags Price Lists
+// @Accept json
+// @Produce json
+// @Param price_list body models.PriceListRequest true "Price list data"
+// @Success 201 {object} models.PriceList
+// @Router /price-lists [post]
+func (h *PriceListHandler) Create(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodePriceListRequest(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := h.service.Create(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(list)
+}
+
+// GetByID godoc
+// @Summary Get price list by ID
+// @Description Get a price list with its prices
+// @Tags Price Lists
+// @Produce json
+// @Param id path int true "Price list ID"
+// @Success 200 {object} models.PriceList
+// @Failure 404 {string} string "Price list not found"
+// @Router /price-lists/{id} [get]
+func (h *PriceListHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	list, err := h.service.GetByID(id)
+	writePriceListResult(w, list, err)
+}
+
+// Update godoc
+// @Summary Update price list
+// @Description Update a price list, its items replace all its prices
+// @Tags Price Lists
+// @Accept json
+// @Produce json
+// @Param id path int true "Price list ID"
+// @Param price_list body models.PriceListRequest true "Price list data"
+// @Success 200 {object} models.PriceList
+// @Router /price-lists/{id} [put]
+func (h *PriceListHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	req, ok := decodePriceListRequest(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := h.service.Update(id, req)
+	writePriceListResult(w, list, err)
+}
+
+// Delete godoc
+// @Summary Delete price list
+// @Description Delete a price list, transactions it priced keep their prices
+// @Tags Price Lists
+// @Produce json
+// @Param id path int true "Price list ID"
+// @Success 200 {object} map[string]string
+// @Router /price-lists/{id} [delete]
+func (h *PriceListHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		writePriceListResult(w, nil, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": fmt.Sprintf("Price list with ID %d deleted successfully", id),
+	})
+}
+
+// Handler routes requests to appropriate method handlers
+func (h *PriceListHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(r.URL.Path, "/")
+
+	switch {
+	case len(pathParts) == 2 || (len(pathParts) == 3 && pathParts[2] == ""):
+		switch r.Method {
+		case http.MethodGet:
+			h.GetAll(w, r)
+		case http.MethodPost:
+			h.Create(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	case len(pathParts) == 3:
+		switch r.Method {
+		case http.MethodGet:
+			h.GetByID(w, r)
+		case http.MethodPut:
+			h.Update(w, r)
+		case http.MethodDelete:
+			h.Delete(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// decodePriceListRequest reads and validates a price list body.
+// On failure it writes the error response and returns ok = false.
+func decodePriceListRequest(w http.ResponseWriter, r *http.Request) (models.PriceListRequest, bool) {
+	var req models.PriceListRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return req, false
+	}
+	if req.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return req, false
+	}
+	switch req.Type {
+	case models.PriceListRetail, models.PriceListMember, models.PriceListWholesale, models.PriceListCustom:
+	default:
+		http.Error(w, "type must be retail, member, wholesale or custom", http.StatusBadRequest)
+		return req, false
+	}
+	for _, item := range req.Items {
+		if item.Price < 0 || item.MinQuantity < 0 {
+			http.Error(w, fmt.Sprintf("Invalid price or min_quantity for product %d", item.ProductID), http.StatusBadRequest)
+			return req, false
+		}
+	}
+	return req, true
+}
+
+func writePriceListResult(w http.ResponseWriter, list *models.PriceList, err error) {
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Price list not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
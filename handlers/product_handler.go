@@ -1,37 +1,47 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"kasir-api/models"
 	"kasir-api/services"
 )
 
 type ProductHandler struct {
-	service *services.ProductService
+	service         *services.ProductService
+	movementService *services.StockMovementService
 }
 
-func NewProductHandler(service *services.ProductService) *ProductHandler {
-	return &ProductHandler{service: service}
+func NewProductHandler(service *services.ProductService, movementService *services.StockMovementService) *ProductHandler {
+	return &ProductHandler{service: service, movementService: movementService}
 }
 
 // GetAll godoc
 // @Summary Get all products
-// @Description Get all products from the database, optionally filtered by name
+// @Description Get all products from the database, optionally filtered by name.
+// @Description stock is the stock at the selected outlet, or the total over all outlets without one.
 // @Tags Products
 // @Produce json
 // @Param name query string false "Search by product name"
+// @Param X-Outlet-ID header int false "Outlet ID (default all outlets)"
 // @Success 200 {array} models.Product
 // @Router /products [get]
 func (h *ProductHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	// Get optional name query parameter
 	name := r.URL.Query().Get("name")
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
 
-	products, err := h.service.GetAll(name)
+	products, err := h.service.GetAll(name, outletID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -43,12 +53,13 @@ func (h *ProductHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 
 // Create godoc
 // @Summary Create product
-// @Description Create a new product
+// @Description Create a new product, or a bundle when components are given
 // @Tags Products
 // @Accept json
 // @Produce json
 // @Param product body models.ProductRequest true "Product data"
 // @Success 201 {object} models.Product
+// @Param X-Outlet-ID header int false "Outlet ID (default the default outlet)"
 // @Router /products [post]
 func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req models.ProductRequest
@@ -56,6 +67,16 @@ func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	if msg := validateProductRequest(req); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	req.Actor = actorFromRequest(r)
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
+	req.OutletID = outletID
 
 	product, err := h.service.Create(req)
 	if err != nil {
@@ -70,10 +91,11 @@ func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 // GetByID godoc
 // @Summary Get product by ID
-// @Description Get a product by its ID
+// @Description Get a product by its ID. stock is the stock at the selected outlet, or the total over all outlets without one.
 // @Tags Products
 // @Produce json
 // @Param id path int true "Product ID"
+// @Param X-Outlet-ID header int false "Outlet ID (default all outlets)"
 // @Success 200 {object} models.Product
 // @Failure 404 {string} string "Product not found"
 // @Router /products/{id} [get]
@@ -83,8 +105,12 @@ func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid ID", http.StatusBadRequest)
 		return
 	}
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
 
-	product, err := h.service.GetByID(id)
+	product, err := h.service.GetByID(id, outletID)
 	if err != nil {
 		http.Error(w, "Product not found", http.StatusNotFound)
 		return
@@ -96,13 +122,16 @@ func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 // Update godoc
 // @Summary Update product
-// @Description Update a product by its ID
+// @Description Update a product by its ID. Sending components turns it into a bundle, once no outlet holds its stock; components left out are kept and an empty list un-bundles it.
+// @Description stock can be left out; when sent it has to match the stock at the selected outlet, stock changes go through /products/{id}/adjustments.
 // @Tags Products
 // @Accept json
 // @Produce json
 // @Param id path int true "Product ID"
 // @Param product body models.ProductRequest true "Product data"
+// @Param X-Outlet-ID header int false "Outlet ID (default all outlets)"
 // @Success 200 {object} models.Product
+// @Failure 400 {string} string "stock differs from the current stock"
 // @Router /products/{id} [put]
 func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
 	id := extractID(r.URL.Path)
@@ -116,10 +145,23 @@ func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	if msg := validateProductRequest(req); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
+	req.OutletID = outletID
 
 	product, err := h.service.Update(id, req)
 	if err != nil {
-		http.Error(w, "Product not found", http.StatusNotFound)
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Product not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -153,11 +195,223 @@ func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GetCostHistory godoc
+// @Summary Get product cost history
+// @Description Get the cost price history of a product, newest first
+// @Tags Products
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {array} models.ProductCost
+// @Router /products/{id}/cost-history [get]
+func (h *ProductHandler) GetCostHistory(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.service.GetCostHistory(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// GetLowStock godoc
+// @Summary Get low-stock products
+// @Description Get products whose stock is at or below their min_stock, with the quantity to reorder
+// @Tags Products
+// @Produce json
+// @Success 200 {array} models.LowStockItem
+// @Param X-Outlet-ID header int false "Outlet ID (default all outlets)"
+// @Router /products/low-stock [get]
+func (h *ProductHandler) GetLowStock(w http.ResponseWriter, r *http.Request) {
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := h.service.GetLowStock(outletID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// GetBatches godoc
+// @Summary Get product batches
+// @Description Get the batches of a product with their expiry dates, first-expired-first-out.
+// @Description Used up batches are only listed with all=true.
+// @Tags Products
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param all query bool false "Include used up batches"
+// @Success 200 {array} models.ProductBatch
+// @Param X-Outlet-ID header int false "Outlet ID (default all outlets)"
+// @Router /products/{id}/batches [get]
+func (h *ProductHandler) GetBatches(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	batches, err := h.service.GetBatches(id, outletID, r.URL.Query().Get("all") == "true")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(batches)
+}
+
+// GetMovements godoc
+// @Summary Get product stock movements
+// @Description Get the stock ledger of a product with the running balance after each movement, optionally filtered by start_date and end_date (YYYY-MM-DD).
+// @Description balance_after is the selected outlet's balance, or without an outlet the running total over all outlets.
+// @Tags Products
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param start_date query string false "Start Date (YYYY-MM-DD)"
+// @Param end_date query string false "End Date (YYYY-MM-DD)"
+// @Success 200 {array} models.StockMovement
+// @Param X-Outlet-ID header int false "Outlet ID (default all outlets)"
+// @Router /products/{id}/movements [get]
+func (h *ProductHandler) GetMovements(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	// Without a range the whole history is returned
+	startDate := time.Time{}
+	endDate := time.Now().AddDate(100, 0, 0)
+	if r.URL.Query().Get("start_date") != "" || r.URL.Query().Get("end_date") != "" {
+		var ok bool
+		startDate, endDate, ok = parseDateRange(w, r)
+		if !ok {
+			return
+		}
+	}
+
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	movements, err := h.movementService.GetByProduct(id, outletID, startDate, endDate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(movements)
+}
+
+// Adjust godoc
+// @Summary Adjust product stock
+// @Description Record a stock adjustment (signed quantity) or waste (positive quantity lost) with a reason. The actor is taken from the X-Actor header
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param X-Actor header string false "User performing the adjustment"
+// @Param X-Outlet-ID header int false "Outlet ID (default the default outlet)"
+// @Param adjustment body models.StockAdjustmentRequest true "Adjustment data"
+// @Success 201 {object} models.StockMovement
+// @Router /products/{id}/adjustments [post]
+func (h *ProductHandler) Adjust(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.StockAdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Type != models.MovementAdjustment && req.Type != models.MovementWaste {
+		http.Error(w, "type must be adjustment or waste", http.StatusBadRequest)
+		return
+	}
+	if req.Quantity == 0 || (req.Type == models.MovementWaste && req.Quantity < 0) {
+		http.Error(w, "Invalid quantity", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		http.Error(w, "reason is required", http.StatusBadRequest)
+		return
+	}
+	req.Actor = actorFromRequest(r)
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
+	req.OutletID = outletID
+
+	movement, err := h.movementService.Adjust(id, req)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Product not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(movement)
+}
+
 // Handler routes requests to appropriate method handlers
 func (h *ProductHandler) Handler(w http.ResponseWriter, r *http.Request) {
 	pathParts := strings.Split(r.URL.Path, "/")
 
-	if len(pathParts) == 2 || (len(pathParts) == 3 && pathParts[2] == "") {
+	if len(pathParts) == 4 {
+		// Sub-resources of a single product: /products/{id}/{resource}
+		routes := map[string]struct {
+			method  string
+			handler http.HandlerFunc
+		}{
+			"cost-history": {http.MethodGet, h.GetCostHistory},
+			"batches":      {http.MethodGet, h.GetBatches},
+			"movements":    {http.MethodGet, h.GetMovements},
+			"adjustments":  {http.MethodPost, h.Adjust},
+		}
+		route, ok := routes[pathParts[3]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != route.method {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		route.handler(w, r)
+	} else if len(pathParts) == 3 && pathParts[2] == "low-stock" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetLowStock(w, r)
+	} else if len(pathParts) == 2 || (len(pathParts) == 3 && pathParts[2] == "") {
 		switch r.Method {
 		case http.MethodGet:
 			h.GetAll(w, r)
@@ -191,3 +445,55 @@ func extractID(path string) int {
 	}
 	return id
 }
+
+// validateProductRequest checks bundle components and units before they reach the database
+func validateProductRequest(req models.ProductRequest) string {
+	seen := make(map[int]bool)
+	for _, c := range req.Components {
+		if c.Quantity <= 0 {
+			return fmt.Sprintf("Invalid quantity for component product %d", c.ProductID)
+		}
+		if seen[c.ProductID] {
+			return fmt.Sprintf("Duplicate component product %d", c.ProductID)
+		}
+		seen[c.ProductID] = true
+	}
+
+	if req.Stock != nil && *req.Stock < 0 {
+		return "Stock cannot be negative"
+	}
+	if req.Price < 0 || req.CostPrice < 0 {
+		return "Price and cost_price cannot be negative"
+	}
+	if req.MinStock < 0 || req.ReorderQuantity < 0 {
+		return "min_stock and reorder_quantity cannot be negative"
+	}
+	if req.Stock != nil && !req.AllowFraction && !req.Stock.IsWhole() {
+		return "Fractional stock requires allow_fraction"
+	}
+
+	baseUnit := req.Unit
+	if baseUnit == "" {
+		baseUnit = models.DefaultUnit
+	}
+	units := map[string]bool{baseUnit: true}
+	for _, u := range req.Units {
+		if u.Name == "" || units[u.Name] {
+			return fmt.Sprintf("Invalid or duplicate unit %q", u.Name)
+		}
+		if u.Factor <= 0 {
+			return fmt.Sprintf("Invalid factor for unit %q", u.Name)
+		}
+		if u.Price != nil && *u.Price < 0 {
+			return fmt.Sprintf("Invalid price for unit %q", u.Name)
+		}
+		units[u.Name] = true
+	}
+	if req.SaleUnit != "" && !units[req.SaleUnit] {
+		return fmt.Sprintf("Unknown sale_unit %q", req.SaleUnit)
+	}
+	if req.PurchaseUnit != "" && !units[req.PurchaseUnit] {
+		return fmt.Sprintf("Unknown purchase_unit %q", req.PurchaseUnit)
+	}
+	return ""
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"kasir-api/models"
+	"kasir-api/services"
+)
+
+type PurchaseOrderHandler struct {
+	service *services.PurchaseOrderService
+}
+
+func NewPurchaseOrderHandler(service *services.PurchaseOrderService) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{service: service}
+}
+
+// GetAll godoc
+// @Summary Get all purchase orders
+// @Description Get all purchase orders, newest first, optionally filtered by status
+// @Tags Purchase Orders
+// @Produce json
+// @Param status query string false "Status (draft, sent, partially_received, received, cancelled)"
+// @Success 200 {array} models.PurchaseOrder
+// @Router /purchase-orders [get]
+func (h *PurchaseOrderHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	orders, err := h.service.GetAll(r.URL.Query().Get("status"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(orders)
+}
+
+// Create godoc
+// @Summary Create purchase order
+// @Description Create a draft purchase order. Line units default to the product's purchase unit
+// @Tags Purchase Orders
+// @Accept json
+// @Produce json
+// @Param X-Actor header string false "User creating the order"
+// @Param purchase_order body models.PurchaseOrderRequest true "Purchase order data"
+// @Success 201 {object} models.PurchaseOrder
+// @Router /purchase-orders [post]
+func (h *PurchaseOrderHandler) Create(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodePurchaseOrderRequest(w, r)
+	if !ok {
+		return
+	}
+
+	order, err := h.service.Create(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(order)
+}
+
+// GetByID godoc
+// @Summary Get purchase order by ID
+// @Description Get a purchase order with its lines and received quantities
+// @Tags Purchase Orders
+// @Produce json
+// @Param id path int true "Purchase order ID"
+// @Success 200 {object} models.PurchaseOrder
+// @Failure 404 {string} string "Purchase order not found"
+// @Router /purchase-orders/{id} [get]
+func (h *PurchaseOrderHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.service.GetByID(id)
+	if err != nil {
+		http.Error(w, "Purchase order not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
+// Update godoc
+// @Summary Update purchase order
+// @Description Replace the supplier, notes and lines of a draft purchase order
+// @Tags Purchase Orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Purchase order ID"
+// @Param purchase_order body models.PurchaseOrderRequest true "Purchase order data"
+// @Success 200 {object} models.PurchaseOrder
+// @Router /purchase-orders/{id} [put]
+func (h *PurchaseOrderHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	req, ok := decodePurchaseOrderRequest(w, r)
+	if !ok {
+		return
+	}
+
+	order, err := h.service.Update(id, req)
+	writePurchaseOrderResult(w, order, err)
+}
+
+// Send godoc
+// @Summary Send purchase order
+// @Description Mark a draft purchase order as sent to the supplier
+// @Tags Purchase Orders
+// @Produce json
+// @Param id path int true "Purchase order ID"
+// @Success 200 {object} models.PurchaseOrder
+// @Router /purchase-orders/{id}/send [post]
+func (h *PurchaseOrderHandler) Send(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.service.Send(id)
+	writePurchaseOrderResult(w, order, err)
+}
+
+// Cancel godoc
+// @Summary Cancel purchase order
+// @Description Cancel a draft or sent purchase order that has not received any goods
+// @Tags Purchase Orders
+// @Produce json
+// @Param id path int true "Purchase order ID"
+// @Success 200 {object} models.PurchaseOrder
+// @Router /purchase-orders/{id}/cancel [post]
+func (h *PurchaseOrderHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.service.Cancel(id)
+	writePurchaseOrderResult(w, order, err)
+}
+
+// ExportPDF godoc
+// @Summary Export purchase order as PDF
+// @Description Download a printable PDF of the purchase order
+// @Tags Purchase Orders
+// @Produce application/pdf
+// @Param id path int true "Purchase order ID"
+// @Success 200 {file} file
+// @Router /purchase-orders/{id}/pdf [get]
+func (h *PurchaseOrderHandler) ExportPDF(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	doc, err := h.service.ExportPDF(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Purchase order not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"purchase-order-%d.pdf\"", id))
+	w.Write(doc)
+}
+
+// Handler routes requests to appropriate method handlers
+func (h *PurchaseOrderHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(r.URL.Path, "/")
+
+	switch {
+	case len(pathParts) == 2 || (len(pathParts) == 3 && pathParts[2] == ""):
+		switch r.Method {
+		case http.MethodGet:
+			h.GetAll(w, r)
+		case http.MethodPost:
+			h.Create(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	case len(pathParts) == 3:
+		switch r.Method {
+		case http.MethodGet:
+			h.GetByID(w, r)
+		case http.MethodPut:
+			h.Update(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	case len(pathParts) == 4:
+		routes := map[string]struct {
+			method  string
+			handler http.HandlerFunc
+		}{
+			"send":   {http.MethodPost, h.Send},
+			"cancel": {http.MethodPost, h.Cancel},
+			"pdf":    {http.MethodGet, h.ExportPDF},
+		}
+		route, ok := routes[pathParts[3]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != route.method {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		route.handler(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// decodePurchaseOrderRequest reads and validates a purchase order body.
+// On failure it writes the error response and returns ok = false.
+func decodePurchaseOrderRequest(w http.ResponseWriter, r *http.Request) (models.PurchaseOrderRequest, bool) {
+	var req models.PurchaseOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return req, false
+	}
+	if req.SupplierID == 0 {
+		http.Error(w, "supplier_id is required", http.StatusBadRequest)
+		return req, false
+	}
+	if req.ExpectedDate != "" {
+		if _, err := time.Parse("2006-01-02", req.ExpectedDate); err != nil {
+			http.Error(w, "Invalid expected_date format (use YYYY-MM-DD)", http.StatusBadRequest)
+			return req, false
+		}
+	}
+	if len(req.Lines) == 0 {
+		http.Error(w, "Purchase order requires at least one line", http.StatusBadRequest)
+		return req, false
+	}
+	for _, line := range req.Lines {
+		if line.Quantity <= 0 || line.UnitCost < 0 {
+			http.Error(w, fmt.Sprintf("Invalid quantity or unit_cost for product %d", line.ProductID), http.StatusBadRequest)
+			return req, false
+		}
+	}
+	req.Actor = actorFromRequest(r)
+	return req, true
+}
+
+func writePurchaseOrderResult(w http.ResponseWriter, order *models.PurchaseOrder, err error) {
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Purchase order not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
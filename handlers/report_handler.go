@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"encoding/csv"
 	"encoding/json"
+	"io"
 	"net/http"
+	"strconv"
 	"time"
 
+	"kasir-api/models"
 	"kasir-api/services"
 )
 
@@ -18,13 +22,19 @@ func NewReportHandler(service *services.ReportService) *ReportHandler {
 
 // GetReportToday godoc
 // @Summary Get sales report for today
-// @Description Get total revenue, total transactions, and best seller for today
+// @Description Get total revenue, total transactions, COGS, gross profit, margin and best seller for today
 // @Tags Reports
 // @Produce json
+// @Param X-Outlet-ID header int false "Outlet ID (default all outlets)"
 // @Success 200 {object} models.SalesReport
 // @Router /reports/today [get]
 func (h *ReportHandler) GetReportToday(w http.ResponseWriter, r *http.Request) {
-	report, err := h.service.GetReportToday()
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.GetReportToday(outletID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -42,40 +52,212 @@ func (h *ReportHandler) GetReportToday(w http.ResponseWriter, r *http.Request) {
 // @Param start_date query string true "Start Date (YYYY-MM-DD)"
 // @Param end_date query string true "End Date (YYYY-MM-DD)"
 // @Success 200 {object} models.SalesReport
+// @Param X-Outlet-ID header int false "Outlet ID (default all outlets)"
 // @Router /reports [get]
 func (h *ReportHandler) GetReportCustom(w http.ResponseWriter, r *http.Request) {
-	startDateStr := r.URL.Query().Get("start_date")
-	endDateStr := r.URL.Query().Get("end_date")
+	startDate, endDate, ok := parseDateRange(w, r)
+	if !ok {
+		return
+	}
 
-	if startDateStr == "" || endDateStr == "" {
-		http.Error(w, "start_date and end_date are required", http.StatusBadRequest)
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
 		return
 	}
 
-	layout := "2006-01-02"
-	startDate, err := time.Parse(layout, startDateStr)
+	report, err := h.service.GetReportByDateRange(startDate, endDate, outletID)
 	if err != nil {
-		http.Error(w, "Invalid start_date format (use YYYY-MM-DD)", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// GetProductSales godoc
+// @Summary Get sales per product
+// @Description Get quantity sold, revenue, COGS, gross profit and margin per product between start_date and end_date (YYYY-MM-DD). Bundles are reported as their components with allocated revenue
+// @Tags Reports
+// @Produce json
+// @Param start_date query string true "Start Date (YYYY-MM-DD)"
+// @Param end_date query string true "End Date (YYYY-MM-DD)"
+// @Success 200 {array} models.ProductSales
+// @Param X-Outlet-ID header int false "Outlet ID (default all outlets)"
+// @Router /reports/products [get]
+func (h *ReportHandler) GetProductSales(w http.ResponseWriter, r *http.Request) {
+	startDate, endDate, ok := parseDateRange(w, r)
+	if !ok {
+		return
+	}
+
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
 		return
 	}
 
-	endDate, err := time.Parse(layout, endDateStr)
+	sales, err := h.service.GetProductSales(startDate, endDate, outletID)
 	if err != nil {
-		http.Error(w, "Invalid end_date format (use YYYY-MM-DD)", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Adjust end date to include the whole day
-	endDate = endDate.Add(24 * time.Hour).Add(-1 * time.Nanosecond)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sales)
+}
+
+// GetCategorySales godoc
+// @Summary Get sales per category
+// @Description Get revenue, COGS, gross profit and margin per category between start_date and end_date (YYYY-MM-DD)
+// @Tags Reports
+// @Produce json
+// @Param start_date query string true "Start Date (YYYY-MM-DD)"
+// @Param end_date query string true "End Date (YYYY-MM-DD)"
+// @Success 200 {array} models.CategorySales
+// @Param X-Outlet-ID header int false "Outlet ID (default all outlets)"
+// @Router /reports/categories [get]
+func (h *ReportHandler) GetCategorySales(w http.ResponseWriter, r *http.Request) {
+	startDate, endDate, ok := parseDateRange(w, r)
+	if !ok {
+		return
+	}
 
-	report, err := h.service.GetReportByDateRange(startDate, endDate)
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	sales, err := h.service.GetCategorySales(startDate, endDate, outletID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(report)
+	json.NewEncoder(w).Encode(sales)
+}
+
+// GetExpiringStock godoc
+// @Summary Get expiring stock
+// @Description Get batches with stock left that expire within the given number of days (default 30),
+// @Description including batches already past their expiry date, valued at cost
+// @Tags Reports
+// @Produce json
+// @Param days query int false "Days ahead (default 30)"
+// @Success 200 {array} models.ExpiringStock
+// @Param X-Outlet-ID header int false "Outlet ID (default all outlets)"
+// @Router /reports/expiring [get]
+func (h *ReportHandler) GetExpiringStock(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "Invalid days", http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	stock, err := h.service.GetExpiringStock(days, outletID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stock)
+}
+
+// GetReorderSuggestions godoc
+// @Summary Get reorder suggestions
+// @Description Suggest what to order from the average daily sales over a window: days of cover left,
+// @Description safety stock, reorder point and suggested quantity. Parameters left out use the configured defaults.
+// @Tags Reports
+// @Produce json
+// @Produce text/csv
+// @Param window_days query int false "Days of sales history"
+// @Param lead_time_days query int false "Supplier lead time in days"
+// @Param safety_days query int false "Days of sales kept as safety stock"
+// @Param cover_days query int false "Days of sales an order should last"
+// @Param all query bool false "Include products that need no order"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {array} models.ReorderSuggestion
+// @Param X-Outlet-ID header int false "Outlet ID (default all outlets)"
+// @Router /reports/reorder [get]
+func (h *ReportHandler) GetReorderSuggestions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var params models.ReorderParams
+	fields := []struct {
+		name  string
+		value *int
+	}{
+		{"window_days", &params.WindowDays},
+		{"lead_time_days", &params.LeadTimeDays},
+		{"safety_days", &params.SafetyDays},
+		{"cover_days", &params.CoverDays},
+	}
+	for _, f := range fields {
+		v := query.Get(f.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "Invalid "+f.name, http.StatusBadRequest)
+			return
+		}
+		*f.value = n
+	}
+
+	format := query.Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		http.Error(w, "Invalid format (use json or csv)", http.StatusBadRequest)
+		return
+	}
+
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	suggestions, err := h.service.GetReorderSuggestions(params, outletID, query.Get("all") == "true")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", "attachment; filename=\"reorder-suggestions.csv\"")
+		writeReorderCSV(w, suggestions)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(suggestions)
+}
+
+func writeReorderCSV(w io.Writer, suggestions []models.ReorderSuggestion) {
+	out := csv.NewWriter(w)
+	out.Write([]string{"product_id", "product_name", "unit", "stock", "sold_quantity", "average_daily_sales",
+		"days_of_cover", "safety_stock", "reorder_point", "suggested_quantity"})
+	for _, s := range suggestions {
+		daysOfCover := ""
+		if s.DaysOfCover != nil {
+			daysOfCover = strconv.FormatFloat(*s.DaysOfCover, 'f', -1, 64)
+		}
+		out.Write([]string{
+			strconv.Itoa(s.ProductID), s.ProductName, s.Unit, s.Stock.String(), s.SoldQuantity.String(),
+			s.AverageDailySales.String(), daysOfCover, s.SafetyStock.String(), s.ReorderPoint.String(),
+			s.SuggestedQuantity.String(),
+		})
+	}
+	out.Flush()
 }
 
 // Handler routes requests to appropriate method handlers
@@ -91,3 +273,33 @@ func (h *ReportHandler) Handler(w http.ResponseWriter, r *http.Request) {
 	// Let's assume this Handler method handles /reports generic which might be today or custom
 	// Actually, better to split in main.go
 }
+
+// parseDateRange reads start_date and end_date (YYYY-MM-DD) from the query string.
+// On failure it writes the error response and returns ok = false.
+func parseDateRange(w http.ResponseWriter, r *http.Request) (startDate, endDate time.Time, ok bool) {
+	startDateStr := r.URL.Query().Get("start_date")
+	endDateStr := r.URL.Query().Get("end_date")
+
+	if startDateStr == "" || endDateStr == "" {
+		http.Error(w, "start_date and end_date are required", http.StatusBadRequest)
+		return
+	}
+
+	layout := "2006-01-02"
+	startDate, err := time.Parse(layout, startDateStr)
+	if err != nil {
+		http.Error(w, "Invalid start_date format (use YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+
+	endDate, err = time.Parse(layout, endDateStr)
+	if err != nil {
+		http.Error(w, "Invalid end_date format (use YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+
+	// Adjust end date to include the whole day
+	endDate = endDate.Add(24 * time.Hour).Add(-1 * time.Nanosecond)
+
+	return startDate, endDate, true
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"kasir-api/models"
+	"kasir-api/services"
+)
+
+type StockTakeHandler struct {
+	service *services.StockTakeService
+}
+
+func NewStockTakeHandler(service *services.StockTakeService) *StockTakeHandler {
+	return &StockTakeHandler{service: service}
+}
+
+// GetAll godoc
+// @Summary Get all stock takes
+// @Description Get all stock take (stock opname) sessions, newest first
+// @Tags Stock Takes
+// @Produce json
+// @Success 200 {array} models.StockTake
+// @Router /stock-takes [get]
+func (h *StockTakeHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	takes, err := h.service.GetAll()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(takes)
+}
+
+// Create godoc
+// @Summary Open stock take
+// @Description Open a count session for all products or only the given categories. Only one stock take can be open per outlet at a time
+// @Tags Stock Takes
+// @Accept json
+// @Produce json
+// @Param X-Actor header string false "User opening the count"
+// @Param X-Outlet-ID header int false "Outlet to count (default the default outlet)"
+// @Param stock_take body models.StockTakeRequest true "Stock take scope"
+// @Success 201 {object} models.StockTake
+// @Router /stock-takes [post]
+func (h *StockTakeHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.StockTakeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Actor = actorFromRequest(r)
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
+	req.OutletID = outletID
+
+	take, err := h.service.Create(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(take)
+}
+
+// GetByID godoc
+// @Summary Get stock take by ID
+// @Description Get a stock take with its lines and variances valued at cost
+// @Tags Stock Takes
+// @Produce json
+// @Param id path int true "Stock take ID"
+// @Success 200 {object} models.StockTake
+// @Failure 404 {string} string "Stock take not found"
+// @Router /stock-takes/{id} [get]
+func (h *StockTakeHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	take, err := h.service.GetByID(id)
+	if err != nil {
+		http.Error(w, "Stock take not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(take)
+}
+
+// SubmitCounts godoc
+// @Summary Submit counted quantities
+// @Description Submit counts by product ID or barcode. With add the quantity is added to the previous count (barcode scans), otherwise it replaces it
+// @Tags Stock Takes
+// @Accept json
+// @Produce json
+// @Param id path int true "Stock take ID"
+// @Param counts body models.StockCountRequest true "Counted quantities"
+// @Success 200 {object} models.StockTake
+// @Router /stock-takes/{id}/counts [post]
+func (h *StockTakeHandler) SubmitCounts(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.StockCountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	for _, item := range req.Items {
+		if item.ProductID == 0 && item.Barcode == "" {
+			http.Error(w, "Each count needs a product_id or barcode", http.StatusBadRequest)
+			return
+		}
+		if item.Quantity < 0 {
+			http.Error(w, fmt.Sprintf("Invalid quantity for product %d", item.ProductID), http.StatusBadRequest)
+			return
+		}
+	}
+	req.Actor = actorFromRequest(r)
+
+	take, err := h.service.SubmitCounts(id, req)
+	writeStockTakeResult(w, take, err)
+}
+
+// Approve godoc
+// @Summary Approve stock take
+// @Description Post the variance of every counted product to the stock ledger as adjustments, atomically
+// @Tags Stock Takes
+// @Produce json
+// @Param id path int true "Stock take ID"
+// @Param X-Actor header string false "User approving the count"
+// @Success 200 {object} models.StockTake
+// @Router /stock-takes/{id}/approve [post]
+func (h *StockTakeHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	take, err := h.service.Approve(id, actorFromRequest(r))
+	writeStockTakeResult(w, take, err)
+}
+
+// Cancel godoc
+// @Summary Cancel stock take
+// @Description Cancel an open stock take without changing stock
+// @Tags Stock Takes
+// @Produce json
+// @Param id path int true "Stock take ID"
+// @Success 200 {object} models.StockTake
+// @Router /stock-takes/{id}/cancel [post]
+func (h *StockTakeHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	take, err := h.service.Cancel(id)
+	writeStockTakeResult(w, take, err)
+}
+
+// Handler routes requests to appropriate method handlers
+func (h *StockTakeHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(r.URL.Path, "/")
+
+	switch {
+	case len(pathParts) == 2 || (len(pathParts) == 3 && pathParts[2] == ""):
+		switch r.Method {
+		case http.MethodGet:
+			h.GetAll(w, r)
+		case http.MethodPost:
+			h.Create(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	case len(pathParts) == 3:
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetByID(w, r)
+	case len(pathParts) == 4:
+		actions := map[string]http.HandlerFunc{
+			"counts":  h.SubmitCounts,
+			"approve": h.Approve,
+			"cancel":  h.Cancel,
+		}
+		action, ok := actions[pathParts[3]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		action(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeStockTakeResult(w http.ResponseWriter, take *models.StockTake, err error) {
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Stock take not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(take)
+}
@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"kasir-api/models"
+	"kasir-api/services"
+)
+
+type StockTransferHandler struct {
+	service *services.StockTransferService
+}
+
+func NewStockTransferHandler(service *services.StockTransferService) *StockTransferHandler {
+	return &StockTransferHandler{service: service}
+}
+
+// GetAll godoc
+// @Summary Get all stock transfers
+// @Description Get stock transfers newest first, optionally filtered by status, outlet or product.
+// @Description With a product_id each transfer only lists that product's line, giving its transfer history.
+// @Tags Stock Transfers
+// @Produce json
+// @Param status query string false "requested, in_transit, received or cancelled"
+// @Param product_id query int false "Product ID"
+// @Param X-Outlet-ID header int false "Only transfers from or to this outlet"
+// @Success 200 {array} models.StockTransfer
+// @Router /stock-transfers [get]
+func (h *StockTransferHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var productID int
+	if v := query.Get("product_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Invalid product_id", http.StatusBadRequest)
+			return
+		}
+		productID = id
+	}
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	transfers, err := h.service.GetAll(query.Get("status"), outletID, productID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transfers)
+}
+
+// Create godoc
+// @Summary Request stock transfer
+// @Description Request stock from a source outlet (e.g. the warehouse) for a destination outlet. Quantities are in the product's base unit
+// @Tags Stock Transfers
+// @Accept json
+// @Produce json
+// @Param X-Actor header string false "User requesting the transfer"
+// @Param stock_transfer body models.StockTransferRequest true "Transfer request"
+// @Success 201 {object} models.StockTransfer
+// @Router /stock-transfers [post]
+func (h *StockTransferHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.StockTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.SourceOutletID == 0 || req.DestinationOutletID == 0 {
+		http.Error(w, "source_outlet_id and destination_outlet_id are required", http.StatusBadRequest)
+		return
+	}
+	if req.SourceOutletID == req.DestinationOutletID {
+		http.Error(w, "Source and destination outlet must differ", http.StatusBadRequest)
+		return
+	}
+	if len(req.Lines) == 0 {
+		http.Error(w, "Stock transfer requires at least one line", http.StatusBadRequest)
+		return
+	}
+	for _, line := range req.Lines {
+		if line.Quantity <= 0 {
+			http.Error(w, fmt.Sprintf("Invalid quantity for product %d", line.ProductID), http.StatusBadRequest)
+			return
+		}
+	}
+	req.Actor = actorFromRequest(r)
+
+	transfer, err := h.service.Create(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(transfer)
+}
+
+// GetByID godoc
+// @Summary Get stock transfer by ID
+// @Description Get a stock transfer with requested, dispatched and received quantities and the dispatched batches
+// @Tags Stock Transfers
+// @Produce json
+// @Param id path int true "Stock transfer ID"
+// @Success 200 {object} models.StockTransfer
+// @Failure 404 {string} string "Stock transfer not found"
+// @Router /stock-transfers/{id} [get]
+func (h *StockTransferHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	transfer, err := h.service.GetByID(id)
+	writeStockTransferResult(w, transfer, err)
+}
+
+// Dispatch godoc
+// @Summary Dispatch stock transfer
+// @Description Take the stock out of the source outlet and put it in transit. Lines left out ship the
+// @Description requested quantity, a line may ship less when the source does not have it all
+// @Tags Stock Transfers
+// @Accept json
+// @Produce json
+// @Param id path int true "Stock transfer ID"
+// @Param X-Actor header string false "User dispatching the goods"
+// @Param dispatch body models.TransferDispatchRequest false "Dispatched quantities"
+// @Success 200 {object} models.StockTransfer
+// @Router /stock-transfers/{id}/dispatch [post]
+func (h *StockTransferHandler) Dispatch(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.TransferDispatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	for _, line := range req.Lines {
+		if line.Quantity < 0 {
+			http.Error(w, fmt.Sprintf("Invalid quantity for product %d", line.ProductID), http.StatusBadRequest)
+			return
+		}
+	}
+	req.Actor = actorFromRequest(r)
+
+	transfer, err := h.service.Dispatch(id, req)
+	writeStockTransferResult(w, transfer, err)
+}
+
+// Receive godoc
+// @Summary Receive stock transfer
+// @Description Book the arrived stock into the destination outlet. Lines left out are received as
+// @Description dispatched; receiving a different quantity records a discrepancy and needs a reason
+// @Tags Stock Transfers
+// @Accept json
+// @Produce json
+// @Param id path int true "Stock transfer ID"
+// @Param X-Actor header string false "User receiving the goods"
+// @Param receive body models.TransferReceiveRequest false "Received quantities"
+// @Success 200 {object} models.StockTransfer
+// @Router /stock-transfers/{id}/receive [post]
+func (h *StockTransferHandler) Receive(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.TransferReceiveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	for _, line := range req.Lines {
+		if line.Quantity < 0 {
+			http.Error(w, fmt.Sprintf("Invalid quantity for product %d", line.ProductID), http.StatusBadRequest)
+			return
+		}
+	}
+	req.Actor = actorFromRequest(r)
+
+	transfer, err := h.service.Receive(id, req)
+	writeStockTransferResult(w, transfer, err)
+}
+
+// Cancel godoc
+// @Summary Cancel stock transfer
+// @Description Cancel a transfer that has not been dispatched yet
+// @Tags Stock Transfers
+// @Produce json
+// @Param id path int true "Stock transfer ID"
+// @Success 200 {object} models.StockTransfer
+// @Router /stock-transfers/{id}/cancel [post]
+func (h *StockTransferHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	transfer, err := h.service.Cancel(id)
+	writeStockTransferResult(w, transfer, err)
+}
+
+// GetInTransit godoc
+// @Summary Get stock in transit
+// @Description Get the stock dispatched but not yet received, per product and destination outlet
+// @Tags Stock Transfers
+// @Produce json
+// @Param X-Outlet-ID header int false "Only transfers from or to this outlet"
+// @Success 200 {array} models.InTransitStock
+// @Router /stock-transfers/in-transit [get]
+func (h *StockTransferHandler) GetInTransit(w http.ResponseWriter, r *http.Request) {
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	stock, err := h.service.GetInTransit(outletID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stock)
+}
+
+// Handler routes requests to appropriate method handlers
+func (h *StockTransferHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(r.URL.Path, "/")
+
+	switch {
+	case len(pathParts) == 2 || (len(pathParts) == 3 && pathParts[2] == ""):
+		switch r.Method {
+		case http.MethodGet:
+			h.GetAll(w, r)
+		case http.MethodPost:
+			h.Create(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	case len(pathParts) == 3:
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if pathParts[2] == "in-transit" {
+			h.GetInTransit(w, r)
+			return
+		}
+		h.GetByID(w, r)
+	case len(pathParts) == 4:
+		actions := map[string]http.HandlerFunc{
+			"dispatch": h.Dispatch,
+			"receive":  h.Receive,
+			"cancel":   h.Cancel,
+		}
+		action, ok := actions[pathParts[3]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		action(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeStockTransferResult(w http.ResponseWriter, transfer *models.StockTransfer, err error) {
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Stock transfer not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transfer)
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"kasir-api/models"
+	"kasir-api/services"
+)
+
+type TableHandler struct {
+	service *services.TableService
+}
+
+func NewTableHandler(service *services.TableService) *TableHandler {
+	return &TableHandler{service: service}
+}
+
+// GetAll godoc
+// @Summary Get all tables
+// @Description Get dining tables by area and name with whether an order occupies them
+// @Tags Tables
+// @Produce json
+// @Param area query string false "Area"
+// @Param X-Outlet-ID header int false "Outlet ID (default all outlets)"
+// @Success 200 {array} models.Table
+// @Router /tables [get]
+func (h *TableHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	tables, err := h.service.GetAll(outletID, strings.TrimSpace(r.URL.Query().Get("area")))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tables)
+}
+
+// Create godoc
+// @Summary Create table
+// @Description Add a dining table to the outlet. Table names are unique per outlet
+// @Tags Tables
+// @Accept json
+// @Produce json
+// @Param table body models.TableRequest true "Table data"
+// @Param X-Outlet-ID header int false "Outlet ID (default the default outlet)"
+// @Success 201 {object} models.Table
+// @Router /tables [post]
+func (h *TableHandler) Create(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeTableRequest(w, r)
+	if !ok {
+		return
+	}
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return
+	}
+	req.OutletID = outletID
+
+	table, err := h.service.Create(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(table)
+}
+
+// GetByID godoc
+// @Summary Get table by ID
+// @Description Get a dining table with the order occupying it
+// @Tags Tables
+// @Produce json
+// @Param id path int true "Table ID"
+// @Success 200 {object} models.Table
+// @Failure 404 {string} string "Table not found"
+// @Router /tables/{id} [get]
+func (h *TableHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	table, err := h.service.GetByID(id)
+	writeTableResult(w, table, err)
+}
+
+// Update godoc
+// @Summary Update table
+// @Description Update a dining table's name, area, seats and whether it is in use
+// @Tags Tables
+// @Accept json
+// @Produce json
+// @Param id path int true "Table ID"
+// @Param table body models.TableRequest true "Table data"
+// @Success 200 {object} models.Table
+// @Failure 404 {string} string "Table not found"
+// @Router /tables/{id} [put]
+func (h *TableHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	req, ok := decodeTableRequest(w, r)
+	if !ok {
+		return
+	}
+
+	table, err := h.service.Update(id, req)
+	writeTableResult(w, table, err)
+}
+
+// Delete godoc
+// @Summary Delete table
+// @Description Delete a dining table that no order occupies
+// @Tags Tables
+// @Produce json
+// @Param id path int true "Table ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {string} string "Table not found"
+// @Router /tables/{id} [delete]
+func (h *TableHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		writeTableResult(w, nil, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": fmt.Sprintf("Table with ID %d deleted successfully", id),
+	})
+}
+
+// Handler routes requests to appropriate method handlers
+func (h *TableHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(r.URL.Path, "/")
+
+	switch {
+	case len(pathParts) == 2 || (len(pathParts) == 3 && pathParts[2] == ""):
+		switch r.Method {
+		case http.MethodGet:
+			h.GetAll(w, r)
+		case http.MethodPost:
+			h.Create(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	case len(pathParts) == 3:
+		switch r.Method {
+		case http.MethodGet:
+			h.GetByID(w, r)
+		case http.MethodPut:
+			h.Update(w, r)
+		case http.MethodDelete:
+			h.Delete(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// decodeTableRequest reads and validates a table body.
+// On failure it writes the error response and returns ok = false.
+func decodeTableRequest(w http.ResponseWriter, r *http.Request) (models.TableRequest, bool) {
+	var req models.TableRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return req, false
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	req.Area = strings.TrimSpace(req.Area)
+	if req.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return req, false
+	}
+	if req.Seats < 0 {
+		http.Error(w, "seats cannot be negative", http.StatusBadRequest)
+		return req, false
+	}
+	return req, true
+}
+
+func writeTableResult(w http.ResponseWriter, table *models.Table, err error) {
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Table not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(table)
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"kasir-api/models"
+	"kasir-api/services"
+)
+
+// apiKeyFromRequest reads the key from an "Authorization: Bearer <key>" header
+func apiKeyFromRequest(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+}
+
+// RequireAdmin only lets requests carrying the admin API key through. Without a configured
+// key the wrapped routes are disabled.
+func RequireAdmin(adminKey string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := apiKeyFromRequest(r)
+		if adminKey == "" || subtle.ConstantTimeCompare([]byte(key), []byte(adminKey)) != 1 {
+			http.Error(w, "Admin API key required", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// TenantRouter authenticates the tenant of each request by its API key and serves it from the
+// tenant's own routes
+type TenantRouter struct {
+	service    *services.TenantService
+	handlerFor func(tenant models.Tenant) (http.Handler, error)
+}
+
+func NewTenantRouter(service *services.TenantService, handlerFor func(tenant models.Tenant) (http.Handler, error)) *TenantRouter {
+	return &TenantRouter{service: service, handlerFor: handlerFor}
+}
+
+func (t *TenantRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := apiKeyFromRequest(r)
+	if key == "" {
+		http.Error(w, "API key required", http.StatusUnauthorized)
+		return
+	}
+	tenant, err := t.service.Authenticate(key)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Invalid API key", http.StatusUnauthorized)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !tenant.Active {
+		http.Error(w, "Tenant is suspended", http.StatusForbidden)
+		return
+	}
+
+	handler, err := t.handlerFor(*tenant)
+	if err != nil {
+		log.Printf("tenant %d: %v", tenant.ID, err)
+		http.Error(w, "Tenant unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	handler.ServeHTTP(w, r)
+}
+
+// TenantCallback serves the notifications third parties post for a tenant at path followed by the
+// tenant ID, such as payment gateway callbacks. They cannot carry the tenant's API key, so the tenant
+// is taken from the URL and served the request at path, where the notification authenticates itself
+// with the sender's signature.
+type TenantCallback struct {
+	service    *services.TenantService
+	handlerFor func(tenant models.Tenant) (http.Handler, error)
+	path       string
+}
+
+func NewTenantCallback(service *services.TenantService, handlerFor func(tenant models.Tenant) (http.Handler, error), path string) *TenantCallback {
+	return &TenantCallback{service: service, handlerFor: handlerFor, path: path}
+}
+
+func (t *TenantCallback) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, t.path+"/"))
+	if err != nil || id <= 0 {
+		http.NotFound(w, r)
+		return
+	}
+	tenant, err := t.service.GetByID(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !tenant.Active {
+		http.Error(w, "Tenant is suspended", http.StatusForbidden)
+		return
+	}
+
+	handler, err := t.handlerFor(*tenant)
+	if err != nil {
+		log.Printf("tenant %d: %v", tenant.ID, err)
+		http.Error(w, "Tenant unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	r = r.Clone(r.Context())
+	r.URL.Path = t.path
+	r.URL.RawPath = ""
+	handler.ServeHTTP(w, r)
+}
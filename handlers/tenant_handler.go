@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"kasir-api/models"
+	"kasir-api/services"
+)
+
+type TenantHandler struct {
+	service *services.TenantService
+}
+
+func NewTenantHandler(service *services.TenantService) *TenantHandler {
+	return &TenantHandler{service: service}
+}
+
+// GetAll godoc
+// @Summary Get all tenants
+// @Description Get all tenants of a multi-tenant deployment. Requires the admin API key
+// @Tags Tenants
+// @Produce json
+// @Param Authorization header string true "Bearer admin API key"
+// @Success 200 {array} models.Tenant
+// @Router /tenants [get]
+func (h *TenantHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	tenants, err := h.service.GetAll()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tenants)
+}
+
+// Create godoc
+// @Summary Provision tenant
+// @Description Create a tenant with a default outlet. The response carries the tenant's API key,
+// @Description which is only shown once. Requires the admin API key
+// @Tags Tenants
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer admin API key"
+// @Param tenant body models.TenantRequest true "Tenant data"
+// @Success 201 {object} models.Tenant
+// @Router /tenants [post]
+func (h *TenantHandler) Create(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeTenantRequest(w, r)
+	if !ok {
+		return
+	}
+
+	tenant, err := h.service.Create(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tenant)
+}
+
+// GetByID godoc
+// @Summary Get tenant by ID
+// @Description Get a tenant with its settings. Requires the admin API key
+// @Tags Tenants
+// @Produce json
+// @Param Authorization header string true "Bearer admin API key"
+// @Param id path int true "Tenant ID"
+// @Success 200 {object} models.Tenant
+// @Failure 404 {string} string "Tenant not found"
+// @Router /tenants/{id} [get]
+func (h *TenantHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	tenant, err := h.service.GetByID(id)
+	writeTenantResult(w, tenant, err)
+}
+
+// Update godoc
+// @Summary Update tenant
+// @Description Update a tenant's name, status and settings. Suspended tenants are refused. Requires the admin API key
+// @Tags Tenants
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer admin API key"
+// @Param id path int true "Tenant ID"
+// @Param tenant body models.TenantRequest true "Tenant data"
+// @Success 200 {object} models.Tenant
+// @Router /tenants/{id} [put]
+func (h *TenantHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	req, ok := decodeTenantRequest(w, r)
+	if !ok {
+		return
+	}
+
+	tenant, err := h.service.Update(id, req)
+	writeTenantResult(w, tenant, err)
+}
+
+// RotateAPIKey godoc
+// @Summary Rotate tenant API key
+// @Description Issue a new API key for the tenant, the previous key stops working. Requires the admin API key
+// @Tags Tenants
+// @Produce json
+// @Param Authorization header string true "Bearer admin API key"
+// @Param id path int true "Tenant ID"
+// @Success 200 {object} models.Tenant
+// @Router /tenants/{id}/api-key [post]
+func (h *TenantHandler) RotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	tenant, err := h.service.RotateAPIKey(id)
+	writeTenantResult(w, tenant, err)
+}
+
+// Handler routes requests to appropriate method handlers
+func (h *TenantHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(r.URL.Path, "/")
+
+	switch {
+	case len(pathParts) == 2 || (len(pathParts) == 3 && pathParts[2] == ""):
+		switch r.Method {
+		case http.MethodGet:
+			h.GetAll(w, r)
+		case http.MethodPost:
+			h.Create(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	case len(pathParts) == 3:
+		switch r.Method {
+		case http.MethodGet:
+			h.GetByID(w, r)
+		case http.MethodPut:
+			h.Update(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	case len(pathParts) == 4 && pathParts[3] == "api-key":
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.RotateAPIKey(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// decodeTenantRequest reads and validates a tenant body.
+// On failure it writes the error response and returns ok = false.
+func decodeTenantRequest(w http.ResponseWriter, r *http.Request) (models.TenantRequest, bool) {
+	var req models.TenantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return req, false
+	}
+	if req.Slug == "" || req.Name == "" {
+		http.Error(w, "slug and name are required", http.StatusBadRequest)
+		return req, false
+	}
+	policy := req.Settings.ExpiredSalePolicy
+	if policy != "" && policy != models.ExpiredSaleBlock && policy != models.ExpiredSaleWarn {
+		http.Error(w, "expired_sale_policy must be block or warn", http.StatusBadRequest)
+		return req, false
+	}
+	s := req.Settings
+	if s.ReorderWindowDays < 0 || s.ReorderLeadTimeDays < 0 || s.ReorderSafetyDays < 0 || s.ReorderCoverDays < 0 {
+		http.Error(w, "Reorder settings cannot be negative", http.StatusBadRequest)
+		return req, false
+	}
+	switch s.LoyaltyRounding {
+	case "", models.LoyaltyRoundDown, models.LoyaltyRoundNearest, models.LoyaltyRoundUp:
+	default:
+		http.Error(w, "loyalty_rounding must be down, nearest or up", http.StatusBadRequest)
+		return req, false
+	}
+	if s.LoyaltySpendPerPoint < 0 || s.LoyaltyPointValue < 0 || s.LoyaltyExpiryDays < 0 {
+		http.Error(w, "Loyalty settings cannot be negative", http.StatusBadRequest)
+		return req, false
+	}
+	if s.GiftCardExpiryDays < 0 {
+		http.Error(w, "gift_card_expiry_days cannot be negative", http.StatusBadRequest)
+		return req, false
+	}
+	if s.DraftOrderExpiryMinutes < 0 {
+		http.Error(w, "draft_order_expiry_minutes cannot be negative", http.StatusBadRequest)
+		return req, false
+	}
+	return req, true
+}
+
+func writeTenantResult(w http.ResponseWriter, tenant *models.Tenant, err error) {
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Tenant not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tenant)
+}
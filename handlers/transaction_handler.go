@@ -1,8 +1,13 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
 
 	"kasir-api/models"
 	"kasir-api/services"
@@ -18,22 +23,31 @@ func NewTransactionHandler(service *services.TransactionService) *TransactionHan
 
 // Create godoc
 // @Summary Create transaction (checkout)
-// @Description Create a new transaction with multiple items
+// @Description Create a new transaction with multiple items. Attach a customer with customer_id or customer_phone,
+// @Description the customer earns loyalty points and can pay part of the total with redeem_points
+// @Description Items are priced from the price list chosen by price_list_id, customer_group or the customer's group
+// @Description gift_cards sells gift cards and vouchers, gift_card_payments pays with them
+// @Description on_account charges what is left to pay to the customer's account within their credit limit
 // @Tags Transactions
 // @Accept json
 // @Produce json
 // @Param checkout body models.CheckoutRequest true "Checkout data"
 // @Success 201 {object} models.Transaction
+// @Failure 400 {string} string "A quantity or amount is out of range"
+// @Param X-Outlet-ID header int false "Outlet ID (default the default outlet)"
 // @Router /transactions [post]
 func (h *TransactionHandler) Create(w http.ResponseWriter, r *http.Request) {
-	var req models.CheckoutRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	req, ok := decodeCheckoutRequest(w, r)
+	if !ok {
 		return
 	}
 
 	transaction, err := h.service.Create(req)
 	if err != nil {
+		if errors.Is(err, models.ErrOutOfRange) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -43,12 +57,105 @@ func (h *TransactionHandler) Create(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(transaction)
 }
 
+// Refund godoc
+// @Summary Refund transaction
+// @Description Refund a whole transaction. The stock returns to the outlet and its batches, loyalty points
+// @Description earned with the sale are reversed and points paid with are given back.
+// @Description The unpaid part of an on-account sale is written off the customer's account.
+// @Description Refunded transactions are left out of the reports.
+// @Description Sales awaiting a QRIS payment cannot be refunded, their payment is cancelled instead.
+// @Tags Transactions
+// @Accept json
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Param refund body models.RefundRequest false "Refund reason"
+// @Param X-Actor header string false "Who refunds the transaction"
+// @Success 200 {object} models.Transaction
+// @Failure 404 {string} string "Transaction not found"
+// @Router /transactions/{id}/refund [post]
+func (h *TransactionHandler) Refund(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.RefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Actor = actorFromRequest(r)
+
+	transaction, err := h.service.Refund(id, req)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Transaction not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transaction)
+}
+
 // Handler routes requests to appropriate method handlers
 func (h *TransactionHandler) Handler(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodPost:
-		h.Create(w, r)
+	pathParts := strings.Split(r.URL.Path, "/")
+
+	switch {
+	case len(pathParts) == 2 || (len(pathParts) == 3 && pathParts[2] == ""):
+		switch r.Method {
+		case http.MethodPost:
+			h.Create(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	case len(pathParts) == 4 && pathParts[3] == "refund":
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.Refund(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		http.NotFound(w, r)
+	}
+}
+
+// decodeCheckoutRequest reads and validates a checkout body, taking the outlet and actor from the headers.
+// On failure it writes the error response and returns ok = false.
+func decodeCheckoutRequest(w http.ResponseWriter, r *http.Request) (models.CheckoutRequest, bool) {
+	var req models.CheckoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return req, false
+	}
+	if len(req.Items) == 0 && len(req.GiftCards) == 0 {
+		http.Error(w, "Checkout requires at least one item or gift card", http.StatusBadRequest)
+		return req, false
+	}
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			http.Error(w, fmt.Sprintf("Invalid quantity for product %d", item.ProductID), http.StatusBadRequest)
+			return req, false
+		}
+	}
+	if req.RedeemPoints < 0 {
+		http.Error(w, "redeem_points cannot be negative", http.StatusBadRequest)
+		return req, false
+	}
+	if msg := validateGiftCards(req.GiftCards, req.GiftCardPayments); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return req, false
+	}
+
+	req.Actor = actorFromRequest(r)
+	outletID, ok := outletFromRequest(w, r)
+	if !ok {
+		return req, false
 	}
+	req.OutletID = outletID
+	return req, true
 }
@@ -0,0 +1,348 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"kasir-api/events"
+	"kasir-api/models"
+	"kasir-api/services"
+)
+
+type WebhookHandler struct {
+	service *services.WebhookService
+}
+
+func NewWebhookHandler(service *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+// GetAll godoc
+// @Summary Get webhook subscriptions
+// @Description Get webhook subscriptions, without their secrets
+// @Tags Webhooks
+// @Produce json
+// @Success 200 {array} models.WebhookSubscription
+// @Router /webhooks [get]
+func (h *WebhookHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := h.service.GetAll()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(subscriptions)
+}
+
+// Create godoc
+// @Summary Create webhook subscription
+// @Description Subscribe a URL to events (transaction.created, transaction.refunded, product.created,
+// @Description product.updated, product.deleted, stock.low), all of them when event_types is empty.
+// @Description Each delivery is a POST of the JSON event signed in the X-Webhook-Signature header.
+// @Description The secret is generated unless given and is only returned here.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param subscription body models.WebhookSubscriptionRequest true "Subscription data"
+// @Success 201 {object} models.WebhookSubscription
+// @Router /webhooks [post]
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeWebhookRequest(w, r)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.service.Create(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(subscription)
+}
+
+// GetByID godoc
+// @Summary Get webhook subscription
+// @Description Get a webhook subscription, without its secret
+// @Tags Webhooks
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {object} models.WebhookSubscription
+// @Failure 404 {string} string "Webhook not found"
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	subscription, err := h.service.GetByID(id)
+	writeWebhookResult(w, subscription, err, "Webhook not found")
+}
+
+// Update godoc
+// @Summary Update webhook subscription
+// @Description Update a webhook subscription. The secret is kept unless a new one is given.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param subscription body models.WebhookSubscriptionRequest true "Subscription data"
+// @Success 200 {object} models.WebhookSubscription
+// @Failure 404 {string} string "Webhook not found"
+// @Router /webhooks/{id} [put]
+func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	req, ok := decodeWebhookRequest(w, r)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.service.Update(id, req)
+	writeWebhookResult(w, subscription, err, "Webhook not found")
+}
+
+// Delete godoc
+// @Summary Delete webhook subscription
+// @Description Delete a webhook subscription with its delivery log
+// @Tags Webhooks
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {string} string "Webhook not found"
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		writeWebhookResult(w, nil, err, "Webhook not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": fmt.Sprintf("Webhook with ID %d deleted successfully", id),
+	})
+}
+
+// GetDeliveries godoc
+// @Summary Get webhook delivery log
+// @Description Get the 100 most recent deliveries of a subscription with their payloads and attempt outcomes
+// @Tags Webhooks
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param status query string false "Status (pending, delivered, failed)"
+// @Success 200 {array} models.WebhookDelivery
+// @Failure 404 {string} string "Webhook not found"
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.WebhookPending, models.WebhookDelivered, models.WebhookFailed:
+	default:
+		http.Error(w, "Invalid status", http.StatusBadRequest)
+		return
+	}
+
+	deliveries, err := h.service.GetDeliveries(id, status)
+	writeWebhookResult(w, deliveries, err, "Webhook not found")
+}
+
+// Test godoc
+// @Summary Test webhook subscription
+// @Description Send a webhook.test event to the subscription, even when inactive, and return the delivery
+// @Tags Webhooks
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {object} models.WebhookDelivery
+// @Failure 404 {string} string "Webhook not found"
+// @Router /webhooks/{id}/test [post]
+func (h *WebhookHandler) Test(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	delivery, err := h.service.Test(id)
+	writeWebhookResult(w, delivery, err, "Webhook not found")
+}
+
+// GetDelivery godoc
+// @Summary Get webhook delivery
+// @Description Get a webhook delivery with its payload and the outcome of its last attempt
+// @Tags Webhooks
+// @Produce json
+// @Param id path int true "Delivery ID"
+// @Success 200 {object} models.WebhookDelivery
+// @Failure 404 {string} string "Delivery not found"
+// @Router /webhook-deliveries/{id} [get]
+func (h *WebhookHandler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	delivery, err := h.service.GetDelivery(id)
+	writeWebhookResult(w, delivery, err, "Delivery not found")
+}
+
+// Redeliver godoc
+// @Summary Redeliver webhook
+// @Description Send a delivery again now, whatever its status, with the same payload and a fresh signature.
+// @Description A failed delivery gets one more attempt, a pending one carries on with its retries.
+// @Tags Webhooks
+// @Produce json
+// @Param id path int true "Delivery ID"
+// @Success 200 {object} models.WebhookDelivery
+// @Failure 404 {string} string "Delivery not found"
+// @Router /webhook-deliveries/{id}/redeliver [post]
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	id := extractID(r.URL.Path)
+	if id == 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	delivery, err := h.service.Redeliver(id)
+	writeWebhookResult(w, delivery, err, "Delivery not found")
+}
+
+// Handler routes /webhooks requests to appropriate method handlers
+func (h *WebhookHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(r.URL.Path, "/")
+
+	switch {
+	case len(pathParts) == 2 || (len(pathParts) == 3 && pathParts[2] == ""):
+		switch r.Method {
+		case http.MethodGet:
+			h.GetAll(w, r)
+		case http.MethodPost:
+			h.Create(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	case len(pathParts) == 3:
+		switch r.Method {
+		case http.MethodGet:
+			h.GetByID(w, r)
+		case http.MethodPut:
+			h.Update(w, r)
+		case http.MethodDelete:
+			h.Delete(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	case len(pathParts) == 4:
+		routes := map[string]struct {
+			method  string
+			handler http.HandlerFunc
+		}{
+			"deliveries": {http.MethodGet, h.GetDeliveries},
+			"test":       {http.MethodPost, h.Test},
+		}
+		route, ok := routes[pathParts[3]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != route.method {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		route.handler(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// DeliveryHandler routes /webhook-deliveries requests to appropriate method handlers
+func (h *WebhookHandler) DeliveryHandler(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(r.URL.Path, "/")
+
+	switch {
+	case len(pathParts) == 3 && pathParts[2] != "":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetDelivery(w, r)
+	case len(pathParts) == 4 && pathParts[3] == "redeliver":
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.Redeliver(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// decodeWebhookRequest reads and validates a webhook subscription body.
+// On failure it writes the error response and returns ok = false.
+func decodeWebhookRequest(w http.ResponseWriter, r *http.Request) (models.WebhookSubscriptionRequest, bool) {
+	var req models.WebhookSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return req, false
+	}
+	req.URL = strings.TrimSpace(req.URL)
+	req.Description = strings.TrimSpace(req.Description)
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		http.Error(w, "url must be an http or https URL", http.StatusBadRequest)
+		return req, false
+	}
+	seen := make(map[string]bool)
+	for _, eventType := range req.EventTypes {
+		if !slices.Contains(events.WebhookTypes, eventType) {
+			http.Error(w, fmt.Sprintf("Unknown event type %q", eventType), http.StatusBadRequest)
+			return req, false
+		}
+		if seen[eventType] {
+			http.Error(w, fmt.Sprintf("Duplicate event type %q", eventType), http.StatusBadRequest)
+			return req, false
+		}
+		seen[eventType] = true
+	}
+	return req, true
+}
+
+func writeWebhookResult(w http.ResponseWriter, result interface{}, err error, notFound string) {
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, notFound, http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
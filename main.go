@@ -1,16 +1,23 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"sync"
+	"time"
 
 	_ "kasir-api/docs"
 
 	"kasir-api/config"
 	"kasir-api/database"
+	"kasir-api/events"
 	"kasir-api/handlers"
+	"kasir-api/models"
+	"kasir-api/payments"
 	"kasir-api/repositories"
 	"kasir-api/services"
 
@@ -62,6 +69,11 @@ func welcomeHandler(w http.ResponseWriter, r *http.Request) {
 			"GET  /products     - Get all products",
 			"POST /products     - Create product",
 			"GET  /products/:id - Get product by ID",
+			"GET  /products/:id/cost-history - Get product cost price history",
+			"GET  /products/:id/batches - Get product batches with expiry dates",
+			"GET  /products/low-stock - Get products at or below their minimum stock",
+			"GET  /products/:id/movements - Get product stock ledger with running balance",
+			"POST /products/:id/adjustments - Adjust stock or record waste",
 			"PUT  /products/:id - Update product",
 			"DELETE /products/:id - Delete product",
 			"GET  /categories     - Get all categories",
@@ -70,8 +82,125 @@ func welcomeHandler(w http.ResponseWriter, r *http.Request) {
 			"PUT  /categories/:id - Update category",
 			"DELETE /categories/:id - Delete category",
 			"POST /transactions   - Create transaction (checkout)",
+			"POST /transactions/:id/refund - Refund transaction",
+			"POST /payments/qris - Check out with a QRIS payment",
+			"GET  /payments - Get payments (status filter)",
+			"GET  /payments/:id - Get payment",
+			"GET  /payments/:id/qr.png - Get payment QR code",
+			"POST /payments/:id/cancel - Cancel payment",
+			"POST /payments/callback - Payment gateway callback (/payments/callback/:tenant_id in multi-tenant mode)",
+			"GET  /draft-orders   - Get parked carts",
+			"POST /draft-orders   - Park a cart without taking stock",
+			"GET  /draft-orders/:id - Get parked cart",
+			"PUT  /draft-orders/:id - Update parked cart",
+			"DELETE /draft-orders/:id - Discard parked cart",
+			"POST /draft-orders/:id/checkout - Check out parked cart",
+			"GET  /tables         - Get dining tables with their occupancy",
+			"POST /tables         - Create dining table",
+			"GET  /tables/:id     - Get dining table",
+			"PUT  /tables/:id     - Update dining table",
+			"DELETE /tables/:id   - Delete dining table",
+			"GET  /dine-in-orders - Get dine-in orders",
+			"POST /dine-in-orders - Open dine-in order at tables",
+			"GET  /dine-in-orders/:id - Get dine-in order",
+			"POST /dine-in-orders/:id/items - Add a round of items",
+			"POST /dine-in-orders/:id/send - Send order to the kitchen",
+			"POST /dine-in-orders/:id/serve - Mark order served",
+			"POST /dine-in-orders/:id/move - Move order to other tables",
+			"POST /dine-in-orders/:id/merge - Merge another order into this one",
+			"POST /dine-in-orders/:id/bill - Bill order, optionally split by item",
+			"POST /dine-in-orders/:id/payments - Pay billed order, optionally split by amount",
+			"POST /dine-in-orders/:id/cancel - Cancel dine-in order",
+			"GET  /kitchen-stations - Get kitchen stations",
+			"POST /kitchen-stations - Create kitchen station for some categories",
+			"GET  /kitchen-stations/:id - Get kitchen station",
+			"PUT  /kitchen-stations/:id - Update kitchen station",
+			"DELETE /kitchen-stations/:id - Delete kitchen station",
+			"GET  /kitchen-stations/:id/queue - Get station queue with timers",
+			"GET  /kitchen-stations/:id/stream - Stream station queue (Server-Sent Events)",
+			"POST /kitchen-items/:id/start - Start preparing kitchen item",
+			"POST /kitchen-items/:id/ready - Mark kitchen item ready",
+			"POST /kitchen-items/:id/bump - Bump kitchen item off the screen",
+			"POST /kitchen-items/:id/recall - Recall bumped kitchen item",
+			"GET  /stock-takes    - Get all stock takes",
+			"POST /stock-takes    - Open stock take (stock opname)",
+			"GET  /stock-takes/:id - Get stock take with variances",
+			"POST /stock-takes/:id/counts - Submit counted quantities or barcode scans",
+			"POST /stock-takes/:id/approve - Approve and post adjustments",
+			"POST /stock-takes/:id/cancel - Cancel stock take",
+			"GET  /suppliers     - Get all suppliers",
+			"POST /suppliers     - Create supplier",
+			"GET  /suppliers/:id - Get supplier by ID",
+			"PUT  /suppliers/:id - Update supplier",
+			"DELETE /suppliers/:id - Delete supplier",
+			"GET  /purchase-orders - Get all purchase orders",
+			"POST /purchase-orders - Create draft purchase order",
+			"GET  /purchase-orders/:id - Get purchase order by ID",
+			"PUT  /purchase-orders/:id - Update draft purchase order",
+			"POST /purchase-orders/:id/send - Mark purchase order as sent",
+			"POST /purchase-orders/:id/cancel - Cancel purchase order",
+			"GET  /purchase-orders/:id/pdf - Export purchase order as PDF",
+			"GET  /goods-receipts - Get goods receipts history",
+			"POST /goods-receipts - Receive goods from a supplier",
+			"GET  /goods-receipts/:id - Get goods receipt by ID",
+			"GET  /outlets     - Get all outlets",
+			"POST /outlets     - Create outlet",
+			"GET  /outlets/:id - Get outlet by ID",
+			"PUT  /outlets/:id - Update outlet",
+			"DELETE /outlets/:id - Delete outlet",
+			"GET  /outlets/:id/stock - Get stock and prices at an outlet",
+			"PUT  /outlets/:id/prices - Set outlet price overrides",
+			"GET  /stock-transfers - Get stock transfers (filter by status, outlet or product)",
+			"POST /stock-transfers - Request stock transfer between outlets",
+			"GET  /stock-transfers/in-transit - Get stock in transit",
+			"GET  /stock-transfers/:id - Get stock transfer by ID",
+			"POST /stock-transfers/:id/dispatch - Dispatch transfer from the source outlet",
+			"POST /stock-transfers/:id/receive - Receive transfer at the destination outlet",
+			"POST /stock-transfers/:id/cancel - Cancel requested transfer",
+			"GET  /customers     - Get all customers (search with q)",
+			"POST /customers     - Create customer",
+			"GET  /customers/lookup - Find customer by phone",
+			"GET  /customers/:id - Get customer by ID",
+			"PUT  /customers/:id - Update customer",
+			"DELETE /customers/:id - Delete customer",
+			"GET  /customers/:id/profile - Get customer spend, visits and purchase history",
+			"GET  /customers/:id/points - Get loyalty points balance and ledger",
+			"GET  /customers/:id/account - Get credit limit, outstanding balance and open invoices",
+			"POST /customers/:id/payments - Record a repayment of the customer's account",
+			"GET  /customers/:id/statement - Get account statement for a date range",
+			"GET  /price-lists     - Get all price lists",
+			"POST /price-lists     - Create price list with tiered prices",
+			"GET  /price-lists/:id - Get price list with prices",
+			"PUT  /price-lists/:id - Update price list",
+			"DELETE /price-lists/:id - Delete price list",
+			"GET  /gift-cards - Get gift cards and vouchers",
+			"GET  /gift-cards/:code - Get gift card with ledger",
+			"GET  /gift-cards/:code/balance - Check gift card balance",
+			"GET  /events - Stream events (stock.low, transaction.*, product.*) as Server-Sent Events",
+			"GET  /webhooks - Get webhook subscriptions",
+			"POST /webhooks - Subscribe URL to events",
+			"GET  /webhooks/:id - Get webhook subscription",
+			"PUT  /webhooks/:id - Update webhook subscription",
+			"DELETE /webhooks/:id - Delete webhook subscription",
+			"GET  /webhooks/:id/deliveries - Get webhook delivery log",
+			"POST /webhooks/:id/test - Send test event to webhook",
+			"GET  /webhook-deliveries/:id - Get webhook delivery",
+			"POST /webhook-deliveries/:id/redeliver - Redeliver webhook",
+			"GET  /tenants - Get all tenants (admin)",
+			"POST /tenants - Provision tenant (admin)",
+			"GET  /tenants/:id - Get tenant by ID (admin)",
+			"PUT  /tenants/:id - Update tenant settings (admin)",
+			"POST /tenants/:id/api-key - Rotate tenant API key (admin)",
 			"GET  /reports/today  - Get sales report for today",
+			"GET  /reports/live   - Stream live sales dashboard (Server-Sent Events)",
+			"GET  /reports/live/ws - Live sales dashboard over WebSocket",
 			"GET  /reports        - Get sales report with custom date",
+			"GET  /reports/products - Get sales and profit per product (bundles split into components)",
+			"GET  /reports/categories - Get sales and profit per category",
+			"GET  /reports/expiring - Get batches expiring within N days",
+			"GET  /reports/reorder - Get reorder suggestions from sales velocity (JSON or CSV)",
+			"GET  /reports/outlets - Get sales and profit per outlet",
+			"GET  /reports/receivables - Get customer account balances by age",
 		},
 	})
 }
@@ -125,48 +254,467 @@ func main() {
 	fmt.Printf("📚 Swagger UI: http://localhost:%s/swagger/index.html\n", port)
 	fmt.Println("📋 Architecture: Layered (Handler → Service → Repository)")
 
-	if err := http.ListenAndServe(":"+port, nil); err != nil {
+	var handler http.Handler = http.DefaultServeMux
+	if id := config.AppConfig.OutletID; id > 0 {
+		fmt.Printf("🏪 Serving outlet %d unless X-Outlet-ID is given\n", id)
+		handler = withDefaultOutlet(handler, id)
+	}
+
+	if err := http.ListenAndServe(":"+port, handler); err != nil {
 		log.Fatalf("Error starting server: %v", err)
 	}
 }
 
+// withDefaultOutlet sets the X-Outlet-ID header on requests that do not select an outlet
+func withDefaultOutlet(next http.Handler, outletID int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(handlers.OutletHeader) == "" && r.URL.Query().Get("outlet_id") == "" {
+			r.Header.Set(handlers.OutletHeader, strconv.Itoa(outletID))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func setupDatabaseRoutes() {
+	gateway := paymentGateway(*config.AppConfig)
+	if !config.AppConfig.MultiTenant {
+		http.HandleFunc("/", welcomeHandler)
+		http.HandleFunc("/health", healthHandler)
+		registerDatabaseRoutes(http.DefaultServeMux, database.DB, models.TenantSettings{}, gateway, newEventBus())
+		return
+	}
+
+	tenantRepo := repositories.NewTenantRepository(database.DB)
+	tenantService := services.NewTenantService(tenantRepo, database.TenantDB)
+	tenantHandler := handlers.NewTenantHandler(tenantService)
+	apps := newTenantApps(tenantService, func(tenant models.Tenant, bus *events.Bus) (http.Handler, func(), error) {
+		db, err := database.TenantDB(tenant.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		mux := http.NewServeMux()
+		stop := registerDatabaseRoutes(mux, db, tenant.Settings, gateway, bus)
+		return mux, stop, nil
+	})
+	apps.Start()
+	tenantRouter := handlers.NewTenantRouter(tenantService, apps.Handler)
+
+	// Everything but the info, health and tenant admin routes is served per tenant
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			welcomeHandler(w, r)
+			return
+		}
+		tenantRouter.ServeHTTP(w, r)
+	})
+	http.HandleFunc("/health", healthHandler)
+
+	// Gateways post payments to the tenant's own callback URL, signed rather than carrying an API key.
+	// Unsigned callbacks would let anyone mark a sale paid, so they are disabled without a secret.
+	if config.AppConfig.PaymentCallbackSecret != "" {
+		http.Handle("/payments/callback/", handlers.NewTenantCallback(tenantService, apps.Handler, "/payments/callback"))
+	} else {
+		log.Println("Payment callbacks are disabled until PAYMENT_CALLBACK_SECRET is set")
+	}
+
+	// Tenant Routes
+	http.HandleFunc("/tenants", handlers.RequireAdmin(config.AppConfig.AdminAPIKey, tenantHandler.Handler))
+	http.HandleFunc("/tenants/", handlers.RequireAdmin(config.AppConfig.AdminAPIKey, tenantHandler.Handler))
+}
+
+// newEventBus creates the event bus of a tenant, writing its events to the log
+func newEventBus() *events.Bus {
+	bus := events.NewBus()
+	bus.AddSink(events.LogSink)
+	return bus
+}
+
+// tenantSyncInterval is how often the running tenants are brought in line with the tenant registry
+const tenantSyncInterval = time.Minute
+
+// tenantApps runs the routes and background work of every active tenant. They are started at boot
+// and kept in step with the tenant registry rather than with requests, so each tenant's outbox is
+// relayed and its payments expire whether it is being served or not. A tenant whose settings change
+// is rebuilt on the same event bus, so its open event streams carry on.
+type tenantApps struct {
+	service *services.TenantService
+	// build wires a tenant's routes on bus and starts their background work, ended by stop
+	build func(tenant models.Tenant, bus *events.Bus) (handler http.Handler, stop func(), err error)
+
+	mu    sync.Mutex
+	apps  map[int]tenantApp
+	buses map[int]*events.Bus
+}
+
+type tenantApp struct {
+	updatedAt time.Time
+	handler   http.Handler
+	stop      func()
+}
+
+func newTenantApps(service *services.TenantService, build func(tenant models.Tenant, bus *events.Bus) (http.Handler, func(), error)) *tenantApps {
+	return &tenantApps{service: service, build: build, apps: make(map[int]tenantApp), buses: make(map[int]*events.Bus)}
+}
+
+// Start runs the active tenants, then follows the registry in the background until stop is called
+func (a *tenantApps) Start() (stop func()) {
+	a.sync()
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(tenantSyncInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				a.sync()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			a.mu.Lock()
+			defer a.mu.Unlock()
+			for id, app := range a.apps {
+				app.stop()
+				delete(a.apps, id)
+			}
+		})
+	}
+}
+
+// Handler returns the routes of an active tenant, rebuilt first when its settings have changed
+func (a *tenantApps) Handler(tenant models.Tenant) (http.Handler, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.run(tenant)
+}
+
+// sync starts the tenants that became active or changed their settings and stops the ones that
+// were suspended or removed
+func (a *tenantApps) sync() {
+	tenants, err := a.service.GetAll()
+	if err != nil {
+		log.Printf("tenants: %v", err)
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	active := make(map[int]bool)
+	for _, tenant := range tenants {
+		if !tenant.Active {
+			continue
+		}
+		active[tenant.ID] = true
+		if _, err := a.run(tenant); err != nil {
+			log.Printf("tenant %d: %v", tenant.ID, err)
+		}
+	}
+	for id, app := range a.apps {
+		if !active[id] {
+			app.stop()
+			delete(a.apps, id)
+		}
+	}
+}
+
+// run returns the running app of a tenant, building it when it is not running on the tenant's
+// current settings. The app it replaces stops its background work.
+func (a *tenantApps) run(tenant models.Tenant) (http.Handler, error) {
+	old, ok := a.apps[tenant.ID]
+	if ok && old.updatedAt.Equal(tenant.UpdatedAt) {
+		return old.handler, nil
+	}
+	bus, ok := a.buses[tenant.ID]
+	if !ok {
+		bus = newEventBus()
+		a.buses[tenant.ID] = bus
+	}
+	handler, stop, err := a.build(tenant, bus)
+	if err != nil {
+		return nil, err
+	}
+	a.apps[tenant.ID] = tenantApp{updatedAt: tenant.UpdatedAt, handler: handler, stop: stop}
+	if old.stop != nil {
+		old.stop()
+	}
+	return handler, nil
+}
+
+// registerDatabaseRoutes wires the repositories, services and handlers on top of db, a pool
+// bound to one tenant, with the tenant's settings overriding the configuration. Payments of all
+// tenants go through gateway and the tenant's events are published on bus. The returned stop ends
+// the background relaying of the outbox, retrying of webhooks and expiry of payments started for them.
+func registerDatabaseRoutes(mux *http.ServeMux, db *sql.DB, settings models.TenantSettings, gateway payments.Gateway, bus *events.Bus) (stop func()) {
+	cfg := tenantConfig(settings)
+
 	// Initialize repositories
-	productRepo := repositories.NewProductRepository(database.DB)
-	categoryRepo := repositories.NewCategoryRepository(database.DB)
-	transactionRepo := repositories.NewTransactionRepository(database.DB)
-	reportRepo := repositories.NewReportRepository(database.DB)
+	productRepo := repositories.NewProductRepository(db)
+	categoryRepo := repositories.NewCategoryRepository(db)
+	loyalty := models.LoyaltyParams{
+		SpendPerPoint:      cfg.LoyaltySpendPerPoint,
+		Rounding:           cfg.LoyaltyRounding,
+		PointValue:         cfg.LoyaltyPointValue,
+		ExpiryDays:         cfg.LoyaltyExpiryDays,
+		ExcludedCategories: cfg.LoyaltyExcludedCategories,
+	}
+	transactionRepo := repositories.NewTransactionRepository(db, cfg.ExpiredSalePolicy, loyalty, cfg.GiftCardExpiryDays)
+	reportRepo := repositories.NewReportRepository(db)
+	stockMovementRepo := repositories.NewStockMovementRepository(db)
+	stockTakeRepo := repositories.NewStockTakeRepository(db)
+	supplierRepo := repositories.NewSupplierRepository(db)
+	purchaseOrderRepo := repositories.NewPurchaseOrderRepository(db)
+	goodsReceiptRepo := repositories.NewGoodsReceiptRepository(db)
+	outletRepo := repositories.NewOutletRepository(db)
+	stockTransferRepo := repositories.NewStockTransferRepository(db)
+	customerRepo := repositories.NewCustomerRepository(db)
+	loyaltyRepo := repositories.NewLoyaltyRepository(db, loyalty)
+	receivableRepo := repositories.NewReceivableRepository(db)
+	priceListRepo := repositories.NewPriceListRepository(db)
+	giftCardRepo := repositories.NewGiftCardRepository(db)
+	draftOrderRepo := repositories.NewDraftOrderRepository(db, cfg.DraftOrderExpiryMinutes)
+	tableRepo := repositories.NewTableRepository(db)
+	dineInOrderRepo := repositories.NewDineInOrderRepository(db, transactionRepo)
+	kitchenRepo := repositories.NewKitchenRepository(db)
+	webhookRepo := repositories.NewWebhookRepository(db)
+	outboxRepo := repositories.NewOutboxRepository(db)
+	paymentRepo := repositories.NewPaymentRepository(db, transactionRepo)
+
+	// Sales and catalog changes commit their events to the outbox, which relays them to the bus and
+	// the broker and queues them for the webhook subscriptions
+	webhookService := services.NewWebhookService(webhookRepo, cfg.WebhookMaxAttempts, time.Duration(cfg.WebhookRetrySeconds)*time.Second, cfg.WebhookAllowPrivateURLs)
+
+	// Subscribers of the broker take the events of the outbox in the background, such as the low stock
+	// webhook, which is posted through the webhook client, signed and retried like a subscription
+	broker := events.NewBroker()
+	stopBroker := func() {}
+	if url := cfg.LowStockWebhookURL; url != "" {
+		if cfg.LowStockWebhookSecret == "" {
+			log.Println("The low stock webhook is disabled until LOW_STOCK_WEBHOOK_SECRET is set")
+		} else if consume, err := webhookService.Consumer(url, cfg.LowStockWebhookSecret); err != nil {
+			log.Printf("The low stock webhook is disabled: %v", err)
+		} else {
+			stopBroker = broker.Subscribe(events.TypeStockLow, consume)
+		}
+	}
+	outbox := services.NewOutboxDispatcher(outboxRepo, time.Duration(cfg.OutboxPollSeconds)*time.Second)
+	outbox.AddSink("bus", bus.Consume)
+	outbox.AddSink("broker", broker.Publish)
+	outbox.AddSink("webhooks", webhookService.Sink)
+	stopOutbox := outbox.Start()
+	stopWebhooks := webhookService.Start()
 
 	// Initialize services
-	productService := services.NewProductService(productRepo)
+	productService := services.NewProductService(productRepo, outbox)
 	categoryService := services.NewCategoryService(categoryRepo)
-	transactionService := services.NewTransactionService(transactionRepo)
-	reportService := services.NewReportService(reportRepo)
+	transactionService := services.NewTransactionService(transactionRepo, bus, outbox)
+	reportService := services.NewReportService(reportRepo, models.ReorderParams{
+		WindowDays:   cfg.ReorderWindowDays,
+		LeadTimeDays: cfg.ReorderLeadTimeDays,
+		SafetyDays:   cfg.ReorderSafetyDays,
+		CoverDays:    cfg.ReorderCoverDays,
+	})
+	stockMovementService := services.NewStockMovementService(stockMovementRepo)
+	stockTakeService := services.NewStockTakeService(stockTakeRepo)
+	supplierService := services.NewSupplierService(supplierRepo)
+	purchaseOrderService := services.NewPurchaseOrderService(purchaseOrderRepo, supplierRepo)
+	goodsReceiptService := services.NewGoodsReceiptService(goodsReceiptRepo)
+	outletService := services.NewOutletService(outletRepo)
+	stockTransferService := services.NewStockTransferService(stockTransferRepo)
+	customerService := services.NewCustomerService(customerRepo)
+	loyaltyService := services.NewLoyaltyService(loyaltyRepo)
+	receivableService := services.NewReceivableService(receivableRepo)
+	priceListService := services.NewPriceListService(priceListRepo)
+	giftCardService := services.NewGiftCardService(giftCardRepo)
+	draftOrderService := services.NewDraftOrderService(draftOrderRepo, transactionService)
+	tableService := services.NewTableService(tableRepo)
+	dineInOrderService := services.NewDineInOrderService(dineInOrderRepo, transactionService)
+	kitchenService := services.NewKitchenService(kitchenRepo, bus)
+	paymentService := services.NewPaymentService(paymentRepo, gateway, transactionService, time.Duration(cfg.QRISExpiryMinutes)*time.Minute)
+	stopPayments := paymentService.Start()
 
 	// Initialize handlers
-	productHandler := handlers.NewProductHandler(productService)
+	productHandler := handlers.NewProductHandler(productService, stockMovementService)
 	categoryHandler := handlers.NewCategoryHandler(categoryService)
 	transactionHandler := handlers.NewTransactionHandler(transactionService)
 	reportHandler := handlers.NewReportHandler(reportService)
-
-	// Setup routing
-	http.HandleFunc("/", welcomeHandler)
-	http.HandleFunc("/health", healthHandler)
+	stockTakeHandler := handlers.NewStockTakeHandler(stockTakeService)
+	supplierHandler := handlers.NewSupplierHandler(supplierService)
+	purchaseOrderHandler := handlers.NewPurchaseOrderHandler(purchaseOrderService)
+	goodsReceiptHandler := handlers.NewGoodsReceiptHandler(goodsReceiptService)
+	outletHandler := handlers.NewOutletHandler(outletService)
+	stockTransferHandler := handlers.NewStockTransferHandler(stockTransferService)
+	customerHandler := handlers.NewCustomerHandler(customerService, loyaltyService, receivableService)
+	priceListHandler := handlers.NewPriceListHandler(priceListService)
+	giftCardHandler := handlers.NewGiftCardHandler(giftCardService)
+	draftOrderHandler := handlers.NewDraftOrderHandler(draftOrderService)
+	tableHandler := handlers.NewTableHandler(tableService)
+	dineInOrderHandler := handlers.NewDineInOrderHandler(dineInOrderService)
+	kitchenHandler := handlers.NewKitchenHandler(kitchenService, bus)
+	dashboardHandler := handlers.NewDashboardHandler(reportService, bus, cfg.DashboardOrigins)
+	eventHandler := handlers.NewEventHandler(bus)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	paymentHandler := handlers.NewPaymentHandler(paymentService)
 
 	// Product Routes
-	http.HandleFunc("/products", productHandler.Handler)
-	http.HandleFunc("/products/", productHandler.Handler)
+	mux.HandleFunc("/products", productHandler.Handler)
+	mux.HandleFunc("/products/", productHandler.Handler)
 
 	// Category Routes
-	http.HandleFunc("/categories", categoryHandler.Handler)
-	http.HandleFunc("/categories/", categoryHandler.Handler)
+	mux.HandleFunc("/categories", categoryHandler.Handler)
+	mux.HandleFunc("/categories/", categoryHandler.Handler)
 
 	// Transaction Routes
-	http.HandleFunc("/transactions", transactionHandler.Handler)
+	mux.HandleFunc("/transactions", transactionHandler.Handler)
+	mux.HandleFunc("/transactions/", transactionHandler.Handler)
+
+	// Payment Routes
+	mux.HandleFunc("/payments", paymentHandler.Handler)
+	mux.HandleFunc("/payments/", paymentHandler.Handler)
+
+	// Draft Order Routes
+	mux.HandleFunc("/draft-orders", draftOrderHandler.Handler)
+	mux.HandleFunc("/draft-orders/", draftOrderHandler.Handler)
+	mux.HandleFunc("/tables", tableHandler.Handler)
+	mux.HandleFunc("/tables/", tableHandler.Handler)
+	mux.HandleFunc("/dine-in-orders", dineInOrderHandler.Handler)
+	mux.HandleFunc("/dine-in-orders/", dineInOrderHandler.Handler)
+	mux.HandleFunc("/kitchen-stations", kitchenHandler.Handler)
+	mux.HandleFunc("/kitchen-stations/", kitchenHandler.Handler)
+	mux.HandleFunc("/kitchen-items/", kitchenHandler.ItemHandler)
+
+	// Stock Take Routes
+	mux.HandleFunc("/stock-takes", stockTakeHandler.Handler)
+	mux.HandleFunc("/stock-takes/", stockTakeHandler.Handler)
+
+	// Supplier Routes
+	mux.HandleFunc("/suppliers", supplierHandler.Handler)
+	mux.HandleFunc("/suppliers/", supplierHandler.Handler)
+
+	// Purchase Order Routes
+	mux.HandleFunc("/purchase-orders", purchaseOrderHandler.Handler)
+	mux.HandleFunc("/purchase-orders/", purchaseOrderHandler.Handler)
+
+	// Goods Receipt Routes
+	mux.HandleFunc("/goods-receipts", goodsReceiptHandler.Handler)
+	mux.HandleFunc("/goods-receipts/", goodsReceiptHandler.Handler)
+
+	// Outlet Routes
+	mux.HandleFunc("/outlets", outletHandler.Handler)
+	mux.HandleFunc("/outlets/", outletHandler.Handler)
+
+	// Stock Transfer Routes
+	mux.HandleFunc("/stock-transfers", stockTransferHandler.Handler)
+	mux.HandleFunc("/stock-transfers/", stockTransferHandler.Handler)
+
+	// Customer Routes
+	mux.HandleFunc("/customers", customerHandler.Handler)
+	mux.HandleFunc("/customers/", customerHandler.Handler)
+
+	// Price List Routes
+	mux.HandleFunc("/price-lists", priceListHandler.Handler)
+	mux.HandleFunc("/price-lists/", priceListHandler.Handler)
+
+	// Gift Card Routes
+	mux.HandleFunc("/gift-cards", giftCardHandler.Handler)
+	mux.HandleFunc("/gift-cards/", giftCardHandler.Handler)
+
+	// Event Routes
+	mux.HandleFunc("/events", eventHandler.Stream)
+
+	// Webhook Routes
+	mux.HandleFunc("/webhooks", webhookHandler.Handler)
+	mux.HandleFunc("/webhooks/", webhookHandler.Handler)
+	mux.HandleFunc("/webhook-deliveries/", webhookHandler.DeliveryHandler)
 
 	// Report Routes
-	http.HandleFunc("/reports/today", reportHandler.GetReportToday)
-	http.HandleFunc("/reports", reportHandler.GetReportCustom)
+	mux.HandleFunc("/reports/today", reportHandler.GetReportToday)
+	mux.HandleFunc("/reports/live", dashboardHandler.Stream)
+	mux.HandleFunc("/reports/live/ws", dashboardHandler.WebSocket)
+	mux.HandleFunc("/reports/products", reportHandler.GetProductSales)
+	mux.HandleFunc("/reports/categories", reportHandler.GetCategorySales)
+	mux.HandleFunc("/reports/expiring", reportHandler.GetExpiringStock)
+	mux.HandleFunc("/reports/reorder", reportHandler.GetReorderSuggestions)
+	mux.HandleFunc("/reports/outlets", outletHandler.GetSales)
+	mux.HandleFunc("/reports/receivables", customerHandler.GetReceivablesAging)
+	mux.HandleFunc("/reports", reportHandler.GetReportCustom)
+	return func() {
+		stopOutbox()
+		stopBroker()
+		stopWebhooks()
+		stopPayments()
+	}
+}
+
+// paymentGateway creates the payment gateway the configuration names
+func paymentGateway(cfg config.Config) payments.Gateway {
+	merchant := payments.Merchant{
+		ID:           cfg.QRISMerchantID,
+		Name:         cfg.QRISMerchantName,
+		City:         cfg.QRISMerchantCity,
+		CategoryCode: cfg.QRISMerchantCategory,
+	}
+	if err := merchant.Validate(); err != nil {
+		log.Fatalf("Invalid QRIS merchant: %v", err)
+	}
+	switch cfg.PaymentGateway {
+	case "mock":
+		return payments.NewMockGateway(merchant, cfg.PaymentCallbackSecret)
+	default:
+		log.Fatalf("Unknown payment gateway %q", cfg.PaymentGateway)
+		return nil
+	}
+}
+
+// tenantConfig applies a tenant's settings on top of the deployment's configuration
+func tenantConfig(settings models.TenantSettings) config.Config {
+	cfg := *config.AppConfig
+	if settings.ExpiredSalePolicy != "" {
+		cfg.ExpiredSalePolicy = settings.ExpiredSalePolicy
+	}
+	if settings.LowStockWebhookURL != "" {
+		cfg.LowStockWebhookURL = settings.LowStockWebhookURL
+		cfg.LowStockWebhookSecret = settings.LowStockWebhookSecret
+	}
+	if settings.ReorderWindowDays != 0 {
+		cfg.ReorderWindowDays = settings.ReorderWindowDays
+	}
+	if settings.ReorderLeadTimeDays != 0 {
+		cfg.ReorderLeadTimeDays = settings.ReorderLeadTimeDays
+	}
+	if settings.ReorderSafetyDays != 0 {
+		cfg.ReorderSafetyDays = settings.ReorderSafetyDays
+	}
+	if settings.ReorderCoverDays != 0 {
+		cfg.ReorderCoverDays = settings.ReorderCoverDays
+	}
+	if settings.LoyaltySpendPerPoint != 0 {
+		cfg.LoyaltySpendPerPoint = settings.LoyaltySpendPerPoint
+	}
+	if settings.LoyaltyRounding != "" {
+		cfg.LoyaltyRounding = settings.LoyaltyRounding
+	}
+	if settings.LoyaltyPointValue != 0 {
+		cfg.LoyaltyPointValue = settings.LoyaltyPointValue
+	}
+	if settings.LoyaltyExpiryDays != 0 {
+		cfg.LoyaltyExpiryDays = settings.LoyaltyExpiryDays
+	}
+	if settings.LoyaltyExcludedCategories != nil {
+		cfg.LoyaltyExcludedCategories = settings.LoyaltyExcludedCategories
+	}
+	if settings.GiftCardExpiryDays != 0 {
+		cfg.GiftCardExpiryDays = settings.GiftCardExpiryDays
+	}
+	if settings.DraftOrderExpiryMinutes != 0 {
+		cfg.DraftOrderExpiryMinutes = settings.DraftOrderExpiryMinutes
+	}
+	return cfg
 }
 
 func setupDemoRoutes() {
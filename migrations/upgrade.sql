@@ -0,0 +1,652 @@
+-- Upgrades a database created with the original schema (categories, products, transactions and
+-- transaction_details) to the schema in the README. It runs in one transaction, so a failure leaves the
+-- database as it was. Run it once, as the owner of the tables:
+--   psql "$DB_CONN" -v ON_ERROR_STOP=1 -f migrations/upgrade.sql
+-- The existing rows become tenant 1's, the merchant of a single-tenant deployment, and their stock
+-- is moved to a default outlet with an opening stock movement. Past sales keep their totals but are
+-- not replayed into the stock ledger.
+BEGIN;
+
+-- Tenants (merchants)
+CREATE TABLE tenants (
+    id SERIAL PRIMARY KEY,
+    slug VARCHAR(100) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    settings JSONB NOT NULL DEFAULT '{}',
+    api_key_hash VARCHAR(64) UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO tenants (id, slug, name) VALUES (1, 'default', 'Default');
+SELECT setval('tenants_id_seq', 1);
+SET LOCAL app.tenant_id = '1';
+
+-- Categories: the existing rows get tenant 1 from the default
+ALTER TABLE categories
+    ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id);
+
+
+-- Outlets (stores), exactly one is the default
+CREATE TABLE outlets (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    address TEXT,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tenant_id, code)
+);
+CREATE UNIQUE INDEX idx_outlets_single_default ON outlets (tenant_id) WHERE is_default;
+INSERT INTO outlets (code, name, is_default) VALUES ('MAIN', 'Main Store', TRUE);
+
+-- Products: stock takes fractions, and the columns added since default to a plain product sold by the piece
+ALTER TABLE products
+    ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    ADD COLUMN barcode VARCHAR(100),
+    ADD COLUMN cost_price INTEGER NOT NULL DEFAULT 0,
+    ALTER COLUMN stock TYPE NUMERIC(14,3),
+    ADD COLUMN is_bundle BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN unit VARCHAR(50) NOT NULL DEFAULT 'pcs',
+    ADD COLUMN sale_unit VARCHAR(50) NOT NULL DEFAULT 'pcs',
+    ADD COLUMN purchase_unit VARCHAR(50) NOT NULL DEFAULT 'pcs',
+    ADD COLUMN allow_fraction BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN min_stock NUMERIC(14,3) NOT NULL DEFAULT 0,
+    ADD COLUMN reorder_quantity NUMERIC(14,3) NOT NULL DEFAULT 0,
+    ADD UNIQUE (tenant_id, barcode);
+
+-- Stock and optional price override per outlet, products.stock is the total over all outlets
+CREATE TABLE outlet_stock (
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    outlet_id INTEGER REFERENCES outlets(id) ON DELETE CASCADE,
+    product_id INTEGER REFERENCES products(id) ON DELETE CASCADE,
+    stock NUMERIC(14,3) NOT NULL DEFAULT 0,
+    price INTEGER,
+    PRIMARY KEY (outlet_id, product_id)
+);
+
+-- Alternative units per product (factor = base units in one unit)
+CREATE TABLE product_units (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    product_id INTEGER REFERENCES products(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    factor NUMERIC(14,3) NOT NULL CHECK (factor > 0),
+    price INTEGER,
+    UNIQUE (product_id, name)
+);
+
+-- Cost price history per product
+CREATE TABLE product_cost_history (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    product_id INTEGER REFERENCES products(id) ON DELETE CASCADE,
+    cost_price INTEGER NOT NULL,
+    reason VARCHAR(50) NOT NULL,
+    effective_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Bundle components table
+CREATE TABLE product_bundle_items (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    bundle_id INTEGER REFERENCES products(id) ON DELETE CASCADE,
+    component_id INTEGER REFERENCES products(id),
+    quantity NUMERIC(14,3) NOT NULL CHECK (quantity > 0),
+    UNIQUE (bundle_id, component_id)
+);
+
+-- Append-only stock ledger
+CREATE TABLE stock_movements (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    product_id INTEGER REFERENCES products(id) ON DELETE CASCADE,
+    outlet_id INTEGER NOT NULL REFERENCES outlets(id),
+    movement_type VARCHAR(20) NOT NULL
+        CHECK (movement_type IN ('sale', 'refund', 'receipt', 'adjustment', 'waste', 'transfer')),
+    quantity NUMERIC(14,3) NOT NULL,
+    balance_after NUMERIC(14,3) NOT NULL,
+    reason TEXT,
+    actor VARCHAR(100),
+    reference_type VARCHAR(50),
+    reference_id INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_stock_movements_product ON stock_movements (product_id, id);
+CREATE INDEX idx_stock_movements_outlet ON stock_movements (outlet_id, product_id, id);
+
+-- The stock of every product is held at the default outlet, recorded as its opening stock like a
+-- product created through the API, along with its initial cost price
+INSERT INTO outlet_stock (outlet_id, product_id, stock)
+SELECT o.id, p.id, p.stock FROM products p CROSS JOIN outlets o WHERE o.is_default;
+
+INSERT INTO stock_movements (product_id, outlet_id, movement_type, quantity, balance_after, reason, actor)
+SELECT p.id, o.id, 'adjustment', p.stock, p.stock, 'Opening stock', 'upgrade'
+FROM products p CROSS JOIN outlets o
+WHERE o.is_default AND p.stock <> 0;
+
+INSERT INTO product_cost_history (product_id, cost_price, reason)
+SELECT id, cost_price, 'initial' FROM products;
+
+-- Stock takes (stock opname)
+CREATE TABLE stock_takes (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    outlet_id INTEGER NOT NULL REFERENCES outlets(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    notes TEXT,
+    created_by VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    approved_by VARCHAR(100),
+    approved_at TIMESTAMP
+);
+CREATE UNIQUE INDEX idx_stock_takes_single_open ON stock_takes (outlet_id) WHERE status = 'open';
+
+CREATE TABLE stock_take_lines (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    stock_take_id INTEGER REFERENCES stock_takes(id) ON DELETE CASCADE,
+    product_id INTEGER REFERENCES products(id) ON DELETE CASCADE,
+    expected_quantity NUMERIC(14,3),
+    counted_quantity NUMERIC(14,3),
+    unit_cost INTEGER NOT NULL DEFAULT 0,
+    counted_at TIMESTAMP,
+    UNIQUE (stock_take_id, product_id)
+);
+
+-- Suppliers table
+CREATE TABLE suppliers (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    name VARCHAR(255) NOT NULL,
+    contact_name VARCHAR(255) NOT NULL DEFAULT '',
+    phone VARCHAR(50) NOT NULL DEFAULT '',
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    address TEXT NOT NULL DEFAULT ''
+);
+
+-- Purchase orders
+CREATE TABLE purchase_orders (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    supplier_id INTEGER REFERENCES suppliers(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'sent', 'partially_received', 'received', 'cancelled')),
+    notes TEXT,
+    expected_date DATE,
+    total_amount INTEGER NOT NULL DEFAULT 0,
+    created_by VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE purchase_order_lines (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    purchase_order_id INTEGER REFERENCES purchase_orders(id) ON DELETE CASCADE,
+    product_id INTEGER REFERENCES products(id),
+    quantity NUMERIC(14,3) NOT NULL,
+    unit VARCHAR(50) NOT NULL,
+    unit_factor NUMERIC(14,3) NOT NULL DEFAULT 1,
+    unit_cost INTEGER NOT NULL,
+    subtotal INTEGER NOT NULL,
+    received_quantity NUMERIC(14,3) NOT NULL DEFAULT 0
+);
+
+-- Batches (lots) with expiry dates, quantity is what is left of the batch
+CREATE TABLE product_batches (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    product_id INTEGER REFERENCES products(id) ON DELETE CASCADE,
+    outlet_id INTEGER NOT NULL REFERENCES outlets(id),
+    batch_number VARCHAR(100) NOT NULL,
+    expiry_date DATE,
+    quantity NUMERIC(14,3) NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    received_quantity NUMERIC(14,3) NOT NULL DEFAULT 0,
+    received_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (product_id, outlet_id, batch_number)
+);
+CREATE INDEX idx_product_batches_expiry ON product_batches (expiry_date) WHERE quantity > 0;
+
+-- Batch side of stock movements: what each movement added to or took from a batch
+CREATE TABLE batch_movements (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    batch_id INTEGER REFERENCES product_batches(id) ON DELETE CASCADE,
+    stock_movement_id INTEGER REFERENCES stock_movements(id),
+    quantity NUMERIC(14,3) NOT NULL
+);
+
+-- Goods receipts (deliveries from suppliers)
+CREATE TABLE goods_receipts (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    supplier_id INTEGER REFERENCES suppliers(id),
+    purchase_order_id INTEGER REFERENCES purchase_orders(id),
+    outlet_id INTEGER NOT NULL REFERENCES outlets(id),
+    reference VARCHAR(100),
+    notes TEXT,
+    total_cost INTEGER NOT NULL DEFAULT 0,
+    received_by VARCHAR(100),
+    received_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE goods_receipt_lines (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    goods_receipt_id INTEGER REFERENCES goods_receipts(id) ON DELETE CASCADE,
+    product_id INTEGER REFERENCES products(id),
+    purchase_order_line_id INTEGER REFERENCES purchase_order_lines(id),
+    quantity NUMERIC(14,3) NOT NULL,
+    unit VARCHAR(50) NOT NULL,
+    unit_factor NUMERIC(14,3) NOT NULL DEFAULT 1,
+    base_quantity NUMERIC(14,3) NOT NULL,
+    unit_cost INTEGER NOT NULL,
+    subtotal INTEGER NOT NULL,
+    average_cost_after INTEGER NOT NULL,
+    batch_id INTEGER REFERENCES product_batches(id)
+);
+
+-- Stock transfers between outlets: requested, in_transit, received or cancelled
+CREATE TABLE stock_transfers (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    source_outlet_id INTEGER NOT NULL REFERENCES outlets(id),
+    destination_outlet_id INTEGER NOT NULL REFERENCES outlets(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'requested'
+        CHECK (status IN ('requested', 'in_transit', 'received', 'cancelled')),
+    notes TEXT,
+    requested_by VARCHAR(100),
+    requested_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    dispatched_by VARCHAR(100),
+    dispatched_at TIMESTAMP,
+    received_by VARCHAR(100),
+    received_at TIMESTAMP,
+    CHECK (source_outlet_id <> destination_outlet_id)
+);
+
+CREATE TABLE stock_transfer_lines (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    stock_transfer_id INTEGER REFERENCES stock_transfers(id) ON DELETE CASCADE,
+    product_id INTEGER REFERENCES products(id),
+    requested_quantity NUMERIC(14,3) NOT NULL,
+    dispatched_quantity NUMERIC(14,3) NOT NULL DEFAULT 0,
+    received_quantity NUMERIC(14,3) NOT NULL DEFAULT 0,
+    discrepancy_reason TEXT,
+    dispatch_movement_id INTEGER REFERENCES stock_movements(id),
+    receive_movement_id INTEGER REFERENCES stock_movements(id),
+    UNIQUE (stock_transfer_id, product_id)
+);
+
+-- Customers table, phone holds digits only
+CREATE TABLE customers (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    name VARCHAR(255) NOT NULL,
+    phone VARCHAR(50),
+    email VARCHAR(255),
+    notes TEXT,
+    customer_group VARCHAR(50),
+    credit_limit INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tenant_id, phone)
+);
+
+-- Price lists, a customer group buys at the prices of its list
+CREATE TABLE price_lists (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    name VARCHAR(255) NOT NULL,
+    list_type VARCHAR(20) NOT NULL,
+    customer_group VARCHAR(50),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tenant_id, customer_group)
+);
+
+-- Price list prices per sale unit, one row per quantity-break tier
+CREATE TABLE price_list_items (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    price_list_id INTEGER NOT NULL REFERENCES price_lists(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    unit VARCHAR(50) NOT NULL,
+    min_quantity NUMERIC(14,3) NOT NULL DEFAULT 1,
+    price INTEGER NOT NULL,
+    UNIQUE (price_list_id, product_id, unit, min_quantity)
+);
+
+-- Transactions: past sales were paid in full at the default outlet
+ALTER TABLE transactions
+    ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    ADD COLUMN outlet_id INTEGER REFERENCES outlets(id),
+    ADD COLUMN customer_id INTEGER REFERENCES customers(id) ON DELETE SET NULL,
+    ADD COLUMN price_list_id INTEGER REFERENCES price_lists(id) ON DELETE SET NULL,
+    ADD COLUMN gift_cards_sold INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN points_redeemed INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN points_amount INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN gift_card_amount INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN on_account_amount INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN on_account_paid INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN points_earned INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN payment_status VARCHAR(20) NOT NULL DEFAULT 'paid',
+    ADD COLUMN refunded_at TIMESTAMP,
+    ADD COLUMN refund_reason TEXT;
+UPDATE transactions SET outlet_id = (SELECT id FROM outlets WHERE is_default);
+ALTER TABLE transactions ALTER COLUMN outlet_id SET NOT NULL;
+CREATE INDEX idx_transactions_customer ON transactions (customer_id, created_at);
+
+-- Parked carts, their items take no stock until checked out
+CREATE TABLE draft_orders (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    outlet_id INTEGER NOT NULL REFERENCES outlets(id),
+    label VARCHAR(255),
+    notes TEXT,
+    customer_id INTEGER REFERENCES customers(id) ON DELETE SET NULL,
+    price_list_id INTEGER REFERENCES price_lists(id) ON DELETE SET NULL,
+    customer_group VARCHAR(50),
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    transaction_id INTEGER REFERENCES transactions(id),
+    created_by VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+);
+CREATE INDEX idx_draft_orders_status ON draft_orders (status, expires_at);
+
+CREATE TABLE draft_order_items (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    draft_order_id INTEGER NOT NULL REFERENCES draft_orders(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    quantity NUMERIC(14,3) NOT NULL,
+    unit VARCHAR(50) NOT NULL
+);
+
+-- Dining tables and the dine-in orders seated at them
+CREATE TABLE dining_tables (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    outlet_id INTEGER NOT NULL REFERENCES outlets(id),
+    name VARCHAR(50) NOT NULL,
+    area VARCHAR(100),
+    seats INTEGER NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (outlet_id, name)
+);
+
+CREATE TABLE dine_in_orders (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    outlet_id INTEGER NOT NULL REFERENCES outlets(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    guests INTEGER NOT NULL DEFAULT 0,
+    notes TEXT,
+    merged_into INTEGER REFERENCES dine_in_orders(id),
+    opened_by VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    billed_at TIMESTAMP,
+    paid_at TIMESTAMP
+);
+CREATE INDEX idx_dine_in_orders_status ON dine_in_orders (status);
+
+CREATE TABLE dine_in_order_tables (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    order_id INTEGER NOT NULL REFERENCES dine_in_orders(id) ON DELETE CASCADE,
+    table_id INTEGER NOT NULL REFERENCES dining_tables(id) ON DELETE CASCADE,
+    UNIQUE (order_id, table_id)
+);
+
+-- Items are ordered in rounds and take stock only once billed
+CREATE TABLE dine_in_order_items (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    order_id INTEGER NOT NULL REFERENCES dine_in_orders(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    quantity NUMERIC(14,3) NOT NULL,
+    unit VARCHAR(50) NOT NULL,
+    notes TEXT,
+    round INTEGER NOT NULL DEFAULT 1,
+    sent_at TIMESTAMP,
+    transaction_id INTEGER REFERENCES transactions(id)
+);
+
+CREATE TABLE dine_in_payments (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    order_id INTEGER NOT NULL REFERENCES dine_in_orders(id) ON DELETE CASCADE,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    method VARCHAR(50),
+    actor VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Kitchen stations prepare the products of their categories, each category goes to one station
+CREATE TABLE kitchen_stations (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE kitchen_station_categories (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    station_id INTEGER NOT NULL REFERENCES kitchen_stations(id) ON DELETE CASCADE,
+    category_id INTEGER NOT NULL UNIQUE REFERENCES categories(id) ON DELETE CASCADE
+);
+
+-- Items to prepare, from a sale or from a round of a dine-in order
+CREATE TABLE kitchen_items (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    station_id INTEGER NOT NULL REFERENCES kitchen_stations(id) ON DELETE CASCADE,
+    outlet_id INTEGER NOT NULL REFERENCES outlets(id),
+    transaction_id INTEGER REFERENCES transactions(id),
+    dine_in_order_id INTEGER REFERENCES dine_in_orders(id) ON DELETE SET NULL,
+    tables VARCHAR(255),
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    quantity NUMERIC(14,3) NOT NULL,
+    unit VARCHAR(50) NOT NULL,
+    notes TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'new',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    ready_at TIMESTAMP,
+    bumped_at TIMESTAMP
+);
+CREATE INDEX idx_kitchen_items_queue ON kitchen_items (station_id, status, created_at);
+
+-- Webhook subscriptions, an empty event_types receives every webhook event
+CREATE TABLE webhook_subscriptions (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    url TEXT NOT NULL,
+    description VARCHAR(255),
+    event_types TEXT[] NOT NULL DEFAULT '{}',
+    secret VARCHAR(100) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One event for one subscription, pending deliveries are retried at next_attempt_at
+CREATE TABLE webhook_deliveries (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    subscription_id INTEGER NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id VARCHAR(64) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_status_code INTEGER,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP,
+    UNIQUE (subscription_id, event_id)
+);
+CREATE INDEX idx_webhook_deliveries_subscription ON webhook_deliveries (subscription_id, id);
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+-- Events committed with the sales and catalog changes they describe, until every sink has them
+CREATE TABLE event_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    event_id VARCHAR(64) NOT NULL UNIQUE,
+    event_type VARCHAR(50) NOT NULL,
+    payload TEXT NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    done_sinks TEXT[] NOT NULL DEFAULT '{}',
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    dispatched_at TIMESTAMP
+);
+CREATE INDEX idx_event_outbox_due ON event_outbox (next_attempt_at) WHERE dispatched_at IS NULL;
+
+-- Payments taken through a payment gateway, sale is the transaction as recorded at checkout
+CREATE TABLE payments (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id),
+    method VARCHAR(20) NOT NULL,
+    gateway VARCHAR(50) NOT NULL,
+    reference VARCHAR(100),
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    qr_payload TEXT,
+    sale JSONB NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    paid_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (gateway, reference)
+);
+CREATE INDEX idx_payments_due ON payments (expires_at) WHERE status = 'pending';
+
+-- Repayments of customer accounts
+CREATE TABLE customer_payments (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    method VARCHAR(50),
+    notes TEXT,
+    actor VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The on-account transactions each repayment settled
+CREATE TABLE customer_payment_allocations (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    payment_id INTEGER NOT NULL REFERENCES customer_payments(id) ON DELETE CASCADE,
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    amount INTEGER NOT NULL
+);
+
+-- Loyalty points ledger, remaining is what is left unspent of a credit
+CREATE TABLE loyalty_ledger (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    transaction_id INTEGER REFERENCES transactions(id) ON DELETE SET NULL,
+    entry_type VARCHAR(20) NOT NULL,
+    points INTEGER NOT NULL,
+    balance_after INTEGER NOT NULL,
+    remaining INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP,
+    reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_loyalty_ledger_customer ON loyalty_ledger (customer_id, id);
+
+-- Gift cards and vouchers, issued by the transaction that sold them
+CREATE TABLE gift_cards (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    code VARCHAR(50) NOT NULL,
+    card_type VARCHAR(20) NOT NULL,
+    initial_amount INTEGER NOT NULL CHECK (initial_amount > 0),
+    balance INTEGER NOT NULL CHECK (balance >= 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    expiry_date DATE,
+    transaction_id INTEGER REFERENCES transactions(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tenant_id, code)
+);
+
+-- Gift card balance ledger
+CREATE TABLE gift_card_ledger (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    gift_card_id INTEGER NOT NULL REFERENCES gift_cards(id),
+    transaction_id INTEGER REFERENCES transactions(id),
+    entry_type VARCHAR(20) NOT NULL,
+    amount INTEGER NOT NULL,
+    balance_after INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_gift_card_ledger_transaction ON gift_card_ledger (transaction_id);
+
+-- Transaction details: past lines were sold by the piece at their average price, without a known cost
+ALTER TABLE transaction_details
+    ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    ALTER COLUMN quantity TYPE NUMERIC(14,3),
+    ADD COLUMN unit VARCHAR(50) NOT NULL DEFAULT 'pcs',
+    ADD COLUMN base_quantity NUMERIC(14,3),
+    ADD COLUMN unit_price INTEGER,
+    ADD COLUMN price_list_id INTEGER REFERENCES price_lists(id) ON DELETE SET NULL,
+    ADD COLUMN unit_cost INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN cogs INTEGER NOT NULL DEFAULT 0;
+UPDATE transaction_details
+SET base_quantity = quantity,
+    unit_price = CASE WHEN quantity = 0 THEN subtotal ELSE ROUND(subtotal / quantity)::int END;
+ALTER TABLE transaction_details
+    ALTER COLUMN base_quantity SET NOT NULL,
+    ALTER COLUMN unit_price SET NOT NULL;
+
+-- Components sold through a bundle line, with their share of the revenue
+CREATE TABLE transaction_detail_components (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT current_setting('app.tenant_id')::int REFERENCES tenants(id),
+    transaction_detail_id INTEGER REFERENCES transaction_details(id) ON DELETE CASCADE,
+    product_id INTEGER REFERENCES products(id),
+    quantity NUMERIC(14,3) NOT NULL,
+    allocated_amount INTEGER NOT NULL,
+    unit_cost INTEGER NOT NULL DEFAULT 0,
+    cogs INTEGER NOT NULL DEFAULT 0
+);
+
+-- Row-level security: a session only sees and writes the rows of its app.tenant_id.
+-- FORCE applies it to the table owner too; connect as a role without SUPERUSER or BYPASSRLS.
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY[
+        'categories', 'outlets', 'products', 'outlet_stock', 'product_units',
+        'product_cost_history', 'product_bundle_items', 'stock_movements', 'stock_takes',
+        'stock_take_lines', 'suppliers', 'purchase_orders', 'purchase_order_lines',
+        'product_batches', 'batch_movements', 'goods_receipts', 'goods_receipt_lines',
+        'stock_transfers', 'stock_transfer_lines', 'customers', 'price_lists', 'price_list_items', 'transactions', 'transaction_details',
+        'transaction_detail_components', 'loyalty_ledger', 'gift_cards', 'gift_card_ledger', 'customer_payments',
+        'customer_payment_allocations', 'draft_orders', 'draft_order_items', 'dining_tables',
+        'dine_in_orders', 'dine_in_order_tables', 'dine_in_order_items', 'dine_in_payments',
+        'kitchen_stations', 'kitchen_station_categories', 'kitchen_items', 'webhook_subscriptions',
+        'webhook_deliveries', 'event_outbox', 'payments'
+    ] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format('CREATE POLICY tenant_isolation ON %I USING (tenant_id = current_setting(''app.tenant_id'')::int)', t);
+    END LOOP;
+END $$;
+
+
+COMMIT;
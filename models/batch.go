@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Policies for selling stock from batches past their expiry date
+const (
+	ExpiredSaleBlock = "block"
+	ExpiredSaleWarn  = "warn"
+)
+
+// ProductBatch is a lot of a product received at an outlet with its own batch number and expiry date.
+// Quantity is what is left of the batch in the product's base unit. Stock received without
+// a batch number is not tracked in any batch.
+type ProductBatch struct {
+	ID               int       `json:"id"`
+	ProductID        int       `json:"product_id"`
+	OutletID         int       `json:"outlet_id"`
+	BatchNumber      string    `json:"batch_number"`
+	ExpiryDate       string    `json:"expiry_date,omitempty"`
+	Quantity         Quantity  `json:"quantity" swaggertype:"number"`
+	ReceivedQuantity Quantity  `json:"received_quantity" swaggertype:"number"`
+	Expired          bool      `json:"expired"`
+	ReceivedAt       time.Time `json:"received_at"`
+}
+
+// BatchAllocation is the part of a stock decrease taken from one batch
+type BatchAllocation struct {
+	BatchID     int      `json:"batch_id"`
+	BatchNumber string   `json:"batch_number"`
+	ExpiryDate  string   `json:"expiry_date,omitempty"`
+	Quantity    Quantity `json:"quantity" swaggertype:"number"`
+	Expired     bool     `json:"expired"`
+}
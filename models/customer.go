@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Customer is a buyer whose purchases are recorded. Phone is stored normalized so a
+// checkout can find the customer by phone however it is typed. A customer in a Group
+// buys at the prices of the group's price list. CreditLimit is how much the customer may owe
+// on account, 0 when they cannot buy on account.
+type Customer struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Phone       string    `json:"phone,omitempty"`
+	Email       string    `json:"email,omitempty"`
+	Notes       string    `json:"notes,omitempty"`
+	Group       string    `json:"customer_group,omitempty"`
+	CreditLimit int       `json:"credit_limit"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CustomerRequest is used for create/update operations
+type CustomerRequest struct {
+	Name        string `json:"name"`
+	Phone       string `json:"phone"`
+	Email       string `json:"email"`
+	Notes       string `json:"notes"`
+	Group       string `json:"customer_group"`
+	CreditLimit int    `json:"credit_limit"`
+}
+
+// CustomerProfile summarises a customer's purchases. Purchases lists every transaction, newest first.
+type CustomerProfile struct {
+	Customer
+	LifetimeSpend int           `json:"lifetime_spend"`
+	VisitCount    int           `json:"visit_count"`
+	AverageSpend  int           `json:"average_spend"`
+	FirstVisit    *time.Time    `json:"first_visit,omitempty"`
+	LastVisit     *time.Time    `json:"last_visit,omitempty"`
+	Purchases     []Transaction `json:"purchases"`
+}
@@ -0,0 +1,127 @@
+package models
+
+import "time"
+
+// Dine-in order statuses. An order is open while it has items not yet sent to the kitchen,
+// sent_to_kitchen until they are served, billed once its bills are checked out and paid when
+// the bills are paid in full. A merged order lives on in the order it was merged into.
+const (
+	DineInOpen      = "open"
+	DineInSent      = "sent_to_kitchen"
+	DineInServed    = "served"
+	DineInBilled    = "billed"
+	DineInPaid      = "paid"
+	DineInCancelled = "cancelled"
+	DineInMerged    = "merged"
+)
+
+// DineInOrder is a table's running order. Items are added over several rounds and only leave
+// stock when the order is billed, which checks out each bill as a Transaction. Bills are then
+// paid by any number of payments until Balance is 0.
+type DineInOrder struct {
+	ID         int               `json:"id"`
+	OutletID   int               `json:"outlet_id"`
+	Status     string            `json:"status"`
+	Guests     int               `json:"guests,omitempty"`
+	Notes      string            `json:"notes,omitempty"`
+	Tables     []DineInTable     `json:"tables"`
+	MergedInto int               `json:"merged_into,omitempty"`
+	OpenedBy   string            `json:"opened_by,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+	BilledAt   *time.Time        `json:"billed_at,omitempty"`
+	PaidAt     *time.Time        `json:"paid_at,omitempty"`
+	Items      []DineInOrderItem `json:"items,omitempty"`
+	Bills      []Transaction     `json:"bills,omitempty"`
+	Payments   []DineInPayment   `json:"payments,omitempty"`
+	AmountDue  int               `json:"amount_due"`
+	AmountPaid int               `json:"amount_paid"`
+	Balance    int               `json:"balance"`
+}
+
+// DineInTable is a table an order sits at
+type DineInTable struct {
+	TableID int    `json:"table_id"`
+	Name    string `json:"name"`
+}
+
+// DineInOrderItem is a product ordered in a round. SentAt is when it went to the kitchen and
+// TransactionID the bill it was checked out on.
+type DineInOrderItem struct {
+	ID            int        `json:"id"`
+	ProductID     int        `json:"product_id"`
+	ProductName   string     `json:"product_name,omitempty"`
+	Quantity      Quantity   `json:"quantity" swaggertype:"number"`
+	Unit          string     `json:"unit"`
+	Notes         string     `json:"notes,omitempty"`
+	Round         int        `json:"round"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	TransactionID int        `json:"transaction_id,omitempty"`
+}
+
+// DineInPayment is a payment towards an order's bills
+type DineInPayment struct {
+	ID        int       `json:"id"`
+	Amount    int       `json:"amount"`
+	Method    string    `json:"method,omitempty"`
+	Actor     string    `json:"actor,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DineInOrderRequest opens an order at one or more tables
+type DineInOrderRequest struct {
+	TableIDs []int  `json:"table_ids"`
+	Guests   int    `json:"guests"`
+	Notes    string `json:"notes"`
+	Actor    string `json:"-"`
+}
+
+// DineInItemsRequest adds a round of items to an order. Units default to the product's sale unit.
+type DineInItemsRequest struct {
+	Items []DineInItemRequest `json:"items"`
+}
+
+// DineInItemRequest orders a product, Notes carries kitchen instructions such as "no ice"
+type DineInItemRequest struct {
+	ProductID int      `json:"product_id"`
+	Quantity  Quantity `json:"quantity" swaggertype:"number"`
+	Unit      string   `json:"unit,omitempty"`
+	Notes     string   `json:"notes,omitempty"`
+}
+
+// DineInTablesRequest moves an order to other tables
+type DineInTablesRequest struct {
+	TableIDs []int `json:"table_ids"`
+}
+
+// DineInMergeRequest merges another order, with its items and tables, into an order
+type DineInMergeRequest struct {
+	OrderID int `json:"order_id"`
+}
+
+// DineInBillRequest bills an order. Without Bills all items go on one bill, otherwise every
+// item must be on exactly one bill to split it by item.
+type DineInBillRequest struct {
+	Bills []DineInBill `json:"bills"`
+	Actor string       `json:"-"`
+}
+
+// DineInBill is one bill of a split, checked out with the options of a regular checkout
+type DineInBill struct {
+	ItemIDs          []int             `json:"item_ids"`
+	CustomerID       int               `json:"customer_id,omitempty"`
+	CustomerPhone    string            `json:"customer_phone,omitempty"`
+	PriceListID      int               `json:"price_list_id,omitempty"`
+	CustomerGroup    string            `json:"customer_group,omitempty"`
+	RedeemPoints     int               `json:"redeem_points,omitempty"`
+	GiftCardPayments []GiftCardPayment `json:"gift_card_payments,omitempty"`
+	OnAccount        bool              `json:"on_account,omitempty"`
+}
+
+// DineInPaymentRequest pays part or all of what is left of an order's bills, so a bill can be
+// split by amount between guests
+type DineInPaymentRequest struct {
+	Amount int    `json:"amount"`
+	Method string `json:"method"`
+	Actor  string `json:"-"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Draft order statuses
+const (
+	DraftOrderOpen      = "open"
+	DraftOrderConverted = "converted"
+	DraftOrderExpired   = "expired"
+)
+
+// DraftOrder is a parked cart. It holds items without taking them out of stock until it is
+// checked out, which turns it into the transaction TransactionID. An open draft not touched
+// until ExpiresAt expires.
+type DraftOrder struct {
+	ID            int              `json:"id"`
+	OutletID      int              `json:"outlet_id"`
+	Label         string           `json:"label,omitempty"`
+	Notes         string           `json:"notes,omitempty"`
+	CustomerID    int              `json:"customer_id,omitempty"`
+	PriceListID   int              `json:"price_list_id,omitempty"`
+	CustomerGroup string           `json:"customer_group,omitempty"`
+	Status        string           `json:"status"`
+	TransactionID int              `json:"transaction_id,omitempty"`
+	CreatedBy     string           `json:"created_by,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+	ExpiresAt     time.Time        `json:"expires_at"`
+	Items         []DraftOrderItem `json:"items,omitempty"`
+}
+
+// DraftOrderItem is a product held in a draft order, Quantity is in Unit
+type DraftOrderItem struct {
+	ProductID   int      `json:"product_id"`
+	ProductName string   `json:"product_name,omitempty"`
+	Quantity    Quantity `json:"quantity" swaggertype:"number"`
+	Unit        string   `json:"unit"`
+}
+
+// DraftOrderRequest is used to park a cart or edit a parked one, Items replaces all items.
+// Label names the draft for the cashier, e.g. a table or the customer's name.
+type DraftOrderRequest struct {
+	Label         string         `json:"label"`
+	Notes         string         `json:"notes"`
+	CustomerID    int            `json:"customer_id,omitempty"`
+	CustomerPhone string         `json:"customer_phone,omitempty"`
+	PriceListID   int            `json:"price_list_id,omitempty"`
+	CustomerGroup string         `json:"customer_group,omitempty"`
+	Items         []CheckoutItem `json:"items"`
+	OutletID      int            `json:"-"`
+	Actor         string         `json:"-"`
+}
@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// Gift card types. A gift card can be spent over several checkouts, a voucher is used
+// up by the checkout it pays and any amount left is forfeited.
+const (
+	GiftCardTypeCard    = "gift_card"
+	GiftCardTypeVoucher = "voucher"
+)
+
+// Gift card statuses
+const (
+	GiftCardActive   = "active"
+	GiftCardRedeemed = "redeemed"
+	GiftCardVoid     = "void"
+)
+
+// Gift card ledger entry types
+const (
+	GiftCardEntryIssue  = "issue"
+	GiftCardEntryRedeem = "redeem"
+	GiftCardEntryRefund = "refund"
+	GiftCardEntryVoid   = "void"
+)
+
+// GiftCard is a prepaid gift card or voucher sold at checkout. TransactionID is the sale that issued it.
+// ExpiryDate uses the YYYY-MM-DD format and is empty when the card never expires.
+type GiftCard struct {
+	ID            int             `json:"id"`
+	Code          string          `json:"code"`
+	Type          string          `json:"type"`
+	InitialAmount int             `json:"initial_amount"`
+	Balance       int             `json:"balance"`
+	Status        string          `json:"status"`
+	ExpiryDate    string          `json:"expiry_date,omitempty"`
+	Expired       bool            `json:"expired"`
+	TransactionID int             `json:"transaction_id,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	Entries       []GiftCardEntry `json:"entries,omitempty"`
+}
+
+// GiftCardEntry is an append-only ledger entry for a change of a gift card's balance
+type GiftCardEntry struct {
+	ID            int       `json:"id"`
+	TransactionID int       `json:"transaction_id,omitempty"`
+	Type          string    `json:"type"`
+	Amount        int       `json:"amount"`
+	BalanceAfter  int       `json:"balance_after"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// GiftCardBalance is the answer of a balance check
+type GiftCardBalance struct {
+	Code       string `json:"code"`
+	Type       string `json:"type"`
+	Balance    int    `json:"balance"`
+	Status     string `json:"status"`
+	ExpiryDate string `json:"expiry_date,omitempty"`
+	Expired    bool   `json:"expired"`
+	// Usable is true when the card can pay a checkout now
+	Usable bool `json:"usable"`
+}
+
+// GiftCardIssueRequest sells a gift card or voucher at checkout. Code is generated when empty and
+// ExpiryDate (YYYY-MM-DD) defaults to the configured validity.
+type GiftCardIssueRequest struct {
+	Type       string `json:"type"`
+	Amount     int    `json:"amount"`
+	Code       string `json:"code,omitempty"`
+	ExpiryDate string `json:"expiry_date,omitempty"`
+}
+
+// GiftCardPayment pays part of a checkout with a gift card or voucher.
+// Amount defaults to as much of what is left to pay as the card covers.
+type GiftCardPayment struct {
+	Code   string `json:"code"`
+	Amount int    `json:"amount,omitempty"`
+}
+
+// GiftCardRedemption is what a gift card paid of a transaction
+type GiftCardRedemption struct {
+	GiftCardID   int    `json:"gift_card_id"`
+	Code         string `json:"code"`
+	Amount       int    `json:"amount"`
+	BalanceAfter int    `json:"balance_after"`
+}
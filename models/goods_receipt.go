@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// GoodsReceipt records a delivery received from a supplier, optionally against a purchase order
+type GoodsReceipt struct {
+	ID              int                `json:"id"`
+	SupplierID      int                `json:"supplier_id"`
+	SupplierName    string             `json:"supplier_name,omitempty"`
+	PurchaseOrderID int                `json:"purchase_order_id,omitempty"`
+	OutletID        int                `json:"outlet_id"`
+	Reference       string             `json:"reference,omitempty"`
+	Notes           string             `json:"notes,omitempty"`
+	TotalCost       int                `json:"total_cost"`
+	ReceivedBy      string             `json:"received_by,omitempty"`
+	ReceivedAt      time.Time          `json:"received_at"`
+	Lines           []GoodsReceiptLine `json:"lines,omitempty"`
+}
+
+// GoodsReceiptLine is a received product. Quantity and UnitCost are in the delivered Unit,
+// BaseQuantity is the amount added to stock in the product's base unit.
+type GoodsReceiptLine struct {
+	ID                  int      `json:"id"`
+	ProductID           int      `json:"product_id"`
+	ProductName         string   `json:"product_name,omitempty"`
+	PurchaseOrderLineID int      `json:"purchase_order_line_id,omitempty"`
+	Quantity            Quantity `json:"quantity" swaggertype:"number"`
+	Unit                string   `json:"unit"`
+	BaseQuantity        Quantity `json:"base_quantity" swaggertype:"number"`
+	UnitCost            int      `json:"unit_cost"`
+	Subtotal            int      `json:"subtotal"`
+	AverageCostAfter    int      `json:"average_cost_after"`
+	BatchID             int      `json:"batch_id,omitempty"`
+	BatchNumber         string   `json:"batch_number,omitempty"`
+	ExpiryDate          string   `json:"expiry_date,omitempty"`
+}
+
+// GoodsReceiptRequest records a delivery. With a PurchaseOrderID the supplier defaults to
+// the order's supplier and lines are matched to order lines by product when not given.
+type GoodsReceiptRequest struct {
+	SupplierID      int                       `json:"supplier_id"`
+	PurchaseOrderID int                       `json:"purchase_order_id,omitempty"`
+	Reference       string                    `json:"reference"`
+	Notes           string                    `json:"notes"`
+	Lines           []GoodsReceiptLineRequest `json:"lines"`
+	OutletID        int                       `json:"-"`
+	Actor           string                    `json:"-"`
+}
+
+// GoodsReceiptLineRequest is a received product. Unit defaults to the purchase order
+// line's unit, or the product's purchase unit. With a BatchNumber the received stock is
+// tracked as a batch, ExpiryDate is YYYY-MM-DD.
+type GoodsReceiptLineRequest struct {
+	ProductID           int      `json:"product_id"`
+	PurchaseOrderLineID int      `json:"purchase_order_line_id,omitempty"`
+	Quantity            Quantity `json:"quantity" swaggertype:"number"`
+	Unit                string   `json:"unit,omitempty"`
+	UnitCost            int      `json:"unit_cost"`
+	BatchNumber         string   `json:"batch_number,omitempty"`
+	ExpiryDate          string   `json:"expiry_date,omitempty"`
+}
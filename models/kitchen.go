@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Kitchen item statuses. Items are new when they reach their station, preparing once the cook starts
+// them, ready to be picked up and bumped off the screen once picked up.
+const (
+	KitchenItemNew       = "new"
+	KitchenItemPreparing = "preparing"
+	KitchenItemReady     = "ready"
+	KitchenItemBumped    = "bumped"
+)
+
+// KitchenStation is a place in the kitchen preparing the products of some categories,
+// such as the bar for drinks. Every category goes to at most one station.
+type KitchenStation struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	CategoryIDs []int     `json:"category_ids"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// KitchenStationRequest is used for create/update operations
+type KitchenStationRequest struct {
+	Name        string `json:"name"`
+	CategoryIDs []int  `json:"category_ids"`
+}
+
+// KitchenItem is an ordered product to prepare at a station. It comes from a sale, TransactionID,
+// or from a round of a dine-in order sent to the kitchen, DineInOrderID with the order's Tables.
+// AgeSeconds runs from when the item reached the station until it is ready,
+// PrepSeconds from when preparing started until it is ready.
+type KitchenItem struct {
+	ID            int        `json:"id"`
+	StationID     int        `json:"station_id"`
+	OutletID      int        `json:"outlet_id"`
+	TransactionID int        `json:"transaction_id,omitempty"`
+	DineInOrderID int        `json:"dine_in_order_id,omitempty"`
+	Tables        string     `json:"tables,omitempty"`
+	ProductID     int        `json:"product_id"`
+	ProductName   string     `json:"product_name"`
+	Quantity      Quantity   `json:"quantity" swaggertype:"number"`
+	Unit          string     `json:"unit"`
+	Notes         string     `json:"notes,omitempty"`
+	Status        string     `json:"status"`
+	CreatedAt     time.Time  `json:"created_at"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	ReadyAt       *time.Time `json:"ready_at,omitempty"`
+	BumpedAt      *time.Time `json:"bumped_at,omitempty"`
+	AgeSeconds    int        `json:"age_seconds"`
+	PrepSeconds   int        `json:"prep_seconds"`
+}
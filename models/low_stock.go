@@ -0,0 +1,14 @@
+package models
+
+// LowStockItem is a product whose stock, in total or at an outlet, is at or below its minimum stock.
+// As an alert it also names the transaction whose sale crossed the threshold.
+type LowStockItem struct {
+	ProductID       int      `json:"product_id"`
+	ProductName     string   `json:"product_name"`
+	OutletID        int      `json:"outlet_id,omitempty"`
+	Unit            string   `json:"unit"`
+	Stock           Quantity `json:"stock" swaggertype:"number"`
+	MinStock        Quantity `json:"min_stock" swaggertype:"number"`
+	ReorderQuantity Quantity `json:"reorder_quantity" swaggertype:"number"`
+	TransactionID   int      `json:"transaction_id,omitempty"`
+}
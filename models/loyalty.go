@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Loyalty ledger entry types
+const (
+	LoyaltyEarn     = "earn"
+	LoyaltyRedeem   = "redeem"
+	LoyaltyExpire   = "expire"
+	LoyaltyReversal = "reversal"
+	LoyaltyRestore  = "restore"
+)
+
+// Rounding of earned points
+const (
+	LoyaltyRoundDown    = "down"
+	LoyaltyRoundNearest = "nearest"
+	LoyaltyRoundUp      = "up"
+)
+
+// LoyaltyParams configures the points program. A customer earns a point for every SpendPerPoint
+// Rupiah spent outside the excluded categories, rounded by Rounding, and a point is worth
+// PointValue Rupiah when redeemed. Points expire ExpiryDays after they are earned.
+// A zero SpendPerPoint stops earning, a zero PointValue stops redemption and a zero
+// ExpiryDays keeps points forever.
+type LoyaltyParams struct {
+	SpendPerPoint      int    `json:"spend_per_point"`
+	Rounding           string `json:"rounding"`
+	PointValue         int    `json:"point_value"`
+	ExpiryDays         int    `json:"expiry_days"`
+	ExcludedCategories []int  `json:"excluded_categories,omitempty"`
+}
+
+// LoyaltyEntry is an append-only ledger entry for a change of a customer's points.
+// Points is the signed change and BalanceAfter the customer's balance right after it.
+// ExpiresAt is set on points credited to the customer.
+type LoyaltyEntry struct {
+	ID            int        `json:"id"`
+	CustomerID    int        `json:"customer_id"`
+	TransactionID int        `json:"transaction_id,omitempty"`
+	Type          string     `json:"type"`
+	Points        int        `json:"points"`
+	BalanceAfter  int        `json:"balance_after"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	Reason        string     `json:"reason,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// LoyaltyAccount is a customer's points balance with its ledger, newest entry first.
+// BalanceValue is what the balance is worth when redeemed and NextExpiryPoints the points
+// that expire first, at NextExpiry. The balance can be negative when points already
+// spent were reversed by a refund.
+type LoyaltyAccount struct {
+	CustomerID       int            `json:"customer_id"`
+	Balance          int            `json:"balance"`
+	BalanceValue     int            `json:"balance_value"`
+	NextExpiry       *time.Time     `json:"next_expiry,omitempty"`
+	NextExpiryPoints int            `json:"next_expiry_points"`
+	Entries          []LoyaltyEntry `json:"entries"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxEvent is an event recorded in the same database transaction as the change it describes,
+// kept until every sink has received it. EventID identifies it to the sinks across repeated relays.
+type OutboxEvent struct {
+	ID         int64
+	EventID    string
+	EventType  string
+	Payload    json.RawMessage
+	OccurredAt time.Time
+	Attempts   int
+	// DoneSinks are the sinks that already received the event, a retry skips them
+	DoneSinks []string
+}
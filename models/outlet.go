@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Outlet is a store with its own stock. The default outlet is used when a
+// request does not name one.
+type Outlet struct {
+	ID        int       `json:"id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address,omitempty"`
+	IsDefault bool      `json:"is_default"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OutletRequest is used for create/update operations
+type OutletRequest struct {
+	Code      string `json:"code"`
+	Name      string `json:"name"`
+	Address   string `json:"address"`
+	IsDefault bool   `json:"is_default"`
+}
+
+// OutletStock is the stock and price of a product at one outlet. Price is the outlet's
+// override of the product price, EffectivePrice is what the outlet sells for.
+type OutletStock struct {
+	OutletID       int      `json:"outlet_id"`
+	OutletName     string   `json:"outlet_name,omitempty"`
+	ProductID      int      `json:"product_id"`
+	ProductName    string   `json:"product_name,omitempty"`
+	Unit           string   `json:"unit,omitempty"`
+	Stock          Quantity `json:"stock" swaggertype:"number"`
+	Price          *int     `json:"price,omitempty"`
+	EffectivePrice int      `json:"effective_price"`
+}
+
+// OutletPriceRequest sets the price of a product at an outlet, a null price
+// removes the override so the product price applies again
+type OutletPriceRequest struct {
+	ProductID int  `json:"product_id"`
+	Price     *int `json:"price"`
+}
+
+// OutletSales summarises the sales of one outlet for the consolidated owner view
+type OutletSales struct {
+	OutletID          int     `json:"outlet_id"`
+	OutletName        string  `json:"outlet_name"`
+	TotalRevenue      int     `json:"total_revenue"`
+	TotalTransactions int     `json:"total_transactions"`
+	TotalCOGS         int     `json:"total_cogs"`
+	GrossProfit       int     `json:"gross_profit"`
+	MarginPercent     float64 `json:"margin_percent"`
+}
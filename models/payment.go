@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Payment statuses, shared by payments and the payment_status of their transactions. A pending
+// payment waits for the customer to pay, the others are final. Sales that are not paid keep their
+// stock reserved while pending and give it back once expired, cancelled or failed.
+const (
+	PaymentPending   = "pending"
+	PaymentPaid      = "paid"
+	PaymentExpired   = "expired"
+	PaymentCancelled = "cancelled"
+	PaymentFailed    = "failed"
+)
+
+// PaymentMethodQRIS is a payment by dynamic QRIS
+const PaymentMethodQRIS = "qris"
+
+// Payment is a payment of a transaction taken through a payment gateway. Reference identifies it
+// at the gateway and QRISPayload is the QRIS the customer scans.
+type Payment struct {
+	ID            int        `json:"id"`
+	TransactionID int        `json:"transaction_id"`
+	Method        string     `json:"method"`
+	Gateway       string     `json:"gateway"`
+	Reference     string     `json:"reference,omitempty"`
+	Amount        int        `json:"amount"`
+	Status        string     `json:"status"`
+	QRISPayload   string     `json:"qris_payload,omitempty"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	PaidAt        *time.Time `json:"paid_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	// Transaction is the sale being paid, as recorded at checkout
+	Transaction *Transaction `json:"transaction,omitempty"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Price list types
+const (
+	PriceListRetail    = "retail"
+	PriceListMember    = "member"
+	PriceListWholesale = "wholesale"
+	PriceListCustom    = "custom"
+)
+
+// PriceList holds alternative selling prices, such as member or wholesale prices.
+// A checkout selects it by ID or by CustomerGroup, the group of customers it applies to.
+type PriceList struct {
+	ID            int             `json:"id"`
+	Name          string          `json:"name"`
+	Type          string          `json:"type"`
+	CustomerGroup string          `json:"customer_group,omitempty"`
+	Active        bool            `json:"active"`
+	CreatedAt     time.Time       `json:"created_at"`
+	Items         []PriceListItem `json:"items,omitempty"`
+}
+
+// PriceListItem prices a product sold in Unit from MinQuantity of that unit up.
+// Several items of the same product and unit form quantity-break tiers, the item with
+// the highest MinQuantity not above the quantity sold applies.
+type PriceListItem struct {
+	ProductID   int      `json:"product_id"`
+	ProductName string   `json:"product_name,omitempty"`
+	Unit        string   `json:"unit"`
+	MinQuantity Quantity `json:"min_quantity" swaggertype:"number"`
+	Price       int      `json:"price"`
+}
+
+// PriceListRequest is used to create or update a price list, Items replaces all prices.
+// Active defaults to true.
+type PriceListRequest struct {
+	Name          string                 `json:"name"`
+	Type          string                 `json:"type"`
+	CustomerGroup string                 `json:"customer_group,omitempty"`
+	Active        *bool                  `json:"active,omitempty"`
+	Items         []PriceListItemRequest `json:"items"`
+}
+
+// PriceListItemRequest prices a product. Unit defaults to the product's sale unit and
+// MinQuantity to 1.
+type PriceListItemRequest struct {
+	ProductID   int      `json:"product_id"`
+	Unit        string   `json:"unit,omitempty"`
+	MinQuantity Quantity `json:"min_quantity,omitempty" swaggertype:"number"`
+	Price       int      `json:"price"`
+}
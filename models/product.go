@@ -1,19 +1,80 @@
 package models
 
-// Product represents a product in the kasir system
+import "time"
+
+// DefaultUnit is the stock unit used when a product does not specify one
+const DefaultUnit = "pcs"
+
+// Product represents a product in the kasir system.
+// Price and CostPrice are per base Unit and Stock is counted in the base Unit.
 type Product struct {
-	ID           int    `json:"id"`
-	Name         string `json:"name"`
-	Price        int    `json:"price"`
-	Stock        int    `json:"stock"`
-	CategoryID   int    `json:"category_id"`
-	CategoryName string `json:"category_name,omitempty"`
+	ID            int               `json:"id"`
+	Name          string            `json:"name"`
+	Barcode       string            `json:"barcode,omitempty"`
+	Price         int               `json:"price"`
+	CostPrice     int               `json:"cost_price"`
+	Stock         Quantity          `json:"stock" swaggertype:"number"`
+	CategoryID    int               `json:"category_id"`
+	CategoryName  string            `json:"category_name,omitempty"`
+	IsBundle      bool              `json:"is_bundle"`
+	Components    []BundleComponent `json:"components,omitempty"`
+	Unit          string            `json:"unit"`
+	SaleUnit      string            `json:"sale_unit"`
+	PurchaseUnit  string            `json:"purchase_unit"`
+	AllowFraction bool              `json:"allow_fraction"`
+	Units         []ProductUnit     `json:"units,omitempty"`
+	// MinStock is the reorder point, 0 disables low-stock alerts. ReorderQuantity is how much to order then.
+	MinStock        Quantity `json:"min_stock" swaggertype:"number"`
+	ReorderQuantity Quantity `json:"reorder_quantity" swaggertype:"number"`
+	// Stock is the total over all outlets, Outlets breaks it down with each outlet's price
+	Outlets []OutletStock `json:"outlets,omitempty"`
 }
 
-// ProductRequest is used for create/update operations
+// ProductRequest is used for create/update operations.
+// A product with components is a bundle: its stock is derived from the components. On update
+// components left out are kept, an empty list turns a bundle back into a plain product.
+// Stock is the opening stock at OutletID, recorded in the stock ledger as an adjustment. On update
+// stock only changes through adjustments, so when it is sent it has to match the current stock.
 type ProductRequest struct {
-	Name       string `json:"name"`
-	Price      int    `json:"price"`
-	Stock      int    `json:"stock"`
-	CategoryID int    `json:"category_id"`
+	Name            string            `json:"name"`
+	Barcode         string            `json:"barcode,omitempty"`
+	Price           int               `json:"price"`
+	CostPrice       int               `json:"cost_price"`
+	Stock           *Quantity         `json:"stock,omitempty" swaggertype:"number"`
+	CategoryID      int               `json:"category_id"`
+	Components      []BundleComponent `json:"components,omitempty"`
+	Unit            string            `json:"unit"`
+	SaleUnit        string            `json:"sale_unit"`
+	PurchaseUnit    string            `json:"purchase_unit"`
+	AllowFraction   bool              `json:"allow_fraction"`
+	Units           []ProductUnit     `json:"units,omitempty"`
+	MinStock        Quantity          `json:"min_stock" swaggertype:"number"`
+	ReorderQuantity Quantity          `json:"reorder_quantity" swaggertype:"number"`
+	OutletID        int               `json:"-"`
+	Actor           string            `json:"-"`
+}
+
+// BundleComponent represents a product and quantity (in the component's base unit) contained in a bundle
+type BundleComponent struct {
+	ProductID   int      `json:"product_id"`
+	ProductName string   `json:"product_name,omitempty"`
+	Quantity    Quantity `json:"quantity" swaggertype:"number"`
+}
+
+// ProductUnit is an alternative unit of a product, e.g. a carton of 40 pcs.
+// Factor is the number of base units in one of this unit. Price is the selling
+// price per unit; when empty the base price times Factor is used.
+type ProductUnit struct {
+	Name   string   `json:"name"`
+	Factor Quantity `json:"factor" swaggertype:"number"`
+	Price  *int     `json:"price,omitempty"`
+}
+
+// ProductCost is an entry in the cost price history of a product
+type ProductCost struct {
+	ID          int       `json:"id"`
+	ProductID   int       `json:"product_id"`
+	CostPrice   int       `json:"cost_price"`
+	Reason      string    `json:"reason"`
+	EffectiveAt time.Time `json:"effective_at"`
 }
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// Purchase order statuses
+const (
+	POStatusDraft             = "draft"
+	POStatusSent              = "sent"
+	POStatusPartiallyReceived = "partially_received"
+	POStatusReceived          = "received"
+	POStatusCancelled         = "cancelled"
+)
+
+// PurchaseOrder represents a planned restock from a supplier
+type PurchaseOrder struct {
+	ID           int                 `json:"id"`
+	SupplierID   int                 `json:"supplier_id"`
+	SupplierName string              `json:"supplier_name,omitempty"`
+	Status       string              `json:"status"`
+	Notes        string              `json:"notes,omitempty"`
+	ExpectedDate string              `json:"expected_date,omitempty"`
+	TotalAmount  int                 `json:"total_amount"`
+	CreatedBy    string              `json:"created_by,omitempty"`
+	CreatedAt    time.Time           `json:"created_at"`
+	UpdatedAt    time.Time           `json:"updated_at"`
+	Lines        []PurchaseOrderLine `json:"lines,omitempty"`
+}
+
+// PurchaseOrderLine is a product ordered in a purchase unit. UnitFactor is the number of
+// base units in one purchase unit and UnitCost the price per purchase unit.
+type PurchaseOrderLine struct {
+	ID               int      `json:"id"`
+	ProductID        int      `json:"product_id"`
+	ProductName      string   `json:"product_name,omitempty"`
+	Quantity         Quantity `json:"quantity" swaggertype:"number"`
+	Unit             string   `json:"unit"`
+	UnitFactor       Quantity `json:"unit_factor" swaggertype:"number"`
+	UnitCost         int      `json:"unit_cost"`
+	Subtotal         int      `json:"subtotal"`
+	ReceivedQuantity Quantity `json:"received_quantity" swaggertype:"number"`
+}
+
+// PurchaseOrderRequest is used to create or edit a draft purchase order.
+// ExpectedDate uses the YYYY-MM-DD format.
+type PurchaseOrderRequest struct {
+	SupplierID   int                        `json:"supplier_id"`
+	Notes        string                     `json:"notes"`
+	ExpectedDate string                     `json:"expected_date,omitempty"`
+	Lines        []PurchaseOrderLineRequest `json:"lines"`
+	Actor        string                     `json:"-"`
+}
+
+// PurchaseOrderLineRequest orders a product. Unit defaults to the product's purchase unit.
+type PurchaseOrderLineRequest struct {
+	ProductID int      `json:"product_id"`
+	Quantity  Quantity `json:"quantity" swaggertype:"number"`
+	Unit      string   `json:"unit,omitempty"`
+	UnitCost  int      `json:"unit_cost"`
+}
@@ -0,0 +1,243 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// QuantityScale is the number of Quantity units in one whole unit (3 decimal places)
+const QuantityScale = 1000
+
+// Quantity is a fixed-point decimal amount of stock, e.g. 0.25 kg or 12 pcs.
+// It is stored as thousandths so stock math never goes through floating point.
+// In JSON it is a plain number (0.25), in Postgres a NUMERIC(14,3).
+type Quantity int64
+
+// MaxQuantity is the largest quantity a NUMERIC(14,3) column holds, 99,999,999,999.999
+const MaxQuantity Quantity = 99_999_999_999_999
+
+// MaxAmount is the largest amount in Rupiah an INTEGER column holds
+const MaxAmount = math.MaxInt32
+
+// ErrOutOfRange is returned when a quantity or an amount computed from one does not fit its column
+var ErrOutOfRange = errors.New("quantity or amount out of range")
+
+// NewQuantity returns a whole Quantity
+func NewQuantity(n int) Quantity {
+	return Quantity(n) * QuantityScale
+}
+
+// ParseQuantity parses a decimal string with at most 3 fractional digits
+func ParseQuantity(s string) (Quantity, error) {
+	q, exact, err := parseDecimal(s)
+	if err != nil {
+		return 0, err
+	}
+	if !exact {
+		return 0, fmt.Errorf("quantity %q has more than 3 decimal places", s)
+	}
+	return q, nil
+}
+
+// parseDecimal parses s into thousandths, rounding half up beyond the third decimal.
+// exact reports whether no rounding was needed.
+func parseDecimal(s string) (q Quantity, exact bool, err error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false, fmt.Errorf("invalid quantity %q", s)
+	}
+
+	negative := false
+	digits := s
+	if digits[0] == '-' || digits[0] == '+' {
+		negative = digits[0] == '-'
+		digits = digits[1:]
+	}
+
+	whole, frac, _ := strings.Cut(digits, ".")
+	if whole == "" && frac == "" {
+		return 0, false, fmt.Errorf("invalid quantity %q", s)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	exact = true
+	roundUp := false
+	if len(frac) > 3 {
+		for _, c := range frac[3:] {
+			if c != '0' {
+				exact = false
+			}
+		}
+		roundUp = frac[3] >= '5'
+		frac = frac[:3]
+	}
+	frac += strings.Repeat("0", 3-len(frac))
+
+	w, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || strings.ContainsAny(whole, "+-") {
+		return 0, false, fmt.Errorf("invalid quantity %q", s)
+	}
+	if w > int64(MaxQuantity/QuantityScale) {
+		return 0, false, fmt.Errorf("quantity %q is out of range", s)
+	}
+	f, err := strconv.ParseInt(frac, 10, 64)
+	if err != nil || strings.ContainsAny(frac, "+-") {
+		return 0, false, fmt.Errorf("invalid quantity %q", s)
+	}
+
+	q = Quantity(w*QuantityScale + f)
+	if roundUp {
+		q++
+	}
+	if q > MaxQuantity {
+		return 0, false, fmt.Errorf("quantity %q is out of range", s)
+	}
+	if negative {
+		q = -q
+	}
+	return q, exact, nil
+}
+
+// String formats the quantity without trailing zeros, e.g. "2", "0.25"
+func (q Quantity) String() string {
+	sign := ""
+	v := int64(q)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+
+	whole := v / QuantityScale
+	frac := v % QuantityScale
+	if frac == 0 {
+		return sign + strconv.FormatInt(whole, 10)
+	}
+	return sign + strconv.FormatInt(whole, 10) + "." + strings.TrimRight(fmt.Sprintf("%03d", frac), "0")
+}
+
+// IsWhole reports whether the quantity has no fractional part
+func (q Quantity) IsWhole() bool {
+	return q%QuantityScale == 0
+}
+
+// Mul multiplies two quantities, rounding half up to 3 decimal places.
+// It fails with ErrOutOfRange when the product is beyond MaxQuantity.
+func (q Quantity) Mul(other Quantity) (Quantity, error) {
+	v, ok := mulDivRound(int64(q), int64(other), QuantityScale)
+	if !ok || v > int64(MaxQuantity) || v < -int64(MaxQuantity) {
+		return 0, fmt.Errorf("%w: %s × %s", ErrOutOfRange, q, other)
+	}
+	return Quantity(v), nil
+}
+
+// Div divides two quantities, rounding half up to 3 decimal places
+func (q Quantity) Div(other Quantity) Quantity {
+	if other == 0 {
+		return 0
+	}
+	n := int64(q) * QuantityScale
+	d := int64(other)
+	if d < 0 {
+		n, d = -n, -d
+	}
+	return Quantity(divRound(n, d))
+}
+
+// MulPrice returns the amount for q units at price per unit, rounded half up to whole Rupiah.
+// It fails with ErrOutOfRange when the amount is beyond MaxAmount.
+func (q Quantity) MulPrice(price int) (int, error) {
+	v, ok := mulDivRound(int64(q), int64(price), QuantityScale)
+	if !ok || v > MaxAmount || v < -MaxAmount {
+		return 0, fmt.Errorf("%w: %s at %d", ErrOutOfRange, q, price)
+	}
+	return int(v), nil
+}
+
+// divRound divides rounding half away from zero
+func divRound(n, d int64) int64 {
+	if n < 0 {
+		return -((-n + d/2) / d)
+	}
+	return (n + d/2) / d
+}
+
+// mulDivRound returns a*b/d rounded half away from zero, with the product taken in 128 bits.
+// ok is false when the result does not fit in an int64.
+func mulDivRound(a, b, d int64) (int64, bool) {
+	negative := (a < 0) != (b < 0)
+	hi, lo := bits.Mul64(absUint(a), absUint(b))
+	lo, carry := bits.Add64(lo, uint64(d/2), 0)
+	hi += carry
+	if hi >= uint64(d) {
+		return 0, false
+	}
+	v, _ := bits.Div64(hi, lo, uint64(d))
+	if v > math.MaxInt64 {
+		return 0, false
+	}
+	if negative {
+		return -int64(v), true
+	}
+	return int64(v), true
+}
+
+// absUint returns the magnitude of n, which fits in a uint64 even for math.MinInt64
+func absUint(n int64) uint64 {
+	if n < 0 {
+		return uint64(-n)
+	}
+	return uint64(n)
+}
+
+// MarshalJSON encodes the quantity as a JSON number
+func (q Quantity) MarshalJSON() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a numeric string
+func (q *Quantity) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" {
+		return nil
+	}
+	parsed, err := ParseQuantity(s)
+	if err != nil {
+		return err
+	}
+	*q = parsed
+	return nil
+}
+
+// Scan implements sql.Scanner for NUMERIC and integer columns
+func (q *Quantity) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*q = 0
+		return nil
+	case int64:
+		*q = Quantity(v * QuantityScale)
+		return nil
+	case string:
+		parsed, _, err := parseDecimal(v)
+		*q = parsed
+		return err
+	case []byte:
+		parsed, _, err := parseDecimal(string(v))
+		*q = parsed
+		return err
+	default:
+		return fmt.Errorf("cannot scan %T into Quantity", src)
+	}
+}
+
+// Value implements driver.Valuer, sending the quantity as a decimal string
+func (q Quantity) Value() (driver.Value, error) {
+	return q.String(), nil
+}
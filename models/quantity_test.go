@@ -0,0 +1,256 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Quantity
+		wantErr bool
+	}{
+		{"1", 1000, false},
+		{"0.25", 250, false},
+		{" 12 ", 12000, false},
+		{".5", 500, false},
+		{"3.", 3000, false},
+		{"+2", 2000, false},
+		{"-1.5", -1500, false},
+		{"0.001", 1, false},
+		{"1.2340", 1234, false},
+		{"99999999999.999", MaxQuantity, false},
+		{"-99999999999.999", -MaxQuantity, false},
+		{"1.2345", 0, true},
+		{"100000000000", 0, true},
+		{"9223372036854774.999", 0, true},
+		{"9223372036854775", 0, true},
+		{"99999999999999999999", 0, true},
+		{"", 0, true},
+		{".", 0, true},
+		{"abc", 0, true},
+		{"--1", 0, true},
+		{"1.-2", 0, true},
+		{"1e3", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseQuantity(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseQuantity(%q) = %d, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseQuantity(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestQuantityString(t *testing.T) {
+	tests := []struct {
+		q    Quantity
+		want string
+	}{
+		{0, "0"},
+		{1, "0.001"},
+		{250, "0.25"},
+		{1000, "1"},
+		{12345, "12.345"},
+		{-1500, "-1.5"},
+		{-1, "-0.001"},
+	}
+	for _, tt := range tests {
+		if got := tt.q.String(); got != tt.want {
+			t.Errorf("Quantity(%d).String() = %q, want %q", int64(tt.q), got, tt.want)
+		}
+	}
+}
+
+func TestQuantityMul(t *testing.T) {
+	tests := []struct {
+		name    string
+		q       Quantity
+		other   Quantity
+		want    Quantity
+		wantErr bool
+	}{
+		{"whole", 1500, 2000, 3000, false},
+		{"rounds half up", 333, 500, 167, false},
+		{"rounds down", 333, 499, 166, false},
+		{"negative rounds away from zero", -333, 500, -167, false},
+		{"by unit factor", NewQuantity(3), NewQuantity(12), NewQuantity(36), false},
+		{"up to the maximum", MaxQuantity, NewQuantity(1), MaxQuantity, false},
+		{"past the maximum", MaxQuantity, 1001, 0, true},
+		{"negative past the maximum", -MaxQuantity, NewQuantity(2), 0, true},
+		{"beyond int64 in between", MaxQuantity, MaxQuantity, 0, true},
+		{"rounding to just past the maximum", MaxQuantity*2 + 1, 500, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := tt.q.Mul(tt.other)
+		if tt.wantErr {
+			if !errors.Is(err, ErrOutOfRange) {
+				t.Errorf("%s: got %d, %v, want ErrOutOfRange", tt.name, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s: got %d, %v, want %d", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestQuantityDiv(t *testing.T) {
+	tests := []struct {
+		name string
+		got  Quantity
+		want Quantity
+	}{
+		{"div exact", NewQuantity(3).Div(NewQuantity(12)), 250},
+		{"div thirds", NewQuantity(1).Div(NewQuantity(3)), 333},
+		{"div rounds half up", NewQuantity(2).Div(NewQuantity(3)), 667},
+		{"div negative numerator", NewQuantity(-2).Div(NewQuantity(3)), -667},
+		{"div negative denominator", NewQuantity(1).Div(NewQuantity(-3)), -333},
+		{"div by zero", NewQuantity(5).Div(0), 0},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestQuantityMulPrice(t *testing.T) {
+	tests := []struct {
+		q       Quantity
+		price   int
+		want    int
+		wantErr bool
+	}{
+		{250, 15000, 3750, false},
+		{NewQuantity(3), 8000, 24000, false},
+		{1500, 333, 500, false},
+		{1499, 333, 499, false},
+		{-1500, 333, -500, false},
+		{1, 499, 0, false},
+		{1, 500, 1, false},
+		{0, 15000, 0, false},
+		{NewQuantity(1), MaxAmount, MaxAmount, false},
+		{NewQuantity(2), MaxAmount, 0, true},
+		{-NewQuantity(2), MaxAmount, 0, true},
+		{NewQuantity(100000), 25000, 0, true},
+		{MaxQuantity, 1 << 40, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := tt.q.MulPrice(tt.price)
+		if tt.wantErr {
+			if !errors.Is(err, ErrOutOfRange) {
+				t.Errorf("Quantity(%s).MulPrice(%d) = %d, %v, want ErrOutOfRange", tt.q, tt.price, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Quantity(%s).MulPrice(%d) = %d, %v, want %d", tt.q, tt.price, got, err, tt.want)
+		}
+	}
+}
+
+func TestQuantityIsWhole(t *testing.T) {
+	if !NewQuantity(4).IsWhole() || !Quantity(-2000).IsWhole() {
+		t.Error("whole quantities reported as fractional")
+	}
+	if Quantity(2500).IsWhole() || Quantity(-1).IsWhole() {
+		t.Error("fractional quantities reported as whole")
+	}
+}
+
+func TestQuantityJSON(t *testing.T) {
+	type line struct {
+		Quantity Quantity `json:"quantity"`
+	}
+
+	for _, q := range []Quantity{0, 1, 250, 1000, -1500, 12345678} {
+		data, err := json.Marshal(line{q})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back line
+		if err := json.Unmarshal(data, &back); err != nil {
+			t.Fatalf("unmarshal %s: %v", data, err)
+		}
+		if back.Quantity != q {
+			t.Errorf("round trip of %d through %s gave %d", int64(q), data, int64(back.Quantity))
+		}
+	}
+
+	data, _ := json.Marshal(line{250})
+	if string(data) != `{"quantity":0.25}` {
+		t.Errorf("marshal = %s, want a plain number", data)
+	}
+
+	tests := []struct {
+		in      string
+		want    Quantity
+		wantErr bool
+	}{
+		{`{"quantity": 1.5}`, 1500, false},
+		{`{"quantity": "0.75"}`, 750, false},
+		{`{"quantity": null}`, 0, false},
+		{`{"quantity": -2}`, -2000, false},
+		{`{"quantity": 1.2345}`, 0, true},
+		{`{"quantity": "x"}`, 0, true},
+	}
+	for _, tt := range tests {
+		var l line
+		err := json.Unmarshal([]byte(tt.in), &l)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("unmarshal %s = %d, want error", tt.in, int64(l.Quantity))
+			}
+			continue
+		}
+		if err != nil || l.Quantity != tt.want {
+			t.Errorf("unmarshal %s = %d, %v, want %d", tt.in, int64(l.Quantity), err, int64(tt.want))
+		}
+	}
+}
+
+func TestQuantityScan(t *testing.T) {
+	tests := []struct {
+		src     interface{}
+		want    Quantity
+		wantErr bool
+	}{
+		{nil, 0, false},
+		{int64(5), 5000, false},
+		{int64(-3), -3000, false},
+		{"1.500", 1500, false},
+		{[]byte("2.25"), 2250, false},
+		// NUMERIC values with more decimals than the scale are rounded half up
+		{"1.2345", 1235, false},
+		{"-1.2345", -1235, false},
+		{"1.2344", 1234, false},
+		{"abc", 0, true},
+		{1.5, 0, true},
+	}
+	for _, tt := range tests {
+		var q Quantity
+		err := q.Scan(tt.src)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Scan(%v) = %d, want error", tt.src, int64(q))
+			}
+			continue
+		}
+		if err != nil || q != tt.want {
+			t.Errorf("Scan(%v) = %d, %v, want %d", tt.src, int64(q), err, int64(tt.want))
+		}
+	}
+
+	v, err := Quantity(-1250).Value()
+	if err != nil || v != "-1.25" {
+		t.Errorf("Value() = %v, %v, want \"-1.25\"", v, err)
+	}
+}
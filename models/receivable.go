@@ -0,0 +1,105 @@
+package models
+
+import "time"
+
+// Statement line types
+const (
+	StatementCharge  = "charge"
+	StatementPayment = "payment"
+	StatementRefund  = "refund"
+)
+
+// CustomerAccount is a customer's pay-later (kasbon) account. Outstanding is what the customer owes,
+// AvailableCredit what they can still buy on account. OpenInvoices lists the on-account
+// transactions not fully repaid, oldest first.
+type CustomerAccount struct {
+	CustomerID      int           `json:"customer_id"`
+	CustomerName    string        `json:"customer_name"`
+	CreditLimit     int           `json:"credit_limit"`
+	Outstanding     int           `json:"outstanding"`
+	AvailableCredit int           `json:"available_credit"`
+	OpenInvoices    []OpenInvoice `json:"open_invoices"`
+}
+
+// OpenInvoice is an on-account transaction with an unpaid balance
+type OpenInvoice struct {
+	TransactionID int       `json:"transaction_id"`
+	Date          time.Time `json:"date"`
+	Amount        int       `json:"amount"`
+	Paid          int       `json:"paid"`
+	Outstanding   int       `json:"outstanding"`
+	AgeDays       int       `json:"age_days"`
+}
+
+// CustomerPayment is a repayment of a customer's account. It settles the oldest open invoices first.
+type CustomerPayment struct {
+	ID          int                 `json:"id"`
+	CustomerID  int                 `json:"customer_id"`
+	Amount      int                 `json:"amount"`
+	Method      string              `json:"method,omitempty"`
+	Notes       string              `json:"notes,omitempty"`
+	Actor       string              `json:"actor,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+	Allocations []PaymentAllocation `json:"allocations"`
+	// Outstanding is what the customer still owes after the payment
+	Outstanding int `json:"outstanding"`
+}
+
+// PaymentAllocation is the part of a payment that settled an invoice
+type PaymentAllocation struct {
+	TransactionID int `json:"transaction_id"`
+	Amount        int `json:"amount"`
+}
+
+// CustomerPaymentRequest is used to record a repayment
+type CustomerPaymentRequest struct {
+	Amount int    `json:"amount"`
+	Method string `json:"method"`
+	Notes  string `json:"notes"`
+	Actor  string `json:"-"`
+}
+
+// CustomerStatement lists a customer's account activity in a period. Charges raise the balance,
+// payments and refunds of unpaid charges lower it.
+type CustomerStatement struct {
+	CustomerID     int             `json:"customer_id"`
+	CustomerName   string          `json:"customer_name"`
+	StartDate      string          `json:"start_date"`
+	EndDate        string          `json:"end_date"`
+	OpeningBalance int             `json:"opening_balance"`
+	Lines          []StatementLine `json:"lines"`
+	ClosingBalance int             `json:"closing_balance"`
+}
+
+// StatementLine is one entry of a statement. ReferenceID is the transaction of a charge or refund
+// and the payment of a payment.
+type StatementLine struct {
+	Date        time.Time `json:"date"`
+	Type        string    `json:"type"`
+	ReferenceID int       `json:"reference_id"`
+	Description string    `json:"description"`
+	Debit       int       `json:"debit"`
+	Credit      int       `json:"credit"`
+	Balance     int       `json:"balance"`
+}
+
+// ReceivablesAging splits what customers owe by the age of the unpaid invoices
+type ReceivablesAging struct {
+	AsOf      string          `json:"as_of"`
+	Current   int             `json:"days_0_30"`
+	Days31_60 int             `json:"days_31_60"`
+	Over60    int             `json:"days_over_60"`
+	Total     int             `json:"total"`
+	Customers []CustomerAging `json:"customers"`
+}
+
+// CustomerAging is one customer's line of the aging report
+type CustomerAging struct {
+	CustomerID   int    `json:"customer_id"`
+	CustomerName string `json:"customer_name"`
+	CreditLimit  int    `json:"credit_limit"`
+	Current      int    `json:"days_0_30"`
+	Days31_60    int    `json:"days_31_60"`
+	Over60       int    `json:"days_over_60"`
+	Total        int    `json:"total"`
+}
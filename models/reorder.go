@@ -0,0 +1,27 @@
+package models
+
+// ReorderParams tunes reorder suggestions, all values are in days.
+// WindowDays is the sales history used for the average, LeadTimeDays how long a supplier
+// takes to deliver, SafetyDays the extra sales kept in stock as a buffer and CoverDays
+// how many days of sales an order should last.
+type ReorderParams struct {
+	WindowDays   int `json:"window_days"`
+	LeadTimeDays int `json:"lead_time_days"`
+	SafetyDays   int `json:"safety_days"`
+	CoverDays    int `json:"cover_days"`
+}
+
+// ReorderSuggestion is what to order of a product based on its sales velocity.
+// Quantities are in the product's base unit. DaysOfCover is null when nothing was sold.
+type ReorderSuggestion struct {
+	ProductID         int      `json:"product_id"`
+	ProductName       string   `json:"product_name"`
+	Unit              string   `json:"unit"`
+	Stock             Quantity `json:"stock" swaggertype:"number"`
+	SoldQuantity      Quantity `json:"sold_quantity" swaggertype:"number"`
+	AverageDailySales Quantity `json:"average_daily_sales" swaggertype:"number"`
+	DaysOfCover       *float64 `json:"days_of_cover"`
+	SafetyStock       Quantity `json:"safety_stock" swaggertype:"number"`
+	ReorderPoint      Quantity `json:"reorder_point" swaggertype:"number"`
+	SuggestedQuantity Quantity `json:"suggested_quantity" swaggertype:"number"`
+}
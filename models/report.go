@@ -1,15 +1,83 @@
 package models
 
+import (
+	"math"
+	"time"
+)
+
 // SalesReport represents the sales report data
 type SalesReport struct {
+	OutletID          int        `json:"outlet_id,omitempty"`
 	TotalRevenue      int        `json:"total_revenue"`
 	TotalTransactions int        `json:"total_transactions"`
+	TotalCOGS         int        `json:"total_cogs"`
+	GrossProfit       int        `json:"gross_profit"`
+	MarginPercent     float64    `json:"margin_percent"`
 	BestSeller        BestSeller `json:"best_seller"`
 }
 
 // BestSeller represents the best selling product
 type BestSeller struct {
-	ProductID   int    `json:"product_id"`
-	ProductName string `json:"product_name"`
-	Quantity    int    `json:"quantity"`
+	ProductID   int      `json:"product_id"`
+	ProductName string   `json:"product_name"`
+	Quantity    Quantity `json:"quantity" swaggertype:"number"`
+}
+
+// DashboardMetrics are today's live sales figures. AverageBasket is revenue per transaction,
+// BestSellers are the products with the most revenue today.
+type DashboardMetrics struct {
+	OutletID      int            `json:"outlet_id,omitempty"`
+	Date          string         `json:"date"`
+	Revenue       int            `json:"revenue"`
+	Transactions  int            `json:"transactions"`
+	AverageBasket int            `json:"average_basket"`
+	GrossProfit   int            `json:"gross_profit"`
+	MarginPercent float64        `json:"margin_percent"`
+	BestSellers   []ProductSales `json:"best_sellers"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// ProductSales represents quantity sold, revenue and profit of a single product.
+// Bundles are broken down into their components using the allocated revenue.
+// Quantity is in the product's stock unit.
+type ProductSales struct {
+	ProductID     int      `json:"product_id"`
+	ProductName   string   `json:"product_name"`
+	Quantity      Quantity `json:"quantity" swaggertype:"number"`
+	Revenue       int      `json:"revenue"`
+	COGS          int      `json:"cogs"`
+	GrossProfit   int      `json:"gross_profit"`
+	MarginPercent float64  `json:"margin_percent"`
+}
+
+// CategorySales represents revenue and profit of all products in a category
+type CategorySales struct {
+	CategoryID    int     `json:"category_id"`
+	CategoryName  string  `json:"category_name"`
+	Revenue       int     `json:"revenue"`
+	COGS          int     `json:"cogs"`
+	GrossProfit   int     `json:"gross_profit"`
+	MarginPercent float64 `json:"margin_percent"`
+}
+
+// ExpiringStock is a batch with stock left that expires within the requested window.
+// DaysToExpiry is negative for batches already past their expiry date.
+type ExpiringStock struct {
+	BatchID      int      `json:"batch_id"`
+	OutletID     int      `json:"outlet_id"`
+	ProductID    int      `json:"product_id"`
+	ProductName  string   `json:"product_name"`
+	BatchNumber  string   `json:"batch_number"`
+	ExpiryDate   string   `json:"expiry_date"`
+	DaysToExpiry int      `json:"days_to_expiry"`
+	Quantity     Quantity `json:"quantity" swaggertype:"number"`
+	CostValue    int      `json:"cost_value"`
+}
+
+// MarginPercent returns gross profit as a percentage of revenue, rounded to 2 decimals
+func MarginPercent(revenue, grossProfit int) float64 {
+	if revenue == 0 {
+		return 0
+	}
+	return math.Round(float64(grossProfit)*10000/float64(revenue)) / 100
 }
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Stock movement types
+const (
+	MovementSale       = "sale"
+	MovementRefund     = "refund"
+	MovementReceipt    = "receipt"
+	MovementAdjustment = "adjustment"
+	MovementWaste      = "waste"
+	MovementTransfer   = "transfer"
+)
+
+// StockMovement is an append-only ledger entry for a change of a product's stock.
+// Quantity is the signed delta in the product's base unit and BalanceAfter is the
+// running stock balance of the outlet right after the movement. Listed for all outlets at
+// once, BalanceAfter is the running balance over all outlets instead.
+type StockMovement struct {
+	ID            int       `json:"id"`
+	ProductID     int       `json:"product_id"`
+	OutletID      int       `json:"outlet_id"`
+	Type          string    `json:"type"`
+	Quantity      Quantity  `json:"quantity" swaggertype:"number"`
+	BalanceAfter  Quantity  `json:"balance_after" swaggertype:"number"`
+	Reason        string    `json:"reason,omitempty"`
+	Actor         string    `json:"actor,omitempty"`
+	ReferenceType string    `json:"reference_type,omitempty"`
+	ReferenceID   int       `json:"reference_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	// Batches lists the batches a decrease was taken from, first-expired-first-out
+	Batches []BatchAllocation `json:"batches,omitempty"`
+}
+
+// StockAdjustmentRequest is used to correct stock or write off waste.
+// For an adjustment Quantity is the signed delta, for waste it is the positive amount lost.
+type StockAdjustmentRequest struct {
+	Type     string   `json:"type"`
+	Quantity Quantity `json:"quantity" swaggertype:"number"`
+	Reason   string   `json:"reason"`
+	OutletID int      `json:"-"`
+	Actor    string   `json:"-"`
+}
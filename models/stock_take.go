@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// Stock take statuses
+const (
+	StockTakeOpen      = "open"
+	StockTakeApproved  = "approved"
+	StockTakeCancelled = "cancelled"
+)
+
+// StockTake is a physical count session (stock opname) of one outlet over all or some categories
+type StockTake struct {
+	ID                 int             `json:"id"`
+	OutletID           int             `json:"outlet_id"`
+	OutletName         string          `json:"outlet_name,omitempty"`
+	Status             string          `json:"status"`
+	Notes              string          `json:"notes,omitempty"`
+	CreatedBy          string          `json:"created_by,omitempty"`
+	CreatedAt          time.Time       `json:"created_at"`
+	ApprovedBy         string          `json:"approved_by,omitempty"`
+	ApprovedAt         *time.Time      `json:"approved_at,omitempty"`
+	CountedLines       int             `json:"counted_lines"`
+	TotalVarianceValue int             `json:"total_variance_value"`
+	Lines              []StockTakeLine `json:"lines,omitempty"`
+}
+
+// StockTakeLine is the count of one product. ExpectedQuantity is the system stock
+// at the moment the product was counted, so sales made after counting are not
+// mistaken for shrinkage. VarianceValue is the variance valued at cost.
+type StockTakeLine struct {
+	ProductID        int        `json:"product_id"`
+	ProductName      string     `json:"product_name"`
+	Barcode          string     `json:"barcode,omitempty"`
+	ExpectedQuantity *Quantity  `json:"expected_quantity,omitempty" swaggertype:"number"`
+	CountedQuantity  *Quantity  `json:"counted_quantity,omitempty" swaggertype:"number"`
+	Variance         Quantity   `json:"variance" swaggertype:"number"`
+	UnitCost         int        `json:"unit_cost"`
+	VarianceValue    int        `json:"variance_value"`
+	CountedAt        *time.Time `json:"counted_at,omitempty"`
+}
+
+// StockTakeRequest opens a count session. Empty CategoryIDs counts every product.
+type StockTakeRequest struct {
+	CategoryIDs []int  `json:"category_ids,omitempty"`
+	Notes       string `json:"notes"`
+	OutletID    int    `json:"-"`
+	Actor       string `json:"-"`
+}
+
+// StockCountRequest submits counted quantities to an open stock take
+type StockCountRequest struct {
+	Items []StockCountItem `json:"items"`
+	Actor string           `json:"-"`
+}
+
+// StockCountItem identifies a product by ID or barcode. With Add the quantity is
+// added to what was counted before (e.g. one barcode scan), otherwise it replaces it.
+type StockCountItem struct {
+	ProductID int      `json:"product_id,omitempty"`
+	Barcode   string   `json:"barcode,omitempty"`
+	Quantity  Quantity `json:"quantity" swaggertype:"number"`
+	Add       bool     `json:"add,omitempty"`
+}
@@ -0,0 +1,94 @@
+package models
+
+import "time"
+
+// Stock transfer statuses
+const (
+	TransferStatusRequested = "requested"
+	TransferStatusInTransit = "in_transit"
+	TransferStatusReceived  = "received"
+	TransferStatusCancelled = "cancelled"
+)
+
+// StockTransfer moves stock from one outlet (a store or warehouse) to another. Stock leaves the
+// source when the transfer is dispatched and reaches the destination when it is received, in
+// between it is in transit and counted at neither outlet.
+type StockTransfer struct {
+	ID                    int                 `json:"id"`
+	SourceOutletID        int                 `json:"source_outlet_id"`
+	SourceOutletName      string              `json:"source_outlet_name,omitempty"`
+	DestinationOutletID   int                 `json:"destination_outlet_id"`
+	DestinationOutletName string              `json:"destination_outlet_name,omitempty"`
+	Status                string              `json:"status"`
+	Notes                 string              `json:"notes,omitempty"`
+	RequestedBy           string              `json:"requested_by,omitempty"`
+	RequestedAt           time.Time           `json:"requested_at"`
+	DispatchedBy          string              `json:"dispatched_by,omitempty"`
+	DispatchedAt          *time.Time          `json:"dispatched_at,omitempty"`
+	ReceivedBy            string              `json:"received_by,omitempty"`
+	ReceivedAt            *time.Time          `json:"received_at,omitempty"`
+	Lines                 []StockTransferLine `json:"lines,omitempty"`
+}
+
+// StockTransferLine is a product on a transfer, quantities are in the product's base unit.
+// Discrepancy is received minus dispatched quantity, negative when goods went missing in transit.
+type StockTransferLine struct {
+	ID                 int               `json:"id"`
+	ProductID          int               `json:"product_id"`
+	ProductName        string            `json:"product_name,omitempty"`
+	Unit               string            `json:"unit,omitempty"`
+	RequestedQuantity  Quantity          `json:"requested_quantity" swaggertype:"number"`
+	DispatchedQuantity Quantity          `json:"dispatched_quantity" swaggertype:"number"`
+	ReceivedQuantity   Quantity          `json:"received_quantity" swaggertype:"number"`
+	Discrepancy        Quantity          `json:"discrepancy" swaggertype:"number"`
+	DiscrepancyReason  string            `json:"discrepancy_reason,omitempty"`
+	Batches            []BatchAllocation `json:"batches,omitempty"`
+}
+
+// StockTransferRequest requests stock from the source outlet for the destination outlet
+type StockTransferRequest struct {
+	SourceOutletID      int                        `json:"source_outlet_id"`
+	DestinationOutletID int                        `json:"destination_outlet_id"`
+	Notes               string                     `json:"notes"`
+	Lines               []StockTransferLineRequest `json:"lines"`
+	Actor               string                     `json:"-"`
+}
+
+// StockTransferLineRequest is a product quantity in its base unit
+type StockTransferLineRequest struct {
+	ProductID int      `json:"product_id"`
+	Quantity  Quantity `json:"quantity" swaggertype:"number"`
+}
+
+// TransferDispatchRequest ships a requested transfer. Lines left out are dispatched as
+// requested, a line can ship less (or 0) when the source does not have it.
+type TransferDispatchRequest struct {
+	Lines []StockTransferLineRequest `json:"lines"`
+	Actor string                     `json:"-"`
+}
+
+// TransferReceiveRequest receives a transfer at its destination. Lines left out are received
+// as dispatched; a different quantity is a discrepancy and needs a reason.
+type TransferReceiveRequest struct {
+	Lines []TransferReceiveLineRequest `json:"lines"`
+	Actor string                       `json:"-"`
+}
+
+// TransferReceiveLineRequest is the quantity of a product that arrived
+type TransferReceiveLineRequest struct {
+	ProductID int      `json:"product_id"`
+	Quantity  Quantity `json:"quantity" swaggertype:"number"`
+	Reason    string   `json:"reason,omitempty"`
+}
+
+// InTransitStock is stock dispatched to an outlet that has not been received yet
+type InTransitStock struct {
+	ProductID             int      `json:"product_id"`
+	ProductName           string   `json:"product_name"`
+	Unit                  string   `json:"unit"`
+	SourceOutletID        int      `json:"source_outlet_id"`
+	DestinationOutletID   int      `json:"destination_outlet_id"`
+	DestinationOutletName string   `json:"destination_outlet_name"`
+	Quantity              Quantity `json:"quantity" swaggertype:"number"`
+	Transfers             int      `json:"transfers"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Table statuses, a table is occupied while an unpaid dine-in order sits at it
+const (
+	TableFree     = "free"
+	TableOccupied = "occupied"
+)
+
+// Table is a dining table at an outlet, grouped into an Area such as "Indoor" or "Terrace".
+// OrderID is the dine-in order occupying it.
+type Table struct {
+	ID        int       `json:"id"`
+	OutletID  int       `json:"outlet_id"`
+	Name      string    `json:"name"`
+	Area      string    `json:"area,omitempty"`
+	Seats     int       `json:"seats"`
+	Active    bool      `json:"active"`
+	Status    string    `json:"status"`
+	OrderID   int       `json:"order_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableRequest is used for create/update operations. Active defaults to true.
+type TableRequest struct {
+	Name     string `json:"name"`
+	Area     string `json:"area"`
+	Seats    int    `json:"seats"`
+	Active   *bool  `json:"active,omitempty"`
+	OutletID int    `json:"-"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Tenant is a merchant hosted on a shared deployment. Its data is isolated by row-level
+// security and its requests are authenticated with its API key.
+type Tenant struct {
+	ID        int            `json:"id"`
+	Slug      string         `json:"slug"`
+	Name      string         `json:"name"`
+	Active    bool           `json:"active"`
+	Settings  TenantSettings `json:"settings"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	// APIKey is only returned when the tenant is created or its key is rotated
+	APIKey string `json:"api_key,omitempty"`
+}
+
+// TenantSettings overrides the deployment's configuration for one tenant,
+// settings left empty use the configured defaults
+type TenantSettings struct {
+	ExpiredSalePolicy  string `json:"expired_sale_policy,omitempty"`
+	LowStockWebhookURL string `json:"low_stock_webhook_url,omitempty"`
+	// LowStockWebhookSecret signs the posts to the tenant's LowStockWebhookURL, the deployment's
+	// secret is never sent to a tenant's receiver
+	LowStockWebhookSecret string `json:"low_stock_webhook_secret,omitempty"`
+	ReorderWindowDays     int    `json:"reorder_window_days,omitempty"`
+	ReorderLeadTimeDays   int    `json:"reorder_lead_time_days,omitempty"`
+	ReorderSafetyDays     int    `json:"reorder_safety_days,omitempty"`
+	ReorderCoverDays      int    `json:"reorder_cover_days,omitempty"`
+	// Loyalty settings, LoyaltyExcludedCategories replaces the configured list when given
+	LoyaltySpendPerPoint      int    `json:"loyalty_spend_per_point,omitempty"`
+	LoyaltyRounding           string `json:"loyalty_rounding,omitempty"`
+	LoyaltyPointValue         int    `json:"loyalty_point_value,omitempty"`
+	LoyaltyExpiryDays         int    `json:"loyalty_expiry_days,omitempty"`
+	LoyaltyExcludedCategories []int  `json:"loyalty_excluded_categories,omitempty"`
+	GiftCardExpiryDays        int    `json:"gift_card_expiry_days,omitempty"`
+	DraftOrderExpiryMinutes   int    `json:"draft_order_expiry_minutes,omitempty"`
+}
+
+// TenantRequest is used to provision or update a tenant. Active defaults to true.
+type TenantRequest struct {
+	Slug     string         `json:"slug"`
+	Name     string         `json:"name"`
+	Active   *bool          `json:"active,omitempty"`
+	Settings TenantSettings `json:"settings"`
+}
@@ -5,28 +5,112 @@ import "time"
 // Transaction represents a sales transaction
 type Transaction struct {
 	ID          int                 `json:"id"`
+	OutletID    int                 `json:"outlet_id"`
+	CustomerID  int                 `json:"customer_id,omitempty"`
+	PriceListID int                 `json:"price_list_id,omitempty"`
 	TotalAmount int                 `json:"total_amount"`
 	CreatedAt   time.Time           `json:"created_at"`
 	Details     []TransactionDetail `json:"details,omitempty"`
+	// GiftCardsSold is the part of the total spent on the IssuedGiftCards
+	GiftCardsSold   int        `json:"gift_cards_sold,omitempty"`
+	IssuedGiftCards []GiftCard `json:"issued_gift_cards,omitempty"`
+	// PointsRedeemed paid PointsAmount of the total and GiftCardPayments paid GiftCardAmount,
+	// OnAccountAmount was charged to the customer's account and AmountDue is left for other tenders.
+	// PointsEarned are credited to the customer for this sale once it is paid.
+	PointsRedeemed   int                  `json:"points_redeemed,omitempty"`
+	PointsAmount     int                  `json:"points_amount,omitempty"`
+	GiftCardAmount   int                  `json:"gift_card_amount,omitempty"`
+	GiftCardPayments []GiftCardRedemption `json:"gift_card_payments,omitempty"`
+	OnAccountAmount  int                  `json:"on_account_amount,omitempty"`
+	AmountDue        int                  `json:"amount_due"`
+	PointsEarned     int                  `json:"points_earned,omitempty"`
+	// PaymentStatus is paid unless the sale waits for a QRIS payment or, as the bill of a dine-in
+	// order, for the table to pay (pending), or its items were put back when a QRIS payment expired,
+	// was cancelled or failed
+	PaymentStatus string `json:"payment_status,omitempty"`
+	// RefundedAt is set once the transaction is refunded
+	RefundedAt   *time.Time `json:"refunded_at,omitempty"`
+	RefundReason string     `json:"refund_reason,omitempty"`
+	// Warnings lists expired batches that were sold when the expired sale policy is "warn"
+	Warnings []string `json:"warnings,omitempty"`
+	// LowStock lists products whose stock fell to or below their minimum with this sale
+	LowStock []LowStockItem `json:"low_stock,omitempty"`
+	// KitchenItems are the items sent to kitchen stations to prepare
+	KitchenItems []KitchenItem `json:"kitchen_items,omitempty"`
 }
 
-// TransactionDetail represents items in a transaction
+// TransactionDetail represents items in a transaction.
+// Quantity is in the sold Unit, BaseQuantity is the same amount in the product's stock unit.
+// UnitCost (per base unit) and COGS snapshot the product cost at the time of sale.
+// PriceListID is the price list that priced the line, if any.
 type TransactionDetail struct {
-	ID            int    `json:"id"`
-	TransactionID int    `json:"transaction_id"`
-	ProductID     int    `json:"product_id"`
-	ProductName   string `json:"product_name,omitempty"`
-	Quantity      int    `json:"quantity"`
-	Subtotal      int    `json:"subtotal"`
+	ID            int                          `json:"id"`
+	TransactionID int                          `json:"transaction_id"`
+	ProductID     int                          `json:"product_id"`
+	ProductName   string                       `json:"product_name,omitempty"`
+	Quantity      Quantity                     `json:"quantity" swaggertype:"number"`
+	Unit          string                       `json:"unit"`
+	BaseQuantity  Quantity                     `json:"base_quantity" swaggertype:"number"`
+	UnitPrice     int                          `json:"unit_price"`
+	PriceListID   int                          `json:"price_list_id,omitempty"`
+	Subtotal      int                          `json:"subtotal"`
+	UnitCost      int                          `json:"unit_cost"`
+	COGS          int                          `json:"cogs"`
+	Components    []TransactionDetailComponent `json:"components,omitempty"`
+	Batches       []BatchAllocation            `json:"batches,omitempty"`
 }
 
-// CheckoutRequest represents the payload for creating a transaction
+// TransactionDetailComponent represents a bundle component sold through a transaction detail,
+// with the share of the bundle revenue allocated to it
+type TransactionDetailComponent struct {
+	ProductID       int               `json:"product_id"`
+	ProductName     string            `json:"product_name,omitempty"`
+	Quantity        Quantity          `json:"quantity" swaggertype:"number"`
+	AllocatedAmount int               `json:"allocated_amount"`
+	UnitCost        int               `json:"unit_cost"`
+	COGS            int               `json:"cogs"`
+	Batches         []BatchAllocation `json:"batches,omitempty"`
+}
+
+// CheckoutRequest represents the payload for creating a transaction.
+// A customer can be attached by CustomerID or looked up by CustomerPhone,
+// and can pay part of the total with RedeemPoints of their loyalty points.
+// Items are priced from the price list chosen by PriceListID, else by CustomerGroup,
+// else by the customer's group, falling back to the regular price.
+// GiftCards sells gift cards and vouchers, GiftCardPayments pays with them.
+// OnAccount charges what is left to pay to the customer's account, within their credit limit.
+// DraftOrderID checks out a parked cart with its items and outlet, and its customer and price list
+// unless the request gives others. DineInOrderID bills items of a dine-in order, which were sent
+// to the kitchen with their round rather than at checkout. AwaitPayment records the sale as pending
+// a QRIS payment: its stock is reserved, but it is reported, sent to the kitchen, credited its points
+// and announced with its low stock events once paid.
 type CheckoutRequest struct {
-	Items []CheckoutItem `json:"items"`
+	Items            []CheckoutItem         `json:"items"`
+	GiftCards        []GiftCardIssueRequest `json:"gift_cards,omitempty"`
+	GiftCardPayments []GiftCardPayment      `json:"gift_card_payments,omitempty"`
+	CustomerID       int                    `json:"customer_id,omitempty"`
+	CustomerPhone    string                 `json:"customer_phone,omitempty"`
+	RedeemPoints     int                    `json:"redeem_points,omitempty"`
+	PriceListID      int                    `json:"price_list_id,omitempty"`
+	CustomerGroup    string                 `json:"customer_group,omitempty"`
+	OnAccount        bool                   `json:"on_account,omitempty"`
+	DraftOrderID     int                    `json:"-"`
+	DineInOrderID    int                    `json:"-"`
+	AwaitPayment     bool                   `json:"-"`
+	OutletID         int                    `json:"-"`
+	Actor            string                 `json:"-"`
 }
 
-// CheckoutItem represents a product and quantity in checkout
+// CheckoutItem represents a product and quantity in checkout.
+// Unit defaults to the product's sale unit.
 type CheckoutItem struct {
-	ProductID int `json:"product_id"`
-	Quantity  int `json:"quantity"`
+	ProductID int      `json:"product_id"`
+	Quantity  Quantity `json:"quantity" swaggertype:"number"`
+	Unit      string   `json:"unit,omitempty"`
+}
+
+// RefundRequest is used to refund a whole transaction
+type RefundRequest struct {
+	Reason string `json:"reason"`
+	Actor  string `json:"-"`
 }
@@ -0,0 +1,59 @@
+// Package payments connects checkout to payment gateways. A Gateway creates dynamic QRIS codes for
+// an amount and reports their payment through notifications (callbacks). MockGateway builds the
+// QRIS itself and takes notifications posted by hand, real gateways such as Midtrans or Xendit
+// implement the same interface.
+package payments
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Notification statuses
+const (
+	StatusPaid    = "paid"
+	StatusExpired = "expired"
+	StatusFailed  = "failed"
+)
+
+// ErrUnauthorized is returned for notifications that cannot be shown to come from the gateway
+var ErrUnauthorized = errors.New("notification not authenticated")
+
+// ErrNotConfigured is returned for notifications the gateway cannot authenticate because it has no
+// secret to check them with
+var ErrNotConfigured = errors.New("payment callbacks are disabled until PAYMENT_CALLBACK_SECRET is set")
+
+// Gateway is a payment provider taking QRIS payments
+type Gateway interface {
+	// Name identifies the gateway on the payments created through it
+	Name() string
+	// CreateQRIS creates a dynamic QRIS for an amount. Notifications refer to it by its Reference.
+	CreateQRIS(ctx context.Context, req QRISRequest) (*QRIS, error)
+	// CancelQRIS makes a QRIS unpayable. It fails when the QRIS was paid already.
+	CancelQRIS(ctx context.Context, reference string) error
+	// ParseNotification authenticates and reads a notification posted by the gateway
+	ParseNotification(r *http.Request) (*Notification, error)
+}
+
+// QRISRequest asks for a QRIS paying Amount Rupiah for an order until ExpiresAt
+type QRISRequest struct {
+	OrderID   string
+	Amount    int
+	ExpiresAt time.Time
+}
+
+// QRIS is a dynamic QRIS, Payload is the EMVCo string the QR code holds
+type QRIS struct {
+	Reference string
+	Payload   string
+	ExpiresAt time.Time
+}
+
+// Notification reports the outcome of a QRIS payment
+type Notification struct {
+	Reference string
+	Status    string
+	Amount    int
+}
@@ -0,0 +1,49 @@
+package payments
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMockGatewayParseNotification(t *testing.T) {
+	const body = `{"reference": "MOCK-3f9a1c2b7d4e5f60", "status": "paid", "amount": 30000}`
+	g := NewMockGateway(testMerchant, "secret")
+
+	tests := []struct {
+		name      string
+		signature string
+		wantErr   error
+	}{
+		{"signed", SignMockNotification("secret", []byte(body)), nil},
+		{"unsigned", "", ErrUnauthorized},
+		{"other secret", SignMockNotification("other", []byte(body)), ErrUnauthorized},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/payments/callback", strings.NewReader(body))
+			r.Header.Set(HeaderCallbackSignature, tc.signature)
+			n, err := g.ParseNotification(r)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && (n.Reference != "MOCK-3f9a1c2b7d4e5f60" || n.Status != StatusPaid || n.Amount != 30000) {
+				t.Errorf("notification %+v", n)
+			}
+		})
+	}
+}
+
+func TestMockGatewayWithoutSecret(t *testing.T) {
+	const body = `{"reference": "MOCK-3f9a1c2b7d4e5f60", "status": "paid", "amount": 30000}`
+	g := NewMockGateway(testMerchant, "")
+
+	for _, signature := range []string{"", SignMockNotification("", []byte(body))} {
+		r := httptest.NewRequest("POST", "/payments/callback", strings.NewReader(body))
+		r.Header.Set(HeaderCallbackSignature, signature)
+		if _, err := g.ParseNotification(r); !errors.Is(err, ErrNotConfigured) {
+			t.Errorf("signature %q: err = %v, want ErrNotConfigured", signature, err)
+		}
+	}
+}
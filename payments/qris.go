@@ -0,0 +1,125 @@
+package payments
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxTagLength is the longest value a QRIS tag can hold, its length being written in two digits
+const maxTagLength = 99
+
+// Merchant identifies the merchant in a QRIS payload
+type Merchant struct {
+	// ID is the National Merchant ID (NMID) assigned on QRIS registration, ID followed by 13 digits
+	ID   string
+	Name string
+	City string
+	// CategoryCode is the ISO 18245 merchant category code, 5411 for grocery stores
+	CategoryCode string
+}
+
+// Validate checks the merchant can be written in a QRIS payload
+func (m Merchant) Validate() error {
+	if len(m.ID) != 15 || !strings.HasPrefix(m.ID, "ID") || !digits(m.ID[2:]) {
+		return fmt.Errorf("invalid QRIS merchant ID %q, want ID followed by 13 digits", m.ID)
+	}
+	if len(m.CategoryCode) != 4 || !digits(m.CategoryCode) {
+		return fmt.Errorf("invalid QRIS merchant category %q, want 4 digits", m.CategoryCode)
+	}
+	if strings.TrimSpace(m.Name) == "" {
+		return fmt.Errorf("QRIS merchant name is required")
+	}
+	if strings.TrimSpace(m.City) == "" {
+		return fmt.Errorf("QRIS merchant city is required")
+	}
+	return nil
+}
+
+// QRISPayload builds the EMVCo merchant-presented payload of a dynamic QRIS paying amount Rupiah,
+// with reference as its bill number. Names and cities are cut to the lengths QRIS allows.
+func QRISPayload(m Merchant, amount int, reference string) (string, error) {
+	if err := m.Validate(); err != nil {
+		return "", err
+	}
+	if amount <= 0 {
+		return "", fmt.Errorf("invalid QRIS amount %d", amount)
+	}
+
+	var account, bill, b tlv
+	account.add("00", "ID.CO.QRIS.WWW")
+	account.add("02", m.ID)
+	account.add("03", "UMI")
+	bill.add("01", truncate(reference, 25))
+
+	b.add("00", "01")
+	b.add("01", "12") // dynamic, for one payment
+	b.add("51", account.String())
+	b.add("52", m.CategoryCode)
+	b.add("53", "360") // Rupiah
+	b.add("54", strconv.Itoa(amount))
+	b.add("58", "ID")
+	b.add("59", truncate(m.Name, 25))
+	b.add("60", truncate(m.City, 15))
+	b.add("62", bill.String())
+	if err := errors.Join(account.err, bill.err, b.err); err != nil {
+		return "", err
+	}
+	b.WriteString("6304")
+	return b.String() + fmt.Sprintf("%04X", crc16(b.String())), nil
+}
+
+// tlv writes QRIS tags, each an ID, a two digit length and a value. A value too long for its
+// length is not written and kept as the error of the builder.
+type tlv struct {
+	strings.Builder
+	err error
+}
+
+func (b *tlv) add(id, value string) {
+	if len(value) > maxTagLength {
+		if b.err == nil {
+			b.err = fmt.Errorf("QRIS tag %s is %d bytes long, at most %d fit", id, len(value), maxTagLength)
+		}
+		return
+	}
+	fmt.Fprintf(b, "%s%02d%s", id, len(value), value)
+}
+
+// truncate cuts s to at most n bytes, dropping a character that would be split rather than part of it
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+func digits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// crc16 is the CRC-16/CCITT-FALSE checksum QRIS ends with
+func crc16(s string) uint16 {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,95 @@
+package payments
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+var testMerchant = Merchant{ID: "ID1020304050607", Name: "Kasir", City: "Jakarta", CategoryCode: "5411"}
+
+// TestCRC16 checks the published check value of CRC-16/CCITT-FALSE
+func TestCRC16(t *testing.T) {
+	if got := crc16("123456789"); got != 0x29B1 {
+		t.Errorf("crc16 = %04X, want 29B1", got)
+	}
+}
+
+func TestQRISPayload(t *testing.T) {
+	got, err := QRISPayload(testMerchant, 30000, "MOCK-3f9a1c2b7d4e5f60")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "000201" + // payload format
+		"010212" + // dynamic
+		"5144" + "0014ID.CO.QRIS.WWW" + "0215ID1020304050607" + "0303UMI" +
+		"52045411" + // merchant category
+		"5303360" + // Rupiah
+		"540530000" +
+		"5802ID" +
+		"5905Kasir" +
+		"6007Jakarta" +
+		"6225" + "0121MOCK-3f9a1c2b7d4e5f60" +
+		"6304ABF1"
+	if got != want {
+		t.Errorf("payload\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestQRISPayloadTruncatesByCharacter(t *testing.T) {
+	m := testMerchant
+	m.Name = strings.Repeat("a", 24) + "é"
+	m.City = strings.Repeat("b", 14) + "ü"
+	payload, err := QRISPayload(m, 1000, "REF")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !utf8.ValidString(payload) {
+		t.Fatalf("payload %q is not valid UTF-8", payload)
+	}
+	if !strings.Contains(payload, "5924"+strings.Repeat("a", 24)+"60") {
+		t.Errorf("name not cut before the split character: %s", payload)
+	}
+	if !strings.Contains(payload, "6014"+strings.Repeat("b", 14)+"62") {
+		t.Errorf("city not cut before the split character: %s", payload)
+	}
+}
+
+func TestQRISPayloadRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(m *Merchant)
+		amount int
+	}{
+		{"empty merchant ID", func(m *Merchant) { m.ID = "" }, 1000},
+		{"merchant ID without prefix", func(m *Merchant) { m.ID = "XX1020304050607" }, 1000},
+		{"merchant ID with letters", func(m *Merchant) { m.ID = "ID10203040506AB" }, 1000},
+		{"long merchant ID", func(m *Merchant) { m.ID = "ID" + strings.Repeat("1", 100) }, 1000},
+		{"short category", func(m *Merchant) { m.CategoryCode = "541" }, 1000},
+		{"category with letters", func(m *Merchant) { m.CategoryCode = "54A1" }, 1000},
+		{"empty name", func(m *Merchant) { m.Name = " " }, 1000},
+		{"empty city", func(m *Merchant) { m.City = "" }, 1000},
+		{"zero amount", func(m *Merchant) {}, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m := testMerchant
+			tc.modify(&m)
+			if payload, err := QRISPayload(m, tc.amount, "REF"); err == nil {
+				t.Errorf("want an error, got payload %s", payload)
+			}
+		})
+	}
+}
+
+func TestTLVRejectsLongValues(t *testing.T) {
+	var b tlv
+	b.add("00", strings.Repeat("x", 99))
+	if b.err != nil {
+		t.Fatalf("99 bytes: %v", b.err)
+	}
+	b.add("01", strings.Repeat("x", 100))
+	if b.err == nil {
+		t.Error("100 bytes: want an error")
+	}
+}
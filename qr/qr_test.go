@@ -0,0 +1,214 @@
+package qr
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// The expected symbols were drawn by an independent encoder (Kazuhiko Arase's QRCode for JavaScript)
+// at the same version, level M, which chose the same mask.
+var knownCodes = []struct {
+	name    string
+	text    string
+	version int
+	mask    int
+	rows    []string
+}{
+	{
+		name:    "short text",
+		text:    "kasir",
+		version: 1,
+		mask:    2,
+		rows: []string{
+			"#######..##...#######",
+			"#.....#.....#.#.....#",
+			"#.###.#.#####.#.###.#",
+			"#.###.#.#.#...#.###.#",
+			"#.###.#.#...#.#.###.#",
+			"#.....#.#.##..#.....#",
+			"#######.#.#.#.#######",
+			"........###..........",
+			"#.#####...##..#####..",
+			"#.####..#.#####...#.#",
+			"..#.####..#.#.##.###.",
+			"#.#.#..#.#.####..####",
+			"###.#.#..#..#..#.#..#",
+			"........#...#..#..#.#",
+			"#######..###.#..##.#.",
+			"#.....#.#.#....#####.",
+			"#.###.#.#..#.#..#..#.",
+			"#.###.#.##.#####.....",
+			"#.###.#.#.#.#.##.##..",
+			"#.....#..##########..",
+			"#######.#...#..#.#.#.",
+		},
+	},
+	{
+		name:    "QRIS payload",
+		text:    "00020101021251440014ID.CO.QRIS.WWW0215ID10203040506070303UMI5204541153033605405300005802ID5905Kasir6007Jakarta62250121MOCK-3f9a1c2b7d4e5f606304ABF1",
+		version: 8,
+		mask:    3,
+		rows: []string{
+			"#######.#.#######......#...#.###.....#..#.#######",
+			"#.....#.#..#.#.#.#.###...#...####.##..###.#.....#",
+			"#.###.#..##.#...####..####..##.#######.##.#.###.#",
+			"#.###.#.#..#...####...#.#.#...##.#..#..#..#.###.#",
+			"#.###.#..####.....##..########.#.....#....#.###.#",
+			"#.....#...##.##.#####.#...###.##.#.#..#...#.....#",
+			"#######.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#######",
+			"........##.#..#.####.##...#####.#...#.#..........",
+			"#.##.###.##.#.#.....#######.##....#..#....#..#.##",
+			".#.....#.#.######.#..#...#....##.#.#.....####...#",
+			"####..#..#..#..###....#...##..##.##..#.###.#.....",
+			"####.#.##.#.#.#...##.####..##.##...####.#...#.##.",
+			".#...#####..##..##.#..###....###....#...###...#..",
+			"##...#..####..#.###.#.....##...##..#..#..#..#...#",
+			"#..#.##.#..##.#..##..##...#.######.#..######.##..",
+			".##.##..##..##.#.#...#.#..#.######.#.#....####..#",
+			".##...###.##.#.#..##...#.....##.#.##...###..####.",
+			"#.##.#.#.######..#.#.###...#......####..#..##.###",
+			".###.##.#.....###.#.#.###.##.#.#..#.####.#...#..#",
+			"#.##...###.#...##.#.##...####.##....#.##.#.#...#.",
+			"##.#..##...#####.##.####.#..#.#...#....#...#.#...",
+			"#......##...#.####...#...#.#.###.#..#..#..#....#.",
+			".#..######.####.###...#####..#..###..#..#####..#.",
+			"....#...####.#...#....#...##.#.#..###...#...#.##.",
+			".####.#.#.#.###....####.#.##.###..#.##.##.#.#.###",
+			"#.#.#...##.#.#.#..#.#.#...#..#.##..#..#.#...#..#.",
+			"#...#####.#.#...##.############..#....#######.##.",
+			".....#.##.#..###.##..###......#..#.#.....#...#.##",
+			"#...###.....####....#.##...#.####..#..#.##..###..",
+			"#.##...###.#.##..#..#.##.......#..##.#...##...###",
+			"..#.######....#.###.#.#..#..##.#..#.###.####.##.#",
+			"..#.##....####.#.#.#.#.#.####....#.##..#.#.#....#",
+			"..#..##.#.##.#.#.##.##..##..#.#..#.#....#...##...",
+			".#...#.#....##.#.#.....###.##.#....###.#..#..#.#.",
+			"...##.###..##.####....#.#.##..###.##....##.#...#.",
+			"#.#.##....#..#.#..#...#.#.##...##.#..#.#####..##.",
+			"##.##########..###.##.##...#.#.#.#.##.##..###.###",
+			".##.#...#.##........#.##.##....#......#...#.#...#",
+			".#...###.#.#####..#.####....#..###..#.#....#.##..",
+			".###...#.####..###...##.#.#.#.##.#.#.....#...#..#",
+			"###...##..###.#.#.##..#####..####..#..#.########.",
+			"........##...##.####.##...#.#...#.#.##.##...#####",
+			"#######.##.###..###...#.#.##.#....#######.#.#...#",
+			"#.....#.#..#.....#..#.#...#.#..#.#..#.#.#...#....",
+			"#.###.#...####...#....#####.#.##.##..##.######...",
+			"#.###.#.#...#..#......#.....#.##...###.##.####.##",
+			"#.###.#.##.#..###.###..#.####.#.#.....#..#..##.##",
+			"#.....#..#..###.#.##.####..#....######.#...####..",
+			"#######.##.#....#..###..#..#..#..#..##.###...#.##",
+		},
+	},
+}
+
+// formatBitsM are the format information strings of level M by mask, as tabled in ISO/IEC 18004
+var formatBitsM = [8]string{
+	"101010000010010",
+	"101000100100101",
+	"101111001111100",
+	"101101101001011",
+	"100010111111001",
+	"100000011001110",
+	"100111110010111",
+	"100101010100000",
+}
+
+func TestEncodeKnownCodes(t *testing.T) {
+	for _, tc := range knownCodes {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := Encode(tc.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.version != tc.version || c.Size != tc.version*4+17 {
+				t.Fatalf("version %d size %d, want version %d size %d", c.version, c.Size, tc.version, tc.version*4+17)
+			}
+			if got := readFormatBits(c); got != formatBitsM[tc.mask] {
+				t.Errorf("format bits %s, want %s (level M, mask %d)", got, formatBitsM[tc.mask], tc.mask)
+			}
+			for y, row := range tc.rows {
+				if got := drawRow(c, y); got != row {
+					t.Errorf("row %d\n got %s\nwant %s", y, got, row)
+				}
+			}
+		})
+	}
+}
+
+// readFormatBits reads the format information around the top left finder pattern, most
+// significant bit first
+func readFormatBits(c *Code) string {
+	var bits [15]bool
+	for i := 0; i <= 5; i++ {
+		bits[i] = c.Dark(8, i)
+	}
+	bits[6] = c.Dark(8, 7)
+	bits[7] = c.Dark(8, 8)
+	bits[8] = c.Dark(7, 8)
+	for i := 9; i < 15; i++ {
+		bits[i] = c.Dark(14-i, 8)
+	}
+
+	var b strings.Builder
+	for i := 14; i >= 0; i-- {
+		if bits[i] {
+			b.WriteByte('1')
+		} else {
+			b.WriteByte('0')
+		}
+	}
+	return b.String()
+}
+
+func drawRow(c *Code, y int) string {
+	var b strings.Builder
+	for x := 0; x < c.Size; x++ {
+		if c.Dark(x, y) {
+			b.WriteByte('#')
+		} else {
+			b.WriteByte('.')
+		}
+	}
+	return b.String()
+}
+
+// TestAddErrorCorrection checks the 1-M example of the thonky.com QR Code tutorial, HELLO WORLD
+func TestAddErrorCorrection(t *testing.T) {
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := append(append([]byte{}, data...), 196, 35, 39, 119, 235, 215, 231, 226, 93, 23)
+	if got := addErrorCorrection(1, data); !bytes.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestEncodeVersion checks the byte mode capacities of level M from ISO/IEC 18004
+func TestEncodeVersion(t *testing.T) {
+	tests := []struct {
+		length  int
+		version int
+	}{
+		{14, 1},
+		{15, 2},
+		{26, 2},
+		{27, 3},
+		{122, 7},
+		{152, 8},
+		{213, 10},
+		{666, 20},
+	}
+	for _, tc := range tests {
+		c, err := Encode(strings.Repeat("a", tc.length))
+		if err != nil {
+			t.Fatalf("%d bytes: %v", tc.length, err)
+		}
+		if c.version != tc.version {
+			t.Errorf("%d bytes: version %d, want %d", tc.length, c.version, tc.version)
+		}
+	}
+
+	if _, err := Encode(strings.Repeat("a", 667)); err == nil {
+		t.Error("667 bytes: want an error")
+	}
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import "sort"
+
+// allocateAmount splits total across weights proportionally. The rounding remainder
+// goes to the parts with the largest fractional share so the parts always sum to total.
+func allocateAmount(total int, weights []int) []int {
+	parts := make([]int, len(weights))
+	if len(weights) == 0 {
+		return parts
+	}
+
+	sum := 0
+	for _, w := range weights {
+		sum += w
+	}
+	if sum == 0 {
+		// Nothing to weigh by, split evenly
+		weights = make([]int, len(parts))
+		for i := range weights {
+			weights[i] = 1
+		}
+		sum = len(weights)
+	}
+
+	remainders := make([]int, len(parts))
+	allocated := 0
+	for i, w := range weights {
+		parts[i] = total * w / sum
+		remainders[i] = total * w % sum
+		allocated += parts[i]
+	}
+
+	order := make([]int, len(parts))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]] > remainders[order[b]]
+	})
+	for i := 0; allocated < total; i++ {
+		parts[order[i%len(order)]]++
+		allocated++
+	}
+	return parts
+}
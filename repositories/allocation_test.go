@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAllocateAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int
+		weights []int
+		want    []int
+	}{
+		{"single component", 99, []int{5}, []int{99}},
+		{"single zero-price component", 99, []int{0}, []int{99}},
+		{"proportional", 100, []int{30, 70}, []int{30, 70}},
+		{"remainder to largest fraction", 10, []int{2, 1}, []int{7, 3}},
+		{"equal remainders go to the first", 100, []int{1, 1, 1}, []int{34, 33, 33}},
+		{"zero-price component gets nothing", 100, []int{0, 100}, []int{0, 100}},
+		{"zero-price among priced", 10, []int{3, 0, 3}, []int{5, 0, 5}},
+		{"all zero-price split evenly", 10, []int{0, 0, 0}, []int{4, 3, 3}},
+		{"zero total", 0, []int{3, 7}, []int{0, 0}},
+		{"no components", 50, nil, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := allocateAmount(tt.total, tt.weights)
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("allocateAmount(%d, %v) = %v, want %v", tt.total, tt.weights, got, tt.want)
+			}
+			sum := 0
+			for _, part := range got {
+				sum += part
+			}
+			if len(tt.weights) > 0 && sum != tt.total {
+				t.Fatalf("parts sum to %d, want %d", sum, tt.total)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"kasir-api/models"
+)
+
+// receiveBatch adds received stock to a batch of the product at the movement's outlet, creating
+// the batch on first receipt. The receipt is linked to the stock movement that brought the stock in.
+func receiveBatch(ctx context.Context, q queryer, m *models.StockMovement, batchNumber, expiryDate string) (int, error) {
+	var batchID int
+	var storedExpiry string
+	err := q.QueryRowContext(ctx,
+		`INSERT INTO product_batches (product_id, outlet_id, batch_number, expiry_date, quantity, received_quantity)
+		 VALUES ($1, $2, $3, NULLIF($4, '')::date, $5, $5)
+		 ON CONFLICT (product_id, outlet_id, batch_number) DO UPDATE
+		 SET quantity = product_batches.quantity + EXCLUDED.quantity,
+		     received_quantity = product_batches.received_quantity + EXCLUDED.received_quantity
+		 RETURNING id, COALESCE(TO_CHAR(expiry_date, 'YYYY-MM-DD'), '')`,
+		m.ProductID, m.OutletID, batchNumber, expiryDate, m.Quantity,
+	).Scan(&batchID, &storedExpiry)
+	if err != nil {
+		return 0, err
+	}
+	if expiryDate != "" && storedExpiry != expiryDate {
+		return 0, fmt.Errorf("batch %s already exists with expiry date %s", batchNumber, storedExpiry)
+	}
+
+	_, err = q.ExecContext(ctx,
+		"INSERT INTO batch_movements (batch_id, stock_movement_id, quantity) VALUES ($1, $2, $3)",
+		batchID, m.ID, m.Quantity,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return batchID, nil
+}
+
+// drainBatches takes the stock decrease of movement m out of the product's batches at the
+// movement's outlet, first-expired-first-out. Sales take expired batches last, after stock that is not
+// tracked in any batch, so expired goods are only assumed sold when nothing else is left.
+func drainBatches(ctx context.Context, q queryer, m models.StockMovement) ([]models.BatchAllocation, error) {
+	rows, err := q.QueryContext(ctx,
+		`SELECT id, batch_number, COALESCE(TO_CHAR(expiry_date, 'YYYY-MM-DD'), ''), quantity,
+		        COALESCE(expiry_date < CURRENT_DATE, false)
+		 FROM product_batches
+		 WHERE product_id = $1 AND outlet_id = $2 AND quantity > 0
+		 ORDER BY expiry_date NULLS LAST, id
+		 FOR UPDATE`, m.ProductID, m.OutletID)
+	if err != nil {
+		return nil, err
+	}
+
+	var batches []models.BatchAllocation
+	var tracked models.Quantity
+	for rows.Next() {
+		var b models.BatchAllocation
+		if err := rows.Scan(&b.BatchID, &b.BatchNumber, &b.ExpiryDate, &b.Quantity, &b.Expired); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		batches = append(batches, b)
+		tracked += b.Quantity
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(batches) == 0 {
+		return nil, nil
+	}
+
+	// Stock outside any batch, before this movement
+	untracked := max(m.BalanceAfter-m.Quantity-tracked, 0)
+
+	// Indexes into batches in the order they are drained, -1 is the untracked stock
+	var order []int
+	if m.Type == models.MovementSale {
+		for i, b := range batches {
+			if !b.Expired {
+				order = append(order, i)
+			}
+		}
+		order = append(order, -1)
+		for i, b := range batches {
+			if b.Expired {
+				order = append(order, i)
+			}
+		}
+	} else {
+		for i := range batches {
+			order = append(order, i)
+		}
+		order = append(order, -1)
+	}
+
+	remaining := -m.Quantity
+	var taken []models.BatchAllocation
+	for _, i := range order {
+		if remaining <= 0 {
+			break
+		}
+		if i < 0 {
+			remaining -= min(remaining, untracked)
+			continue
+		}
+
+		b := batches[i]
+		b.Quantity = min(remaining, b.Quantity)
+		_, err := q.ExecContext(ctx, "UPDATE product_batches SET quantity = quantity - $1 WHERE id = $2", b.Quantity, b.BatchID)
+		if err != nil {
+			return nil, err
+		}
+		_, err = q.ExecContext(ctx,
+			"INSERT INTO batch_movements (batch_id, stock_movement_id, quantity) VALUES ($1, $2, $3)",
+			b.BatchID, m.ID, -b.Quantity,
+		)
+		if err != nil {
+			return nil, err
+		}
+		taken = append(taken, b)
+		remaining -= b.Quantity
+	}
+	return taken, nil
+}
+
+// expiredStock returns how much of the product's stock at an outlet sits in batches past their expiry date
+func expiredStock(ctx context.Context, q queryer, outletID, productID int) (models.Quantity, error) {
+	var expired models.Quantity
+	err := q.QueryRowContext(ctx,
+		`SELECT COALESCE(SUM(quantity), 0) FROM product_batches
+		 WHERE product_id = $1 AND outlet_id = $2 AND quantity > 0 AND expiry_date < CURRENT_DATE`,
+		productID, outletID,
+	).Scan(&expired)
+	return expired, err
+}
+
+// expiredWarnings describes the expired batches in a sale
+func expiredWarnings(productName string, batches []models.BatchAllocation) []string {
+	var warnings []string
+	for _, b := range batches {
+		if b.Expired {
+			warnings = append(warnings, fmt.Sprintf("Sold %s of %s from batch %s which expired on %s",
+				b.Quantity, productName, b.BatchNumber, b.ExpiryDate))
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,228 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"strings"
+)
+
+const customerColumns = "id, name, COALESCE(phone, ''), COALESCE(email, ''), COALESCE(notes, ''), COALESCE(customer_group, ''), credit_limit, created_at"
+
+type CustomerRepository struct {
+	db *sql.DB
+}
+
+func NewCustomerRepository(db *sql.DB) *CustomerRepository {
+	return &CustomerRepository{db: db}
+}
+
+// GetAll lists customers by name, optionally only those whose name, phone or email contains search
+func (r *CustomerRepository) GetAll(search string) ([]models.Customer, error) {
+	rows, err := r.db.Query(
+		`SELECT `+customerColumns+` FROM customers
+		 WHERE $1 = '' OR name ILIKE '%' || $1 || '%' OR email ILIKE '%' || $1 || '%'
+		    OR ($2 <> '' AND phone LIKE '%' || $2 || '%')
+		 ORDER BY name, id`,
+		search, normalizePhone(search))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var customers []models.Customer
+	for rows.Next() {
+		var c models.Customer
+		if err := rows.Scan(&c.ID, &c.Name, &c.Phone, &c.Email, &c.Notes, &c.Group, &c.CreditLimit, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		customers = append(customers, c)
+	}
+	return customers, nil
+}
+
+func (r *CustomerRepository) GetByID(id int) (*models.Customer, error) {
+	var c models.Customer
+	err := r.db.QueryRow("SELECT "+customerColumns+" FROM customers WHERE id = $1", id).
+		Scan(&c.ID, &c.Name, &c.Phone, &c.Email, &c.Notes, &c.Group, &c.CreditLimit, &c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// GetByPhone finds a customer by phone number in any common notation
+func (r *CustomerRepository) GetByPhone(phone string) (*models.Customer, error) {
+	var c models.Customer
+	err := r.db.QueryRow("SELECT "+customerColumns+" FROM customers WHERE phone = $1", normalizePhone(phone)).
+		Scan(&c.ID, &c.Name, &c.Phone, &c.Email, &c.Notes, &c.Group, &c.CreditLimit, &c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *CustomerRepository) Create(req models.CustomerRequest) (*models.Customer, error) {
+	var c models.Customer
+	err := r.db.QueryRow(
+		`INSERT INTO customers (name, phone, email, notes, customer_group, credit_limit)
+		 VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), $6)
+		 RETURNING `+customerColumns,
+		req.Name, normalizePhone(req.Phone), req.Email, req.Notes, req.Group, req.CreditLimit,
+	).Scan(&c.ID, &c.Name, &c.Phone, &c.Email, &c.Notes, &c.Group, &c.CreditLimit, &c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *CustomerRepository) Update(id int, req models.CustomerRequest) (*models.Customer, error) {
+	var c models.Customer
+	err := r.db.QueryRow(
+		`UPDATE customers SET name = $1, phone = NULLIF($2, ''), email = NULLIF($3, ''), notes = NULLIF($4, ''),
+		 customer_group = NULLIF($5, ''), credit_limit = $6
+		 WHERE id = $7 RETURNING `+customerColumns,
+		req.Name, normalizePhone(req.Phone), req.Email, req.Notes, req.Group, req.CreditLimit, id,
+	).Scan(&c.ID, &c.Name, &c.Phone, &c.Email, &c.Notes, &c.Group, &c.CreditLimit, &c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Delete removes a customer, their transactions stay as anonymous sales.
+// A customer who still owes on account cannot be deleted.
+func (r *CustomerRepository) Delete(id int) error {
+	outstanding, err := accountOutstanding(context.Background(), r.db, id)
+	if err != nil {
+		return err
+	}
+	if outstanding > 0 {
+		return fmt.Errorf("customer with ID %d still owes %d on account", id, outstanding)
+	}
+
+	result, err := r.db.Exec("DELETE FROM customers WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetProfile returns a customer with lifetime spend, visits and the full purchase history.
+// Refunded and unpaid transactions are listed but do not count as spend or visits, and gift cards
+// sold are not spend, as in the sales reports: the spend comes when the card is used.
+func (r *CustomerRepository) GetProfile(id int) (*models.CustomerProfile, error) {
+	customer, err := r.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	profile := models.CustomerProfile{Customer: *customer, Purchases: []models.Transaction{}}
+
+	err = r.db.QueryRow(
+		`SELECT COALESCE(SUM(total_amount - gift_cards_sold), 0), COUNT(*), MIN(created_at), MAX(created_at)
+		 FROM transactions WHERE customer_id = $1 AND refunded_at IS NULL AND payment_status = 'paid'`, id,
+	).Scan(&profile.LifetimeSpend, &profile.VisitCount, &profile.FirstVisit, &profile.LastVisit)
+	if err != nil {
+		return nil, err
+	}
+	if profile.VisitCount > 0 {
+		profile.AverageSpend = profile.LifetimeSpend / profile.VisitCount
+	}
+
+	rows, err := r.db.Query(
+		`SELECT id, outlet_id, COALESCE(price_list_id, 0), total_amount, gift_cards_sold, points_redeemed, points_amount,
+		        gift_card_amount, on_account_amount, points_earned, created_at, payment_status,
+		        refunded_at, COALESCE(refund_reason, '')
+		 FROM transactions
+		 WHERE customer_id = $1 ORDER BY created_at DESC, id DESC`, id)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[int]int)
+	for rows.Next() {
+		t := models.Transaction{CustomerID: id}
+		var refundedAt sql.NullTime
+		if err := rows.Scan(&t.ID, &t.OutletID, &t.PriceListID, &t.TotalAmount, &t.GiftCardsSold, &t.PointsRedeemed, &t.PointsAmount,
+			&t.GiftCardAmount, &t.OnAccountAmount, &t.PointsEarned, &t.CreatedAt, &t.PaymentStatus, &refundedAt, &t.RefundReason); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		t.AmountDue = t.TotalAmount - t.PointsAmount - t.GiftCardAmount - t.OnAccountAmount
+		if refundedAt.Valid {
+			t.RefundedAt = &refundedAt.Time
+		}
+		index[t.ID] = len(profile.Purchases)
+		profile.Purchases = append(profile.Purchases, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = r.db.Query(
+		`SELECT td.id, td.transaction_id, td.product_id, p.name, td.quantity, td.unit, td.base_quantity,
+		        td.unit_price, COALESCE(td.price_list_id, 0), td.subtotal, td.unit_cost, td.cogs
+		 FROM transaction_details td
+		 JOIN transactions t ON t.id = td.transaction_id
+		 JOIN products p ON p.id = td.product_id
+		 WHERE t.customer_id = $1
+		 ORDER BY td.id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d models.TransactionDetail
+		if err := rows.Scan(&d.ID, &d.TransactionID, &d.ProductID, &d.ProductName, &d.Quantity, &d.Unit,
+			&d.BaseQuantity, &d.UnitPrice, &d.PriceListID, &d.Subtotal, &d.UnitCost, &d.COGS); err != nil {
+			return nil, err
+		}
+		if i, ok := index[d.TransactionID]; ok {
+			profile.Purchases[i].Details = append(profile.Purchases[i].Details, d)
+		}
+	}
+	return &profile, rows.Err()
+}
+
+// resolveCustomer returns the customer of a checkout, found by ID or else by phone, or 0 without either
+func resolveCustomer(ctx context.Context, q queryer, customerID int, phone string) (int, error) {
+	if customerID == 0 && phone == "" {
+		return 0, nil
+	}
+
+	var id int
+	var err error
+	if customerID != 0 {
+		err = q.QueryRowContext(ctx, "SELECT id FROM customers WHERE id = $1", customerID).Scan(&id)
+	} else {
+		err = q.QueryRowContext(ctx, "SELECT id FROM customers WHERE phone = $1", normalizePhone(phone)).Scan(&id)
+	}
+	if err == sql.ErrNoRows {
+		if customerID != 0 {
+			return 0, fmt.Errorf("customer with ID %d not found", customerID)
+		}
+		return 0, fmt.Errorf("no customer with phone %s", phone)
+	}
+	return id, err
+}
+
+// normalizePhone keeps only the digits of a phone number and writes the Indonesian
+// country code as a leading 0, so "+62 812-3456" and "08123456" match
+func normalizePhone(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	digits := b.String()
+	if strings.HasPrefix(digits, "62") {
+		digits = "0" + digits[2:]
+	}
+	return digits
+}
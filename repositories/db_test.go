@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// testDB connects to the database of TEST_DB_CONN in a schema of its own, created from the schema in
+// the README as tenant 1 and dropped when the test ends. Tests needing a database are skipped without
+// TEST_DB_CONN.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+	conn := os.Getenv("TEST_DB_CONN")
+	if conn == "" {
+		t.Skip("TEST_DB_CONN is not set")
+	}
+	script := readmeSchema(t)
+
+	config, err := pgx.ParseConfig(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin := stdlib.OpenDB(*config)
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		admin.Close()
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Errorf("drop schema %s: %v", schema, err)
+		}
+		admin.Close()
+	})
+
+	config.RuntimeParams["search_path"] = schema
+	config.RuntimeParams["app.tenant_id"] = "1"
+	db := stdlib.OpenDB(*config)
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec(script); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	return db
+}
+
+// readmeSchema returns the SQL script of the README's Database Schema section
+func readmeSchema(t *testing.T) string {
+	t.Helper()
+	readme, err := os.ReadFile("../README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, section, ok := strings.Cut(string(readme), "## 🗄️ Database Schema")
+	if ok {
+		_, section, ok = strings.Cut(section, "```sql\n")
+	}
+	if ok {
+		section, _, ok = strings.Cut(section, "\n```")
+	}
+	if !ok {
+		t.Fatal("README has no database schema")
+	}
+	return section
+}
+
+// queryInt runs a query returning a single number
+func queryInt(t *testing.T, db *sql.DB, query string, args ...interface{}) int {
+	t.Helper()
+	var n float64
+	if err := db.QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return int(n)
+}
@@ -0,0 +1,822 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"slices"
+	"strings"
+)
+
+const dineInOrderColumns = `id, outlet_id, status, guests, COALESCE(notes, ''), COALESCE(merged_into, 0),
+	COALESCE(opened_by, ''), created_at, updated_at, billed_at, paid_at`
+
+type DineInOrderRepository struct {
+	db *sql.DB
+	// transactions checks out the bills of an order
+	transactions *TransactionRepository
+}
+
+func NewDineInOrderRepository(db *sql.DB, transactions *TransactionRepository) *DineInOrderRepository {
+	return &DineInOrderRepository{db: db, transactions: transactions}
+}
+
+// GetAll lists dine-in orders, newest first, of one status or else all still occupying their tables,
+// optionally of one outlet
+func (r *DineInOrderRepository) GetAll(status string, outletID int) ([]models.DineInOrder, error) {
+	statuses := activeDineInStatuses
+	if status != "" {
+		statuses = []string{status}
+	}
+	rows, err := r.db.Query(
+		`SELECT `+dineInOrderColumns+` FROM dine_in_orders
+		 WHERE status = ANY($1::text[]) AND ($2 = 0 OR outlet_id = $2)
+		 ORDER BY id DESC`, statuses, outletID)
+	if err != nil {
+		return nil, err
+	}
+	orders := []models.DineInOrder{}
+	index := make(map[int]int)
+	for rows.Next() {
+		order, err := scanDineInOrder(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		index[order.ID] = len(orders)
+		orders = append(orders, *order)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = r.db.Query(
+		`SELECT ot.order_id, t.id, t.name FROM dine_in_order_tables ot
+		 JOIN dining_tables t ON t.id = ot.table_id
+		 JOIN dine_in_orders d ON d.id = ot.order_id
+		 WHERE d.status = ANY($1::text[]) AND ($2 = 0 OR d.outlet_id = $2)
+		 ORDER BY t.name`, statuses, outletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var orderID int
+		var table models.DineInTable
+		if err := rows.Scan(&orderID, &table.TableID, &table.Name); err != nil {
+			return nil, err
+		}
+		if i, ok := index[orderID]; ok {
+			orders[i].Tables = append(orders[i].Tables, table)
+		}
+	}
+	return orders, rows.Err()
+}
+
+// GetByID returns an order with its tables, items, bills and payments
+func (r *DineInOrderRepository) GetByID(id int) (*models.DineInOrder, error) {
+	ctx := context.Background()
+	order, err := scanDineInOrder(r.db.QueryRow("SELECT "+dineInOrderColumns+" FROM dine_in_orders WHERE id = $1", id))
+	if err != nil {
+		return nil, err
+	}
+	if order.Tables, err = dineInTables(ctx, r.db, id); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(
+		`SELECT i.id, i.product_id, p.name, i.quantity, i.unit, COALESCE(i.notes, ''), i.round, i.sent_at,
+		        COALESCE(i.transaction_id, 0)
+		 FROM dine_in_order_items i
+		 JOIN products p ON p.id = i.product_id
+		 WHERE i.order_id = $1
+		 ORDER BY i.round, i.id`, id)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var item models.DineInOrderItem
+		if err := rows.Scan(&item.ID, &item.ProductID, &item.ProductName, &item.Quantity, &item.Unit, &item.Notes,
+			&item.Round, &item.SentAt, &item.TransactionID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		order.Items = append(order.Items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = r.db.Query(
+		`SELECT id, outlet_id, COALESCE(customer_id, 0), total_amount, points_amount, gift_card_amount, on_account_amount,
+		        payment_status, created_at
+		 FROM transactions
+		 WHERE id IN (SELECT transaction_id FROM dine_in_order_items WHERE order_id = $1)
+		 ORDER BY id`, id)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var t models.Transaction
+		if err := rows.Scan(&t.ID, &t.OutletID, &t.CustomerID, &t.TotalAmount, &t.PointsAmount, &t.GiftCardAmount,
+			&t.OnAccountAmount, &t.PaymentStatus, &t.CreatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		t.AmountDue = t.TotalAmount - t.PointsAmount - t.GiftCardAmount - t.OnAccountAmount
+		order.Bills = append(order.Bills, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = r.db.Query(
+		`SELECT id, amount, COALESCE(method, ''), COALESCE(actor, ''), created_at
+		 FROM dine_in_payments WHERE order_id = $1 ORDER BY id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p models.DineInPayment
+		if err := rows.Scan(&p.ID, &p.Amount, &p.Method, &p.Actor, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		order.Payments = append(order.Payments, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if order.AmountDue, order.AmountPaid, err = dineInDue(ctx, r.db, id); err != nil {
+		return nil, err
+	}
+	order.Balance = order.AmountDue - order.AmountPaid
+	return order, nil
+}
+
+// Open seats a new order at free tables of one outlet
+func (r *DineInOrderRepository) Open(req models.DineInOrderRequest) (*models.DineInOrder, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	outletID, err := lockFreeTables(ctx, tx, req.TableIDs, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var id int
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO dine_in_orders (outlet_id, status, guests, notes, opened_by)
+		 VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, '')) RETURNING id`,
+		outletID, models.DineInOpen, req.Guests, req.Notes, req.Actor,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+	if err := seatDineInOrder(ctx, tx, id, req.TableIDs); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// AddItems adds a round of items to an order that is not billed yet, which reopens it
+// until the round is sent to the kitchen
+func (r *DineInOrderRepository) AddItems(id int, req models.DineInItemsRequest) (*models.DineInOrder, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := lockDineInOrder(ctx, tx, id, models.DineInOpen, models.DineInSent, models.DineInServed); err != nil {
+		return nil, err
+	}
+
+	var round int
+	err = tx.QueryRowContext(ctx,
+		"SELECT COALESCE(MAX(round), 0) + 1 FROM dine_in_order_items WHERE order_id = $1", id,
+	).Scan(&round)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range req.Items {
+		unit, err := checkSaleUnit(ctx, tx, item.ProductID, item.Unit)
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO dine_in_order_items (order_id, product_id, quantity, unit, notes, round)
+			 VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)`,
+			id, item.ProductID, item.Quantity, unit, item.Notes, round,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := setDineInStatus(ctx, tx, id, models.DineInOpen); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// SendToKitchen sends the items not yet sent to the kitchen, routing them to the stations preparing them.
+// It returns the order and the items that reached a station.
+func (r *DineInOrderRepository) SendToKitchen(id int) (*models.DineInOrder, []models.KitchenItem, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	order, err := lockDineInOrder(ctx, tx, id, models.DineInOpen)
+	if err != nil {
+		return nil, nil, err
+	}
+	tables, err := dineInTables(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	names := make([]string, len(tables))
+	for i, t := range tables {
+		names[i] = t.Name
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		`UPDATE dine_in_order_items SET sent_at = CURRENT_TIMESTAMP WHERE order_id = $1 AND sent_at IS NULL
+		 RETURNING product_id, quantity, unit, COALESCE(notes, '')`, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	var round []models.KitchenItem
+	for rows.Next() {
+		item := models.KitchenItem{OutletID: order.OutletID, DineInOrderID: id, Tables: strings.Join(names, ", ")}
+		if err := rows.Scan(&item.ProductID, &item.Quantity, &item.Unit, &item.Notes); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		round = append(round, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(round) == 0 {
+		return nil, nil, fmt.Errorf("order %d has no items to send to the kitchen", id)
+	}
+
+	kitchen, err := routeToKitchen(ctx, tx, round)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := setDineInStatus(ctx, tx, id, models.DineInSent); err != nil {
+		return nil, nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+
+	order, err = r.GetByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return order, kitchen, nil
+}
+
+// Serve marks the items sent to the kitchen as served
+func (r *DineInOrderRepository) Serve(id int) (*models.DineInOrder, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := lockDineInOrder(ctx, tx, id, models.DineInSent); err != nil {
+		return nil, err
+	}
+	if err := setDineInStatus(ctx, tx, id, models.DineInServed); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// MoveTables moves an order that is not billed yet to other free tables of its outlet
+func (r *DineInOrderRepository) MoveTables(id int, req models.DineInTablesRequest) (*models.DineInOrder, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	order, err := lockDineInOrder(ctx, tx, id, models.DineInOpen, models.DineInSent, models.DineInServed)
+	if err != nil {
+		return nil, err
+	}
+	outletID, err := lockFreeTables(ctx, tx, req.TableIDs, id)
+	if err != nil {
+		return nil, err
+	}
+	if outletID != order.OutletID {
+		return nil, fmt.Errorf("order %d cannot move to tables of another outlet", id)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM dine_in_order_tables WHERE order_id = $1", id); err != nil {
+		return nil, err
+	}
+	if err := seatDineInOrder(ctx, tx, id, req.TableIDs); err != nil {
+		return nil, err
+	}
+	if err := setDineInStatus(ctx, tx, id, order.Status); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// Merge moves the items and tables of another order that is not billed yet into this one,
+// so a party that joins tables gets one order. The other order is left as merged.
+func (r *DineInOrderRepository) Merge(id int, req models.DineInMergeRequest) (*models.DineInOrder, error) {
+	if req.OrderID == id {
+		return nil, fmt.Errorf("order %d cannot be merged into itself", id)
+	}
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock in ID order so two merges of the same orders cannot deadlock
+	first, second := min(id, req.OrderID), max(id, req.OrderID)
+	locked := make(map[int]*models.DineInOrder)
+	for _, orderID := range []int{first, second} {
+		order, err := lockDineInOrder(ctx, tx, orderID, models.DineInOpen, models.DineInSent, models.DineInServed)
+		if err == sql.ErrNoRows && orderID == req.OrderID {
+			return nil, fmt.Errorf("order %d not found", req.OrderID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		locked[orderID] = order
+	}
+	order, other := locked[id], locked[req.OrderID]
+	if order.OutletID != other.OutletID {
+		return nil, fmt.Errorf("orders of different outlets cannot be merged")
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE dine_in_order_items SET order_id = $1 WHERE order_id = $2", id, other.ID)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO dine_in_order_tables (order_id, table_id)
+		 SELECT $1, table_id FROM dine_in_order_tables WHERE order_id = $2
+		 ON CONFLICT DO NOTHING`, id, other.ID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM dine_in_order_tables WHERE order_id = $1", other.ID); err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx,
+		`UPDATE dine_in_orders SET status = $1, merged_into = $2, guests = 0, updated_at = CURRENT_TIMESTAMP
+		 WHERE id = $3`, models.DineInMerged, id, other.ID)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE dine_in_orders SET guests = guests + $1 WHERE id = $2", other.Guests, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// The merged order is open while it has unsent items and served only when both were served
+	var unsent bool
+	err = tx.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM dine_in_order_items WHERE order_id = $1 AND sent_at IS NULL)", id,
+	).Scan(&unsent)
+	if err != nil {
+		return nil, err
+	}
+	status := models.DineInSent
+	switch {
+	case unsent:
+		status = models.DineInOpen
+	case order.Status != models.DineInSent && other.Status != models.DineInSent:
+		status = models.DineInServed
+	}
+	if err := setDineInStatus(ctx, tx, id, status); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// Bill checks out an order's items as one transaction per bill, taking them out of stock. Bills are
+// pending until Pay covers them, so they are reported, earn points and are announced once paid.
+// The order is paid right away when points, gift cards or the customer's account cover the bills.
+// It returns the order with the full bill transactions.
+func (r *DineInOrderRepository) Bill(id int, req models.DineInBillRequest) (*models.DineInOrder, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	order, err := lockDineInOrder(ctx, tx, id, models.DineInOpen, models.DineInSent, models.DineInServed)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		"SELECT id, product_id, quantity, unit FROM dine_in_order_items WHERE order_id = $1 ORDER BY id", id)
+	if err != nil {
+		return nil, err
+	}
+	items := make(map[int]models.CheckoutItem)
+	var itemIDs []int
+	for rows.Next() {
+		var itemID int
+		var item models.CheckoutItem
+		if err := rows.Scan(&itemID, &item.ProductID, &item.Quantity, &item.Unit); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		items[itemID] = item
+		itemIDs = append(itemIDs, itemID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(itemIDs) == 0 {
+		return nil, fmt.Errorf("order %d has no items to bill", id)
+	}
+
+	bills := req.Bills
+	if len(bills) == 0 {
+		bills = []models.DineInBill{{ItemIDs: itemIDs}}
+	}
+	billed := make(map[int]bool)
+	for _, bill := range bills {
+		for _, itemID := range bill.ItemIDs {
+			if _, ok := items[itemID]; !ok {
+				return nil, fmt.Errorf("item %d is not part of order %d", itemID, id)
+			}
+			if billed[itemID] {
+				return nil, fmt.Errorf("item %d is on more than one bill", itemID)
+			}
+			billed[itemID] = true
+		}
+	}
+	for _, itemID := range itemIDs {
+		if !billed[itemID] {
+			return nil, fmt.Errorf("item %d is not on any bill", itemID)
+		}
+	}
+
+	var transactions []models.Transaction
+	amountDue := 0
+	for _, bill := range bills {
+		checkout := models.CheckoutRequest{
+			CustomerID:       bill.CustomerID,
+			CustomerPhone:    bill.CustomerPhone,
+			PriceListID:      bill.PriceListID,
+			CustomerGroup:    bill.CustomerGroup,
+			RedeemPoints:     bill.RedeemPoints,
+			GiftCardPayments: bill.GiftCardPayments,
+			OnAccount:        bill.OnAccount,
+			OutletID:         order.OutletID,
+			DineInOrderID:    id,
+			Actor:            req.Actor,
+		}
+		for _, itemID := range bill.ItemIDs {
+			checkout.Items = append(checkout.Items, items[itemID])
+		}
+		if len(checkout.Items) == 0 {
+			return nil, fmt.Errorf("a bill needs at least one item")
+		}
+
+		transaction, err := r.transactions.checkout(ctx, tx, checkout)
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.ExecContext(ctx,
+			"UPDATE dine_in_order_items SET transaction_id = $1 WHERE id = ANY($2::int[])", transaction.ID, bill.ItemIDs)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, *transaction)
+		amountDue += transaction.AmountDue
+	}
+
+	status := models.DineInBilled
+	if amountDue == 0 {
+		status = models.DineInPaid
+	}
+	_, err = tx.ExecContext(ctx,
+		`UPDATE dine_in_orders SET status = $1, billed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP,
+		 paid_at = CASE WHEN $1 = $2 THEN CURRENT_TIMESTAMP END
+		 WHERE id = $3`,
+		status, models.DineInPaid, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	result, err := r.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	result.Bills = transactions
+	return result, nil
+}
+
+// Pay records a payment towards a billed order. Once its bills are covered the order and the bills
+// are paid, completing the sales. Guests can each pay a part to split the bill by amount.
+func (r *DineInOrderRepository) Pay(id int, req models.DineInPaymentRequest) (*models.DineInOrder, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := lockDineInOrder(ctx, tx, id, models.DineInBilled); err != nil {
+		return nil, err
+	}
+
+	due, paid, err := dineInDue(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Amount > due-paid {
+		return nil, fmt.Errorf("payment of %d exceeds the balance of %d", req.Amount, due-paid)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO dine_in_payments (order_id, amount, method, actor) VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''))",
+		id, req.Amount, req.Method, req.Actor,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if paid+req.Amount == due {
+		if err := r.completeBills(ctx, tx, id); err != nil {
+			return nil, err
+		}
+		_, err := tx.ExecContext(ctx,
+			"UPDATE dine_in_orders SET status = $1, paid_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id = $2",
+			models.DineInPaid, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// completeBills completes the sales of the pending bills of an order that was paid
+func (r *DineInOrderRepository) completeBills(ctx context.Context, tx *sql.Tx, orderID int) error {
+	ids, err := pendingBills(ctx, tx, orderID)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		t, err := lockTransaction(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if t.Details, err = transactionDetails(ctx, tx, id); err != nil {
+			return err
+		}
+		if err := r.transactions.completeSale(ctx, tx, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cancel cancels an order and frees its tables. Items of an order that is not billed yet never left
+// stock. A billed order is voided: its pending bills are given back like a refund, returning their
+// stock, redeemed points and gift card balances and writing off what they charged on account. Payments
+// already taken towards the bills stay listed on the order, to be handed back.
+func (r *DineInOrderRepository) Cancel(id int, actor string) (*models.DineInOrder, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	order, err := lockDineInOrder(ctx, tx, id, models.DineInOpen, models.DineInSent, models.DineInServed, models.DineInBilled)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status == models.DineInBilled {
+		if err := r.voidBills(ctx, tx, id, actor); err != nil {
+			return nil, err
+		}
+	}
+	if err := setDineInStatus(ctx, tx, id, models.DineInCancelled); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// voidBills gives back the pending bills of a billed order that is cancelled
+func (r *DineInOrderRepository) voidBills(ctx context.Context, tx *sql.Tx, orderID int, actor string) error {
+	ids, err := pendingBills(ctx, tx, orderID)
+	if err != nil {
+		return err
+	}
+	reason := fmt.Sprintf("dine-in order %d voided", orderID)
+	for _, id := range ids {
+		t, err := lockTransaction(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if err := r.transactions.reverseSale(ctx, tx, *t, reason, actor); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			`UPDATE transactions SET payment_status = $1, refunded_at = CURRENT_TIMESTAMP, refund_reason = $2
+			 WHERE id = $3`,
+			models.PaymentCancelled, reason, id)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dineInDue returns what is left to pay of an order's bills after points, gift cards and the customer's
+// account, leaving out refunded and voided bills, and what was paid towards them
+func dineInDue(ctx context.Context, q queryer, orderID int) (due, paid int, err error) {
+	err = q.QueryRowContext(ctx,
+		`SELECT COALESCE(SUM(total_amount - points_amount - gift_card_amount - on_account_amount), 0),
+		        (SELECT COALESCE(SUM(amount), 0) FROM dine_in_payments WHERE order_id = $1)
+		 FROM transactions
+		 WHERE id IN (SELECT transaction_id FROM dine_in_order_items WHERE order_id = $1) AND refunded_at IS NULL`, orderID,
+	).Scan(&due, &paid)
+	return due, paid, err
+}
+
+// pendingBills lists the bills of an order that are waiting for its payment
+func pendingBills(ctx context.Context, tx *sql.Tx, orderID int) ([]int, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id FROM transactions
+		 WHERE id IN (SELECT transaction_id FROM dine_in_order_items WHERE order_id = $1) AND payment_status = $2
+		 ORDER BY id`, orderID, models.PaymentPending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func scanDineInOrder(row rowScanner) (*models.DineInOrder, error) {
+	var o models.DineInOrder
+	err := row.Scan(&o.ID, &o.OutletID, &o.Status, &o.Guests, &o.Notes, &o.MergedInto, &o.OpenedBy,
+		&o.CreatedAt, &o.UpdatedAt, &o.BilledAt, &o.PaidAt)
+	if err != nil {
+		return nil, err
+	}
+	o.Tables = []models.DineInTable{}
+	return &o, nil
+}
+
+func dineInTables(ctx context.Context, q queryer, orderID int) ([]models.DineInTable, error) {
+	rows, err := q.QueryContext(ctx,
+		`SELECT t.id, t.name FROM dine_in_order_tables ot
+		 JOIN dining_tables t ON t.id = ot.table_id
+		 WHERE ot.order_id = $1
+		 ORDER BY t.name`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := []models.DineInTable{}
+	for rows.Next() {
+		var t models.DineInTable
+		if err := rows.Scan(&t.TableID, &t.Name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+	return tables, rows.Err()
+}
+
+// lockDineInOrder locks an order for the rest of the transaction and returns it,
+// refusing it unless it has one of the allowed statuses
+func lockDineInOrder(ctx context.Context, tx *sql.Tx, id int, allowed ...string) (*models.DineInOrder, error) {
+	order, err := scanDineInOrder(tx.QueryRowContext(ctx,
+		"SELECT "+dineInOrderColumns+" FROM dine_in_orders WHERE id = $1 FOR UPDATE", id))
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(allowed, order.Status) {
+		return nil, fmt.Errorf("order %d is %s", id, order.Status)
+	}
+	return order, nil
+}
+
+// lockFreeTables locks the tables an order is to sit at and returns their outlet. The tables must be
+// active, of one outlet and not taken by another order than orderID.
+func lockFreeTables(ctx context.Context, tx *sql.Tx, tableIDs []int, orderID int) (int, error) {
+	// Occupancy is read after the lock is held, so it sees an order seated by a concurrent request
+	_, err := tx.ExecContext(ctx, "SELECT id FROM dining_tables WHERE id = ANY($1::int[]) ORDER BY id FOR UPDATE", tableIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	outletID := 0
+	for _, tableID := range tableIDs {
+		table, err := scanTable(tx.QueryRowContext(ctx,
+			"SELECT "+tableColumns+" FROM dining_tables t WHERE t.id = $2", activeDineInStatuses, tableID))
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("table with ID %d not found", tableID)
+		}
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case !table.Active:
+			return 0, fmt.Errorf("table %s is not in use", table.Name)
+		case table.OrderID != 0 && table.OrderID != orderID:
+			return 0, fmt.Errorf("table %s is occupied by order %d", table.Name, table.OrderID)
+		case outletID != 0 && table.OutletID != outletID:
+			return 0, fmt.Errorf("tables of an order must be at the same outlet")
+		}
+		outletID = table.OutletID
+	}
+	return outletID, nil
+}
+
+func seatDineInOrder(ctx context.Context, tx *sql.Tx, orderID int, tableIDs []int) error {
+	for _, tableID := range tableIDs {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO dine_in_order_tables (order_id, table_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", orderID, tableID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setDineInStatus(ctx context.Context, tx *sql.Tx, id int, status string) error {
+	_, err := tx.ExecContext(ctx,
+		"UPDATE dine_in_orders SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2", status, id)
+	return err
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"testing"
+
+	"kasir-api/models"
+)
+
+// TestCancelBilledOrder bills an order partly paid with points, voids it and checks that the stock,
+// the points and the table are given back
+func TestCancelBilledOrder(t *testing.T) {
+	db := testDB(t)
+	loyalty := models.LoyaltyParams{SpendPerPoint: 1000, Rounding: models.LoyaltyRoundDown, PointValue: 100}
+	orders := NewDineInOrderRepository(db, NewTransactionRepository(db, models.ExpiredSaleBlock, loyalty, 0))
+	tables := NewTableRepository(db)
+
+	productID := queryInt(t, db, "INSERT INTO products (name, price, stock) VALUES ('Nasi Goreng', 25000, 10) RETURNING id")
+	queryInt(t, db, "INSERT INTO outlet_stock (outlet_id, product_id, stock) SELECT id, $1, 10 FROM outlets WHERE is_default RETURNING 1", productID)
+	customerID := queryInt(t, db, "INSERT INTO customers (name) VALUES ('Sari') RETURNING id")
+	queryInt(t, db, `INSERT INTO loyalty_ledger (customer_id, entry_type, points, balance_after, remaining)
+		VALUES ($1, $2, 100, 100, 100) RETURNING 1`, customerID, models.LoyaltyEarn)
+	tableID := queryInt(t, db, "INSERT INTO dining_tables (outlet_id, name) SELECT id, 'T1' FROM outlets WHERE is_default RETURNING id")
+
+	stock := func() int { return queryInt(t, db, "SELECT stock FROM products WHERE id = $1", productID) }
+	outletStock := func() int { return queryInt(t, db, "SELECT stock FROM outlet_stock WHERE product_id = $1", productID) }
+	points := func() int {
+		return queryInt(t, db, "SELECT balance_after FROM loyalty_ledger WHERE customer_id = $1 ORDER BY id DESC LIMIT 1", customerID)
+	}
+	seatedOrder := func() int {
+		table, err := tables.GetByID(tableID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return table.OrderID
+	}
+
+	order, err := orders.Open(models.DineInOrderRequest{TableIDs: []int{tableID}, Guests: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	order, err = orders.AddItems(order.ID, models.DineInItemsRequest{
+		Items: []models.DineInItemRequest{{ProductID: productID, Quantity: models.NewQuantity(2)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	order, err = orders.Bill(order.ID, models.DineInBillRequest{
+		Bills: []models.DineInBill{{ItemIDs: []int{order.Items[0].ID}, CustomerID: customerID, RedeemPoints: 50}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != models.DineInBilled || order.Balance != 45000 {
+		t.Fatalf("billed order is %s with a balance of %d, want billed with 45000", order.Status, order.Balance)
+	}
+	if stock() != 8 || outletStock() != 8 || points() != 50 || seatedOrder() != order.ID {
+		t.Fatalf("after billing: stock %d, outlet stock %d, points %d, table order %d", stock(), outletStock(), points(), seatedOrder())
+	}
+	billID := order.Bills[0].ID
+
+	order, err = orders.Cancel(order.ID, "andi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != models.DineInCancelled {
+		t.Errorf("status %s, want %s", order.Status, models.DineInCancelled)
+	}
+	if order.AmountDue != 0 || order.Balance != 0 {
+		t.Errorf("voided order is due %d with a balance of %d, want 0", order.AmountDue, order.Balance)
+	}
+	if got := stock(); got != 10 {
+		t.Errorf("stock %d, want 10", got)
+	}
+	if got := outletStock(); got != 10 {
+		t.Errorf("outlet stock %d, want 10", got)
+	}
+	if got := points(); got != 100 {
+		t.Errorf("points %d, want 100", got)
+	}
+	if got := seatedOrder(); got != 0 {
+		t.Errorf("table still taken by order %d", got)
+	}
+	var status string
+	if err := db.QueryRow("SELECT payment_status FROM transactions WHERE id = $1", billID).Scan(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status != models.PaymentCancelled {
+		t.Errorf("bill is %s, want %s", status, models.PaymentCancelled)
+	}
+}
@@ -0,0 +1,306 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+)
+
+const draftOrderColumns = `id, outlet_id, COALESCE(label, ''), COALESCE(notes, ''), COALESCE(customer_id, 0),
+	COALESCE(price_list_id, 0), COALESCE(customer_group, ''), status, COALESCE(transaction_id, 0),
+	COALESCE(created_by, ''), created_at, updated_at, expires_at`
+
+type DraftOrderRepository struct {
+	db *sql.DB
+	// expiryMinutes is how long an open draft lives after it was last saved
+	expiryMinutes int
+}
+
+func NewDraftOrderRepository(db *sql.DB, expiryMinutes int) *DraftOrderRepository {
+	return &DraftOrderRepository{db: db, expiryMinutes: expiryMinutes}
+}
+
+// GetAll lists draft orders, newest first, of one status and optionally one outlet.
+// Stale drafts are expired first.
+func (r *DraftOrderRepository) GetAll(status string, outletID int) ([]models.DraftOrder, error) {
+	ctx := context.Background()
+	if err := expireDraftOrders(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(
+		`SELECT `+draftOrderColumns+` FROM draft_orders
+		 WHERE status = $1 AND ($2 = 0 OR outlet_id = $2)
+		 ORDER BY updated_at DESC, id DESC`, status, outletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []models.DraftOrder{}
+	for rows.Next() {
+		order, err := scanDraftOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *order)
+	}
+	return orders, rows.Err()
+}
+
+// GetByID returns a draft order with its items, expiring it first when it is stale
+func (r *DraftOrderRepository) GetByID(id int) (*models.DraftOrder, error) {
+	ctx := context.Background()
+	if err := expireDraftOrders(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	order, err := scanDraftOrder(r.db.QueryRow("SELECT "+draftOrderColumns+" FROM draft_orders WHERE id = $1", id))
+	if err != nil {
+		return nil, err
+	}
+	order.Items, err = draftOrderItems(ctx, r.db, id)
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// Create parks a cart at the request's outlet, or the default outlet
+func (r *DraftOrderRepository) Create(req models.DraftOrderRequest) (*models.DraftOrder, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	outletID, err := resolveOutlet(ctx, tx, req.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	customerID, err := resolveCustomer(ctx, tx, req.CustomerID, req.CustomerPhone)
+	if err != nil {
+		return nil, err
+	}
+
+	var id int
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO draft_orders (outlet_id, label, notes, customer_id, price_list_id, customer_group, status, created_by, expires_at)
+		 VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), NULLIF($4, 0), NULLIF($5, 0), NULLIF($6, ''), $7, NULLIF($8, ''),
+		         CURRENT_TIMESTAMP + make_interval(mins => $9))
+		 RETURNING id`,
+		outletID, req.Label, req.Notes, customerID, req.PriceListID, req.CustomerGroup, models.DraftOrderOpen, req.Actor,
+		r.expiryMinutes,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+	if err := replaceDraftOrderItems(ctx, tx, id, req.Items); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// Update replaces the items and details of an open draft order and restarts its expiry.
+// The draft stays at its outlet.
+func (r *DraftOrderRepository) Update(id int, req models.DraftOrderRequest) (*models.DraftOrder, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := lockOpenDraftOrder(ctx, tx, id); err != nil {
+		return nil, err
+	}
+	customerID, err := resolveCustomer(ctx, tx, req.CustomerID, req.CustomerPhone)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE draft_orders SET label = NULLIF($1, ''), notes = NULLIF($2, ''), customer_id = NULLIF($3, 0),
+		 price_list_id = NULLIF($4, 0), customer_group = NULLIF($5, ''), updated_at = CURRENT_TIMESTAMP,
+		 expires_at = CURRENT_TIMESTAMP + make_interval(mins => $6)
+		 WHERE id = $7`,
+		req.Label, req.Notes, customerID, req.PriceListID, req.CustomerGroup, r.expiryMinutes, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := replaceDraftOrderItems(ctx, tx, id, req.Items); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// Delete discards a draft order that has not been checked out
+func (r *DraftOrderRepository) Delete(id int) error {
+	var status string
+	err := r.db.QueryRow("SELECT status FROM draft_orders WHERE id = $1", id).Scan(&status)
+	if err != nil {
+		return err
+	}
+	if status == models.DraftOrderConverted {
+		return fmt.Errorf("draft order %d was checked out and cannot be deleted", id)
+	}
+
+	_, err = r.db.Exec("DELETE FROM draft_orders WHERE id = $1 AND status <> $2", id, models.DraftOrderConverted)
+	return err
+}
+
+func scanDraftOrder(row rowScanner) (*models.DraftOrder, error) {
+	var o models.DraftOrder
+	err := row.Scan(&o.ID, &o.OutletID, &o.Label, &o.Notes, &o.CustomerID, &o.PriceListID, &o.CustomerGroup,
+		&o.Status, &o.TransactionID, &o.CreatedBy, &o.CreatedAt, &o.UpdatedAt, &o.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func draftOrderItems(ctx context.Context, q queryer, draftOrderID int) ([]models.DraftOrderItem, error) {
+	rows, err := q.QueryContext(ctx,
+		`SELECT i.product_id, p.name, i.quantity, i.unit
+		 FROM draft_order_items i
+		 JOIN products p ON p.id = i.product_id
+		 WHERE i.draft_order_id = $1
+		 ORDER BY i.id`, draftOrderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.DraftOrderItem
+	for rows.Next() {
+		var item models.DraftOrderItem
+		if err := rows.Scan(&item.ProductID, &item.ProductName, &item.Quantity, &item.Unit); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// replaceDraftOrderItems rewrites the items of a draft order. Products and units are checked,
+// stock is not: it is only taken when the draft is checked out.
+func replaceDraftOrderItems(ctx context.Context, tx *sql.Tx, draftOrderID int, items []models.CheckoutItem) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM draft_order_items WHERE draft_order_id = $1", draftOrderID); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		unit, err := checkSaleUnit(ctx, tx, item.ProductID, item.Unit)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO draft_order_items (draft_order_id, product_id, quantity, unit) VALUES ($1, $2, $3, $4)",
+			draftOrderID, item.ProductID, item.Quantity, unit,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSaleUnit checks that a product exists and can be sold in unit, and returns the unit,
+// which defaults to the product's sale unit
+func checkSaleUnit(ctx context.Context, q queryer, productID int, unit string) (string, error) {
+	var price int
+	var baseUnit, saleUnit string
+	err := q.QueryRowContext(ctx,
+		"SELECT price, unit, sale_unit FROM products WHERE id = $1", productID,
+	).Scan(&price, &baseUnit, &saleUnit)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("product with ID %d not found", productID)
+		}
+		return "", err
+	}
+
+	if unit == "" {
+		unit = saleUnit
+	}
+	if _, _, err := resolveUnit(ctx, q, productID, baseUnit, unit, price); err != nil {
+		return "", err
+	}
+	return unit, nil
+}
+
+// lockOpenDraftOrder locks a draft order for the rest of the transaction and returns it,
+// refusing drafts that are checked out or expired
+func lockOpenDraftOrder(ctx context.Context, tx *sql.Tx, id int) (*models.DraftOrder, error) {
+	if err := expireDraftOrders(ctx, tx); err != nil {
+		return nil, err
+	}
+	order, err := scanDraftOrder(tx.QueryRowContext(ctx,
+		"SELECT "+draftOrderColumns+" FROM draft_orders WHERE id = $1 FOR UPDATE", id))
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != models.DraftOrderOpen {
+		return nil, fmt.Errorf("draft order %d is %s", id, order.Status)
+	}
+	return order, nil
+}
+
+// claimDraftOrder fills a checkout from the open draft order it converts. The draft is locked
+// so it cannot be checked out twice.
+func claimDraftOrder(ctx context.Context, tx *sql.Tx, req *models.CheckoutRequest) error {
+	order, err := lockOpenDraftOrder(ctx, tx, req.DraftOrderID)
+	if err != nil {
+		return err
+	}
+	items, err := draftOrderItems(ctx, tx, order.ID)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 && len(req.GiftCards) == 0 {
+		return fmt.Errorf("draft order %d has no items", order.ID)
+	}
+
+	req.OutletID = order.OutletID
+	req.Items = req.Items[:0]
+	for _, item := range items {
+		req.Items = append(req.Items, models.CheckoutItem{ProductID: item.ProductID, Quantity: item.Quantity, Unit: item.Unit})
+	}
+	if req.CustomerID == 0 && req.CustomerPhone == "" {
+		req.CustomerID = order.CustomerID
+	}
+	if req.PriceListID == 0 && req.CustomerGroup == "" {
+		req.PriceListID, req.CustomerGroup = order.PriceListID, order.CustomerGroup
+	}
+	return nil
+}
+
+// convertDraftOrder marks a claimed draft order as checked out by a transaction
+func convertDraftOrder(ctx context.Context, tx *sql.Tx, draftOrderID, transactionID int) error {
+	_, err := tx.ExecContext(ctx,
+		"UPDATE draft_orders SET status = $1, transaction_id = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3",
+		models.DraftOrderConverted, transactionID, draftOrderID,
+	)
+	return err
+}
+
+// expireDraftOrders expires the open drafts that passed their expiry time
+func expireDraftOrders(ctx context.Context, q queryer) error {
+	_, err := q.ExecContext(ctx,
+		"UPDATE draft_orders SET status = $1 WHERE status = $2 AND expires_at <= CURRENT_TIMESTAMP",
+		models.DraftOrderExpired, models.DraftOrderOpen,
+	)
+	return err
+}
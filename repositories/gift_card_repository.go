@@ -0,0 +1,273 @@
+package repositories
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"strings"
+	"time"
+)
+
+const giftCardColumns = `id, code, card_type, initial_amount, balance, status,
+	COALESCE(TO_CHAR(expiry_date, 'YYYY-MM-DD'), ''), COALESCE(expiry_date < CURRENT_DATE, false),
+	COALESCE(transaction_id, 0), created_at`
+
+// giftCardCodeAlphabet leaves out characters that are easily misread, such as 0/O and 1/I
+const giftCardCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+type GiftCardRepository struct {
+	db *sql.DB
+}
+
+func NewGiftCardRepository(db *sql.DB) *GiftCardRepository {
+	return &GiftCardRepository{db: db}
+}
+
+// GetAll lists gift cards and vouchers, newest first, optionally of one status
+func (r *GiftCardRepository) GetAll(status string) ([]models.GiftCard, error) {
+	rows, err := r.db.Query(
+		"SELECT "+giftCardColumns+" FROM gift_cards WHERE $1 = '' OR status = $1 ORDER BY id DESC", status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cards []models.GiftCard
+	for rows.Next() {
+		card, err := scanGiftCard(rows)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, *card)
+	}
+	return cards, nil
+}
+
+// GetByCode returns a gift card with its ledger
+func (r *GiftCardRepository) GetByCode(code string) (*models.GiftCard, error) {
+	card, err := scanGiftCard(r.db.QueryRow("SELECT "+giftCardColumns+" FROM gift_cards WHERE code = $1", normalizeGiftCardCode(code)))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(
+		`SELECT id, COALESCE(transaction_id, 0), entry_type, amount, balance_after, created_at
+		 FROM gift_card_ledger WHERE gift_card_id = $1 ORDER BY id`, card.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e models.GiftCardEntry
+		if err := rows.Scan(&e.ID, &e.TransactionID, &e.Type, &e.Amount, &e.BalanceAfter, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		card.Entries = append(card.Entries, e)
+	}
+	return card, rows.Err()
+}
+
+func scanGiftCard(row rowScanner) (*models.GiftCard, error) {
+	var c models.GiftCard
+	err := row.Scan(&c.ID, &c.Code, &c.Type, &c.InitialAmount, &c.Balance, &c.Status, &c.ExpiryDate, &c.Expired,
+		&c.TransactionID, &c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// issueGiftCard creates a gift card sold by a transaction, with a generated code unless one is given.
+// Without an expiry date the card expires expiryDays from today, or never when expiryDays is 0.
+func issueGiftCard(ctx context.Context, tx *sql.Tx, transactionID int, req models.GiftCardIssueRequest, expiryDays int) (*models.GiftCard, error) {
+	code := normalizeGiftCardCode(req.Code)
+	if code == "" {
+		var err error
+		if code, err = generateGiftCardCode(); err != nil {
+			return nil, err
+		}
+	}
+	expiry := req.ExpiryDate
+	if expiry == "" && expiryDays > 0 {
+		expiry = time.Now().AddDate(0, 0, expiryDays).Format("2006-01-02")
+	}
+
+	card, err := scanGiftCard(tx.QueryRowContext(ctx,
+		`INSERT INTO gift_cards (code, card_type, initial_amount, balance, status, expiry_date, transaction_id)
+		 VALUES ($1, $2, $3, $3, $4, NULLIF($5, '')::date, $6)
+		 ON CONFLICT (tenant_id, code) DO NOTHING
+		 RETURNING `+giftCardColumns,
+		code, req.Type, req.Amount, models.GiftCardActive, expiry, transactionID,
+	))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("gift card code %s is already in use", code)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = addGiftCardEntry(ctx, tx, card.ID, transactionID, models.GiftCardEntryIssue, card.Balance, card.Balance)
+	if err != nil {
+		return nil, err
+	}
+	return card, nil
+}
+
+// redeemGiftCard pays up to due with a gift card. The card row is locked so concurrent checkouts
+// cannot spend the same balance twice. A voucher is used up whatever it pays.
+func redeemGiftCard(ctx context.Context, tx *sql.Tx, transactionID int, payment models.GiftCardPayment, due int) (*models.GiftCardRedemption, error) {
+	code := normalizeGiftCardCode(payment.Code)
+	card, err := scanGiftCard(tx.QueryRowContext(ctx,
+		"SELECT "+giftCardColumns+" FROM gift_cards WHERE code = $1 FOR UPDATE", code))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("gift card %s not found", code)
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case card.Status != models.GiftCardActive:
+		return nil, fmt.Errorf("gift card %s is %s", code, card.Status)
+	case card.Expired:
+		return nil, fmt.Errorf("gift card %s expired on %s", code, card.ExpiryDate)
+	case card.Balance <= 0:
+		return nil, fmt.Errorf("gift card %s has no balance left", code)
+	}
+
+	amount := payment.Amount
+	if amount == 0 {
+		amount = min(card.Balance, due)
+	}
+	if amount > card.Balance {
+		return nil, fmt.Errorf("gift card %s has a balance of %d, cannot pay %d", code, card.Balance, amount)
+	}
+	if amount > due {
+		return nil, fmt.Errorf("gift card %s cannot pay %d, only %d is left to pay", code, amount, due)
+	}
+
+	// A voucher takes its whole balance, the part it did not pay is forfeited
+	taken, status := amount, models.GiftCardActive
+	if card.Type == models.GiftCardTypeVoucher {
+		taken, status = card.Balance, models.GiftCardRedeemed
+	}
+	var balance int
+	err = tx.QueryRowContext(ctx,
+		"UPDATE gift_cards SET balance = balance - $1, status = $2 WHERE id = $3 RETURNING balance",
+		taken, status, card.ID,
+	).Scan(&balance)
+	if err != nil {
+		return nil, err
+	}
+	if err := addGiftCardEntry(ctx, tx, card.ID, transactionID, models.GiftCardEntryRedeem, -taken, balance); err != nil {
+		return nil, err
+	}
+	return &models.GiftCardRedemption{GiftCardID: card.ID, Code: card.Code, Amount: amount, BalanceAfter: balance}, nil
+}
+
+// refundGiftCards undoes the gift cards of a refunded transaction: the balances it spent are given back
+// and the cards it sold are voided, which is refused once a sold card has been used
+func refundGiftCards(ctx context.Context, tx *sql.Tx, transactionID int) error {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT gift_card_id, -SUM(amount) FROM gift_card_ledger
+		 WHERE transaction_id = $1 AND entry_type = $2
+		 GROUP BY gift_card_id ORDER BY gift_card_id`, transactionID, models.GiftCardEntryRedeem)
+	if err != nil {
+		return err
+	}
+	spent := make(map[int]int)
+	var ids []int
+	for rows.Next() {
+		var id, amount int
+		if err := rows.Scan(&id, &amount); err != nil {
+			rows.Close()
+			return err
+		}
+		spent[id] = amount
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		var balance int
+		err := tx.QueryRowContext(ctx,
+			"UPDATE gift_cards SET balance = balance + $1, status = $2 WHERE id = $3 RETURNING balance",
+			spent[id], models.GiftCardActive, id,
+		).Scan(&balance)
+		if err != nil {
+			return err
+		}
+		if err := addGiftCardEntry(ctx, tx, id, transactionID, models.GiftCardEntryRefund, spent[id], balance); err != nil {
+			return err
+		}
+	}
+
+	rows, err = tx.QueryContext(ctx,
+		"SELECT "+giftCardColumns+" FROM gift_cards WHERE transaction_id = $1 ORDER BY id FOR UPDATE", transactionID)
+	if err != nil {
+		return err
+	}
+	var sold []models.GiftCard
+	for rows.Next() {
+		card, err := scanGiftCard(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		sold = append(sold, *card)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, card := range sold {
+		if card.Status != models.GiftCardActive || card.Balance != card.InitialAmount {
+			return fmt.Errorf("gift card %s sold with this transaction has been used and cannot be refunded", card.Code)
+		}
+		_, err := tx.ExecContext(ctx,
+			"UPDATE gift_cards SET balance = 0, status = $1 WHERE id = $2", models.GiftCardVoid, card.ID)
+		if err != nil {
+			return err
+		}
+		if err := addGiftCardEntry(ctx, tx, card.ID, transactionID, models.GiftCardEntryVoid, -card.Balance, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func addGiftCardEntry(ctx context.Context, tx *sql.Tx, giftCardID, transactionID int, entryType string, amount, balanceAfter int) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO gift_card_ledger (gift_card_id, transaction_id, entry_type, amount, balance_after)
+		 VALUES ($1, NULLIF($2, 0), $3, $4, $5)`,
+		giftCardID, transactionID, entryType, amount, balanceAfter,
+	)
+	return err
+}
+
+// normalizeGiftCardCode makes codes case-insensitive and ignores surrounding spaces
+func normalizeGiftCardCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// generateGiftCardCode returns a random code such as "K7QP-M2XD-9HTA-R4WC"
+func generateGiftCardCode() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	var code strings.Builder
+	for i, v := range b {
+		if i > 0 && i%4 == 0 {
+			code.WriteByte('-')
+		}
+		code.WriteByte(giftCardCodeAlphabet[int(v)%len(giftCardCodeAlphabet)])
+	}
+	return code.String(), nil
+}
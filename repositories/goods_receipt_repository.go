@@ -0,0 +1,310 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+)
+
+const goodsReceiptColumns = `gr.id, gr.supplier_id, s.name, COALESCE(gr.purchase_order_id, 0), gr.outlet_id, COALESCE(gr.reference, ''),
+	COALESCE(gr.notes, ''), gr.total_cost, COALESCE(gr.received_by, ''), gr.received_at`
+
+type GoodsReceiptRepository struct {
+	db *sql.DB
+}
+
+func NewGoodsReceiptRepository(db *sql.DB) *GoodsReceiptRepository {
+	return &GoodsReceiptRepository{db: db}
+}
+
+// GetAll lists receipts newest first, optionally only those from a supplier or containing a product
+func (r *GoodsReceiptRepository) GetAll(supplierID, productID int) ([]models.GoodsReceipt, error) {
+	rows, err := r.db.Query(
+		`SELECT `+goodsReceiptColumns+`
+		 FROM goods_receipts gr
+		 JOIN suppliers s ON s.id = gr.supplier_id
+		 WHERE ($1 = 0 OR gr.supplier_id = $1)
+		   AND ($2 = 0 OR EXISTS (SELECT 1 FROM goods_receipt_lines l WHERE l.goods_receipt_id = gr.id AND l.product_id = $2))
+		 ORDER BY gr.id DESC`,
+		supplierID, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var receipts []models.GoodsReceipt
+	for rows.Next() {
+		var gr models.GoodsReceipt
+		if err := rows.Scan(&gr.ID, &gr.SupplierID, &gr.SupplierName, &gr.PurchaseOrderID, &gr.OutletID, &gr.Reference,
+			&gr.Notes, &gr.TotalCost, &gr.ReceivedBy, &gr.ReceivedAt); err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, gr)
+	}
+	return receipts, nil
+}
+
+func (r *GoodsReceiptRepository) GetByID(id int) (*models.GoodsReceipt, error) {
+	var gr models.GoodsReceipt
+	err := r.db.QueryRow(
+		`SELECT `+goodsReceiptColumns+` FROM goods_receipts gr JOIN suppliers s ON s.id = gr.supplier_id WHERE gr.id = $1`, id,
+	).Scan(&gr.ID, &gr.SupplierID, &gr.SupplierName, &gr.PurchaseOrderID, &gr.OutletID, &gr.Reference,
+		&gr.Notes, &gr.TotalCost, &gr.ReceivedBy, &gr.ReceivedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(
+		`SELECT l.id, l.product_id, p.name, COALESCE(l.purchase_order_line_id, 0), l.quantity, l.unit, l.base_quantity,
+		        l.unit_cost, l.subtotal, l.average_cost_after, COALESCE(l.batch_id, 0), COALESCE(b.batch_number, ''),
+		        COALESCE(TO_CHAR(b.expiry_date, 'YYYY-MM-DD'), '')
+		 FROM goods_receipt_lines l
+		 JOIN products p ON p.id = l.product_id
+		 LEFT JOIN product_batches b ON b.id = l.batch_id
+		 WHERE l.goods_receipt_id = $1
+		 ORDER BY l.id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l models.GoodsReceiptLine
+		if err := rows.Scan(&l.ID, &l.ProductID, &l.ProductName, &l.PurchaseOrderLineID, &l.Quantity, &l.Unit,
+			&l.BaseQuantity, &l.UnitCost, &l.Subtotal, &l.AverageCostAfter, &l.BatchID, &l.BatchNumber, &l.ExpiryDate); err != nil {
+			return nil, err
+		}
+		gr.Lines = append(gr.Lines, l)
+	}
+	return &gr, nil
+}
+
+// Create records a delivery: stock of every line is incremented through the ledger, the
+// weighted-average cost is updated and the purchase order's received quantities advance
+func (r *GoodsReceiptRepository) Create(req models.GoodsReceiptRequest) (*models.GoodsReceipt, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	supplierID := req.SupplierID
+	if req.PurchaseOrderID != 0 {
+		var status string
+		var poSupplierID int
+		err := tx.QueryRowContext(ctx,
+			"SELECT status, supplier_id FROM purchase_orders WHERE id = $1 FOR UPDATE", req.PurchaseOrderID,
+		).Scan(&status, &poSupplierID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, fmt.Errorf("purchase order with ID %d not found", req.PurchaseOrderID)
+			}
+			return nil, err
+		}
+		if status != models.POStatusSent && status != models.POStatusPartiallyReceived {
+			return nil, fmt.Errorf("purchase order %d is %s and cannot receive goods", req.PurchaseOrderID, status)
+		}
+		if supplierID == 0 {
+			supplierID = poSupplierID
+		} else if supplierID != poSupplierID {
+			return nil, fmt.Errorf("purchase order %d belongs to another supplier", req.PurchaseOrderID)
+		}
+	}
+	if supplierID == 0 {
+		return nil, fmt.Errorf("supplier_id is required without a purchase order")
+	}
+
+	var supplierName string
+	err = tx.QueryRowContext(ctx, "SELECT name FROM suppliers WHERE id = $1", supplierID).Scan(&supplierName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("supplier with ID %d not found", supplierID)
+		}
+		return nil, err
+	}
+
+	req.OutletID, err = resolveOutlet(ctx, tx, req.OutletID)
+	if err != nil {
+		return nil, err
+	}
+
+	var receiptID int
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO goods_receipts (supplier_id, purchase_order_id, outlet_id, reference, notes, received_by)
+		 VALUES ($1, NULLIF($2, 0), $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, '')) RETURNING id`,
+		supplierID, req.PurchaseOrderID, req.OutletID, req.Reference, req.Notes, req.Actor,
+	).Scan(&receiptID)
+	if err != nil {
+		return nil, err
+	}
+
+	totalCost := 0
+	for _, line := range req.Lines {
+		subtotal, err := receiveLine(ctx, tx, receiptID, req, line, supplierName)
+		if err != nil {
+			return nil, err
+		}
+		totalCost += subtotal
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE goods_receipts SET total_cost = $1 WHERE id = $2", totalCost, receiptID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.PurchaseOrderID != 0 {
+		_, err = tx.ExecContext(ctx,
+			`UPDATE purchase_orders SET updated_at = CURRENT_TIMESTAMP,
+			     status = CASE WHEN (SELECT bool_and(received_quantity >= quantity) FROM purchase_order_lines WHERE purchase_order_id = $1)
+			                   THEN $2 ELSE $3 END
+			 WHERE id = $1`,
+			req.PurchaseOrderID, models.POStatusReceived, models.POStatusPartiallyReceived,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(receiptID)
+}
+
+// receiveLine books one received product and returns its cost
+func receiveLine(ctx context.Context, tx *sql.Tx, receiptID int, req models.GoodsReceiptRequest, line models.GoodsReceiptLineRequest, supplierName string) (int, error) {
+	productID := line.ProductID
+	poLineID := 0
+	var poUnit string
+	var poFactor models.Quantity
+
+	if req.PurchaseOrderID != 0 {
+		// Match the delivery to the order line, by ID or else by product
+		err := tx.QueryRowContext(ctx,
+			`SELECT id, product_id, unit, unit_factor FROM purchase_order_lines
+			 WHERE purchase_order_id = $1 AND (id = $2 OR ($2 = 0 AND product_id = $3))
+			 ORDER BY id LIMIT 1 FOR UPDATE`,
+			req.PurchaseOrderID, line.PurchaseOrderLineID, line.ProductID,
+		).Scan(&poLineID, &productID, &poUnit, &poFactor)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return 0, fmt.Errorf("product with ID %d is not on purchase order %d", line.ProductID, req.PurchaseOrderID)
+			}
+			return 0, err
+		}
+		if line.ProductID != 0 && line.ProductID != productID {
+			return 0, fmt.Errorf("purchase order line %d is for another product", poLineID)
+		}
+	}
+
+	var name, baseUnit, purchaseUnit string
+	var price, costPrice int
+	var stock models.Quantity
+	var isBundle bool
+	err := tx.QueryRowContext(ctx,
+		"SELECT name, price, cost_price, stock, unit, purchase_unit, is_bundle FROM products WHERE id = $1 FOR UPDATE", productID,
+	).Scan(&name, &price, &costPrice, &stock, &baseUnit, &purchaseUnit, &isBundle)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("product with ID %d not found", productID)
+		}
+		return 0, err
+	}
+	if isBundle {
+		return 0, fmt.Errorf("product %s is a bundle, receive its components instead", name)
+	}
+
+	unit := line.Unit
+	if unit == "" {
+		unit = poUnit
+	}
+	if unit == "" {
+		unit = purchaseUnit
+	}
+	factor := poFactor
+	if poLineID == 0 || unit != poUnit {
+		factor, _, err = resolveUnit(ctx, tx, productID, baseUnit, unit, price)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	baseQuantity, err := line.Quantity.Mul(factor)
+	if err != nil {
+		return 0, err
+	}
+	subtotal, err := line.Quantity.MulPrice(line.UnitCost)
+	if err != nil {
+		return 0, err
+	}
+	averageCost := weightedAverageCost(stock, costPrice, baseQuantity, subtotal)
+
+	movement, err := applyStockMovement(ctx, tx, models.StockMovement{
+		ProductID:     productID,
+		OutletID:      req.OutletID,
+		Type:          models.MovementReceipt,
+		Quantity:      baseQuantity,
+		Reason:        "Goods receipt from " + supplierName,
+		Actor:         req.Actor,
+		ReferenceType: "goods_receipt",
+		ReferenceID:   receiptID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	batchID := 0
+	if line.BatchNumber != "" {
+		batchID, err = receiveBatch(ctx, tx, movement, line.BatchNumber, line.ExpiryDate)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if averageCost != costPrice {
+		if _, err := tx.ExecContext(ctx, "UPDATE products SET cost_price = $1 WHERE id = $2", averageCost, productID); err != nil {
+			return 0, err
+		}
+		if err := recordCostPrice(ctx, tx, productID, averageCost, "receipt"); err != nil {
+			return 0, err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO goods_receipt_lines (goods_receipt_id, product_id, purchase_order_line_id, quantity, unit, unit_factor,
+		     base_quantity, unit_cost, subtotal, average_cost_after, batch_id)
+		 VALUES ($1, $2, NULLIF($3, 0), $4, $5, $6, $7, $8, $9, $10, NULLIF($11, 0))`,
+		receiptID, productID, poLineID, line.Quantity, unit, factor, baseQuantity, line.UnitCost, subtotal, averageCost, batchID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	if poLineID != 0 {
+		// Received quantities on the order are kept in the order's unit
+		_, err = tx.ExecContext(ctx,
+			"UPDATE purchase_order_lines SET received_quantity = received_quantity + $1 WHERE id = $2",
+			baseQuantity.Div(poFactor), poLineID,
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return subtotal, nil
+}
+
+// weightedAverageCost blends the cost of the stock on hand with the cost of a delivery.
+// Stock at or below zero carries no value, so the delivery cost is used as is.
+func weightedAverageCost(stock models.Quantity, cost int, received models.Quantity, receivedValue int) int {
+	if received <= 0 {
+		return cost
+	}
+	if stock <= 0 {
+		return divRoundQuantity(receivedValue, received)
+	}
+	total := int64(stock) + int64(received)
+	value := int64(stock)*int64(cost) + int64(receivedValue)*models.QuantityScale
+	return int((value + total/2) / total)
+}
@@ -0,0 +1,315 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+)
+
+// kitchenItemColumns reads a kitchen item with its timers, which run until the item is ready
+const kitchenItemColumns = `k.id, k.station_id, k.outlet_id, COALESCE(k.transaction_id, 0), COALESCE(k.dine_in_order_id, 0),
+	COALESCE(k.tables, ''), k.product_id, p.name, k.quantity, k.unit, COALESCE(k.notes, ''), k.status,
+	k.created_at, k.started_at, k.ready_at, k.bumped_at,
+	EXTRACT(EPOCH FROM COALESCE(k.ready_at, LOCALTIMESTAMP) - k.created_at)::int,
+	COALESCE(EXTRACT(EPOCH FROM COALESCE(k.ready_at, LOCALTIMESTAMP) - k.started_at)::int, 0)`
+
+// kitchenQueueStatuses are the statuses of items still on a station's screen
+var kitchenQueueStatuses = []string{models.KitchenItemNew, models.KitchenItemPreparing, models.KitchenItemReady}
+
+type KitchenRepository struct {
+	db *sql.DB
+}
+
+func NewKitchenRepository(db *sql.DB) *KitchenRepository {
+	return &KitchenRepository{db: db}
+}
+
+func (r *KitchenRepository) GetStations() ([]models.KitchenStation, error) {
+	rows, err := r.db.Query("SELECT id, name, created_at FROM kitchen_stations ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stations := []models.KitchenStation{}
+	for rows.Next() {
+		var s models.KitchenStation
+		if err := rows.Scan(&s.ID, &s.Name, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		stations = append(stations, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range stations {
+		stations[i].CategoryIDs, err = stationCategories(r.db, stations[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return stations, nil
+}
+
+func (r *KitchenRepository) GetStation(id int) (*models.KitchenStation, error) {
+	var s models.KitchenStation
+	err := r.db.QueryRow("SELECT id, name, created_at FROM kitchen_stations WHERE id = $1", id).
+		Scan(&s.ID, &s.Name, &s.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	s.CategoryIDs, err = stationCategories(r.db, id)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *KitchenRepository) CreateStation(req models.KitchenStationRequest) (*models.KitchenStation, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	if err := tx.QueryRowContext(ctx, "INSERT INTO kitchen_stations (name) VALUES ($1) RETURNING id", req.Name).Scan(&id); err != nil {
+		return nil, err
+	}
+	if err := replaceStationCategories(ctx, tx, id, req.CategoryIDs); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetStation(id)
+}
+
+// UpdateStation renames a station and replaces the categories it prepares.
+// Items already on its queue stay there.
+func (r *KitchenRepository) UpdateStation(id int, req models.KitchenStationRequest) (*models.KitchenStation, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "UPDATE kitchen_stations SET name = $1 WHERE id = $2", req.Name, id)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, sql.ErrNoRows
+	}
+	if err := replaceStationCategories(ctx, tx, id, req.CategoryIDs); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetStation(id)
+}
+
+// DeleteStation removes a station with nothing left to prepare, along with its bumped items
+func (r *KitchenRepository) DeleteStation(id int) error {
+	station, err := r.GetStation(id)
+	if err != nil {
+		return err
+	}
+
+	var open int
+	err = r.db.QueryRow(
+		"SELECT COUNT(*) FROM kitchen_items WHERE station_id = $1 AND status = ANY($2::text[])",
+		id, kitchenQueueStatuses,
+	).Scan(&open)
+	if err != nil {
+		return err
+	}
+	if open > 0 {
+		return fmt.Errorf("station %s still has %d items on its queue", station.Name, open)
+	}
+
+	_, err = r.db.Exec("DELETE FROM kitchen_stations WHERE id = $1", id)
+	return err
+}
+
+// GetQueue lists a station's items, oldest first, optionally of one outlet.
+// Without a status it lists the items not bumped yet.
+func (r *KitchenRepository) GetQueue(stationID, outletID int, status string) ([]models.KitchenItem, error) {
+	if _, err := r.GetStation(stationID); err != nil {
+		return nil, err
+	}
+	statuses := kitchenQueueStatuses
+	if status != "" {
+		statuses = []string{status}
+	}
+
+	rows, err := r.db.Query(
+		`SELECT `+kitchenItemColumns+` FROM kitchen_items k
+		 JOIN products p ON p.id = k.product_id
+		 WHERE k.station_id = $1 AND ($2 = 0 OR k.outlet_id = $2) AND k.status = ANY($3::text[])
+		 ORDER BY k.created_at, k.id`,
+		stationID, outletID, statuses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.KitchenItem{}
+	for rows.Next() {
+		item, err := scanKitchenItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	return items, rows.Err()
+}
+
+func (r *KitchenRepository) GetItem(id int) (*models.KitchenItem, error) {
+	return scanKitchenItem(r.db.QueryRow(
+		"SELECT "+kitchenItemColumns+" FROM kitchen_items k JOIN products p ON p.id = k.product_id WHERE k.id = $1", id))
+}
+
+// SetItemStatus moves an item into status when it is in one of the from statuses,
+// stamping when preparing started, when it became ready and when it was bumped
+func (r *KitchenRepository) SetItemStatus(id int, status string, from ...string) (*models.KitchenItem, error) {
+	result, err := r.db.Exec(
+		`UPDATE kitchen_items SET status = $1,
+		 started_at = COALESCE(started_at, CASE WHEN $1 = $2 THEN LOCALTIMESTAMP END),
+		 ready_at = CASE WHEN $1 IN ($3, $4) THEN COALESCE(ready_at, LOCALTIMESTAMP) END,
+		 bumped_at = CASE WHEN $1 = $4 THEN LOCALTIMESTAMP END
+		 WHERE id = $5 AND status = ANY($6::text[])`,
+		status, models.KitchenItemPreparing, models.KitchenItemReady, models.KitchenItemBumped, id, from,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		item, err := r.GetItem(id)
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("kitchen item %d is %s", id, item.Status)
+	}
+	return r.GetItem(id)
+}
+
+// replaceStationCategories rewrites the categories a station prepares
+func replaceStationCategories(ctx context.Context, tx *sql.Tx, stationID int, categoryIDs []int) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM kitchen_station_categories WHERE station_id = $1", stationID); err != nil {
+		return err
+	}
+
+	for _, categoryID := range categoryIDs {
+		var station string
+		err := tx.QueryRowContext(ctx,
+			`SELECT s.name FROM kitchen_station_categories c
+			 JOIN kitchen_stations s ON s.id = c.station_id
+			 WHERE c.category_id = $1`, categoryID,
+		).Scan(&station)
+		if err == nil {
+			return fmt.Errorf("category %d is already prepared at station %s", categoryID, station)
+		}
+		if err != sql.ErrNoRows {
+			return err
+		}
+
+		var exists bool
+		if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM categories WHERE id = $1)", categoryID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("category with ID %d not found", categoryID)
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO kitchen_station_categories (station_id, category_id) VALUES ($1, $2)", stationID, categoryID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// routeToKitchen sends items to the stations preparing their products' categories.
+// Items no station prepares are left out. It returns the items sent.
+func routeToKitchen(ctx context.Context, tx *sql.Tx, items []models.KitchenItem) ([]models.KitchenItem, error) {
+	var ids []int
+	for _, item := range items {
+		var id int
+		err := tx.QueryRowContext(ctx,
+			`INSERT INTO kitchen_items (station_id, outlet_id, transaction_id, dine_in_order_id, tables, product_id, quantity, unit, notes)
+			 SELECT c.station_id, $1, NULLIF($2, 0), NULLIF($3, 0), NULLIF($4, ''), p.id, $6, $7, NULLIF($8, '')
+			 FROM products p
+			 JOIN kitchen_station_categories c ON c.category_id = p.category_id
+			 WHERE p.id = $5
+			 RETURNING id`,
+			item.OutletID, item.TransactionID, item.DineInOrderID, item.Tables, item.ProductID, item.Quantity, item.Unit, item.Notes,
+		).Scan(&id)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		"SELECT "+kitchenItemColumns+" FROM kitchen_items k JOIN products p ON p.id = k.product_id WHERE k.id = ANY($1::int[]) ORDER BY k.id", ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sent []models.KitchenItem
+	for rows.Next() {
+		item, err := scanKitchenItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		sent = append(sent, *item)
+	}
+	return sent, rows.Err()
+}
+
+func stationCategories(db *sql.DB, stationID int) ([]int, error) {
+	rows, err := db.Query(
+		"SELECT category_id FROM kitchen_station_categories WHERE station_id = $1 ORDER BY category_id", stationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categoryIDs := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		categoryIDs = append(categoryIDs, id)
+	}
+	return categoryIDs, rows.Err()
+}
+
+func scanKitchenItem(row rowScanner) (*models.KitchenItem, error) {
+	var k models.KitchenItem
+	err := row.Scan(&k.ID, &k.StationID, &k.OutletID, &k.TransactionID, &k.DineInOrderID, &k.Tables,
+		&k.ProductID, &k.ProductName, &k.Quantity, &k.Unit, &k.Notes, &k.Status,
+		&k.CreatedAt, &k.StartedAt, &k.ReadyAt, &k.BumpedAt, &k.AgeSeconds, &k.PrepSeconds)
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
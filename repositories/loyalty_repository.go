@@ -0,0 +1,254 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"time"
+)
+
+type LoyaltyRepository struct {
+	db     *sql.DB
+	params models.LoyaltyParams
+}
+
+func NewLoyaltyRepository(db *sql.DB, params models.LoyaltyParams) *LoyaltyRepository {
+	return &LoyaltyRepository{db: db, params: params}
+}
+
+// GetAccount returns a customer's points balance and ledger. Points past their expiry date
+// are expired first, so the balance is what the customer can redeem now.
+func (r *LoyaltyRepository) GetAccount(customerID int) (*models.LoyaltyAccount, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	balance, err := lockLoyaltyBalance(ctx, tx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	balance, err = expirePoints(ctx, tx, customerID, balance)
+	if err != nil {
+		return nil, err
+	}
+	account := models.LoyaltyAccount{
+		CustomerID:   customerID,
+		Balance:      balance,
+		BalanceValue: max(balance, 0) * r.params.PointValue,
+		Entries:      []models.LoyaltyEntry{},
+	}
+
+	var nextExpiry sql.NullTime
+	err = tx.QueryRowContext(ctx,
+		`SELECT MIN(expires_at), COALESCE(SUM(remaining) FILTER (WHERE expires_at = (
+		            SELECT MIN(expires_at) FROM loyalty_ledger WHERE customer_id = $1 AND remaining > 0)), 0)
+		 FROM loyalty_ledger WHERE customer_id = $1 AND remaining > 0`, customerID,
+	).Scan(&nextExpiry, &account.NextExpiryPoints)
+	if err != nil {
+		return nil, err
+	}
+	if nextExpiry.Valid {
+		account.NextExpiry = &nextExpiry.Time
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id, customer_id, COALESCE(transaction_id, 0), entry_type, points, balance_after, expires_at,
+		        COALESCE(reason, ''), created_at
+		 FROM loyalty_ledger WHERE customer_id = $1
+		 ORDER BY id DESC`, customerID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var e models.LoyaltyEntry
+		var expiresAt sql.NullTime
+		if err := rows.Scan(&e.ID, &e.CustomerID, &e.TransactionID, &e.Type, &e.Points, &e.BalanceAfter, &expiresAt,
+			&e.Reason, &e.CreatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if expiresAt.Valid {
+			e.ExpiresAt = &expiresAt.Time
+		}
+		account.Entries = append(account.Entries, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// lockLoyaltyBalance locks the customer so their points change one transaction at a time,
+// and returns the current balance
+func lockLoyaltyBalance(ctx context.Context, q queryer, customerID int) (int, error) {
+	var balance int
+	err := q.QueryRowContext(ctx,
+		`SELECT COALESCE((SELECT balance_after FROM loyalty_ledger WHERE customer_id = c.id ORDER BY id DESC LIMIT 1), 0)
+		 FROM customers c WHERE c.id = $1 FOR UPDATE`, customerID,
+	).Scan(&balance)
+	return balance, err
+}
+
+// expirePoints writes off the unspent points of every credit past its expiry date,
+// one expire entry per credit, and returns the balance left
+func expirePoints(ctx context.Context, q queryer, customerID, balance int) (int, error) {
+	rows, err := q.QueryContext(ctx,
+		`UPDATE loyalty_ledger l SET remaining = 0
+		 FROM (SELECT id, remaining FROM loyalty_ledger
+		       WHERE customer_id = $1 AND remaining > 0 AND expires_at <= CURRENT_TIMESTAMP
+		       FOR UPDATE) expired
+		 WHERE l.id = expired.id
+		 RETURNING expired.remaining, COALESCE(l.transaction_id, 0), l.created_at`, customerID)
+	if err != nil {
+		return 0, err
+	}
+	var entries []models.LoyaltyEntry
+	for rows.Next() {
+		var points int
+		var earnedAt time.Time
+		e := models.LoyaltyEntry{CustomerID: customerID, Type: models.LoyaltyExpire}
+		if err := rows.Scan(&points, &e.TransactionID, &earnedAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		e.Points = -points
+		e.Reason = fmt.Sprintf("Expired points credited on %s", earnedAt.Format("2006-01-02"))
+		entries = append(entries, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for i := range entries {
+		if balance, err = addLoyaltyEntry(ctx, q, &entries[i], balance); err != nil {
+			return 0, err
+		}
+	}
+	return balance, nil
+}
+
+// addLoyaltyEntry appends entry e to the ledger on top of balance and returns the new balance.
+// Credited points are available until spent, expired or reversed, but first pay off a negative balance.
+func addLoyaltyEntry(ctx context.Context, q queryer, e *models.LoyaltyEntry, balance int) (int, error) {
+	e.BalanceAfter = balance + e.Points
+	remaining := 0
+	if e.Points > 0 {
+		remaining = min(e.Points, max(e.BalanceAfter, 0))
+	}
+	err := q.QueryRowContext(ctx,
+		`INSERT INTO loyalty_ledger (customer_id, transaction_id, entry_type, points, balance_after, remaining, expires_at, reason)
+		 VALUES ($1, NULLIF($2, 0), $3, $4, $5, $6, $7, NULLIF($8, ''))
+		 RETURNING id, created_at`,
+		e.CustomerID, e.TransactionID, e.Type, e.Points, e.BalanceAfter, remaining, e.ExpiresAt, e.Reason,
+	).Scan(&e.ID, &e.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return e.BalanceAfter, nil
+}
+
+// spendPoints takes points out of the customer's unspent credits, those expiring first first.
+// Credits of preferTransactionID go before all others, so a reversal takes back the points it earned.
+func spendPoints(ctx context.Context, q queryer, customerID, points, preferTransactionID int) error {
+	rows, err := q.QueryContext(ctx,
+		`SELECT id, remaining FROM loyalty_ledger
+		 WHERE customer_id = $1 AND remaining > 0
+		 ORDER BY (transaction_id IS NOT DISTINCT FROM NULLIF($2, 0)) DESC, expires_at NULLS LAST, id
+		 FOR UPDATE`, customerID, preferTransactionID)
+	if err != nil {
+		return err
+	}
+	type credit struct{ id, remaining int }
+	var credits []credit
+	for rows.Next() {
+		var c credit
+		if err := rows.Scan(&c.id, &c.remaining); err != nil {
+			rows.Close()
+			return err
+		}
+		credits = append(credits, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, c := range credits {
+		if points <= 0 {
+			break
+		}
+		take := min(points, c.remaining)
+		if _, err := q.ExecContext(ctx, "UPDATE loyalty_ledger SET remaining = remaining - $1 WHERE id = $2", take, c.id); err != nil {
+			return err
+		}
+		points -= take
+	}
+	return nil
+}
+
+// pointsExpiry is when points credited now expire, nil when they never do
+func pointsExpiry(params models.LoyaltyParams) *time.Time {
+	if params.ExpiryDays <= 0 {
+		return nil
+	}
+	t := time.Now().AddDate(0, 0, params.ExpiryDays)
+	return &t
+}
+
+// earnedPoints converts an eligible amount into points with the configured rounding
+func earnedPoints(params models.LoyaltyParams, amount int) int {
+	if params.SpendPerPoint <= 0 || amount <= 0 {
+		return 0
+	}
+	switch params.Rounding {
+	case models.LoyaltyRoundNearest:
+		return (amount + params.SpendPerPoint/2) / params.SpendPerPoint
+	case models.LoyaltyRoundUp:
+		return (amount + params.SpendPerPoint - 1) / params.SpendPerPoint
+	default:
+		return amount / params.SpendPerPoint
+	}
+}
+
+// eligiblePaid is the share of eligibleAmount paid otherwise than with points. Points pay for
+// eligible and excluded items alike, so they take their share off each in proportion.
+func eligiblePaid(eligibleAmount, total, pointsAmount int) int {
+	if total <= 0 {
+		return 0
+	}
+	return int(int64(eligibleAmount) * int64(total-pointsAmount) / int64(total))
+}
+
+// refundedPoints returns the points a refund of t takes back from what it earned and gives back of
+// what it was paid with. A sale given back before it was paid was never credited its points.
+func refundedPoints(t models.Transaction) (reversed, restored int) {
+	if t.PaymentStatus == models.PaymentPaid {
+		reversed = t.PointsEarned
+	}
+	return reversed, t.PointsRedeemed
+}
+
+// redeemPoints checks that the customer can pay with points, and returns the amount they pay
+func redeemPoints(params models.LoyaltyParams, points, balance, total int) (int, error) {
+	if params.PointValue <= 0 {
+		return 0, fmt.Errorf("points redemption is disabled")
+	}
+	if points > balance {
+		return 0, fmt.Errorf("customer has %d points, cannot redeem %d", max(balance, 0), points)
+	}
+	amount := points * params.PointValue
+	if amount > total {
+		return 0, fmt.Errorf("%d points are worth %d, more than the total of %d", points, amount, total)
+	}
+	return amount, nil
+}
@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"strings"
+	"testing"
+
+	"kasir-api/models"
+)
+
+func TestEarnedPoints(t *testing.T) {
+	params := func(rounding string) models.LoyaltyParams {
+		return models.LoyaltyParams{SpendPerPoint: 10000, Rounding: rounding}
+	}
+
+	tests := []struct {
+		name   string
+		params models.LoyaltyParams
+		amount int
+		want   int
+	}{
+		{"down below one point", params(models.LoyaltyRoundDown), 9999, 0},
+		{"down exact", params(models.LoyaltyRoundDown), 10000, 1},
+		{"down drops the rest", params(models.LoyaltyRoundDown), 19999, 1},
+		{"nearest just below half", params(models.LoyaltyRoundNearest), 14999, 1},
+		{"nearest half rounds up", params(models.LoyaltyRoundNearest), 15000, 2},
+		{"nearest below one point", params(models.LoyaltyRoundNearest), 4999, 0},
+		{"up one rupiah over", params(models.LoyaltyRoundUp), 10001, 2},
+		{"up exact", params(models.LoyaltyRoundUp), 20000, 2},
+		{"up a single rupiah", params(models.LoyaltyRoundUp), 1, 1},
+		{"unknown rounding rounds down", params("sideways"), 19999, 1},
+		{"nothing spent", params(models.LoyaltyRoundUp), 0, 0},
+		{"negative amount", params(models.LoyaltyRoundUp), -5000, 0},
+		{"earning disabled", models.LoyaltyParams{Rounding: models.LoyaltyRoundUp}, 50000, 0},
+	}
+	for _, tt := range tests {
+		if got := earnedPoints(tt.params, tt.amount); got != tt.want {
+			t.Errorf("%s: earnedPoints(%d) = %d, want %d", tt.name, tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestRedeemPoints(t *testing.T) {
+	params := models.LoyaltyParams{PointValue: 100}
+
+	tests := []struct {
+		name    string
+		params  models.LoyaltyParams
+		points  int
+		balance int
+		total   int
+		want    int
+		wantErr string
+	}{
+		{"part of the balance", params, 30, 100, 50000, 3000, ""},
+		{"whole balance", params, 100, 100, 50000, 10000, ""},
+		{"whole total", params, 500, 800, 50000, 50000, ""},
+		{"more than the balance", params, 101, 100, 50000, 0, "customer has 100 points, cannot redeem 101"},
+		{"negative balance", params, 1, -20, 50000, 0, "customer has 0 points, cannot redeem 1"},
+		{"worth more than the total", params, 501, 800, 50000, 0, "more than the total of 50000"},
+		{"redemption disabled", models.LoyaltyParams{}, 10, 100, 50000, 0, "disabled"},
+	}
+	for _, tt := range tests {
+		got, err := redeemPoints(tt.params, tt.points, tt.balance, tt.total)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: got %d, %v, want error %q", tt.name, got, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s: got %d, %v, want %d", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestEligiblePaid(t *testing.T) {
+	tests := []struct {
+		name                          string
+		eligible, total, pointsAmount int
+		want                          int
+	}{
+		{"no points", 80000, 100000, 0, 80000},
+		{"points share the eligible part", 80000, 100000, 25000, 60000},
+		{"rounds down", 10000, 30000, 10000, 6666},
+		{"all paid with points", 80000, 100000, 100000, 0},
+		{"nothing eligible", 0, 100000, 25000, 0},
+		{"empty sale", 0, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := eligiblePaid(tt.eligible, tt.total, tt.pointsAmount); got != tt.want {
+			t.Errorf("%s: eligiblePaid(%d, %d, %d) = %d, want %d", tt.name, tt.eligible, tt.total, tt.pointsAmount, got, tt.want)
+		}
+	}
+}
+
+func TestRefundedPoints(t *testing.T) {
+	tests := []struct {
+		name         string
+		t            models.Transaction
+		wantReversed int
+		wantRestored int
+	}{
+		{"paid sale", models.Transaction{PaymentStatus: models.PaymentPaid, PointsEarned: 5, PointsRedeemed: 30}, 5, 30},
+		{"paid without redeeming", models.Transaction{PaymentStatus: models.PaymentPaid, PointsEarned: 5}, 5, 0},
+		{"never paid", models.Transaction{PaymentStatus: models.PaymentPending, PointsEarned: 5, PointsRedeemed: 30}, 0, 30},
+		{"no points", models.Transaction{PaymentStatus: models.PaymentPaid}, 0, 0},
+	}
+	for _, tt := range tests {
+		reversed, restored := refundedPoints(tt.t)
+		if reversed != tt.wantReversed || restored != tt.wantRestored {
+			t.Errorf("%s: reversed %d, restored %d, want %d and %d", tt.name, reversed, restored, tt.wantReversed, tt.wantRestored)
+		}
+	}
+}
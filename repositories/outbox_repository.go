@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"kasir-api/events"
+	"kasir-api/models"
+	"strings"
+	"time"
+)
+
+const outboxColumns = "id, event_id, event_type, payload, occurred_at, attempts, array_to_string(done_sinks, ',')"
+
+// outboxLease is how long a claimed event is left to its dispatcher. Events of a dispatcher that
+// died before finishing them are claimed again once it runs out.
+const outboxLease = time.Minute
+
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// Claim claims up to limit events that are due, oldest first, so that only one dispatcher relays each
+func (r *OutboxRepository) Claim(limit int) ([]models.OutboxEvent, error) {
+	rows, err := r.db.Query(
+		`WITH claimed AS (
+		     UPDATE event_outbox SET next_attempt_at = LOCALTIMESTAMP + make_interval(secs => $1)
+		     WHERE id IN (SELECT id FROM event_outbox
+		                  WHERE dispatched_at IS NULL AND next_attempt_at <= LOCALTIMESTAMP
+		                  ORDER BY id LIMIT $2
+		                  FOR UPDATE SKIP LOCKED)
+		     RETURNING `+outboxColumns+`)
+		 SELECT * FROM claimed ORDER BY id`,
+		outboxLease.Seconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var claimed []models.OutboxEvent
+	for rows.Next() {
+		var e models.OutboxEvent
+		var payload []byte
+		var doneSinks string
+		if err := rows.Scan(&e.ID, &e.EventID, &e.EventType, &payload, &e.OccurredAt, &e.Attempts, &doneSinks); err != nil {
+			return nil, err
+		}
+		e.Payload = payload
+		if doneSinks != "" {
+			e.DoneSinks = strings.Split(doneSinks, ",")
+		}
+		claimed = append(claimed, e)
+	}
+	return claimed, rows.Err()
+}
+
+// RecordAttempt stores the sinks that have received an event. Without attemptErr the event is
+// dispatched, otherwise it is due again after retryIn.
+func (r *OutboxRepository) RecordAttempt(id int64, doneSinks []string, attemptErr string, retryIn time.Duration) error {
+	_, err := r.db.Exec(
+		`UPDATE event_outbox SET attempts = attempts + 1, done_sinks = $1::text[], last_error = NULLIF($2, ''),
+		 dispatched_at = CASE WHEN $2 = '' THEN LOCALTIMESTAMP END,
+		 next_attempt_at = LOCALTIMESTAMP + make_interval(secs => $3)
+		 WHERE id = $4`,
+		nonNilStrings(doneSinks), attemptErr, retryIn.Seconds(), id)
+	return err
+}
+
+// Prune deletes the events dispatched longer than age ago
+func (r *OutboxRepository) Prune(age time.Duration) error {
+	_, err := r.db.Exec(
+		"DELETE FROM event_outbox WHERE dispatched_at < LOCALTIMESTAMP - make_interval(secs => $1)",
+		age.Seconds())
+	return err
+}
+
+// addOutboxEvent records a new event in the outbox within the database transaction of the change it
+// describes, so the event exists if and only if the change is committed
+func addOutboxEvent(ctx context.Context, q queryer, eventType string, data interface{}) error {
+	e := events.New(eventType, data)
+	payload, err := json.Marshal(e.Data)
+	if err != nil {
+		return err
+	}
+	_, err = q.ExecContext(ctx,
+		"INSERT INTO event_outbox (event_id, event_type, payload, occurred_at) VALUES ($1, $2, $3, $4)",
+		e.ID, e.Type, string(payload), e.OccurredAt)
+	return err
+}
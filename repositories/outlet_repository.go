@@ -0,0 +1,240 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"time"
+)
+
+type OutletRepository struct {
+	db *sql.DB
+}
+
+func NewOutletRepository(db *sql.DB) *OutletRepository {
+	return &OutletRepository{db: db}
+}
+
+func (r *OutletRepository) GetAll() ([]models.Outlet, error) {
+	rows, err := r.db.Query("SELECT id, code, name, COALESCE(address, ''), is_default, created_at FROM outlets ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var outlets []models.Outlet
+	for rows.Next() {
+		var o models.Outlet
+		if err := rows.Scan(&o.ID, &o.Code, &o.Name, &o.Address, &o.IsDefault, &o.CreatedAt); err != nil {
+			return nil, err
+		}
+		outlets = append(outlets, o)
+	}
+	return outlets, rows.Err()
+}
+
+func (r *OutletRepository) GetByID(id int) (*models.Outlet, error) {
+	var o models.Outlet
+	err := r.db.QueryRow("SELECT id, code, name, COALESCE(address, ''), is_default, created_at FROM outlets WHERE id = $1", id).
+		Scan(&o.ID, &o.Code, &o.Name, &o.Address, &o.IsDefault, &o.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func (r *OutletRepository) Create(req models.OutletRequest) (*models.Outlet, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if req.IsDefault {
+		if _, err := tx.ExecContext(ctx, "UPDATE outlets SET is_default = FALSE WHERE is_default"); err != nil {
+			return nil, err
+		}
+	}
+
+	var id int
+	err = tx.QueryRowContext(ctx,
+		"INSERT INTO outlets (code, name, address, is_default) VALUES ($1, $2, NULLIF($3, ''), $4) RETURNING id",
+		req.Code, req.Name, req.Address, req.IsDefault,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// Update changes an outlet. Making it the default takes the flag from the previous default,
+// the default outlet itself cannot give it up without another outlet taking over.
+func (r *OutletRepository) Update(id int, req models.OutletRequest) (*models.Outlet, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var isDefault bool
+	err = tx.QueryRowContext(ctx, "SELECT is_default FROM outlets WHERE id = $1 FOR UPDATE", id).Scan(&isDefault)
+	if err != nil {
+		return nil, err
+	}
+	if isDefault && !req.IsDefault {
+		return nil, fmt.Errorf("make another outlet the default first")
+	}
+	if req.IsDefault && !isDefault {
+		if _, err := tx.ExecContext(ctx, "UPDATE outlets SET is_default = FALSE WHERE is_default"); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE outlets SET code = $1, name = $2, address = NULLIF($3, ''), is_default = $4 WHERE id = $5",
+		req.Code, req.Name, req.Address, req.IsDefault, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// Delete removes an outlet that holds no stock and has no history
+func (r *OutletRepository) Delete(id int) error {
+	var isDefault, hasStock bool
+	err := r.db.QueryRow(
+		`SELECT is_default, EXISTS (SELECT 1 FROM outlet_stock WHERE outlet_id = $1 AND stock <> 0)
+		 FROM outlets WHERE id = $1`, id,
+	).Scan(&isDefault, &hasStock)
+	if err != nil {
+		return err
+	}
+	if isDefault {
+		return fmt.Errorf("the default outlet cannot be deleted")
+	}
+	if hasStock {
+		return fmt.Errorf("outlet %d still holds stock", id)
+	}
+	_, err = r.db.Exec("DELETE FROM outlets WHERE id = $1", id)
+	return err
+}
+
+// GetStock lists the stock and prices of every non-bundle product at an outlet
+func (r *OutletRepository) GetStock(outletID int) ([]models.OutletStock, error) {
+	rows, err := r.db.Query(
+		`SELECT o.id, o.name, p.id, p.name, p.unit, COALESCE(os.stock, 0), os.price, COALESCE(os.price, p.price)
+		 FROM outlets o
+		 CROSS JOIN products p
+		 LEFT JOIN outlet_stock os ON os.outlet_id = o.id AND os.product_id = p.id
+		 WHERE o.id = $1 AND NOT p.is_bundle
+		 ORDER BY p.name`, outletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stock []models.OutletStock
+	for rows.Next() {
+		var s models.OutletStock
+		if err := rows.Scan(&s.OutletID, &s.OutletName, &s.ProductID, &s.ProductName, &s.Unit, &s.Stock, &s.Price, &s.EffectivePrice); err != nil {
+			return nil, err
+		}
+		stock = append(stock, s)
+	}
+	return stock, rows.Err()
+}
+
+// SetPrices sets or clears the outlet's price overrides
+func (r *OutletRepository) SetPrices(outletID int, prices []models.OutletPriceRequest) error {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, p := range prices {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO outlet_stock (outlet_id, product_id, stock, price) VALUES ($1, $2, 0, $3)
+			 ON CONFLICT (outlet_id, product_id) DO UPDATE SET price = EXCLUDED.price`,
+			outletID, p.ProductID, p.Price,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetSales summarises sales per outlet, every outlet is listed even without sales
+func (r *OutletRepository) GetSales(startDate, endDate time.Time) ([]models.OutletSales, error) {
+	rows, err := r.db.Query(`
+		SELECT o.id, o.name, COALESCE(SUM(t.total_amount - t.gift_cards_sold), 0), COUNT(t.id),
+		       COALESCE(SUM((SELECT SUM(td.cogs) FROM transaction_details td WHERE td.transaction_id = t.id)), 0)
+		FROM outlets o
+		LEFT JOIN transactions t ON t.outlet_id = o.id AND t.created_at BETWEEN $1 AND $2 AND t.refunded_at IS NULL AND t.payment_status = 'paid'
+		GROUP BY o.id, o.name
+		ORDER BY o.id
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sales []models.OutletSales
+	for rows.Next() {
+		var s models.OutletSales
+		if err := rows.Scan(&s.OutletID, &s.OutletName, &s.TotalRevenue, &s.TotalTransactions, &s.TotalCOGS); err != nil {
+			return nil, err
+		}
+		s.GrossProfit = s.TotalRevenue - s.TotalCOGS
+		s.MarginPercent = models.MarginPercent(s.TotalRevenue, s.GrossProfit)
+		sales = append(sales, s)
+	}
+	return sales, rows.Err()
+}
+
+// resolveOutlet returns the outlet to use, the default outlet when outletID is 0
+func resolveOutlet(ctx context.Context, q queryer, outletID int) (int, error) {
+	var id int
+	err := q.QueryRowContext(ctx,
+		"SELECT id FROM outlets WHERE ($1 = 0 AND is_default) OR id = $1", outletID,
+	).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			if outletID == 0 {
+				return 0, fmt.Errorf("no default outlet configured")
+			}
+			return 0, fmt.Errorf("outlet with ID %d not found", outletID)
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+// lockOutletStock locks a product's stock row at an outlet for the rest of the transaction
+// and returns its stock and price override. A product never stocked there has no row yet.
+func lockOutletStock(ctx context.Context, q queryer, outletID, productID int) (models.Quantity, *int, error) {
+	var stock models.Quantity
+	var price *int
+	err := q.QueryRowContext(ctx,
+		"SELECT stock, price FROM outlet_stock WHERE outlet_id = $1 AND product_id = $2 FOR UPDATE",
+		outletID, productID,
+	).Scan(&stock, &price)
+	if err == sql.ErrNoRows {
+		return 0, nil, nil
+	}
+	return stock, price, err
+}
@@ -0,0 +1,229 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"kasir-api/models"
+	"time"
+)
+
+const paymentColumns = `id, transaction_id, method, gateway, COALESCE(reference, ''), amount, status,
+	COALESCE(qr_payload, ''), expires_at, paid_at, created_at, sale`
+
+type PaymentRepository struct {
+	db *sql.DB
+	// transactions records the sales being paid
+	transactions *TransactionRepository
+}
+
+func NewPaymentRepository(db *sql.DB, transactions *TransactionRepository) *PaymentRepository {
+	return &PaymentRepository{db: db, transactions: transactions}
+}
+
+// GetAll lists payments, newest first, optionally of one status
+func (r *PaymentRepository) GetAll(status string) ([]models.Payment, error) {
+	rows, err := r.db.Query("SELECT "+paymentColumns+" FROM payments WHERE $1 = '' OR status = $1 ORDER BY id DESC", status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []models.Payment{}
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, *payment)
+	}
+	return payments, rows.Err()
+}
+
+func (r *PaymentRepository) GetByID(id int) (*models.Payment, error) {
+	return scanPayment(r.db.QueryRow("SELECT "+paymentColumns+" FROM payments WHERE id = $1", id))
+}
+
+// GetByReference returns the payment a gateway knows by reference
+func (r *PaymentRepository) GetByReference(gateway, reference string) (*models.Payment, error) {
+	return scanPayment(r.db.QueryRow("SELECT "+paymentColumns+" FROM payments WHERE gateway = $1 AND reference = $2", gateway, reference))
+}
+
+// GetDue lists the pending payments whose QRIS has expired
+func (r *PaymentRepository) GetDue() ([]models.Payment, error) {
+	rows, err := r.db.Query(
+		"SELECT "+paymentColumns+" FROM payments WHERE status = $1 AND expires_at <= LOCALTIMESTAMP ORDER BY id",
+		models.PaymentPending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var due []models.Payment
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		due = append(due, *payment)
+	}
+	return due, rows.Err()
+}
+
+// Create records the sale of req as pending and a payment of what is left to pay, expiring after
+// expiry. The sale's stock stays reserved until the payment is paid or released.
+func (r *PaymentRepository) Create(req models.CheckoutRequest, gateway string, expiry time.Duration) (*models.Payment, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	req.AwaitPayment = true
+	transaction, err := r.transactions.checkout(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+	sale, err := json.Marshal(transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	var id int
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO payments (transaction_id, method, gateway, amount, status, sale, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, LOCALTIMESTAMP + make_interval(secs => $7)) RETURNING id`,
+		transaction.ID, models.PaymentMethodQRIS, gateway, transaction.AmountDue, models.PaymentPending, string(sale), expiry.Seconds(),
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// SetQRIS stores the QRIS the gateway created for a payment
+func (r *PaymentRepository) SetQRIS(id int, reference, payload string) (*models.Payment, error) {
+	_, err := r.db.Exec("UPDATE payments SET reference = $1, qr_payload = $2 WHERE id = $3", reference, payload, id)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// MarkPaid settles a payment and its sale, which then goes to the kitchen, earns its points and goes
+// out as a transaction.created event along with its stock.low events. paid is false when the payment
+// was paid already, which is not an error since gateways may notify more than once. Released payments
+// cannot be paid.
+func (r *PaymentRepository) MarkPaid(id int) (payment *models.Payment, paid bool, err error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	payment, err = lockPayment(ctx, tx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	switch payment.Status {
+	case models.PaymentPaid:
+		return payment, false, nil
+	case models.PaymentPending:
+	default:
+		return nil, false, fmt.Errorf("payment %d is %s and cannot be paid", id, payment.Status)
+	}
+
+	t := payment.Transaction
+	t.KitchenItems, err = routeToKitchen(ctx, tx, kitchenItemsFor(t))
+	if err != nil {
+		return nil, false, err
+	}
+	if err := r.transactions.completeSale(ctx, tx, t); err != nil {
+		return nil, false, err
+	}
+
+	sale, err := json.Marshal(t)
+	if err != nil {
+		return nil, false, err
+	}
+	_, err = tx.ExecContext(ctx,
+		"UPDATE payments SET status = $1, paid_at = LOCALTIMESTAMP, sale = $2 WHERE id = $3",
+		models.PaymentPaid, string(sale), id)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+	payment, err = r.GetByID(id)
+	return payment, true, err
+}
+
+// Release ends a pending payment as expired, cancelled or failed. Its sale is reversed like a
+// refund, giving back the reserved stock, but is not reported as refunded.
+func (r *PaymentRepository) Release(id int, status, reason, actor string) (*models.Payment, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	payment, err := lockPayment(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != models.PaymentPending {
+		return nil, fmt.Errorf("payment %d is %s already", id, payment.Status)
+	}
+
+	t, err := lockTransaction(ctx, tx, payment.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.transactions.reverseSale(ctx, tx, *t, reason, actor); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE transactions SET payment_status = $1 WHERE id = $2", status, t.ID); err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx,
+		"UPDATE payments SET status = $1, sale = jsonb_set(sale, '{payment_status}', to_jsonb($1::text)) WHERE id = $2",
+		status, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// lockPayment locks a payment for update
+func lockPayment(ctx context.Context, tx *sql.Tx, id int) (*models.Payment, error) {
+	return scanPayment(tx.QueryRowContext(ctx, "SELECT "+paymentColumns+" FROM payments WHERE id = $1 FOR UPDATE", id))
+}
+
+func scanPayment(row rowScanner) (*models.Payment, error) {
+	var p models.Payment
+	var sale []byte
+	err := row.Scan(&p.ID, &p.TransactionID, &p.Method, &p.Gateway, &p.Reference, &p.Amount, &p.Status,
+		&p.QRISPayload, &p.ExpiresAt, &p.PaidAt, &p.CreatedAt, &sale)
+	if err != nil {
+		return nil, err
+	}
+	p.Transaction = &models.Transaction{}
+	if err := json.Unmarshal(sale, p.Transaction); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
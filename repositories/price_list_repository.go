@@ -0,0 +1,234 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+)
+
+const priceListColumns = "id, name, list_type, COALESCE(customer_group, ''), active, created_at"
+
+type PriceListRepository struct {
+	db *sql.DB
+}
+
+func NewPriceListRepository(db *sql.DB) *PriceListRepository {
+	return &PriceListRepository{db: db}
+}
+
+func (r *PriceListRepository) GetAll() ([]models.PriceList, error) {
+	rows, err := r.db.Query("SELECT " + priceListColumns + " FROM price_lists ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lists []models.PriceList
+	for rows.Next() {
+		var pl models.PriceList
+		if err := rows.Scan(&pl.ID, &pl.Name, &pl.Type, &pl.CustomerGroup, &pl.Active, &pl.CreatedAt); err != nil {
+			return nil, err
+		}
+		lists = append(lists, pl)
+	}
+	return lists, nil
+}
+
+func (r *PriceListRepository) GetByID(id int) (*models.PriceList, error) {
+	var pl models.PriceList
+	err := r.db.QueryRow("SELECT "+priceListColumns+" FROM price_lists WHERE id = $1", id).
+		Scan(&pl.ID, &pl.Name, &pl.Type, &pl.CustomerGroup, &pl.Active, &pl.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(
+		`SELECT i.product_id, p.name, i.unit, i.min_quantity, i.price
+		 FROM price_list_items i
+		 JOIN products p ON p.id = i.product_id
+		 WHERE i.price_list_id = $1
+		 ORDER BY i.product_id, i.unit, i.min_quantity`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.PriceListItem
+		if err := rows.Scan(&item.ProductID, &item.ProductName, &item.Unit, &item.MinQuantity, &item.Price); err != nil {
+			return nil, err
+		}
+		pl.Items = append(pl.Items, item)
+	}
+	return &pl, rows.Err()
+}
+
+func (r *PriceListRepository) Create(req models.PriceListRequest) (*models.PriceList, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO price_lists (name, list_type, customer_group, active) VALUES ($1, $2, NULLIF($3, ''), $4)
+		 RETURNING id`,
+		req.Name, req.Type, req.CustomerGroup, req.Active == nil || *req.Active,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+	if err := replacePriceListItems(ctx, tx, id, req.Items); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// Update changes a price list and replaces its prices. Active is kept when not given.
+func (r *PriceListRepository) Update(id int, req models.PriceListRequest) (*models.PriceList, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		`UPDATE price_lists SET name = $1, list_type = $2, customer_group = NULLIF($3, ''), active = COALESCE($4, active)
+		 WHERE id = $5`,
+		req.Name, req.Type, req.CustomerGroup, req.Active, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, sql.ErrNoRows
+	}
+	if err := replacePriceListItems(ctx, tx, id, req.Items); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// Delete removes a price list, transaction lines it priced keep their prices
+func (r *PriceListRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM price_lists WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// replacePriceListItems rewrites the prices of a price list
+func replacePriceListItems(ctx context.Context, tx *sql.Tx, priceListID int, items []models.PriceListItemRequest) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM price_list_items WHERE price_list_id = $1", priceListID); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		var price int
+		var baseUnit, saleUnit string
+		err := tx.QueryRowContext(ctx,
+			"SELECT price, unit, sale_unit FROM products WHERE id = $1", item.ProductID,
+		).Scan(&price, &baseUnit, &saleUnit)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("product with ID %d not found", item.ProductID)
+			}
+			return err
+		}
+
+		unit := item.Unit
+		if unit == "" {
+			unit = saleUnit
+		}
+		if _, _, err := resolveUnit(ctx, tx, item.ProductID, baseUnit, unit, price); err != nil {
+			return err
+		}
+		minQuantity := item.MinQuantity
+		if minQuantity == 0 {
+			minQuantity = models.NewQuantity(1)
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO price_list_items (price_list_id, product_id, unit, min_quantity, price) VALUES ($1, $2, $3, $4, $5)
+			 ON CONFLICT (price_list_id, product_id, unit, min_quantity) DO UPDATE SET price = EXCLUDED.price`,
+			priceListID, item.ProductID, unit, minQuantity, item.Price,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolvePriceList returns the active price list of a checkout: the one chosen by ID, else the one of the
+// customer group given, else the one of the customer's own group, or 0 when none applies
+func resolvePriceList(ctx context.Context, q queryer, priceListID int, group string, customerID int) (int, error) {
+	var id int
+	var err error
+	switch {
+	case priceListID != 0:
+		var active bool
+		err = q.QueryRowContext(ctx, "SELECT id, active FROM price_lists WHERE id = $1", priceListID).Scan(&id, &active)
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("price list with ID %d not found", priceListID)
+		}
+		if err == nil && !active {
+			return 0, fmt.Errorf("price list with ID %d is inactive", priceListID)
+		}
+	case group != "":
+		err = q.QueryRowContext(ctx,
+			"SELECT id FROM price_lists WHERE customer_group = $1 AND active", group,
+		).Scan(&id)
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("no active price list for customer group %s", group)
+		}
+	case customerID != 0:
+		err = q.QueryRowContext(ctx,
+			`SELECT pl.id FROM price_lists pl
+			 JOIN customers c ON c.customer_group = pl.customer_group
+			 WHERE c.id = $1 AND pl.active`, customerID,
+		).Scan(&id)
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+	}
+	return id, err
+}
+
+// priceFromList returns the price list's price for quantity of a product sold in unit,
+// taking the highest quantity-break tier reached, or nil when the list does not price it
+func priceFromList(ctx context.Context, q queryer, priceListID, productID int, unit string, quantity models.Quantity) (*int, error) {
+	if priceListID == 0 {
+		return nil, nil
+	}
+	var price int
+	err := q.QueryRowContext(ctx,
+		`SELECT price FROM price_list_items
+		 WHERE price_list_id = $1 AND product_id = $2 AND unit = $3 AND min_quantity <= $4
+		 ORDER BY min_quantity DESC LIMIT 1`,
+		priceListID, productID, unit, quantity,
+	).Scan(&price)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &price, nil
+}
@@ -1,10 +1,35 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"kasir-api/events"
 	"kasir-api/models"
 )
 
+// productStockColumn is the stock at the outlet in the outletParam placeholder, or the total over all
+// outlets when it is 0. A bundle's stock is derived per outlet from its most limiting component there.
+func productStockColumn(outletParam string) string {
+	return fmt.Sprintf(`CASE WHEN p.is_bundle THEN COALESCE((
+		SELECT SUM(a.available) FROM (
+		    SELECT GREATEST(MIN(FLOOR(COALESCE(os.stock, 0) / bi.quantity)), 0) AS available
+		    FROM outlets o
+		    CROSS JOIN product_bundle_items bi
+		    LEFT JOIN outlet_stock os ON os.outlet_id = o.id AND os.product_id = bi.component_id
+		    WHERE bi.bundle_id = p.id AND (%[1]s::int = 0 OR o.id = %[1]s::int)
+		    GROUP BY o.id) a), 0)
+	WHEN %[1]s::int = 0 THEN p.stock
+	ELSE COALESCE((SELECT os.stock FROM outlet_stock os WHERE os.outlet_id = %[1]s::int AND os.product_id = p.id), 0) END`, outletParam)
+}
+
+// productColumns are the product columns shared by GetAll and GetByID, with the stock at the outlet
+// in the outletParam placeholder
+func productColumns(outletParam string) string {
+	return "p.id, p.name, COALESCE(p.barcode, ''), p.price, p.cost_price, " + productStockColumn(outletParam) +
+		", p.category_id, p.is_bundle, p.unit, p.sale_unit, p.purchase_unit, p.allow_fraction, p.min_stock, p.reorder_quantity"
+}
+
 type ProductRepository struct {
 	db *sql.DB
 }
@@ -13,18 +38,17 @@ func NewProductRepository(db *sql.DB) *ProductRepository {
 	return &ProductRepository{db: db}
 }
 
-func (r *ProductRepository) GetAll(name string) ([]models.Product, error) {
+// GetAll lists products with their stock at outletID, or their total stock when it is 0
+func (r *ProductRepository) GetAll(name string, outletID int) ([]models.Product, error) {
 	var rows *sql.Rows
 	var err error
 
+	query := "SELECT " + productColumns("$1") + " FROM products p"
 	if name != "" {
 		// Search with ILIKE for case-insensitive matching
-		rows, err = r.db.Query(
-			"SELECT id, name, price, stock, category_id FROM products WHERE name ILIKE $1 ORDER BY id",
-			"%"+name+"%",
-		)
+		rows, err = r.db.Query(query+" WHERE p.name ILIKE $2 ORDER BY p.id", outletID, "%"+name+"%")
 	} else {
-		rows, err = r.db.Query("SELECT id, name, price, stock, category_id FROM products ORDER BY id")
+		rows, err = r.db.Query(query+" ORDER BY p.id", outletID)
 	}
 
 	if err != nil {
@@ -35,53 +59,450 @@ func (r *ProductRepository) GetAll(name string) ([]models.Product, error) {
 	var products []models.Product
 	for rows.Next() {
 		var p models.Product
-		if err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.CategoryID); err != nil {
+		if err := rows.Scan(&p.ID, &p.Name, &p.Barcode, &p.Price, &p.CostPrice, &p.Stock, &p.CategoryID, &p.IsBundle,
+			&p.Unit, &p.SaleUnit, &p.PurchaseUnit, &p.AllowFraction, &p.MinStock, &p.ReorderQuantity); err != nil {
 			return nil, err
 		}
 		products = append(products, p)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 	return products, nil
 }
 
-func (r *ProductRepository) GetByID(id int) (*models.Product, error) {
+// GetByID reads a product with its stock at outletID, or its total stock when it is 0
+func (r *ProductRepository) GetByID(id, outletID int) (*models.Product, error) {
+	return getProduct(context.Background(), r.db, id, outletID)
+}
+
+// getProduct reads a product with its components, units and stock per outlet through q, which may be
+// the transaction that just changed it. Its stock is the stock at outletID, or the total when it is 0.
+func getProduct(ctx context.Context, q queryer, id, outletID int) (*models.Product, error) {
 	var p models.Product
-	err := r.db.QueryRow(
-		`SELECT p.id, p.name, p.price, p.stock, p.category_id, COALESCE(c.name, '') as category_name 
+	err := q.QueryRowContext(ctx,
+		`SELECT `+productColumns("$2")+`, COALESCE(c.name, '') as category_name
 		 FROM products p 
 		 LEFT JOIN categories c ON p.category_id = c.id 
-		 WHERE p.id = $1`, id).
-		Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.CategoryID, &p.CategoryName)
+		 WHERE p.id = $1`, id, outletID).
+		Scan(&p.ID, &p.Name, &p.Barcode, &p.Price, &p.CostPrice, &p.Stock, &p.CategoryID, &p.IsBundle,
+			&p.Unit, &p.SaleUnit, &p.PurchaseUnit, &p.AllowFraction, &p.MinStock, &p.ReorderQuantity, &p.CategoryName)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.IsBundle {
+		p.Components, err = getComponents(ctx, q, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	p.Units, err = getUnits(ctx, q, id)
 	if err != nil {
 		return nil, err
 	}
+
+	if !p.IsBundle {
+		p.Outlets, err = getOutletStock(ctx, q, id)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return &p, nil
 }
 
+// getOutletStock breaks a product's stock and price down per outlet
+func getOutletStock(ctx context.Context, q queryer, productID int) ([]models.OutletStock, error) {
+	rows, err := q.QueryContext(ctx,
+		`SELECT o.id, o.name, p.id, COALESCE(os.stock, 0), os.price, COALESCE(os.price, p.price)
+		 FROM outlets o
+		 JOIN products p ON p.id = $1
+		 LEFT JOIN outlet_stock os ON os.outlet_id = o.id AND os.product_id = p.id
+		 ORDER BY o.id`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stock []models.OutletStock
+	for rows.Next() {
+		var s models.OutletStock
+		if err := rows.Scan(&s.OutletID, &s.OutletName, &s.ProductID, &s.Stock, &s.Price, &s.EffectivePrice); err != nil {
+			return nil, err
+		}
+		stock = append(stock, s)
+	}
+	return stock, nil
+}
+
 func (r *ProductRepository) Create(req models.ProductRequest) (*models.Product, error) {
-	var p models.Product
-	err := r.db.QueryRow(
-		"INSERT INTO products (name, price, stock, category_id) VALUES ($1, $2, $3, $4) RETURNING id, name, price, stock, category_id",
-		req.Name, req.Price, req.Stock, req.CategoryID,
-	).Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.CategoryID)
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
-	return &p, nil
+	defer tx.Rollback()
+
+	isBundle := len(req.Components) > 0
+	var stock models.Quantity
+	if req.Stock != nil && !isBundle {
+		// A bundle holds no stock of its own
+		stock = *req.Stock
+	}
+
+	var id int
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO products (name, barcode, price, cost_price, stock, category_id, is_bundle, unit, sale_unit, purchase_unit, allow_fraction,
+		     min_stock, reorder_quantity)
+		 VALUES ($1, NULLIF($2, ''), $3, $4, 0, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id`,
+		req.Name, req.Barcode, req.Price, req.CostPrice, req.CategoryID, isBundle, req.Unit, req.SaleUnit, req.PurchaseUnit, req.AllowFraction,
+		req.MinStock, req.ReorderQuantity,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	if stock != 0 {
+		_, err = applyStockMovement(ctx, tx, models.StockMovement{
+			ProductID: id,
+			OutletID:  req.OutletID,
+			Type:      models.MovementAdjustment,
+			Quantity:  stock,
+			Reason:    "Opening stock",
+			Actor:     req.Actor,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := recordCostPrice(ctx, tx, id, req.CostPrice, "initial"); err != nil {
+		return nil, err
+	}
+
+	if isBundle {
+		if err := replaceComponents(ctx, tx, id, req.Components); err != nil {
+			return nil, err
+		}
+	}
+	if err := replaceUnits(ctx, tx, id, req.Units); err != nil {
+		return nil, err
+	}
+
+	product, err := getProduct(ctx, tx, id, req.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	if err := addOutboxEvent(ctx, tx, events.TypeProductCreated, *product); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return product, nil
 }
 
 func (r *ProductRepository) Update(id int, req models.ProductRequest) (*models.Product, error) {
-	var p models.Product
-	err := r.db.QueryRow(
-		"UPDATE products SET name = $1, price = $2, stock = $3, category_id = $4 WHERE id = $5 RETURNING id, name, price, stock, category_id",
-		req.Name, req.Price, req.Stock, req.CategoryID, id,
-	).Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.CategoryID)
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
-	return &p, nil
+	defer tx.Rollback()
+
+	var oldCost int
+	var wasBundle bool
+	err = tx.QueryRowContext(ctx, "SELECT cost_price, is_bundle FROM products WHERE id = $1 FOR UPDATE", id).Scan(&oldCost, &wasBundle)
+	if err != nil {
+		return nil, err
+	}
+
+	// Components are only replaced when sent, an explicit empty list un-bundles the product
+	isBundle := wasBundle
+	if req.Components != nil {
+		isBundle = len(req.Components) > 0
+	}
+
+	// A bundle holds no stock of its own, so stock left at any outlet has to be adjusted away first
+	if isBundle && !wasBundle {
+		var hasStock bool
+		err = tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM outlet_stock WHERE product_id = $1 AND stock <> 0)", id).Scan(&hasStock)
+		if err != nil {
+			return nil, err
+		}
+		if hasStock {
+			return nil, fmt.Errorf("product %d still has stock at an outlet, adjust it to zero before turning it into a bundle", id)
+		}
+	}
+
+	// Stock only changes through the ledger's adjustments, so a stock that is sent back has to match the
+	// current one instead of being dropped silently
+	if req.Stock != nil {
+		var current models.Quantity
+		err = tx.QueryRowContext(ctx, "SELECT "+productStockColumn("$2")+" FROM products p WHERE p.id = $1", id, req.OutletID).Scan(&current)
+		if err != nil {
+			return nil, err
+		}
+		if *req.Stock != current {
+			return nil, fmt.Errorf("stock of product %d is %s, change it through POST /products/%d/adjustments", id, current, id)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE products SET name = $1, barcode = NULLIF($2, ''), price = $3, cost_price = $4, category_id = $5, is_bundle = $6,
+		 unit = $7, sale_unit = $8, purchase_unit = $9, allow_fraction = $10, min_stock = $11, reorder_quantity = $12 WHERE id = $13`,
+		req.Name, req.Barcode, req.Price, req.CostPrice, req.CategoryID, isBundle, req.Unit, req.SaleUnit, req.PurchaseUnit, req.AllowFraction,
+		req.MinStock, req.ReorderQuantity, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.CostPrice != oldCost {
+		if err := recordCostPrice(ctx, tx, id, req.CostPrice, "manual"); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Components != nil {
+		if err := replaceComponents(ctx, tx, id, req.Components); err != nil {
+			return nil, err
+		}
+	}
+	if err := replaceUnits(ctx, tx, id, req.Units); err != nil {
+		return nil, err
+	}
+
+	product, err := getProduct(ctx, tx, id, req.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	if err := addOutboxEvent(ctx, tx, events.TypeProductUpdated, *product); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return product, nil
 }
 
 func (r *ProductRepository) Delete(id int) error {
-	_, err := r.db.Exec("DELETE FROM products WHERE id = $1", id)
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM products WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		if err := addOutboxEvent(ctx, tx, events.TypeProductDeleted, map[string]int{"id": id}); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *ProductRepository) GetCostHistory(productID int) ([]models.ProductCost, error) {
+	rows, err := r.db.Query(
+		"SELECT id, product_id, cost_price, reason, effective_at FROM product_cost_history WHERE product_id = $1 ORDER BY effective_at DESC, id DESC",
+		productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []models.ProductCost
+	for rows.Next() {
+		var c models.ProductCost
+		if err := rows.Scan(&c.ID, &c.ProductID, &c.CostPrice, &c.Reason, &c.EffectiveAt); err != nil {
+			return nil, err
+		}
+		history = append(history, c)
+	}
+	return history, nil
+}
+
+// GetLowStock lists products with a minimum stock whose stock is at or below it, emptiest first.
+// With an outletID the stock at that outlet is compared, otherwise the total stock.
+func (r *ProductRepository) GetLowStock(outletID int) ([]models.LowStockItem, error) {
+	rows, err := r.db.Query(
+		`SELECT p.id, p.name, p.unit, $1::int, s.stock, p.min_stock, p.reorder_quantity
+		 FROM products p
+		 CROSS JOIN LATERAL (
+		     SELECT CASE WHEN $1 = 0 THEN p.stock
+		                 ELSE COALESCE((SELECT stock FROM outlet_stock WHERE outlet_id = $1 AND product_id = p.id), 0) END AS stock
+		 ) s
+		 WHERE NOT p.is_bundle AND p.min_stock > 0 AND s.stock <= p.min_stock
+		 ORDER BY s.stock / p.min_stock, p.name`, outletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.LowStockItem
+	for rows.Next() {
+		var i models.LowStockItem
+		if err := rows.Scan(&i.ProductID, &i.ProductName, &i.Unit, &i.OutletID, &i.Stock, &i.MinStock, &i.ReorderQuantity); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	return items, nil
+}
+
+// GetBatches lists the batches of a product in first-expired-first-out order, at one outlet
+// or at all outlets when outletID is 0. Used up batches are only included when all is true.
+func (r *ProductRepository) GetBatches(productID, outletID int, all bool) ([]models.ProductBatch, error) {
+	rows, err := r.db.Query(
+		`SELECT id, product_id, outlet_id, batch_number, COALESCE(TO_CHAR(expiry_date, 'YYYY-MM-DD'), ''), quantity, received_quantity,
+		        COALESCE(expiry_date < CURRENT_DATE, false), received_at
+		 FROM product_batches
+		 WHERE product_id = $1 AND ($3 = 0 OR outlet_id = $3) AND ($2 OR quantity > 0)
+		 ORDER BY expiry_date NULLS LAST, id`,
+		productID, all, outletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batches []models.ProductBatch
+	for rows.Next() {
+		var b models.ProductBatch
+		if err := rows.Scan(&b.ID, &b.ProductID, &b.OutletID, &b.BatchNumber, &b.ExpiryDate, &b.Quantity, &b.ReceivedQuantity,
+			&b.Expired, &b.ReceivedAt); err != nil {
+			return nil, err
+		}
+		batches = append(batches, b)
+	}
+	return batches, nil
+}
+
+func getComponents(ctx context.Context, q queryer, bundleID int) ([]models.BundleComponent, error) {
+	rows, err := q.QueryContext(ctx,
+		`SELECT bi.component_id, c.name, bi.quantity
+		 FROM product_bundle_items bi
+		 JOIN products c ON c.id = bi.component_id
+		 WHERE bi.bundle_id = $1
+		 ORDER BY bi.id`, bundleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var components []models.BundleComponent
+	for rows.Next() {
+		var c models.BundleComponent
+		if err := rows.Scan(&c.ProductID, &c.ProductName, &c.Quantity); err != nil {
+			return nil, err
+		}
+		components = append(components, c)
+	}
+	return components, nil
+}
+
+func getUnits(ctx context.Context, q queryer, productID int) ([]models.ProductUnit, error) {
+	rows, err := q.QueryContext(ctx,
+		"SELECT name, factor, price FROM product_units WHERE product_id = $1 ORDER BY factor",
+		productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var units []models.ProductUnit
+	for rows.Next() {
+		var u models.ProductUnit
+		var price sql.NullInt64
+		if err := rows.Scan(&u.Name, &u.Factor, &price); err != nil {
+			return nil, err
+		}
+		if price.Valid {
+			p := int(price.Int64)
+			u.Price = &p
+		}
+		units = append(units, u)
+	}
+	return units, nil
+}
+
+// replaceComponents rewrites the component list of a bundle, rejecting nested bundles
+func replaceComponents(ctx context.Context, tx *sql.Tx, bundleID int, components []models.BundleComponent) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM product_bundle_items WHERE bundle_id = $1", bundleID); err != nil {
+		return err
+	}
+	if len(components) == 0 {
+		return nil
+	}
+
+	var usedAsComponent bool
+	err := tx.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM product_bundle_items WHERE component_id = $1)", bundleID,
+	).Scan(&usedAsComponent)
+	if err != nil {
+		return err
+	}
+	if usedAsComponent {
+		return fmt.Errorf("product with ID %d is a component of another bundle", bundleID)
+	}
+
+	for _, c := range components {
+		if c.ProductID == bundleID {
+			return fmt.Errorf("bundle cannot contain itself")
+		}
+
+		var isBundle bool
+		err := tx.QueryRowContext(ctx, "SELECT is_bundle FROM products WHERE id = $1", c.ProductID).Scan(&isBundle)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("component product with ID %d not found", c.ProductID)
+			}
+			return err
+		}
+		if isBundle {
+			return fmt.Errorf("component product with ID %d is itself a bundle", c.ProductID)
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO product_bundle_items (bundle_id, component_id, quantity) VALUES ($1, $2, $3)",
+			bundleID, c.ProductID, c.Quantity,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceUnits rewrites the alternative units of a product
+func replaceUnits(ctx context.Context, tx *sql.Tx, productID int, units []models.ProductUnit) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM product_units WHERE product_id = $1", productID); err != nil {
+		return err
+	}
+
+	for _, u := range units {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO product_units (product_id, name, factor, price) VALUES ($1, $2, $3, $4)",
+			productID, u.Name, u.Factor, u.Price,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordCostPrice appends an entry to the cost price history of a product
+func recordCostPrice(ctx context.Context, q queryer, productID, costPrice int, reason string) error {
+	_, err := q.ExecContext(ctx,
+		"INSERT INTO product_cost_history (product_id, cost_price, reason) VALUES ($1, $2, $3)",
+		productID, costPrice, reason,
+	)
 	return err
 }
@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"strings"
+	"testing"
+
+	"kasir-api/models"
+)
+
+// TestBundleStockPerOutlet checks that a bundle counts only the components held at the selected
+// outlet, and sums the outlets when none is selected
+func TestBundleStockPerOutlet(t *testing.T) {
+	db := testDB(t)
+	products := NewProductRepository(db)
+
+	mainID := queryInt(t, db, "SELECT id FROM outlets WHERE is_default")
+	branchID := queryInt(t, db, "INSERT INTO outlets (code, name) VALUES ('BDG', 'Bandung') RETURNING id")
+	coffeeID := queryInt(t, db, "INSERT INTO products (name, price, stock) VALUES ('Coffee', 10000, 7) RETURNING id")
+	breadID := queryInt(t, db, "INSERT INTO products (name, price, stock) VALUES ('Bread', 8000, 3) RETURNING id")
+	// Coffee 6 at main and 1 at the branch, bread 0 at main and 3 at the branch
+	queryInt(t, db, `INSERT INTO outlet_stock (outlet_id, product_id, stock)
+		VALUES ($1, $3, 6), ($2, $3, 1), ($2, $4, 3) RETURNING 1`, mainID, branchID, coffeeID, breadID)
+
+	bundle, err := products.Create(models.ProductRequest{
+		Name: "Breakfast", Price: 15000, Unit: models.DefaultUnit, SaleUnit: models.DefaultUnit, PurchaseUnit: models.DefaultUnit,
+		Components: []models.BundleComponent{
+			{ProductID: coffeeID, Quantity: models.NewQuantity(2)},
+			{ProductID: breadID, Quantity: models.NewQuantity(1)},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		outletID int
+		want     models.Quantity
+	}{
+		{"main has no bread", mainID, 0},
+		{"branch is short of coffee", branchID, 0},
+		{"all outlets", 0, 0},
+	}
+	for _, tt := range tests {
+		p, err := products.GetByID(bundle.ID, tt.outletID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Stock != tt.want {
+			t.Errorf("%s: stock %s, want %s", tt.name, p.Stock, tt.want)
+		}
+	}
+
+	// Two more coffees at the branch make one bundle there, none at main
+	queryInt(t, db, "UPDATE outlet_stock SET stock = 3 WHERE outlet_id = $1 AND product_id = $2 RETURNING 1", branchID, coffeeID)
+	for outletID, want := range map[int]models.Quantity{mainID: 0, branchID: models.NewQuantity(1), 0: models.NewQuantity(1)} {
+		p, err := products.GetByID(bundle.ID, outletID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Stock != want {
+			t.Errorf("outlet %d: stock %s, want %s", outletID, p.Stock, want)
+		}
+	}
+}
+
+// TestUpdateStock checks that an update leaves stock to the adjustments and refuses a stock that
+// differs from the current one
+func TestUpdateStock(t *testing.T) {
+	db := testDB(t)
+	products := NewProductRepository(db)
+
+	opening := models.NewQuantity(5)
+	p, err := products.Create(models.ProductRequest{
+		Name: "Tea", Price: 5000, Stock: &opening, Unit: models.DefaultUnit, SaleUnit: models.DefaultUnit, PurchaseUnit: models.DefaultUnit,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := models.ProductRequest{Name: "Green Tea", Price: 6000, Unit: models.DefaultUnit, SaleUnit: models.DefaultUnit, PurchaseUnit: models.DefaultUnit}
+	if _, err := products.Update(p.ID, req); err != nil {
+		t.Fatalf("update without stock: %v", err)
+	}
+
+	req.Stock = &opening
+	if _, err := products.Update(p.ID, req); err != nil {
+		t.Fatalf("update with the current stock: %v", err)
+	}
+
+	changed := models.NewQuantity(9)
+	req.Stock = &changed
+	_, err = products.Update(p.ID, req)
+	if err == nil || !strings.Contains(err.Error(), "/adjustments") {
+		t.Fatalf("update changing stock: got %v, want an error pointing to the adjustments", err)
+	}
+
+	got, err := products.GetByID(p.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Stock != opening || got.Name != "Green Tea" {
+		t.Fatalf("product is %q with stock %s, want Green Tea with %s", got.Name, got.Stock, opening)
+	}
+}
+
+// TestUpdateKeepsComponents checks that an update without components keeps a bundle and that an
+// explicit empty list un-bundles it
+func TestUpdateKeepsComponents(t *testing.T) {
+	db := testDB(t)
+	products := NewProductRepository(db)
+
+	componentID := queryInt(t, db, "INSERT INTO products (name, price) VALUES ('Coffee', 10000) RETURNING id")
+	req := models.ProductRequest{
+		Name: "Coffee Pack", Price: 25000, Unit: models.DefaultUnit, SaleUnit: models.DefaultUnit, PurchaseUnit: models.DefaultUnit,
+		Components: []models.BundleComponent{{ProductID: componentID, Quantity: models.NewQuantity(3)}},
+	}
+	bundle, err := products.Create(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Components = nil
+	req.Price = 24000
+	p, err := products.Update(bundle.ID, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.IsBundle || len(p.Components) != 1 {
+		t.Fatalf("update without components: bundle %v with %d components, want a bundle with 1", p.IsBundle, len(p.Components))
+	}
+
+	req.Components = []models.BundleComponent{}
+	p, err = products.Update(bundle.ID, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.IsBundle || len(p.Components) != 0 {
+		t.Fatalf("update with no components: bundle %v with %d components, want a plain product", p.IsBundle, len(p.Components))
+	}
+}
@@ -0,0 +1,235 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+)
+
+const purchaseOrderColumns = `po.id, po.supplier_id, s.name, po.status, COALESCE(po.notes, ''),
+	COALESCE(TO_CHAR(po.expected_date, 'YYYY-MM-DD'), ''), po.total_amount, COALESCE(po.created_by, ''), po.created_at, po.updated_at`
+
+type PurchaseOrderRepository struct {
+	db *sql.DB
+}
+
+func NewPurchaseOrderRepository(db *sql.DB) *PurchaseOrderRepository {
+	return &PurchaseOrderRepository{db: db}
+}
+
+func (r *PurchaseOrderRepository) GetAll(status string) ([]models.PurchaseOrder, error) {
+	query := "SELECT " + purchaseOrderColumns + " FROM purchase_orders po JOIN suppliers s ON s.id = po.supplier_id"
+	var rows *sql.Rows
+	var err error
+	if status != "" {
+		rows, err = r.db.Query(query+" WHERE po.status = $1 ORDER BY po.id DESC", status)
+	} else {
+		rows, err = r.db.Query(query + " ORDER BY po.id DESC")
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []models.PurchaseOrder
+	for rows.Next() {
+		var po models.PurchaseOrder
+		if err := rows.Scan(&po.ID, &po.SupplierID, &po.SupplierName, &po.Status, &po.Notes,
+			&po.ExpectedDate, &po.TotalAmount, &po.CreatedBy, &po.CreatedAt, &po.UpdatedAt); err != nil {
+			return nil, err
+		}
+		orders = append(orders, po)
+	}
+	return orders, nil
+}
+
+func (r *PurchaseOrderRepository) GetByID(id int) (*models.PurchaseOrder, error) {
+	var po models.PurchaseOrder
+	err := r.db.QueryRow(
+		"SELECT "+purchaseOrderColumns+" FROM purchase_orders po JOIN suppliers s ON s.id = po.supplier_id WHERE po.id = $1", id,
+	).Scan(&po.ID, &po.SupplierID, &po.SupplierName, &po.Status, &po.Notes,
+		&po.ExpectedDate, &po.TotalAmount, &po.CreatedBy, &po.CreatedAt, &po.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(
+		`SELECT l.id, l.product_id, p.name, l.quantity, l.unit, l.unit_factor, l.unit_cost, l.subtotal, l.received_quantity
+		 FROM purchase_order_lines l
+		 JOIN products p ON p.id = l.product_id
+		 WHERE l.purchase_order_id = $1
+		 ORDER BY l.id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l models.PurchaseOrderLine
+		if err := rows.Scan(&l.ID, &l.ProductID, &l.ProductName, &l.Quantity, &l.Unit, &l.UnitFactor,
+			&l.UnitCost, &l.Subtotal, &l.ReceivedQuantity); err != nil {
+			return nil, err
+		}
+		po.Lines = append(po.Lines, l)
+	}
+	return &po, nil
+}
+
+func (r *PurchaseOrderRepository) Create(req models.PurchaseOrderRequest) (*models.PurchaseOrder, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO purchase_orders (supplier_id, status, notes, expected_date, created_by)
+		 VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, '')::date, NULLIF($5, '')) RETURNING id`,
+		req.SupplierID, models.POStatusDraft, req.Notes, req.ExpectedDate, req.Actor,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := replacePurchaseOrderLines(ctx, tx, id, req.Lines); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// Update edits a purchase order, which is only allowed while it is a draft
+func (r *PurchaseOrderRepository) Update(id int, req models.PurchaseOrderRequest) (*models.PurchaseOrder, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	status, err := lockPurchaseOrder(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if status != models.POStatusDraft {
+		return nil, fmt.Errorf("purchase order %d is %s, only drafts can be edited", id, status)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE purchase_orders SET supplier_id = $1, notes = NULLIF($2, ''), expected_date = NULLIF($3, '')::date,
+		 updated_at = CURRENT_TIMESTAMP WHERE id = $4`,
+		req.SupplierID, req.Notes, req.ExpectedDate, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := replacePurchaseOrderLines(ctx, tx, id, req.Lines); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// UpdateStatus moves a purchase order to status, provided its current status is one of from
+func (r *PurchaseOrderRepository) UpdateStatus(id int, from []string, status string) (*models.PurchaseOrder, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	current, err := lockPurchaseOrder(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	allowed := false
+	for _, f := range from {
+		if current == f {
+			allowed = true
+		}
+	}
+	if !allowed {
+		return nil, fmt.Errorf("purchase order %d cannot change from %s to %s", id, current, status)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE purchase_orders SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2", status, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// lockPurchaseOrder locks a purchase order for the rest of the transaction and returns its status
+func lockPurchaseOrder(ctx context.Context, tx *sql.Tx, id int) (string, error) {
+	var status string
+	err := tx.QueryRowContext(ctx, "SELECT status FROM purchase_orders WHERE id = $1 FOR UPDATE", id).Scan(&status)
+	return status, err
+}
+
+// replacePurchaseOrderLines rewrites the lines of a purchase order and its total.
+// The unit conversion factor is snapshotted so later receipts convert consistently.
+func replacePurchaseOrderLines(ctx context.Context, tx *sql.Tx, poID int, lines []models.PurchaseOrderLineRequest) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM purchase_order_lines WHERE purchase_order_id = $1", poID); err != nil {
+		return err
+	}
+
+	total := 0
+	for _, line := range lines {
+		var price int
+		var baseUnit, purchaseUnit string
+		var isBundle bool
+		err := tx.QueryRowContext(ctx,
+			"SELECT price, unit, purchase_unit, is_bundle FROM products WHERE id = $1", line.ProductID,
+		).Scan(&price, &baseUnit, &purchaseUnit, &isBundle)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("product with ID %d not found", line.ProductID)
+			}
+			return err
+		}
+		if isBundle {
+			return fmt.Errorf("product with ID %d is a bundle, order its components instead", line.ProductID)
+		}
+
+		unit := line.Unit
+		if unit == "" {
+			unit = purchaseUnit
+		}
+		factor, _, err := resolveUnit(ctx, tx, line.ProductID, baseUnit, unit, price)
+		if err != nil {
+			return err
+		}
+
+		subtotal, err := line.Quantity.MulPrice(line.UnitCost)
+		if err != nil {
+			return err
+		}
+		total += subtotal
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO purchase_order_lines (purchase_order_id, product_id, quantity, unit, unit_factor, unit_cost, subtotal)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			poID, line.ProductID, line.Quantity, unit, factor, line.UnitCost, subtotal,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.ExecContext(ctx, "UPDATE purchase_orders SET total_amount = $1 WHERE id = $2", total, poID)
+	return err
+}
@@ -0,0 +1,293 @@
+package repositories
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"slices"
+	"strings"
+	"time"
+)
+
+// openInvoicesQuery lists a customer's on-account transactions that are not refunded or fully repaid, oldest first
+const openInvoicesQuery = `SELECT id, created_at, on_account_amount, on_account_paid, CURRENT_DATE - created_at::date
+	FROM transactions
+	WHERE customer_id = $1 AND refunded_at IS NULL AND on_account_amount > on_account_paid
+	ORDER BY created_at, id`
+
+type ReceivableRepository struct {
+	db *sql.DB
+}
+
+func NewReceivableRepository(db *sql.DB) *ReceivableRepository {
+	return &ReceivableRepository{db: db}
+}
+
+// GetAccount returns a customer's credit limit, what they owe and their open invoices
+func (r *ReceivableRepository) GetAccount(customerID int) (*models.CustomerAccount, error) {
+	account := models.CustomerAccount{CustomerID: customerID, OpenInvoices: []models.OpenInvoice{}}
+	err := r.db.QueryRow("SELECT name, credit_limit FROM customers WHERE id = $1", customerID).
+		Scan(&account.CustomerName, &account.CreditLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(openInvoicesQuery, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var inv models.OpenInvoice
+		if err := rows.Scan(&inv.TransactionID, &inv.Date, &inv.Amount, &inv.Paid, &inv.AgeDays); err != nil {
+			return nil, err
+		}
+		inv.Outstanding = inv.Amount - inv.Paid
+		account.Outstanding += inv.Outstanding
+		account.OpenInvoices = append(account.OpenInvoices, inv)
+	}
+	account.AvailableCredit = max(account.CreditLimit-account.Outstanding, 0)
+	return &account, rows.Err()
+}
+
+// RecordPayment records a repayment and settles the customer's oldest open invoices with it.
+// A payment above what the customer owes is refused.
+func (r *ReceivableRepository) RecordPayment(customerID int, req models.CustomerPaymentRequest) (*models.CustomerPayment, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// The customer row serialises payments and on-account checkouts of the same customer
+	var id int
+	if err := tx.QueryRowContext(ctx, "SELECT id FROM customers WHERE id = $1 FOR UPDATE", customerID).Scan(&id); err != nil {
+		return nil, err
+	}
+	outstanding, err := accountOutstanding(ctx, tx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Amount > outstanding {
+		return nil, fmt.Errorf("payment of %d exceeds the outstanding balance of %d", req.Amount, outstanding)
+	}
+
+	payment := models.CustomerPayment{
+		CustomerID:  customerID,
+		Amount:      req.Amount,
+		Method:      req.Method,
+		Notes:       req.Notes,
+		Actor:       req.Actor,
+		Allocations: []models.PaymentAllocation{},
+		Outstanding: outstanding - req.Amount,
+	}
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO customer_payments (customer_id, amount, method, notes, actor)
+		 VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, '')) RETURNING id, created_at`,
+		customerID, req.Amount, req.Method, req.Notes, req.Actor,
+	).Scan(&payment.ID, &payment.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, openInvoicesQuery+" FOR UPDATE", customerID)
+	if err != nil {
+		return nil, err
+	}
+	var invoices []models.OpenInvoice
+	for rows.Next() {
+		var inv models.OpenInvoice
+		if err := rows.Scan(&inv.TransactionID, &inv.Date, &inv.Amount, &inv.Paid, &inv.AgeDays); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		invoices = append(invoices, inv)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	left := req.Amount
+	for _, inv := range invoices {
+		if left == 0 {
+			break
+		}
+		amount := min(inv.Amount-inv.Paid, left)
+		left -= amount
+		_, err := tx.ExecContext(ctx,
+			"UPDATE transactions SET on_account_paid = on_account_paid + $1 WHERE id = $2", amount, inv.TransactionID)
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO customer_payment_allocations (payment_id, transaction_id, amount) VALUES ($1, $2, $3)",
+			payment.ID, inv.TransactionID, amount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		payment.Allocations = append(payment.Allocations, models.PaymentAllocation{TransactionID: inv.TransactionID, Amount: amount})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// GetStatement lists a customer's charges, payments and refunds between start and end with a running
+// balance. The opening balance is everything before start.
+func (r *ReceivableRepository) GetStatement(customerID int, start, end time.Time) (*models.CustomerStatement, error) {
+	statement := models.CustomerStatement{
+		CustomerID: customerID,
+		StartDate:  start.Format("2006-01-02"),
+		EndDate:    end.Format("2006-01-02"),
+		Lines:      []models.StatementLine{},
+	}
+	err := r.db.QueryRow("SELECT name FROM customers WHERE id = $1", customerID).Scan(&statement.CustomerName)
+	if err != nil {
+		return nil, err
+	}
+
+	// A refund writes off what was still unpaid of the charge when it was refunded
+	rows, err := r.db.Query(
+		`SELECT created_at, $2, id, on_account_amount, 0 FROM transactions
+		 WHERE customer_id = $1 AND on_account_amount > 0 AND created_at <= $5
+		 UNION ALL
+		 SELECT created_at, $3, id, 0, amount FROM customer_payments
+		 WHERE customer_id = $1 AND created_at <= $5
+		 UNION ALL
+		 SELECT refunded_at, $4, id, 0, on_account_amount - on_account_paid FROM transactions
+		 WHERE customer_id = $1 AND refunded_at <= $5 AND on_account_amount > on_account_paid
+		 ORDER BY 1, 2, 3`,
+		customerID, models.StatementCharge, models.StatementPayment, models.StatementRefund, end,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balance := 0
+	for rows.Next() {
+		var line models.StatementLine
+		if err := rows.Scan(&line.Date, &line.Type, &line.ReferenceID, &line.Debit, &line.Credit); err != nil {
+			return nil, err
+		}
+		balance += line.Debit - line.Credit
+		if line.Date.Before(start) {
+			statement.OpeningBalance = balance
+			continue
+		}
+		switch line.Type {
+		case models.StatementCharge:
+			line.Description = fmt.Sprintf("Purchase #%d on account", line.ReferenceID)
+		case models.StatementPayment:
+			line.Description = fmt.Sprintf("Payment #%d", line.ReferenceID)
+		case models.StatementRefund:
+			line.Description = fmt.Sprintf("Refund of purchase #%d", line.ReferenceID)
+		}
+		line.Balance = balance
+		statement.Lines = append(statement.Lines, line)
+	}
+	statement.ClosingBalance = balance
+	return &statement, rows.Err()
+}
+
+// GetAging splits the open invoices of every customer by age: 0-30, 31-60 and over 60 days.
+// Customers owing the most come first.
+func (r *ReceivableRepository) GetAging() (*models.ReceivablesAging, error) {
+	report := models.ReceivablesAging{
+		AsOf:      time.Now().Format("2006-01-02"),
+		Customers: []models.CustomerAging{},
+	}
+	rows, err := r.db.Query(
+		`SELECT c.id, c.name, c.credit_limit, CURRENT_DATE - t.created_at::date, SUM(t.on_account_amount - t.on_account_paid)
+		 FROM transactions t
+		 JOIN customers c ON c.id = t.customer_id
+		 WHERE t.refunded_at IS NULL AND t.on_account_amount > t.on_account_paid
+		 GROUP BY c.id, c.name, c.credit_limit, CURRENT_DATE - t.created_at::date
+		 ORDER BY c.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a models.CustomerAging
+		var ageDays, amount int
+		if err := rows.Scan(&a.CustomerID, &a.CustomerName, &a.CreditLimit, &ageDays, &amount); err != nil {
+			return nil, err
+		}
+		if n := len(report.Customers); n == 0 || report.Customers[n-1].CustomerID != a.CustomerID {
+			report.Customers = append(report.Customers, a)
+		}
+		addAging(&report.Customers[len(report.Customers)-1], ageDays, amount)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(report.Customers, func(a, b models.CustomerAging) int {
+		return cmp.Or(cmp.Compare(b.Total, a.Total), strings.Compare(a.CustomerName, b.CustomerName))
+	})
+	for _, a := range report.Customers {
+		report.Current += a.Current
+		report.Days31_60 += a.Days31_60
+		report.Over60 += a.Over60
+		report.Total += a.Total
+	}
+	return &report, nil
+}
+
+// addAging adds amount owed on invoices ageDays old to the bucket of their age
+func addAging(a *models.CustomerAging, ageDays, amount int) {
+	switch {
+	case ageDays <= 30:
+		a.Current += amount
+	case ageDays <= 60:
+		a.Days31_60 += amount
+	default:
+		a.Over60 += amount
+	}
+	a.Total += amount
+}
+
+// chargeAccount checks that a customer can buy amount on account: they need a credit limit
+// and what they owe including amount must stay within it
+func chargeAccount(ctx context.Context, tx *sql.Tx, customerID, amount int) error {
+	if customerID == 0 {
+		return fmt.Errorf("buying on account requires a customer")
+	}
+	var limit int
+	err := tx.QueryRowContext(ctx, "SELECT credit_limit FROM customers WHERE id = $1 FOR UPDATE", customerID).Scan(&limit)
+	if err != nil {
+		return err
+	}
+	if limit <= 0 {
+		return fmt.Errorf("customer with ID %d cannot buy on account", customerID)
+	}
+	outstanding, err := accountOutstanding(ctx, tx, customerID)
+	if err != nil {
+		return err
+	}
+	if outstanding+amount > limit {
+		return fmt.Errorf("credit limit of %d exceeded: customer owes %d, available credit is %d",
+			limit, outstanding, max(limit-outstanding, 0))
+	}
+	return nil
+}
+
+// accountOutstanding returns what a customer owes on account
+func accountOutstanding(ctx context.Context, q queryer, customerID int) (int, error) {
+	var outstanding int
+	err := q.QueryRowContext(ctx,
+		`SELECT COALESCE(SUM(on_account_amount - on_account_paid), 0) FROM transactions
+		 WHERE customer_id = $1 AND refunded_at IS NULL`, customerID,
+	).Scan(&outstanding)
+	return outstanding, err
+}
@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"testing"
+
+	"kasir-api/models"
+)
+
+func TestAddAging(t *testing.T) {
+	tests := []struct {
+		name    string
+		ageDays int
+		want    models.CustomerAging
+	}{
+		{"today", 0, models.CustomerAging{Current: 1000, Total: 1000}},
+		{"last day current", 30, models.CustomerAging{Current: 1000, Total: 1000}},
+		{"first day of 31-60", 31, models.CustomerAging{Days31_60: 1000, Total: 1000}},
+		{"last day of 31-60", 60, models.CustomerAging{Days31_60: 1000, Total: 1000}},
+		{"first day over 60", 61, models.CustomerAging{Over60: 1000, Total: 1000}},
+		{"a year old", 365, models.CustomerAging{Over60: 1000, Total: 1000}},
+	}
+	for _, tt := range tests {
+		var got models.CustomerAging
+		addAging(&got, tt.ageDays, 1000)
+		if got != tt.want {
+			t.Errorf("%s: addAging at %d days = %+v, want %+v", tt.name, tt.ageDays, got, tt.want)
+		}
+	}
+}
+
+func TestAddAgingAccumulates(t *testing.T) {
+	var got models.CustomerAging
+	for _, invoice := range []struct{ ageDays, amount int }{{5, 100}, {30, 200}, {31, 400}, {60, 800}, {61, 1600}} {
+		addAging(&got, invoice.ageDays, invoice.amount)
+	}
+	want := models.CustomerAging{Current: 300, Days31_60: 1200, Over60: 1600, Total: 3100}
+	if got != want {
+		t.Errorf("aging %+v, want %+v", got, want)
+	}
+}
@@ -3,9 +3,26 @@ package repositories
 import (
 	"database/sql"
 	"kasir-api/models"
+	"math"
 	"time"
 )
 
+// soldLinesQuery lists every sold product line between $1 and $2 with its revenue and cost, refunds and unpaid sales left out,
+// at outlet $3 or at all outlets when $3 is 0.
+// Bundle lines are replaced by their components so revenue and cost land on real products.
+const soldLinesQuery = `
+	SELECT td.product_id, td.base_quantity as quantity, td.subtotal as revenue, td.cogs
+	FROM transaction_details td
+	JOIN transactions t ON td.transaction_id = t.id
+	WHERE t.created_at BETWEEN $1 AND $2 AND ($3 = 0 OR t.outlet_id = $3) AND t.refunded_at IS NULL AND t.payment_status = 'paid'
+	  AND NOT EXISTS (SELECT 1 FROM transaction_detail_components tdc WHERE tdc.transaction_detail_id = td.id)
+	UNION ALL
+	SELECT tdc.product_id, tdc.quantity, tdc.allocated_amount as revenue, tdc.cogs
+	FROM transaction_detail_components tdc
+	JOIN transaction_details td ON tdc.transaction_detail_id = td.id
+	JOIN transactions t ON td.transaction_id = t.id
+	WHERE t.created_at BETWEEN $1 AND $2 AND ($3 = 0 OR t.outlet_id = $3) AND t.refunded_at IS NULL AND t.payment_status = 'paid'`
+
 type ReportRepository struct {
 	db *sql.DB
 }
@@ -14,13 +31,14 @@ func NewReportRepository(db *sql.DB) *ReportRepository {
 	return &ReportRepository{db: db}
 }
 
-func (r *ReportRepository) GetSalesReport(startDate, endDate time.Time) (*models.SalesReport, error) {
-	var report models.SalesReport
+// GetSalesReport summarises sales of one outlet, or of all outlets when outletID is 0
+func (r *ReportRepository) GetSalesReport(startDate, endDate time.Time, outletID int) (*models.SalesReport, error) {
+	report := models.SalesReport{OutletID: outletID}
 
-	// 1. Calculate Total Revenue
+	// 1. Calculate Total Revenue, gift cards sold are prepaid money rather than revenue
 	err := r.db.QueryRow(
-		"SELECT COALESCE(SUM(total_amount), 0) FROM transactions WHERE created_at BETWEEN $1 AND $2",
-		startDate, endDate,
+		"SELECT COALESCE(SUM(total_amount - gift_cards_sold), 0) FROM transactions WHERE created_at BETWEEN $1 AND $2 AND ($3 = 0 OR outlet_id = $3) AND refunded_at IS NULL AND payment_status = 'paid'",
+		startDate, endDate, outletID,
 	).Scan(&report.TotalRevenue)
 	if err != nil {
 		return nil, err
@@ -28,24 +46,37 @@ func (r *ReportRepository) GetSalesReport(startDate, endDate time.Time) (*models
 
 	// 2. Calculate Total Transactions
 	err = r.db.QueryRow(
-		"SELECT COUNT(id) FROM transactions WHERE created_at BETWEEN $1 AND $2",
-		startDate, endDate,
+		"SELECT COUNT(id) FROM transactions WHERE created_at BETWEEN $1 AND $2 AND ($3 = 0 OR outlet_id = $3) AND refunded_at IS NULL AND payment_status = 'paid'",
+		startDate, endDate, outletID,
 	).Scan(&report.TotalTransactions)
 	if err != nil {
 		return nil, err
 	}
 
-	// 3. Find Best Seller
+	// 3. Calculate COGS and Gross Profit
 	err = r.db.QueryRow(`
-		SELECT td.product_id, p.name, SUM(td.quantity) as total_qty
+		SELECT COALESCE(SUM(td.cogs), 0)
+		FROM transaction_details td
+		JOIN transactions t ON td.transaction_id = t.id
+		WHERE t.created_at BETWEEN $1 AND $2 AND ($3 = 0 OR t.outlet_id = $3) AND t.refunded_at IS NULL AND t.payment_status = 'paid'
+	`, startDate, endDate, outletID).Scan(&report.TotalCOGS)
+	if err != nil {
+		return nil, err
+	}
+	report.GrossProfit = report.TotalRevenue - report.TotalCOGS
+	report.MarginPercent = models.MarginPercent(report.TotalRevenue, report.GrossProfit)
+
+	// 4. Find Best Seller
+	err = r.db.QueryRow(`
+		SELECT td.product_id, p.name, SUM(td.base_quantity) as total_qty
 		FROM transaction_details td
 		JOIN transactions t ON td.transaction_id = t.id
 		JOIN products p ON td.product_id = p.id
-		WHERE t.created_at BETWEEN $1 AND $2
+		WHERE t.created_at BETWEEN $1 AND $2 AND ($3 = 0 OR t.outlet_id = $3) AND t.refunded_at IS NULL AND t.payment_status = 'paid'
 		GROUP BY td.product_id, p.name
 		ORDER BY total_qty DESC
 		LIMIT 1
-	`, startDate, endDate).Scan(&report.BestSeller.ProductID, &report.BestSeller.ProductName, &report.BestSeller.Quantity)
+	`, startDate, endDate, outletID).Scan(&report.BestSeller.ProductID, &report.BestSeller.ProductName, &report.BestSeller.Quantity)
 
 	if err != nil && err != sql.ErrNoRows {
 		return nil, err
@@ -54,3 +85,179 @@ func (r *ReportRepository) GetSalesReport(startDate, endDate time.Time) (*models
 
 	return &report, nil
 }
+
+func (r *ReportRepository) GetProductSales(startDate, endDate time.Time, outletID int) ([]models.ProductSales, error) {
+	rows, err := r.db.Query(`
+		SELECT s.product_id, p.name, SUM(s.quantity) as total_qty, SUM(s.revenue) as total_revenue, SUM(s.cogs) as total_cogs
+		FROM (`+soldLinesQuery+`) s
+		JOIN products p ON s.product_id = p.id
+		GROUP BY s.product_id, p.name
+		ORDER BY total_revenue DESC
+	`, startDate, endDate, outletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sales []models.ProductSales
+	for rows.Next() {
+		var ps models.ProductSales
+		if err := rows.Scan(&ps.ProductID, &ps.ProductName, &ps.Quantity, &ps.Revenue, &ps.COGS); err != nil {
+			return nil, err
+		}
+		ps.GrossProfit = ps.Revenue - ps.COGS
+		ps.MarginPercent = models.MarginPercent(ps.Revenue, ps.GrossProfit)
+		sales = append(sales, ps)
+	}
+	return sales, rows.Err()
+}
+
+func (r *ReportRepository) GetCategorySales(startDate, endDate time.Time, outletID int) ([]models.CategorySales, error) {
+	rows, err := r.db.Query(`
+		SELECT COALESCE(c.id, 0), COALESCE(c.name, 'Uncategorized'), SUM(s.revenue) as total_revenue, SUM(s.cogs) as total_cogs
+		FROM (`+soldLinesQuery+`) s
+		JOIN products p ON s.product_id = p.id
+		LEFT JOIN categories c ON p.category_id = c.id
+		GROUP BY c.id, c.name
+		ORDER BY total_revenue DESC
+	`, startDate, endDate, outletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sales []models.CategorySales
+	for rows.Next() {
+		var cs models.CategorySales
+		if err := rows.Scan(&cs.CategoryID, &cs.CategoryName, &cs.Revenue, &cs.COGS); err != nil {
+			return nil, err
+		}
+		cs.GrossProfit = cs.Revenue - cs.COGS
+		cs.MarginPercent = models.MarginPercent(cs.Revenue, cs.GrossProfit)
+		sales = append(sales, cs)
+	}
+	return sales, rows.Err()
+}
+
+// GetExpiringStock lists batches with stock left that expire within the given number of days,
+// including those already expired, soonest first. outletID 0 covers all outlets.
+func (r *ReportRepository) GetExpiringStock(days, outletID int) ([]models.ExpiringStock, error) {
+	rows, err := r.db.Query(`
+		SELECT b.id, b.outlet_id, b.product_id, p.name, b.batch_number, TO_CHAR(b.expiry_date, 'YYYY-MM-DD'),
+		       b.expiry_date - CURRENT_DATE, b.quantity, p.cost_price
+		FROM product_batches b
+		JOIN products p ON p.id = b.product_id
+		WHERE b.quantity > 0 AND b.expiry_date <= CURRENT_DATE + $1::int AND ($2 = 0 OR b.outlet_id = $2)
+		ORDER BY b.expiry_date, p.name, b.id
+	`, days, outletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stock []models.ExpiringStock
+	for rows.Next() {
+		var s models.ExpiringStock
+		var costPrice int
+		if err := rows.Scan(&s.BatchID, &s.OutletID, &s.ProductID, &s.ProductName, &s.BatchNumber, &s.ExpiryDate,
+			&s.DaysToExpiry, &s.Quantity, &costPrice); err != nil {
+			return nil, err
+		}
+		s.CostValue, err = s.Quantity.MulPrice(costPrice)
+		if err != nil {
+			return nil, err
+		}
+		stock = append(stock, s)
+	}
+	return stock, rows.Err()
+}
+
+// GetReorderSuggestions suggests what to order from the average daily sales over the window.
+// Safety stock covers SafetyDays of sales but never less than the product's min_stock. Once stock
+// is at or below the reorder point (lead time sales plus safety stock) the suggestion tops stock up
+// to last the lead time and CoverDays, in whole units or multiples of the reorder_quantity.
+// Products needing no order are only included when all is true. With an outletID the sales
+// and stock of that outlet are used, otherwise those of all outlets.
+func (r *ReportRepository) GetReorderSuggestions(params models.ReorderParams, outletID int, all bool) ([]models.ReorderSuggestion, error) {
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -params.WindowDays)
+
+	rows, err := r.db.Query(`
+		SELECT p.id, p.name, p.unit,
+		       CASE WHEN $3 = 0 THEN p.stock
+		            ELSE COALESCE((SELECT stock FROM outlet_stock WHERE outlet_id = $3 AND product_id = p.id), 0) END,
+		       p.min_stock, p.reorder_quantity, p.allow_fraction, COALESCE(s.sold, 0)
+		FROM products p
+		LEFT JOIN (
+			SELECT l.product_id, SUM(l.quantity) as sold FROM (`+soldLinesQuery+`) l GROUP BY l.product_id
+		) s ON s.product_id = p.id
+		WHERE NOT p.is_bundle
+		ORDER BY p.name
+	`, startDate, endDate, outletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suggestions []models.ReorderSuggestion
+	for rows.Next() {
+		var s models.ReorderSuggestion
+		var minStock, reorderQuantity models.Quantity
+		var allowFraction bool
+		if err := rows.Scan(&s.ProductID, &s.ProductName, &s.Unit, &s.Stock, &minStock, &reorderQuantity,
+			&allowFraction, &s.SoldQuantity); err != nil {
+			return nil, err
+		}
+
+		if err := suggestReorder(&s, params, minStock, reorderQuantity, allowFraction); err != nil {
+			return nil, err
+		}
+
+		if all || s.SuggestedQuantity > 0 {
+			suggestions = append(suggestions, s)
+		}
+	}
+	return suggestions, rows.Err()
+}
+
+// suggestReorder works out the sales velocity, safety stock, reorder point and suggested order of s
+// from its stock and the quantity sold over the window
+func suggestReorder(s *models.ReorderSuggestion, params models.ReorderParams, minStock, reorderQuantity models.Quantity, allowFraction bool) error {
+	s.AverageDailySales = s.SoldQuantity.Div(models.NewQuantity(params.WindowDays))
+	if s.AverageDailySales > 0 {
+		cover := math.Round(float64(s.Stock)/float64(s.AverageDailySales)*10) / 10
+		s.DaysOfCover = &cover
+	}
+	safetySales, err := s.AverageDailySales.Mul(models.NewQuantity(params.SafetyDays))
+	if err != nil {
+		return err
+	}
+	leadTimeSales, err := s.AverageDailySales.Mul(models.NewQuantity(params.LeadTimeDays))
+	if err != nil {
+		return err
+	}
+	s.SafetyStock = max(safetySales, minStock)
+	s.ReorderPoint = leadTimeSales + s.SafetyStock
+
+	if s.ReorderPoint > 0 && s.Stock <= s.ReorderPoint {
+		coverSales, err := s.AverageDailySales.Mul(models.NewQuantity(params.LeadTimeDays + params.CoverDays))
+		if err != nil {
+			return err
+		}
+		target := coverSales + s.SafetyStock
+		step := reorderQuantity
+		if step <= 0 && !allowFraction {
+			step = models.NewQuantity(1)
+		}
+		s.SuggestedQuantity = roundUpQuantity(max(target-s.Stock, 0), step)
+	}
+	return nil
+}
+
+// roundUpQuantity rounds q up to a multiple of step, a step of 0 leaves q as is
+func roundUpQuantity(q, step models.Quantity) models.Quantity {
+	if step <= 0 {
+		return q
+	}
+	return (q + step - 1) / step * step
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"testing"
+
+	"kasir-api/models"
+)
+
+func TestSuggestReorder(t *testing.T) {
+	params := models.ReorderParams{WindowDays: 30, LeadTimeDays: 7, SafetyDays: 3, CoverDays: 14}
+	q := models.NewQuantity
+
+	// Selling 60 in 30 days is 2 a day: safety stock 6, reorder point 2×7 + 6 = 20 and target 2×21 + 6 = 48
+	tests := []struct {
+		name            string
+		stock, sold     models.Quantity
+		minStock        models.Quantity
+		reorderQuantity models.Quantity
+		allowFraction   bool
+		wantSafety      models.Quantity
+		wantPoint       models.Quantity
+		wantSuggested   models.Quantity
+	}{
+		{"at the reorder point", q(20), q(60), 0, 0, false, q(6), q(20), q(28)},
+		{"just above the reorder point", q(20) + 1, q(60), 0, 0, true, q(6), q(20), 0},
+		{"one unit above the reorder point", q(21), q(60), 0, 0, false, q(6), q(20), 0},
+		{"out of stock", 0, q(60), 0, 0, false, q(6), q(20), q(48)},
+		{"in multiples of the reorder quantity", q(20), q(60), 0, q(12), false, q(6), q(20), q(36)},
+		{"exact multiple of the reorder quantity", q(6), q(60), 0, q(21), false, q(6), q(20), q(42)},
+		{"min stock above the safety sales", q(24), q(60), q(10), 0, false, q(10), q(24), q(28)},
+		{"min stock below the safety sales", q(20), q(60), q(5), 0, false, q(6), q(20), q(28)},
+		{"no sales, at min stock", q(5), 0, q(5), 0, false, q(5), q(5), 0},
+		{"no sales, below min stock", q(4), 0, q(5), 0, false, q(5), q(5), q(1)},
+		{"no sales and no min stock", 0, 0, 0, 0, false, 0, 0, 0},
+		// 10 in 30 days is 0.333 a day: safety 0.999, point 2.331 + 0.999, target 6.993 + 0.999
+		{"fractions allowed", q(3), q(10), 0, 0, true, 999, 3330, 4992},
+		{"rounded up to whole units", q(3), q(10), 0, 0, false, 999, 3330, q(5)},
+	}
+	for _, tt := range tests {
+		s := models.ReorderSuggestion{Stock: tt.stock, SoldQuantity: tt.sold}
+		if err := suggestReorder(&s, params, tt.minStock, tt.reorderQuantity, tt.allowFraction); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if s.SafetyStock != tt.wantSafety || s.ReorderPoint != tt.wantPoint || s.SuggestedQuantity != tt.wantSuggested {
+			t.Errorf("%s: safety %s, reorder point %s, suggested %s, want %s, %s and %s", tt.name,
+				s.SafetyStock, s.ReorderPoint, s.SuggestedQuantity, tt.wantSafety, tt.wantPoint, tt.wantSuggested)
+		}
+	}
+}
+
+func TestSuggestReorderDaysOfCover(t *testing.T) {
+	params := models.ReorderParams{WindowDays: 30, LeadTimeDays: 7, SafetyDays: 3, CoverDays: 14}
+
+	s := models.ReorderSuggestion{Stock: models.NewQuantity(25), SoldQuantity: models.NewQuantity(60)}
+	if err := suggestReorder(&s, params, 0, 0, false); err != nil {
+		t.Fatal(err)
+	}
+	if s.AverageDailySales != models.NewQuantity(2) || s.DaysOfCover == nil || *s.DaysOfCover != 12.5 {
+		t.Errorf("average %s, days of cover %v, want 2 a day lasting 12.5 days", s.AverageDailySales, s.DaysOfCover)
+	}
+
+	s = models.ReorderSuggestion{Stock: models.NewQuantity(25)}
+	if err := suggestReorder(&s, params, 0, 0, false); err != nil {
+		t.Fatal(err)
+	}
+	if s.DaysOfCover != nil {
+		t.Errorf("days of cover %v without sales, want none", *s.DaysOfCover)
+	}
+}
@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"time"
+)
+
+type StockMovementRepository struct {
+	db *sql.DB
+}
+
+func NewStockMovementRepository(db *sql.DB) *StockMovementRepository {
+	return &StockMovementRepository{db: db}
+}
+
+// GetByProduct lists a product's movements, of one outlet or of all outlets when outletID is 0.
+// The balance after each movement is the outlet's, or with all outlets the running total over all
+// of them, summed over the whole ledger so it is right for a date range too.
+func (r *StockMovementRepository) GetByProduct(productID, outletID int, startDate, endDate time.Time) ([]models.StockMovement, error) {
+	rows, err := r.db.Query(
+		`SELECT id, product_id, outlet_id, movement_type, quantity, balance, COALESCE(reason, ''), COALESCE(actor, ''),
+		        COALESCE(reference_type, ''), COALESCE(reference_id, 0), created_at
+		 FROM (
+		     SELECT m.*, CASE WHEN $4 = 0 THEN SUM(quantity) OVER (ORDER BY id) ELSE balance_after END AS balance
+		     FROM stock_movements m
+		     WHERE product_id = $1 AND ($4 = 0 OR outlet_id = $4)
+		 ) m
+		 WHERE created_at BETWEEN $2 AND $3
+		 ORDER BY id`,
+		productID, startDate, endDate, outletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movements []models.StockMovement
+	for rows.Next() {
+		var m models.StockMovement
+		if err := rows.Scan(&m.ID, &m.ProductID, &m.OutletID, &m.Type, &m.Quantity, &m.BalanceAfter, &m.Reason, &m.Actor,
+			&m.ReferenceType, &m.ReferenceID, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		movements = append(movements, m)
+	}
+	return movements, rows.Err()
+}
+
+func (r *StockMovementRepository) Adjust(productID int, req models.StockAdjustmentRequest) (*models.StockMovement, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	delta := req.Quantity
+	if req.Type == models.MovementWaste {
+		delta = -delta
+	}
+
+	var isBundle bool
+	err = tx.QueryRowContext(ctx, "SELECT is_bundle FROM products WHERE id = $1 FOR UPDATE", productID).Scan(&isBundle)
+	if err != nil {
+		return nil, err
+	}
+	if isBundle {
+		return nil, fmt.Errorf("bundle stock is derived from its components and cannot be adjusted")
+	}
+	outletID, err := resolveOutlet(ctx, tx, req.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	stock, _, err := lockOutletStock(ctx, tx, outletID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if stock+delta < 0 {
+		return nil, fmt.Errorf("adjustment would make stock negative (current stock %s)", stock)
+	}
+
+	movement, err := applyStockMovement(ctx, tx, models.StockMovement{
+		ProductID: productID,
+		OutletID:  outletID,
+		Type:      req.Type,
+		Quantity:  delta,
+		Reason:    req.Reason,
+		Actor:     req.Actor,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return movement, nil
+}
+
+// applyStockMovement changes a product's stock at outlet m.OutletID (the default outlet when 0)
+// by m.Quantity and appends the movement to the ledger with the outlet's resulting balance.
+// products.stock is kept as the total over all outlets. Every stock change must go through here.
+// Decreases are also taken out of the product's batches at the outlet.
+func applyStockMovement(ctx context.Context, q queryer, m models.StockMovement) (*models.StockMovement, error) {
+	outletID, err := resolveOutlet(ctx, q, m.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	m.OutletID = outletID
+
+	_, err = q.ExecContext(ctx, "UPDATE products SET stock = stock + $1 WHERE id = $2", m.Quantity, m.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	err = q.QueryRowContext(ctx,
+		`INSERT INTO outlet_stock (outlet_id, product_id, stock) VALUES ($1, $2, $3)
+		 ON CONFLICT (outlet_id, product_id) DO UPDATE SET stock = outlet_stock.stock + EXCLUDED.stock
+		 RETURNING stock`,
+		m.OutletID, m.ProductID, m.Quantity,
+	).Scan(&m.BalanceAfter)
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.QueryRowContext(ctx,
+		`INSERT INTO stock_movements (product_id, outlet_id, movement_type, quantity, balance_after, reason, actor, reference_type, reference_id)
+		 VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, 0))
+		 RETURNING id, created_at`,
+		m.ProductID, m.OutletID, m.Type, m.Quantity, m.BalanceAfter, m.Reason, m.Actor, m.ReferenceType, m.ReferenceID,
+	).Scan(&m.ID, &m.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Quantity < 0 {
+		m.Batches, err = drainBatches(ctx, q, m)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &m, nil
+}
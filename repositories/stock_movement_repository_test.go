@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"kasir-api/models"
+)
+
+// TestMovementBalances checks the balance after each movement at one outlet and over all outlets
+func TestMovementBalances(t *testing.T) {
+	db := testDB(t)
+	movements := NewStockMovementRepository(db)
+
+	mainID := queryInt(t, db, "SELECT id FROM outlets WHERE is_default")
+	branchID := queryInt(t, db, "INSERT INTO outlets (code, name) VALUES ('BDG', 'Bandung') RETURNING id")
+	productID := queryInt(t, db, "INSERT INTO products (name, price) VALUES ('Sugar', 15000) RETURNING id")
+	for _, m := range []struct{ outletID, quantity int }{{mainID, 10}, {branchID, 4}, {mainID, -3}, {branchID, 2}} {
+		_, err := movements.Adjust(productID, models.StockAdjustmentRequest{
+			OutletID: m.outletID, Type: models.MovementAdjustment, Quantity: models.NewQuantity(m.quantity), Reason: "count",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		outletID int
+		want     []int
+	}{
+		{mainID, []int{10, 7}},
+		{branchID, []int{4, 6}},
+		{0, []int{10, 14, 11, 13}},
+	}
+	for _, tt := range tests {
+		ledger, err := movements.GetByProduct(productID, tt.outletID, time.Time{}, time.Now().AddDate(1, 0, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ledger) != len(tt.want) {
+			t.Fatalf("outlet %d: %d movements, want %d", tt.outletID, len(ledger), len(tt.want))
+		}
+		for i, m := range ledger {
+			if m.BalanceAfter != models.NewQuantity(tt.want[i]) {
+				t.Errorf("outlet %d movement %d: balance %s, want %d", tt.outletID, i, m.BalanceAfter, tt.want[i])
+			}
+		}
+	}
+}
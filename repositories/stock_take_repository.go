@@ -0,0 +1,318 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+)
+
+type StockTakeRepository struct {
+	db *sql.DB
+}
+
+func NewStockTakeRepository(db *sql.DB) *StockTakeRepository {
+	return &StockTakeRepository{db: db}
+}
+
+func (r *StockTakeRepository) GetAll() ([]models.StockTake, error) {
+	rows, err := r.db.Query(
+		`SELECT st.id, st.outlet_id, o.name, st.status, COALESCE(st.notes, ''), COALESCE(st.created_by, ''), st.created_at,
+		        COALESCE(st.approved_by, ''), st.approved_at
+		 FROM stock_takes st JOIN outlets o ON o.id = st.outlet_id ORDER BY st.id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var takes []models.StockTake
+	for rows.Next() {
+		var st models.StockTake
+		if err := rows.Scan(&st.ID, &st.OutletID, &st.OutletName, &st.Status, &st.Notes, &st.CreatedBy, &st.CreatedAt, &st.ApprovedBy, &st.ApprovedAt); err != nil {
+			return nil, err
+		}
+		takes = append(takes, st)
+	}
+	return takes, rows.Err()
+}
+
+func (r *StockTakeRepository) GetByID(id int) (*models.StockTake, error) {
+	var st models.StockTake
+	err := r.db.QueryRow(
+		`SELECT st.id, st.outlet_id, o.name, st.status, COALESCE(st.notes, ''), COALESCE(st.created_by, ''), st.created_at,
+		        COALESCE(st.approved_by, ''), st.approved_at
+		 FROM stock_takes st JOIN outlets o ON o.id = st.outlet_id WHERE st.id = $1`, id,
+	).Scan(&st.ID, &st.OutletID, &st.OutletName, &st.Status, &st.Notes, &st.CreatedBy, &st.CreatedAt, &st.ApprovedBy, &st.ApprovedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(
+		`SELECT l.product_id, p.name, COALESCE(p.barcode, ''), l.expected_quantity, l.counted_quantity, l.unit_cost, l.counted_at
+		 FROM stock_take_lines l
+		 JOIN products p ON p.id = l.product_id
+		 WHERE l.stock_take_id = $1
+		 ORDER BY p.name`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line models.StockTakeLine
+		var expected, counted sql.Null[models.Quantity]
+		if err := rows.Scan(&line.ProductID, &line.ProductName, &line.Barcode, &expected, &counted, &line.UnitCost, &line.CountedAt); err != nil {
+			return nil, err
+		}
+		if counted.Valid {
+			line.ExpectedQuantity = &expected.V
+			line.CountedQuantity = &counted.V
+			line.Variance = counted.V - expected.V
+			line.VarianceValue, err = line.Variance.MulPrice(line.UnitCost)
+			if err != nil {
+				return nil, err
+			}
+			st.CountedLines++
+			st.TotalVarianceValue += line.VarianceValue
+		}
+		st.Lines = append(st.Lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+func (r *StockTakeRepository) Create(req models.StockTakeRequest) (*models.StockTake, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	outletID, err := resolveOutlet(ctx, tx, req.OutletID)
+	if err != nil {
+		return nil, err
+	}
+
+	var open bool
+	err = tx.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM stock_takes WHERE outlet_id = $1 AND status = $2)", outletID, models.StockTakeOpen,
+	).Scan(&open)
+	if err != nil {
+		return nil, err
+	}
+	if open {
+		return nil, fmt.Errorf("another stock take is still open at this outlet")
+	}
+
+	var id int
+	err = tx.QueryRowContext(ctx,
+		"INSERT INTO stock_takes (outlet_id, status, notes, created_by) VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, '')) RETURNING id",
+		outletID, models.StockTakeOpen, req.Notes, req.Actor,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every stocked product in scope gets a line, bundles have no stock of their own
+	categoryIDs := req.CategoryIDs
+	if categoryIDs == nil {
+		categoryIDs = []int{}
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO stock_take_lines (stock_take_id, product_id)
+		 SELECT $1, id FROM products
+		 WHERE NOT is_bundle AND (cardinality($2::int[]) = 0 OR category_id = ANY($2::int[]))`,
+		id, categoryIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+func (r *StockTakeRepository) SubmitCounts(id int, req models.StockCountRequest) (*models.StockTake, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	outletID, err := lockOpenStockTake(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range req.Items {
+		productID := item.ProductID
+		if item.Barcode != "" {
+			err := tx.QueryRowContext(ctx, "SELECT id FROM products WHERE barcode = $1", item.Barcode).Scan(&productID)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					return nil, fmt.Errorf("no product with barcode %s", item.Barcode)
+				}
+				return nil, err
+			}
+		}
+
+		// Lock the product so the expected quantity matches the shelf at this moment
+		var costPrice int
+		var isBundle bool
+		err := tx.QueryRowContext(ctx,
+			"SELECT cost_price, is_bundle FROM products WHERE id = $1 FOR UPDATE", productID,
+		).Scan(&costPrice, &isBundle)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, fmt.Errorf("product with ID %d not found", productID)
+			}
+			return nil, err
+		}
+		if isBundle {
+			return nil, fmt.Errorf("product with ID %d is a bundle and cannot be counted", productID)
+		}
+		stock, _, err := lockOutletStock(ctx, tx, outletID, productID)
+		if err != nil {
+			return nil, err
+		}
+
+		if item.Add {
+			// Accumulate scans, the expected quantity stays at the first count
+			_, err = tx.ExecContext(ctx,
+				`INSERT INTO stock_take_lines (stock_take_id, product_id, expected_quantity, counted_quantity, unit_cost, counted_at)
+				 VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+				 ON CONFLICT (stock_take_id, product_id) DO UPDATE SET
+				     expected_quantity = COALESCE(stock_take_lines.expected_quantity, EXCLUDED.expected_quantity),
+				     counted_quantity = COALESCE(stock_take_lines.counted_quantity, 0) + EXCLUDED.counted_quantity,
+				     unit_cost = CASE WHEN stock_take_lines.counted_quantity IS NULL THEN EXCLUDED.unit_cost ELSE stock_take_lines.unit_cost END,
+				     counted_at = EXCLUDED.counted_at`,
+				id, productID, stock, item.Quantity, costPrice,
+			)
+		} else {
+			_, err = tx.ExecContext(ctx,
+				`INSERT INTO stock_take_lines (stock_take_id, product_id, expected_quantity, counted_quantity, unit_cost, counted_at)
+				 VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+				 ON CONFLICT (stock_take_id, product_id) DO UPDATE SET
+				     expected_quantity = EXCLUDED.expected_quantity,
+				     counted_quantity = EXCLUDED.counted_quantity,
+				     unit_cost = EXCLUDED.unit_cost,
+				     counted_at = EXCLUDED.counted_at`,
+				id, productID, stock, item.Quantity, costPrice,
+			)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// Approve posts the variance of every counted line to the stock ledger in one database transaction
+func (r *StockTakeRepository) Approve(id int, actor string) (*models.StockTake, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	outletID, err := lockOpenStockTake(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT product_id, counted_quantity - expected_quantity
+		 FROM stock_take_lines
+		 WHERE stock_take_id = $1 AND counted_quantity IS NOT NULL AND counted_quantity <> expected_quantity
+		 ORDER BY product_id`, id)
+	if err != nil {
+		return nil, err
+	}
+	var adjustments []models.StockMovement
+	for rows.Next() {
+		m := models.StockMovement{
+			OutletID:      outletID,
+			Type:          models.MovementAdjustment,
+			Reason:        fmt.Sprintf("Stock take #%d", id),
+			Actor:         actor,
+			ReferenceType: "stock_take",
+			ReferenceID:   id,
+		}
+		if err := rows.Scan(&m.ProductID, &m.Quantity); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		adjustments = append(adjustments, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, m := range adjustments {
+		if _, err := applyStockMovement(ctx, tx, m); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE stock_takes SET status = $1, approved_by = NULLIF($2, ''), approved_at = CURRENT_TIMESTAMP WHERE id = $3",
+		models.StockTakeApproved, actor, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+func (r *StockTakeRepository) Cancel(id int) (*models.StockTake, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := lockOpenStockTake(ctx, tx, id); err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE stock_takes SET status = $1 WHERE id = $2", models.StockTakeCancelled, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// lockOpenStockTake locks a stock take for the rest of the transaction, ensures it is still open
+// and returns the outlet being counted
+func lockOpenStockTake(ctx context.Context, tx *sql.Tx, id int) (int, error) {
+	var status string
+	var outletID int
+	err := tx.QueryRowContext(ctx, "SELECT status, outlet_id FROM stock_takes WHERE id = $1 FOR UPDATE", id).Scan(&status, &outletID)
+	if err != nil {
+		return 0, err
+	}
+	if status != models.StockTakeOpen {
+		return 0, fmt.Errorf("stock take %d is %s", id, status)
+	}
+	return outletID, nil
+}
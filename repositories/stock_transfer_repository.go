@@ -0,0 +1,527 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+)
+
+const stockTransferColumns = `st.id, st.source_outlet_id, so.name, st.destination_outlet_id, d.name, st.status, COALESCE(st.notes, ''),
+	COALESCE(st.requested_by, ''), st.requested_at, COALESCE(st.dispatched_by, ''), st.dispatched_at,
+	COALESCE(st.received_by, ''), st.received_at`
+
+const stockTransferTables = ` FROM stock_transfers st
+	JOIN outlets so ON so.id = st.source_outlet_id
+	JOIN outlets d ON d.id = st.destination_outlet_id`
+
+type StockTransferRepository struct {
+	db *sql.DB
+}
+
+func NewStockTransferRepository(db *sql.DB) *StockTransferRepository {
+	return &StockTransferRepository{db: db}
+}
+
+// GetAll lists transfers newest first, optionally only those with a status, from or to an outlet,
+// or containing a product. With a product only that product's line is included, giving its transfer history.
+func (r *StockTransferRepository) GetAll(status string, outletID, productID int) ([]models.StockTransfer, error) {
+	rows, err := r.db.Query(
+		`SELECT `+stockTransferColumns+stockTransferTables+`
+		 WHERE ($1 = '' OR st.status = $1)
+		   AND ($2 = 0 OR st.source_outlet_id = $2 OR st.destination_outlet_id = $2)
+		   AND ($3 = 0 OR EXISTS (SELECT 1 FROM stock_transfer_lines l WHERE l.stock_transfer_id = st.id AND l.product_id = $3))
+		 ORDER BY st.id DESC`,
+		status, outletID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	var transfers []models.StockTransfer
+	for rows.Next() {
+		var st models.StockTransfer
+		if err := scanStockTransfer(rows, &st); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		transfers = append(transfers, st)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if productID != 0 {
+		for i := range transfers {
+			transfers[i].Lines, err = r.getLines(transfers[i], productID)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return transfers, nil
+}
+
+func (r *StockTransferRepository) GetByID(id int) (*models.StockTransfer, error) {
+	var st models.StockTransfer
+	if err := scanStockTransfer(r.db.QueryRow(`SELECT `+stockTransferColumns+stockTransferTables+` WHERE st.id = $1`, id), &st); err != nil {
+		return nil, err
+	}
+
+	var err error
+	st.Lines, err = r.getLines(st, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// getLines loads the lines of a transfer, or only the line of one product, with the batches they were dispatched from
+func (r *StockTransferRepository) getLines(st models.StockTransfer, productID int) ([]models.StockTransferLine, error) {
+	rows, err := r.db.Query(
+		`SELECT l.id, l.product_id, p.name, p.unit, l.requested_quantity, l.dispatched_quantity, l.received_quantity,
+		        COALESCE(l.discrepancy_reason, ''), COALESCE(l.dispatch_movement_id, 0)
+		 FROM stock_transfer_lines l
+		 JOIN products p ON p.id = l.product_id
+		 WHERE l.stock_transfer_id = $1 AND ($2 = 0 OR l.product_id = $2)
+		 ORDER BY l.id`, st.ID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []models.StockTransferLine
+	var movementIDs []int
+	for rows.Next() {
+		var l models.StockTransferLine
+		var movementID int
+		if err := rows.Scan(&l.ID, &l.ProductID, &l.ProductName, &l.Unit, &l.RequestedQuantity, &l.DispatchedQuantity,
+			&l.ReceivedQuantity, &l.DiscrepancyReason, &movementID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		lines = append(lines, l)
+		movementIDs = append(movementIDs, movementID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range lines {
+		if st.Status == models.TransferStatusReceived {
+			lines[i].Discrepancy = lines[i].ReceivedQuantity - lines[i].DispatchedQuantity
+		}
+		if movementIDs[i] == 0 {
+			continue
+		}
+		lines[i].Batches, err = dispatchedBatches(context.Background(), r.db, movementIDs[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return lines, nil
+}
+
+func (r *StockTransferRepository) Create(req models.StockTransferRequest) (*models.StockTransfer, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, outletID := range []int{req.SourceOutletID, req.DestinationOutletID} {
+		if _, err := resolveOutlet(ctx, tx, outletID); err != nil {
+			return nil, err
+		}
+	}
+
+	var id int
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO stock_transfers (source_outlet_id, destination_outlet_id, status, notes, requested_by)
+		 VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, '')) RETURNING id`,
+		req.SourceOutletID, req.DestinationOutletID, models.TransferStatusRequested, req.Notes, req.Actor,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int]bool)
+	for _, line := range req.Lines {
+		if seen[line.ProductID] {
+			return nil, fmt.Errorf("product with ID %d is listed more than once", line.ProductID)
+		}
+		seen[line.ProductID] = true
+
+		var isBundle bool
+		err := tx.QueryRowContext(ctx, "SELECT is_bundle FROM products WHERE id = $1", line.ProductID).Scan(&isBundle)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, fmt.Errorf("product with ID %d not found", line.ProductID)
+			}
+			return nil, err
+		}
+		if isBundle {
+			return nil, fmt.Errorf("product with ID %d is a bundle, transfer its components instead", line.ProductID)
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO stock_transfer_lines (stock_transfer_id, product_id, requested_quantity) VALUES ($1, $2, $3)",
+			id, line.ProductID, line.Quantity,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// Dispatch takes the transfer's stock out of the source outlet, after which it is in transit
+func (r *StockTransferRepository) Dispatch(id int, req models.TransferDispatchRequest) (*models.StockTransfer, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	st, err := lockStockTransfer(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if st.Status != models.TransferStatusRequested {
+		return nil, fmt.Errorf("stock transfer %d is %s and cannot be dispatched", id, st.Status)
+	}
+
+	quantities := make(map[int]models.Quantity)
+	for _, line := range req.Lines {
+		quantities[line.ProductID] = line.Quantity
+	}
+
+	lines, err := lockStockTransferLines(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	var total models.Quantity
+	for _, l := range lines {
+		qty, ok := quantities[l.ProductID]
+		if !ok {
+			qty = l.RequestedQuantity
+		}
+		delete(quantities, l.ProductID)
+		if qty > l.RequestedQuantity {
+			return nil, fmt.Errorf("cannot dispatch more %s than the %s requested", l.ProductName, l.RequestedQuantity)
+		}
+
+		movementID := 0
+		if qty > 0 {
+			stock, _, err := lockOutletStock(ctx, tx, st.SourceOutletID, l.ProductID)
+			if err != nil {
+				return nil, err
+			}
+			if stock < qty {
+				return nil, fmt.Errorf("insufficient stock for %s at %s (available %s, dispatching %s)",
+					l.ProductName, st.SourceOutletName, stock, qty)
+			}
+
+			movement, err := applyStockMovement(ctx, tx, models.StockMovement{
+				ProductID:     l.ProductID,
+				OutletID:      st.SourceOutletID,
+				Type:          models.MovementTransfer,
+				Quantity:      -qty,
+				Reason:        fmt.Sprintf("Transfer #%d to %s", id, st.DestinationOutletName),
+				Actor:         req.Actor,
+				ReferenceType: "stock_transfer",
+				ReferenceID:   id,
+			})
+			if err != nil {
+				return nil, err
+			}
+			movementID = movement.ID
+		}
+		total += qty
+
+		_, err = tx.ExecContext(ctx,
+			"UPDATE stock_transfer_lines SET dispatched_quantity = $1, dispatch_movement_id = NULLIF($2, 0) WHERE id = $3",
+			qty, movementID, l.ID,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for productID := range quantities {
+		return nil, fmt.Errorf("product with ID %d is not on stock transfer %d", productID, id)
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("nothing to dispatch, cancel the transfer instead")
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE stock_transfers SET status = $1, dispatched_by = NULLIF($2, ''), dispatched_at = CURRENT_TIMESTAMP
+		 WHERE id = $3`,
+		models.TransferStatusInTransit, req.Actor, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// Receive books the arrived stock into the destination outlet. Batches keep their batch number
+// and expiry date at the destination, any shortage is taken from the last-expiring batches.
+func (r *StockTransferRepository) Receive(id int, req models.TransferReceiveRequest) (*models.StockTransfer, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	st, err := lockStockTransfer(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if st.Status != models.TransferStatusInTransit {
+		return nil, fmt.Errorf("stock transfer %d is %s and cannot be received", id, st.Status)
+	}
+
+	received := make(map[int]models.TransferReceiveLineRequest)
+	for _, line := range req.Lines {
+		received[line.ProductID] = line
+	}
+
+	lines, err := lockStockTransferLines(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range lines {
+		line, ok := received[l.ProductID]
+		if !ok {
+			line = models.TransferReceiveLineRequest{ProductID: l.ProductID, Quantity: l.DispatchedQuantity}
+		}
+		delete(received, l.ProductID)
+		if line.Quantity != l.DispatchedQuantity && line.Reason == "" {
+			return nil, fmt.Errorf("received %s of %s but %s was dispatched, give a reason for the discrepancy",
+				line.Quantity, l.ProductName, l.DispatchedQuantity)
+		}
+
+		movementID := 0
+		if line.Quantity > 0 {
+			movement, err := applyStockMovement(ctx, tx, models.StockMovement{
+				ProductID:     l.ProductID,
+				OutletID:      st.DestinationOutletID,
+				Type:          models.MovementTransfer,
+				Quantity:      line.Quantity,
+				Reason:        fmt.Sprintf("Transfer #%d from %s", id, st.SourceOutletName),
+				Actor:         req.Actor,
+				ReferenceType: "stock_transfer",
+				ReferenceID:   id,
+			})
+			if err != nil {
+				return nil, err
+			}
+			movementID = movement.ID
+
+			if l.dispatchMovementID != 0 {
+				if err := restoreBatches(ctx, tx, movement, l.dispatchMovementID); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`UPDATE stock_transfer_lines SET received_quantity = $1, discrepancy_reason = NULLIF($2, ''),
+			 receive_movement_id = NULLIF($3, 0) WHERE id = $4`,
+			line.Quantity, line.Reason, movementID, l.ID,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for productID := range received {
+		return nil, fmt.Errorf("product with ID %d is not on stock transfer %d", productID, id)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE stock_transfers SET status = $1, received_by = NULLIF($2, ''), received_at = CURRENT_TIMESTAMP
+		 WHERE id = $3`,
+		models.TransferStatusReceived, req.Actor, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// Cancel cancels a transfer that has not been dispatched yet
+func (r *StockTransferRepository) Cancel(id int) (*models.StockTransfer, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	st, err := lockStockTransfer(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if st.Status != models.TransferStatusRequested {
+		return nil, fmt.Errorf("stock transfer %d is %s, only requested transfers can be cancelled", id, st.Status)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE stock_transfers SET status = $1 WHERE id = $2", models.TransferStatusCancelled, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// GetInTransit sums the stock dispatched but not yet received per product and route,
+// optionally only transfers from or to an outlet
+func (r *StockTransferRepository) GetInTransit(outletID int) ([]models.InTransitStock, error) {
+	rows, err := r.db.Query(
+		`SELECT p.id, p.name, p.unit, st.source_outlet_id, st.destination_outlet_id, d.name,
+		        SUM(l.dispatched_quantity), COUNT(DISTINCT st.id)
+		 FROM stock_transfer_lines l
+		 JOIN stock_transfers st ON st.id = l.stock_transfer_id
+		 JOIN outlets d ON d.id = st.destination_outlet_id
+		 JOIN products p ON p.id = l.product_id
+		 WHERE st.status = $1 AND l.dispatched_quantity > 0
+		   AND ($2 = 0 OR st.source_outlet_id = $2 OR st.destination_outlet_id = $2)
+		 GROUP BY p.id, p.name, p.unit, st.source_outlet_id, st.destination_outlet_id, d.name
+		 ORDER BY p.name, st.destination_outlet_id`,
+		models.TransferStatusInTransit, outletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stock []models.InTransitStock
+	for rows.Next() {
+		var s models.InTransitStock
+		if err := rows.Scan(&s.ProductID, &s.ProductName, &s.Unit, &s.SourceOutletID, &s.DestinationOutletID,
+			&s.DestinationOutletName, &s.Quantity, &s.Transfers); err != nil {
+			return nil, err
+		}
+		stock = append(stock, s)
+	}
+	return stock, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanStockTransfer(row rowScanner, st *models.StockTransfer) error {
+	return row.Scan(&st.ID, &st.SourceOutletID, &st.SourceOutletName, &st.DestinationOutletID, &st.DestinationOutletName,
+		&st.Status, &st.Notes, &st.RequestedBy, &st.RequestedAt, &st.DispatchedBy, &st.DispatchedAt,
+		&st.ReceivedBy, &st.ReceivedAt)
+}
+
+// lockStockTransfer locks a transfer for the rest of the transaction and returns it without lines
+func lockStockTransfer(ctx context.Context, tx *sql.Tx, id int) (*models.StockTransfer, error) {
+	var st models.StockTransfer
+	err := scanStockTransfer(tx.QueryRowContext(ctx,
+		`SELECT `+stockTransferColumns+stockTransferTables+` WHERE st.id = $1 FOR UPDATE OF st`, id), &st)
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+type lockedTransferLine struct {
+	models.StockTransferLine
+	dispatchMovementID int
+}
+
+// lockStockTransferLines locks the lines of a transfer
+func lockStockTransferLines(ctx context.Context, tx *sql.Tx, id int) ([]lockedTransferLine, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT l.id, l.product_id, p.name, l.requested_quantity, l.dispatched_quantity, COALESCE(l.dispatch_movement_id, 0)
+		 FROM stock_transfer_lines l
+		 JOIN products p ON p.id = l.product_id
+		 WHERE l.stock_transfer_id = $1
+		 ORDER BY l.id
+		 FOR UPDATE OF l`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []lockedTransferLine
+	for rows.Next() {
+		var l lockedTransferLine
+		if err := rows.Scan(&l.ID, &l.ProductID, &l.ProductName, &l.RequestedQuantity, &l.DispatchedQuantity,
+			&l.dispatchMovementID); err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+	return lines, rows.Err()
+}
+
+// dispatchedBatches returns the batches a stock decrease was taken from, first-expired-first-out
+func dispatchedBatches(ctx context.Context, q queryer, movementID int) ([]models.BatchAllocation, error) {
+	rows, err := q.QueryContext(ctx,
+		`SELECT b.id, b.batch_number, COALESCE(TO_CHAR(b.expiry_date, 'YYYY-MM-DD'), ''), -bm.quantity,
+		        COALESCE(b.expiry_date < CURRENT_DATE, false)
+		 FROM batch_movements bm
+		 JOIN product_batches b ON b.id = bm.batch_id
+		 WHERE bm.stock_movement_id = $1
+		 ORDER BY b.expiry_date NULLS LAST, b.id`, movementID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batches []models.BatchAllocation
+	for rows.Next() {
+		var b models.BatchAllocation
+		if err := rows.Scan(&b.BatchID, &b.BatchNumber, &b.ExpiryDate, &b.Quantity, &b.Expired); err != nil {
+			return nil, err
+		}
+		batches = append(batches, b)
+	}
+	return batches, rows.Err()
+}
+
+// restoreBatches books the incoming movement m into batches at its outlet with the batch numbers
+// and expiry dates an earlier decrease took the stock from, for transfers received at their destination
+// and sales refunded. Stock beyond those batches stays untracked, as it was when it left.
+func restoreBatches(ctx context.Context, tx *sql.Tx, m *models.StockMovement, fromMovementID int) error {
+	batches, err := dispatchedBatches(ctx, tx, fromMovementID)
+	if err != nil {
+		return err
+	}
+
+	remaining := m.Quantity
+	for _, b := range batches {
+		if remaining <= 0 {
+			break
+		}
+		part := *m
+		part.Quantity = min(remaining, b.Quantity)
+		if _, err := receiveBatch(ctx, tx, &part, b.BatchNumber, b.ExpiryDate); err != nil {
+			return err
+		}
+		remaining -= part.Quantity
+	}
+	return nil
+}
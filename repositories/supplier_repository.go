@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"database/sql"
+	"kasir-api/models"
+)
+
+type SupplierRepository struct {
+	db *sql.DB
+}
+
+func NewSupplierRepository(db *sql.DB) *SupplierRepository {
+	return &SupplierRepository{db: db}
+}
+
+func (r *SupplierRepository) GetAll() ([]models.Supplier, error) {
+	rows, err := r.db.Query("SELECT id, name, contact_name, phone, email, address FROM suppliers ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suppliers []models.Supplier
+	for rows.Next() {
+		var s models.Supplier
+		if err := rows.Scan(&s.ID, &s.Name, &s.ContactName, &s.Phone, &s.Email, &s.Address); err != nil {
+			return nil, err
+		}
+		suppliers = append(suppliers, s)
+	}
+	return suppliers, nil
+}
+
+func (r *SupplierRepository) GetByID(id int) (*models.Supplier, error) {
+	var s models.Supplier
+	err := r.db.QueryRow("SELECT id, name, contact_name, phone, email, address FROM suppliers WHERE id = $1", id).
+		Scan(&s.ID, &s.Name, &s.ContactName, &s.Phone, &s.Email, &s.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *SupplierRepository) Create(req models.SupplierRequest) (*models.Supplier, error) {
+	var s models.Supplier
+	err := r.db.QueryRow(
+		`INSERT INTO suppliers (name, contact_name, phone, email, address) VALUES ($1, $2, $3, $4, $5)
+		 RETURNING id, name, contact_name, phone, email, address`,
+		req.Name, req.ContactName, req.Phone, req.Email, req.Address,
+	).Scan(&s.ID, &s.Name, &s.ContactName, &s.Phone, &s.Email, &s.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *SupplierRepository) Update(id int, req models.SupplierRequest) (*models.Supplier, error) {
+	var s models.Supplier
+	err := r.db.QueryRow(
+		`UPDATE suppliers SET name = $1, contact_name = $2, phone = $3, email = $4, address = $5 WHERE id = $6
+		 RETURNING id, name, contact_name, phone, email, address`,
+		req.Name, req.ContactName, req.Phone, req.Email, req.Address, id,
+	).Scan(&s.ID, &s.Name, &s.ContactName, &s.Phone, &s.Email, &s.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *SupplierRepository) Delete(id int) error {
+	_, err := r.db.Exec("DELETE FROM suppliers WHERE id = $1", id)
+	return err
+}
@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+)
+
+// tableColumns reads a dining table with the active dine-in order sitting at it, if any
+const tableColumns = `t.id, t.outlet_id, t.name, COALESCE(t.area, ''), t.seats, t.active, t.created_at,
+	COALESCE((SELECT ot.order_id FROM dine_in_order_tables ot
+	          JOIN dine_in_orders d ON d.id = ot.order_id
+	          WHERE ot.table_id = t.id AND d.status = ANY($1::text[]) LIMIT 1), 0)`
+
+// activeDineInStatuses are the statuses of orders still occupying their tables
+var activeDineInStatuses = []string{models.DineInOpen, models.DineInSent, models.DineInServed, models.DineInBilled}
+
+type TableRepository struct {
+	db *sql.DB
+}
+
+func NewTableRepository(db *sql.DB) *TableRepository {
+	return &TableRepository{db: db}
+}
+
+// GetAll lists tables by area and name, optionally of one outlet and one area
+func (r *TableRepository) GetAll(outletID int, area string) ([]models.Table, error) {
+	rows, err := r.db.Query(
+		`SELECT `+tableColumns+` FROM dining_tables t
+		 WHERE ($2 = 0 OR t.outlet_id = $2) AND ($3 = '' OR t.area = $3)
+		 ORDER BY t.outlet_id, t.area NULLS FIRST, t.name`,
+		activeDineInStatuses, outletID, area)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := []models.Table{}
+	for rows.Next() {
+		table, err := scanTable(rows)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, *table)
+	}
+	return tables, rows.Err()
+}
+
+func (r *TableRepository) GetByID(id int) (*models.Table, error) {
+	return scanTable(r.db.QueryRow("SELECT "+tableColumns+" FROM dining_tables t WHERE t.id = $2", activeDineInStatuses, id))
+}
+
+// Create adds a table to the request's outlet, or the default outlet
+func (r *TableRepository) Create(req models.TableRequest) (*models.Table, error) {
+	ctx := context.Background()
+	outletID, err := resolveOutlet(ctx, r.db, req.OutletID)
+	if err != nil {
+		return nil, err
+	}
+
+	var id int
+	err = r.db.QueryRow(
+		`INSERT INTO dining_tables (outlet_id, name, area, seats, active) VALUES ($1, $2, NULLIF($3, ''), $4, $5)
+		 ON CONFLICT (outlet_id, name) DO NOTHING RETURNING id`,
+		outletID, req.Name, req.Area, req.Seats, req.Active == nil || *req.Active,
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("table %s already exists at this outlet", req.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// Update changes a table, Active is kept when not given
+func (r *TableRepository) Update(id int, req models.TableRequest) (*models.Table, error) {
+	result, err := r.db.Exec(
+		`UPDATE dining_tables SET name = $1, area = NULLIF($2, ''), seats = $3, active = COALESCE($4, active)
+		 WHERE id = $5`,
+		req.Name, req.Area, req.Seats, req.Active, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return r.GetByID(id)
+}
+
+// Delete removes a table that no order is sitting at
+func (r *TableRepository) Delete(id int) error {
+	table, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if table.OrderID != 0 {
+		return fmt.Errorf("table %s is occupied by order %d", table.Name, table.OrderID)
+	}
+
+	_, err = r.db.Exec("DELETE FROM dining_tables WHERE id = $1", id)
+	return err
+}
+
+func scanTable(row rowScanner) (*models.Table, error) {
+	var t models.Table
+	err := row.Scan(&t.ID, &t.OutletID, &t.Name, &t.Area, &t.Seats, &t.Active, &t.CreatedAt, &t.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	t.Status = models.TableFree
+	if t.OrderID != 0 {
+		t.Status = models.TableOccupied
+	}
+	return &t, nil
+}
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"kasir-api/models"
+)
+
+const tenantColumns = "id, slug, name, active, settings, created_at, updated_at"
+
+// TenantRepository manages the tenant registry. The tenants table is not tenant-scoped
+// and is read through the deployment's own pool.
+type TenantRepository struct {
+	db *sql.DB
+}
+
+func NewTenantRepository(db *sql.DB) *TenantRepository {
+	return &TenantRepository{db: db}
+}
+
+func (r *TenantRepository) GetAll() ([]models.Tenant, error) {
+	rows, err := r.db.Query("SELECT " + tenantColumns + " FROM tenants ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tenants []models.Tenant
+	for rows.Next() {
+		t, err := scanTenant(rows)
+		if err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, *t)
+	}
+	return tenants, rows.Err()
+}
+
+func (r *TenantRepository) GetByID(id int) (*models.Tenant, error) {
+	return scanTenant(r.db.QueryRow("SELECT "+tenantColumns+" FROM tenants WHERE id = $1", id))
+}
+
+// GetByAPIKeyHash finds the tenant an API key belongs to
+func (r *TenantRepository) GetByAPIKeyHash(hash string) (*models.Tenant, error) {
+	return scanTenant(r.db.QueryRow("SELECT "+tenantColumns+" FROM tenants WHERE api_key_hash = $1", hash))
+}
+
+func (r *TenantRepository) Create(req models.TenantRequest, apiKeyHash string) (*models.Tenant, error) {
+	settings, err := json.Marshal(req.Settings)
+	if err != nil {
+		return nil, err
+	}
+	active := req.Active == nil || *req.Active
+
+	var id int
+	err = r.db.QueryRow(
+		"INSERT INTO tenants (slug, name, active, settings, api_key_hash) VALUES ($1, $2, $3, $4::jsonb, $5) RETURNING id",
+		req.Slug, req.Name, active, string(settings), apiKeyHash,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// Update changes a tenant's name, status and settings. Active is kept when not given.
+func (r *TenantRepository) Update(id int, req models.TenantRequest) (*models.Tenant, error) {
+	settings, err := json.Marshal(req.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.db.Exec(
+		`UPDATE tenants SET slug = $1, name = $2, active = COALESCE($3, active), settings = $4::jsonb,
+		 updated_at = CURRENT_TIMESTAMP WHERE id = $5`,
+		req.Slug, req.Name, req.Active, string(settings), id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return r.GetByID(id)
+}
+
+// SetAPIKeyHash replaces a tenant's API key, the previous key stops working immediately
+func (r *TenantRepository) SetAPIKeyHash(id int, hash string) error {
+	result, err := r.db.Exec(
+		"UPDATE tenants SET api_key_hash = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2", hash, id,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Delete removes a tenant that has no data, used to undo a failed provisioning
+func (r *TenantRepository) Delete(id int) error {
+	_, err := r.db.Exec("DELETE FROM tenants WHERE id = $1", id)
+	return err
+}
+
+func scanTenant(row rowScanner) (*models.Tenant, error) {
+	var t models.Tenant
+	var settings []byte
+	if err := row.Scan(&t.ID, &t.Slug, &t.Name, &t.Active, &settings, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(settings, &t.Settings); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
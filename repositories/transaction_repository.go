@@ -4,15 +4,22 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"kasir-api/events"
 	"kasir-api/models"
+	"slices"
 )
 
 type TransactionRepository struct {
 	db *sql.DB
+	// expiredSalePolicy is models.ExpiredSaleBlock or models.ExpiredSaleWarn
+	expiredSalePolicy string
+	loyalty           models.LoyaltyParams
+	// giftCardExpiryDays is how long sold gift cards stay valid, 0 for no expiry
+	giftCardExpiryDays int
 }
 
-func NewTransactionRepository(db *sql.DB) *TransactionRepository {
-	return &TransactionRepository{db: db}
+func NewTransactionRepository(db *sql.DB, expiredSalePolicy string, loyalty models.LoyaltyParams, giftCardExpiryDays int) *TransactionRepository {
+	return &TransactionRepository{db: db, expiredSalePolicy: expiredSalePolicy, loyalty: loyalty, giftCardExpiryDays: giftCardExpiryDays}
 }
 
 func (r *TransactionRepository) Create(req models.CheckoutRequest) (*models.Transaction, error) {
@@ -24,16 +31,84 @@ func (r *TransactionRepository) Create(req models.CheckoutRequest) (*models.Tran
 	// Defer rollback in case of panic or error (if not committed)
 	defer tx.Rollback()
 
-	var totalAmount int
+	transaction, err := r.checkout(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 5. Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// checkout records a sale within tx: it prices the items, takes them out of stock and settles
+// points, gift cards and the customer's account. A sale awaiting payment, by QRIS or as the bill of
+// a dine-in order, is recorded pending with its stock reserved, and earns its points and goes to the
+// kitchen and out as an event once paid. A bill that points, gift cards or the account cover is paid.
+func (r *TransactionRepository) checkout(ctx context.Context, tx *sql.Tx, req models.CheckoutRequest) (*models.Transaction, error) {
+	// A parked cart brings its own items, outlet and customer
+	if req.DraftOrderID != 0 {
+		if err := claimDraftOrder(ctx, tx, &req); err != nil {
+			return nil, err
+		}
+	}
+
+	// 1. Create Transaction record at the outlet for the customer, if any. The total is filled in once all items are priced
+	var transaction models.Transaction
+	var err error
+	transaction.OutletID, err = resolveOutlet(ctx, tx, req.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	transaction.CustomerID, err = resolveCustomer(ctx, tx, req.CustomerID, req.CustomerPhone)
+	if err != nil {
+		return nil, err
+	}
+	transaction.PriceListID, err = resolvePriceList(ctx, tx, req.PriceListID, req.CustomerGroup, transaction.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	awaitPayment := req.AwaitPayment || req.DineInOrderID != 0
+	transaction.PaymentStatus = models.PaymentPaid
+	if awaitPayment {
+		transaction.PaymentStatus = models.PaymentPending
+	}
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO transactions (outlet_id, customer_id, price_list_id, total_amount, payment_status)
+		 VALUES ($1, NULLIF($2, 0), NULLIF($3, 0), 0, $4) RETURNING id, created_at`,
+		transaction.OutletID, transaction.CustomerID, transaction.PriceListID, transaction.PaymentStatus,
+	).Scan(&transaction.ID, &transaction.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stock leaves the outlet through the ledger as a sale referencing this transaction
+	sale := models.StockMovement{
+		OutletID:      transaction.OutletID,
+		Type:          models.MovementSale,
+		Actor:         req.Actor,
+		ReferenceType: "transaction",
+		ReferenceID:   transaction.ID,
+	}
+
+	var totalAmount, loyaltyAmount int
 	var details []models.TransactionDetail
 
-	// 1. Calculate total and validate stock for all items
+	// 2. Calculate total, validate and decrease stock for all items
 	for _, item := range req.Items {
-		var price, stock int
-		var name string
+		var price, costPrice, categoryID int
+		var name, baseUnit, saleUnit string
+		var isBundle, allowFraction bool
 
 		// Get product info and lock row for update
-		err := tx.QueryRowContext(ctx, "SELECT name, price, stock FROM products WHERE id = $1 FOR UPDATE", item.ProductID).Scan(&name, &price, &stock)
+		err := tx.QueryRowContext(ctx,
+			`SELECT name, price, cost_price, is_bundle, unit, sale_unit, allow_fraction, COALESCE(category_id, 0)
+			 FROM products WHERE id = $1 FOR UPDATE`,
+			item.ProductID,
+		).Scan(&name, &price, &costPrice, &isBundle, &baseUnit, &saleUnit, &allowFraction, &categoryID)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				return nil, fmt.Errorf("product with ID %d not found", item.ProductID)
@@ -41,30 +116,171 @@ func (r *TransactionRepository) Create(req models.CheckoutRequest) (*models.Tran
 			return nil, err
 		}
 
-		if stock < item.Quantity {
-			return nil, fmt.Errorf("insufficient stock for product %s (ID: %d)", name, item.ProductID)
+		// Stock and an optional price override come from the outlet
+		stock, outletPrice, err := lockOutletStock(ctx, tx, transaction.OutletID, item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if outletPrice != nil {
+			price = *outletPrice
+		}
+
+		unit := item.Unit
+		if unit == "" {
+			unit = saleUnit
+		}
+		factor, unitPrice, err := resolveUnit(ctx, tx, item.ProductID, baseUnit, unit, price)
+		if err != nil {
+			return nil, err
+		}
+		// The price list, when it prices the product in this unit, wins over regular and outlet prices
+		listPrice, err := priceFromList(ctx, tx, transaction.PriceListID, item.ProductID, unit, item.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		var priceListID int
+		if listPrice != nil {
+			unitPrice, priceListID = *listPrice, transaction.PriceListID
+		}
+
+		baseQuantity, err := item.Quantity.Mul(factor)
+		if err != nil {
+			return nil, fmt.Errorf("product %s (ID: %d): %w", name, item.ProductID, err)
+		}
+		if !allowFraction && !baseQuantity.IsWhole() {
+			return nil, fmt.Errorf("product %s (ID: %d) cannot be sold in fractions of a %s", name, item.ProductID, baseUnit)
 		}
 
-		subtotal := price * item.Quantity
+		subtotal, err := item.Quantity.MulPrice(unitPrice)
+		if err != nil {
+			return nil, fmt.Errorf("product %s (ID: %d): %w", name, item.ProductID, err)
+		}
 		totalAmount += subtotal
+		if !slices.Contains(r.loyalty.ExcludedCategories, categoryID) {
+			loyaltyAmount += subtotal
+		}
+
+		detail := models.TransactionDetail{
+			ProductID:    item.ProductID,
+			ProductName:  name,
+			Quantity:     item.Quantity,
+			Unit:         unit,
+			BaseQuantity: baseQuantity,
+			UnitPrice:    unitPrice,
+			PriceListID:  priceListID,
+			Subtotal:     subtotal,
+		}
+
+		if isBundle {
+			// A bundle is priced as one line but its stock and cost live in the components
+			detail.Components, err = r.sellBundleComponents(ctx, tx, item.ProductID, name, baseQuantity, subtotal, sale)
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range detail.Components {
+				detail.COGS += c.COGS
+				transaction.Warnings = append(transaction.Warnings, expiredWarnings(c.ProductName, c.Batches)...)
+			}
+			detail.UnitCost = divRoundQuantity(detail.COGS, baseQuantity)
+		} else {
+			if stock < baseQuantity {
+				return nil, fmt.Errorf("insufficient stock for product %s (ID: %d)", name, item.ProductID)
+			}
+			if err := r.checkExpiredStock(ctx, tx, transaction.OutletID, item.ProductID, name, stock, baseQuantity); err != nil {
+				return nil, err
+			}
+
+			// Decrease stock
+			sale.ProductID = item.ProductID
+			sale.Quantity = -baseQuantity
+			movement, err := applyStockMovement(ctx, tx, sale)
+			if err != nil {
+				return nil, err
+			}
+			detail.Batches = movement.Batches
+			transaction.Warnings = append(transaction.Warnings, expiredWarnings(name, movement.Batches)...)
+
+			// Snapshot the cost at the time of sale
+			detail.UnitCost = costPrice
+			detail.COGS, err = baseQuantity.MulPrice(costPrice)
+			if err != nil {
+				return nil, fmt.Errorf("product %s (ID: %d): %w", name, item.ProductID, err)
+			}
+		}
+
+		details = append(details, detail)
+	}
+
+	// Products this sale took from above their minimum stock to at or below it
+	transaction.LowStock, err = crossedMinStock(ctx, tx, transaction.ID, transaction.OutletID)
+	if err != nil {
+		return nil, err
+	}
 
-		// 2. Decrease stock
-		_, err = tx.ExecContext(ctx, "UPDATE products SET stock = stock - $1 WHERE id = $2", item.Quantity, item.ProductID)
+	// Gift cards sold are paid for like items, but earn no points
+	for _, card := range req.GiftCards {
+		issued, err := issueGiftCard(ctx, tx, transaction.ID, card, r.giftCardExpiryDays)
 		if err != nil {
 			return nil, err
 		}
-
-		details = append(details, models.TransactionDetail{
-			ProductID:   item.ProductID,
-			ProductName: name,
-			Quantity:    item.Quantity,
-			Subtotal:    subtotal,
-		})
+		transaction.IssuedGiftCards = append(transaction.IssuedGiftCards, *issued)
+		transaction.GiftCardsSold += issued.InitialAmount
+		totalAmount += issued.InitialAmount
 	}
 
-	// 3. Create Transaction record
-	var transaction models.Transaction
-	err = tx.QueryRowContext(ctx, "INSERT INTO transactions (total_amount) VALUES ($1) RETURNING id, total_amount, created_at", totalAmount).Scan(&transaction.ID, &transaction.TotalAmount, &transaction.CreatedAt)
+	// 3. Store the total and settle the customer's loyalty points, then the gift card payments and the account charge
+	transaction.TotalAmount = totalAmount
+	if err := r.settlePoints(ctx, tx, &transaction, req.RedeemPoints, loyaltyAmount); err != nil {
+		return nil, err
+	}
+	transaction.AmountDue = transaction.TotalAmount - transaction.PointsAmount
+	if len(req.GiftCardPayments) > 0 && transaction.GiftCardsSold > 0 {
+		return nil, fmt.Errorf("gift cards cannot be bought with gift cards")
+	}
+	for _, payment := range req.GiftCardPayments {
+		redemption, err := redeemGiftCard(ctx, tx, transaction.ID, payment, transaction.AmountDue)
+		if err != nil {
+			return nil, err
+		}
+		transaction.GiftCardPayments = append(transaction.GiftCardPayments, *redemption)
+		transaction.GiftCardAmount += redemption.Amount
+		transaction.AmountDue -= redemption.Amount
+	}
+	if req.OnAccount {
+		if transaction.GiftCardsSold > 0 {
+			return nil, fmt.Errorf("gift cards cannot be bought on account")
+		}
+		if err := chargeAccount(ctx, tx, transaction.CustomerID, transaction.AmountDue); err != nil {
+			return nil, err
+		}
+		transaction.OnAccountAmount, transaction.AmountDue = transaction.AmountDue, 0
+	}
+	if req.AwaitPayment {
+		switch {
+		case req.OnAccount:
+			return nil, fmt.Errorf("a sale on account cannot be paid by QRIS")
+		case transaction.GiftCardsSold > 0:
+			return nil, fmt.Errorf("gift cards cannot be bought by QRIS")
+		case transaction.AmountDue <= 0:
+			return nil, fmt.Errorf("nothing is left to pay by QRIS")
+		}
+	}
+	if transaction.AmountDue <= 0 {
+		transaction.PaymentStatus = models.PaymentPaid
+	}
+	if transaction.PaymentStatus == models.PaymentPaid {
+		if err := r.creditPoints(ctx, tx, transaction); err != nil {
+			return nil, err
+		}
+	}
+	_, err = tx.ExecContext(ctx,
+		`UPDATE transactions SET total_amount = $1, points_redeemed = $2, points_amount = $3, points_earned = $4,
+		 gift_cards_sold = $5, gift_card_amount = $6, on_account_amount = $7, payment_status = $8
+		 WHERE id = $9`,
+		transaction.TotalAmount, transaction.PointsRedeemed, transaction.PointsAmount, transaction.PointsEarned,
+		transaction.GiftCardsSold, transaction.GiftCardAmount, transaction.OnAccountAmount, transaction.PaymentStatus,
+		transaction.ID,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -73,22 +289,505 @@ func (r *TransactionRepository) Create(req models.CheckoutRequest) (*models.Tran
 	for i, detail := range details {
 		var detailID int
 		err := tx.QueryRowContext(ctx,
-			"INSERT INTO transaction_details (transaction_id, product_id, quantity, subtotal) VALUES ($1, $2, $3, $4) RETURNING id",
-			transaction.ID, detail.ProductID, detail.Quantity, detail.Subtotal,
+			`INSERT INTO transaction_details (transaction_id, product_id, quantity, unit, base_quantity, unit_price, price_list_id, subtotal, unit_cost, cogs)
+			 VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, 0), $8, $9, $10) RETURNING id`,
+			transaction.ID, detail.ProductID, detail.Quantity, detail.Unit, detail.BaseQuantity, detail.UnitPrice, detail.PriceListID, detail.Subtotal, detail.UnitCost, detail.COGS,
 		).Scan(&detailID)
 		if err != nil {
 			return nil, err
 		}
 		details[i].ID = detailID
 		details[i].TransactionID = transaction.ID
+
+		for _, component := range detail.Components {
+			_, err := tx.ExecContext(ctx,
+				`INSERT INTO transaction_detail_components (transaction_detail_id, product_id, quantity, allocated_amount, unit_cost, cogs)
+				 VALUES ($1, $2, $3, $4, $5, $6)`,
+				detailID, component.ProductID, component.Quantity, component.AllocatedAmount, component.UnitCost, component.COGS,
+			)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	transaction.Details = details
 
-	// 5. Commit transaction
-	if err = tx.Commit(); err != nil {
-		return nil, err
+	// Send the items to the stations preparing them, dine-in items went to the kitchen with their round
+	// and a sale awaiting payment goes once paid
+	if req.DineInOrderID == 0 && transaction.PaymentStatus == models.PaymentPaid {
+		transaction.KitchenItems, err = routeToKitchen(ctx, tx, kitchenItemsFor(&transaction))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if req.DraftOrderID != 0 {
+		if err := convertDraftOrder(ctx, tx, req.DraftOrderID, transaction.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	// The sale's events are committed with it, a sale awaiting payment is announced once paid
+	if transaction.PaymentStatus == models.PaymentPaid {
+		if err := announceSale(ctx, tx, transaction); err != nil {
+			return nil, err
+		}
 	}
 
 	return &transaction, nil
 }
+
+// announceSale commits the events of a paid sale: the sale itself and the products it took to or
+// below their minimum stock
+func announceSale(ctx context.Context, tx *sql.Tx, t models.Transaction) error {
+	if err := addOutboxEvent(ctx, tx, events.TypeTransactionCreated, t); err != nil {
+		return err
+	}
+	for _, item := range t.LowStock {
+		if err := addOutboxEvent(ctx, tx, events.TypeStockLow, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// completeSale settles a sale that awaited payment within tx once it is paid: the customer is credited
+// the points it earned, and it is announced with the products it took to or below their minimum stock
+// then, as checkout does for a sale paid at once
+func (r *TransactionRepository) completeSale(ctx context.Context, tx *sql.Tx, t *models.Transaction) error {
+	t.PaymentStatus = models.PaymentPaid
+	if _, err := tx.ExecContext(ctx, "UPDATE transactions SET payment_status = $1 WHERE id = $2", t.PaymentStatus, t.ID); err != nil {
+		return err
+	}
+
+	if err := r.creditPoints(ctx, tx, *t); err != nil {
+		return err
+	}
+
+	var err error
+	t.LowStock, err = crossedMinStock(ctx, tx, t.ID, t.OutletID)
+	if err != nil {
+		return err
+	}
+	return announceSale(ctx, tx, *t)
+}
+
+// kitchenItemsFor lists the items of a sale for the kitchen stations
+func kitchenItemsFor(t *models.Transaction) []models.KitchenItem {
+	kitchen := make([]models.KitchenItem, len(t.Details))
+	for i, detail := range t.Details {
+		kitchen[i] = models.KitchenItem{
+			OutletID:      t.OutletID,
+			TransactionID: t.ID,
+			ProductID:     detail.ProductID,
+			Quantity:      detail.Quantity,
+			Unit:          detail.Unit,
+		}
+	}
+	return kitchen
+}
+
+// settlePoints pays part of the transaction with redeem of the customer's points, and works out the
+// points earned on eligibleAmount, the spend outside excluded categories. Points pay for eligible and
+// excluded items alike, so only the eligible share of what is left to pay earns points. The points
+// earned are credited by creditPoints once the sale is paid.
+func (r *TransactionRepository) settlePoints(ctx context.Context, tx *sql.Tx, t *models.Transaction, redeem, eligibleAmount int) error {
+	if t.CustomerID == 0 {
+		if redeem > 0 {
+			return fmt.Errorf("redeeming points requires a customer")
+		}
+		return nil
+	}
+
+	balance, err := lockLoyaltyBalance(ctx, tx, t.CustomerID)
+	if err != nil {
+		return err
+	}
+	balance, err = expirePoints(ctx, tx, t.CustomerID, balance)
+	if err != nil {
+		return err
+	}
+
+	if redeem > 0 {
+		t.PointsAmount, err = redeemPoints(r.loyalty, redeem, balance, t.TotalAmount)
+		if err != nil {
+			return err
+		}
+		if err := spendPoints(ctx, tx, t.CustomerID, redeem, 0); err != nil {
+			return err
+		}
+		entry := models.LoyaltyEntry{
+			CustomerID:    t.CustomerID,
+			TransactionID: t.ID,
+			Type:          models.LoyaltyRedeem,
+			Points:        -redeem,
+		}
+		if _, err := addLoyaltyEntry(ctx, tx, &entry, balance); err != nil {
+			return err
+		}
+		t.PointsRedeemed = redeem
+	}
+
+	t.PointsEarned = earnedPoints(r.loyalty, eligiblePaid(eligibleAmount, t.TotalAmount, t.PointsAmount))
+	return nil
+}
+
+// creditPoints credits the customer of a paid sale the points it earned
+func (r *TransactionRepository) creditPoints(ctx context.Context, tx *sql.Tx, t models.Transaction) error {
+	if t.CustomerID == 0 || t.PointsEarned <= 0 {
+		return nil
+	}
+	balance, err := lockLoyaltyBalance(ctx, tx, t.CustomerID)
+	if err != nil {
+		return err
+	}
+	balance, err = expirePoints(ctx, tx, t.CustomerID, balance)
+	if err != nil {
+		return err
+	}
+	entry := models.LoyaltyEntry{
+		CustomerID:    t.CustomerID,
+		TransactionID: t.ID,
+		Type:          models.LoyaltyEarn,
+		Points:        t.PointsEarned,
+		ExpiresAt:     pointsExpiry(r.loyalty),
+	}
+	_, err = addLoyaltyEntry(ctx, tx, &entry, balance)
+	return err
+}
+
+// Refund refunds a whole transaction. The sold stock returns to the outlet and to the batches it was
+// sold from, points earned with the sale are taken back and points it was paid with are given back.
+// Gift card balances it spent are restored and the gift cards it sold are voided.
+// What the customer has not yet repaid of an on-account sale is written off their account.
+func (r *TransactionRepository) Refund(id int, req models.RefundRequest) (*models.Transaction, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	t, err := lockTransaction(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t.RefundedAt != nil {
+		return nil, fmt.Errorf("transaction %d was already refunded", id)
+	}
+	if t.PaymentStatus != models.PaymentPaid {
+		return nil, fmt.Errorf("transaction %d is not paid, its payment is %s", id, t.PaymentStatus)
+	}
+	if err := r.reverseSale(ctx, tx, *t, req.Reason, req.Actor); err != nil {
+		return nil, err
+	}
+
+	var refundedAt sql.NullTime
+	var reason sql.NullString
+	err = tx.QueryRowContext(ctx,
+		`UPDATE transactions SET refunded_at = CURRENT_TIMESTAMP, refund_reason = NULLIF($1, '')
+		 WHERE id = $2 RETURNING refunded_at, refund_reason`,
+		req.Reason, id,
+	).Scan(&refundedAt, &reason)
+	if err != nil {
+		return nil, err
+	}
+	t.RefundedAt = &refundedAt.Time
+	t.RefundReason = reason.String
+	if err := addOutboxEvent(ctx, tx, events.TypeTransactionRefunded, t); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// lockTransaction locks a transaction for update and returns it without its items
+func lockTransaction(ctx context.Context, tx *sql.Tx, id int) (*models.Transaction, error) {
+	var t models.Transaction
+	var refundedAt sql.NullTime
+	err := tx.QueryRowContext(ctx,
+		`SELECT id, outlet_id, COALESCE(customer_id, 0), total_amount, gift_cards_sold, points_redeemed, points_amount,
+		        gift_card_amount, on_account_amount, points_earned, created_at, payment_status, refunded_at
+		 FROM transactions WHERE id = $1 FOR UPDATE`, id,
+	).Scan(&t.ID, &t.OutletID, &t.CustomerID, &t.TotalAmount, &t.GiftCardsSold, &t.PointsRedeemed, &t.PointsAmount,
+		&t.GiftCardAmount, &t.OnAccountAmount, &t.PointsEarned, &t.CreatedAt, &t.PaymentStatus, &refundedAt)
+	if err != nil {
+		return nil, err
+	}
+	if refundedAt.Valid {
+		t.RefundedAt = &refundedAt.Time
+	}
+	t.AmountDue = t.TotalAmount - t.PointsAmount - t.GiftCardAmount - t.OnAccountAmount
+	return &t, nil
+}
+
+// transactionDetails returns the items of a transaction as recorded at checkout, without their
+// bundle components and batches
+func transactionDetails(ctx context.Context, q queryer, transactionID int) ([]models.TransactionDetail, error) {
+	rows, err := q.QueryContext(ctx,
+		`SELECT td.id, td.product_id, p.name, td.quantity, td.unit, td.base_quantity, td.unit_price,
+		        COALESCE(td.price_list_id, 0), td.subtotal, td.unit_cost, td.cogs
+		 FROM transaction_details td
+		 JOIN products p ON p.id = td.product_id
+		 WHERE td.transaction_id = $1
+		 ORDER BY td.id`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var details []models.TransactionDetail
+	for rows.Next() {
+		d := models.TransactionDetail{TransactionID: transactionID}
+		if err := rows.Scan(&d.ID, &d.ProductID, &d.ProductName, &d.Quantity, &d.Unit, &d.BaseQuantity, &d.UnitPrice,
+			&d.PriceListID, &d.Subtotal, &d.UnitCost, &d.COGS); err != nil {
+			return nil, err
+		}
+		details = append(details, d)
+	}
+	return details, rows.Err()
+}
+
+// reverseSale undoes what a sale did within tx: its stock returns to the outlet and to the batches
+// it was sold from, its points are reversed or given back and its gift cards are restored or voided
+func (r *TransactionRepository) reverseSale(ctx context.Context, tx *sql.Tx, t models.Transaction, reason, actor string) error {
+	// Every sale movement is returned, so bundles come back as their components
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id, product_id, outlet_id, -quantity FROM stock_movements
+		 WHERE reference_type = 'transaction' AND reference_id = $1 AND movement_type = $2
+		 ORDER BY id`, t.ID, models.MovementSale)
+	if err != nil {
+		return err
+	}
+	var saleIDs []int
+	var returns []models.StockMovement
+	for rows.Next() {
+		var saleID int
+		m := models.StockMovement{
+			Type:          models.MovementRefund,
+			Reason:        reason,
+			Actor:         actor,
+			ReferenceType: "transaction",
+			ReferenceID:   t.ID,
+		}
+		if err := rows.Scan(&saleID, &m.ProductID, &m.OutletID, &m.Quantity); err != nil {
+			rows.Close()
+			return err
+		}
+		saleIDs = append(saleIDs, saleID)
+		returns = append(returns, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i, m := range returns {
+		if _, _, err := lockOutletStock(ctx, tx, m.OutletID, m.ProductID); err != nil {
+			return err
+		}
+		movement, err := applyStockMovement(ctx, tx, m)
+		if err != nil {
+			return err
+		}
+		if err := restoreBatches(ctx, tx, movement, saleIDs[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := r.refundPoints(ctx, tx, t, reason); err != nil {
+		return err
+	}
+	return refundGiftCards(ctx, tx, t.ID)
+}
+
+// refundPoints reverses the points a refunded transaction earned and gives back the points it was paid with.
+// A reversal of points the customer already spent leaves the balance negative until new points make up for it.
+// A sale given back before it was paid was never credited its points, so there are none to reverse.
+func (r *TransactionRepository) refundPoints(ctx context.Context, tx *sql.Tx, t models.Transaction, reason string) error {
+	reversed, restored := refundedPoints(t)
+	if t.CustomerID == 0 || (reversed == 0 && restored == 0) {
+		return nil
+	}
+
+	balance, err := lockLoyaltyBalance(ctx, tx, t.CustomerID)
+	if err != nil {
+		return err
+	}
+	balance, err = expirePoints(ctx, tx, t.CustomerID, balance)
+	if err != nil {
+		return err
+	}
+
+	if reversed > 0 {
+		if err := spendPoints(ctx, tx, t.CustomerID, reversed, t.ID); err != nil {
+			return err
+		}
+		entry := models.LoyaltyEntry{
+			CustomerID:    t.CustomerID,
+			TransactionID: t.ID,
+			Type:          models.LoyaltyReversal,
+			Points:        -reversed,
+			Reason:        reason,
+		}
+		if balance, err = addLoyaltyEntry(ctx, tx, &entry, balance); err != nil {
+			return err
+		}
+	}
+	if restored > 0 {
+		entry := models.LoyaltyEntry{
+			CustomerID:    t.CustomerID,
+			TransactionID: t.ID,
+			Type:          models.LoyaltyRestore,
+			Points:        restored,
+			ExpiresAt:     pointsExpiry(r.loyalty),
+			Reason:        reason,
+		}
+		if _, err := addLoyaltyEntry(ctx, tx, &entry, balance); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sellBundleComponents locks and sells the stock of every component of a bundle,
+// and splits the bundle subtotal across the components by their list price
+func (r *TransactionRepository) sellBundleComponents(ctx context.Context, tx *sql.Tx, bundleID int, bundleName string, quantity models.Quantity, subtotal int, sale models.StockMovement) ([]models.TransactionDetailComponent, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT c.id, c.name, c.price, c.cost_price, bi.quantity
+		 FROM product_bundle_items bi
+		 JOIN products c ON c.id = bi.component_id
+		 WHERE bi.bundle_id = $1
+		 ORDER BY c.id
+		 FOR UPDATE OF c`, bundleID)
+	if err != nil {
+		return nil, err
+	}
+
+	var components []models.TransactionDetailComponent
+	var weights []int
+	var stocks []models.Quantity
+	for rows.Next() {
+		var c models.TransactionDetailComponent
+		var price int
+		var perBundle models.Quantity
+		if err := rows.Scan(&c.ProductID, &c.ProductName, &price, &c.UnitCost, &perBundle); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		weight, err := componentAmounts(&c, perBundle, quantity, price)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("component %s (ID: %d) of bundle %s: %w", c.ProductName, c.ProductID, bundleName, err)
+		}
+		components = append(components, c)
+		weights = append(weights, weight)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(components) == 0 {
+		return nil, fmt.Errorf("bundle %s (ID: %d) has no components", bundleName, bundleID)
+	}
+
+	for _, c := range components {
+		stock, _, err := lockOutletStock(ctx, tx, sale.OutletID, c.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if stock < c.Quantity {
+			return nil, fmt.Errorf("insufficient stock for component %s (ID: %d) of bundle %s", c.ProductName, c.ProductID, bundleName)
+		}
+		stocks = append(stocks, stock)
+	}
+
+	shares := allocateAmount(subtotal, weights)
+	for i, c := range components {
+		if err := r.checkExpiredStock(ctx, tx, sale.OutletID, c.ProductID, c.ProductName, stocks[i], c.Quantity); err != nil {
+			return nil, err
+		}
+		sale.ProductID = c.ProductID
+		sale.Quantity = -c.Quantity
+		sale.Reason = "Sold in bundle " + bundleName
+		movement, err := applyStockMovement(ctx, tx, sale)
+		if err != nil {
+			return nil, err
+		}
+		components[i].AllocatedAmount = shares[i]
+		components[i].Batches = movement.Batches
+	}
+	return components, nil
+}
+
+// componentAmounts sets the quantity and COGS of a component sold in quantity bundles, and returns its
+// value at list price, its weight in the split of the bundle subtotal
+func componentAmounts(c *models.TransactionDetailComponent, perBundle, quantity models.Quantity, price int) (int, error) {
+	var err error
+	c.Quantity, err = perBundle.Mul(quantity)
+	if err != nil {
+		return 0, err
+	}
+	c.COGS, err = c.Quantity.MulPrice(c.UnitCost)
+	if err != nil {
+		return 0, err
+	}
+	return c.Quantity.MulPrice(price)
+}
+
+// checkExpiredStock rejects a sale that can only be filled from expired batches,
+// unless the expired sale policy allows it with a warning
+func (r *TransactionRepository) checkExpiredStock(ctx context.Context, tx *sql.Tx, outletID, productID int, name string, stock, quantity models.Quantity) error {
+	if r.expiredSalePolicy == models.ExpiredSaleWarn {
+		return nil
+	}
+	expired, err := expiredStock(ctx, tx, outletID, productID)
+	if err != nil {
+		return err
+	}
+	if stock-expired < quantity {
+		return fmt.Errorf("insufficient unexpired stock for product %s (ID: %d): %s in stock is past its expiry date", name, productID, expired)
+	}
+	return nil
+}
+
+// crossedMinStock lists the products whose stock at the outlet was above their minimum
+// before the transaction and is at or below it now
+func crossedMinStock(ctx context.Context, tx *sql.Tx, transactionID, outletID int) ([]models.LowStockItem, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT p.id, p.name, p.unit, os.stock, p.min_stock, p.reorder_quantity
+		 FROM products p
+		 JOIN outlet_stock os ON os.product_id = p.id AND os.outlet_id = $2
+		 JOIN (SELECT product_id, SUM(quantity) AS delta FROM stock_movements
+		       WHERE reference_type = 'transaction' AND reference_id = $1
+		       GROUP BY product_id) m ON m.product_id = p.id
+		 WHERE p.min_stock > 0 AND os.stock <= p.min_stock AND os.stock - m.delta > p.min_stock
+		 ORDER BY p.id`, transactionID, outletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.LowStockItem
+	for rows.Next() {
+		i := models.LowStockItem{TransactionID: transactionID, OutletID: outletID}
+		if err := rows.Scan(&i.ProductID, &i.ProductName, &i.Unit, &i.Stock, &i.MinStock, &i.ReorderQuantity); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	return items, rows.Err()
+}
+
+// divRoundQuantity returns amount per single unit of quantity, rounded to whole Rupiah
+func divRoundQuantity(amount int, quantity models.Quantity) int {
+	if quantity == 0 {
+		return 0
+	}
+	q := int64(quantity)
+	return int((int64(amount)*models.QuantityScale + q/2) / q)
+}
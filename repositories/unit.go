@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+)
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// resolveUnit returns how many base units one unit holds and the selling price per unit.
+// An empty unit or the base unit itself resolves to a factor of one at the base price.
+func resolveUnit(ctx context.Context, q queryer, productID int, baseUnit, unit string, basePrice int) (models.Quantity, int, error) {
+	if unit == "" || unit == baseUnit {
+		return models.NewQuantity(1), basePrice, nil
+	}
+
+	var factor models.Quantity
+	var price sql.NullInt64
+	err := q.QueryRowContext(ctx,
+		"SELECT factor, price FROM product_units WHERE product_id = $1 AND name = $2",
+		productID, unit,
+	).Scan(&factor, &price)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, 0, fmt.Errorf("unit %s is not defined for product with ID %d", unit, productID)
+		}
+		return 0, 0, err
+	}
+
+	if price.Valid {
+		return factor, int(price.Int64), nil
+	}
+	unitPrice, err := factor.MulPrice(basePrice)
+	if err != nil {
+		return 0, 0, err
+	}
+	return factor, unitPrice, nil
+}
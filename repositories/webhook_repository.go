@@ -0,0 +1,233 @@
+package repositories
+
+import (
+	"database/sql"
+	"kasir-api/models"
+	"strings"
+	"time"
+)
+
+const webhookSubscriptionColumns = "id, url, COALESCE(description, ''), array_to_string(event_types, ','), secret, active, created_at"
+
+const webhookDeliveryColumns = `id, subscription_id, event_id, event_type, payload, status, attempts,
+	COALESCE(last_status_code, 0), COALESCE(last_error, ''), next_attempt_at, created_at, delivered_at`
+
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) GetAll() ([]models.WebhookSubscription, error) {
+	rows, err := r.db.Query("SELECT " + webhookSubscriptionColumns + " FROM webhook_subscriptions ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions := []models.WebhookSubscription{}
+	for rows.Next() {
+		subscription, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, *subscription)
+	}
+	return subscriptions, rows.Err()
+}
+
+// GetByID returns the subscription with its secret
+func (r *WebhookRepository) GetByID(id int) (*models.WebhookSubscription, error) {
+	return scanWebhookSubscription(r.db.QueryRow("SELECT "+webhookSubscriptionColumns+" FROM webhook_subscriptions WHERE id = $1", id))
+}
+
+func (r *WebhookRepository) Create(req models.WebhookSubscriptionRequest) (*models.WebhookSubscription, error) {
+	var id int
+	err := r.db.QueryRow(
+		`INSERT INTO webhook_subscriptions (url, description, event_types, secret, active)
+		 VALUES ($1, NULLIF($2, ''), $3::text[], $4, $5) RETURNING id`,
+		req.URL, req.Description, nonNilStrings(req.EventTypes), req.Secret, req.Active == nil || *req.Active,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// Update changes a subscription, the secret and Active are kept when not given
+func (r *WebhookRepository) Update(id int, req models.WebhookSubscriptionRequest) (*models.WebhookSubscription, error) {
+	result, err := r.db.Exec(
+		`UPDATE webhook_subscriptions SET url = $1, description = NULLIF($2, ''), event_types = $3::text[],
+		 secret = COALESCE(NULLIF($4, ''), secret), active = COALESCE($5, active)
+		 WHERE id = $6`,
+		req.URL, req.Description, nonNilStrings(req.EventTypes), req.Secret, req.Active, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return r.GetByID(id)
+}
+
+// Delete removes a subscription with its delivery log
+func (r *WebhookRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM webhook_subscriptions WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetDeliveries lists a subscription's most recent deliveries, optionally of one status
+func (r *WebhookRepository) GetDeliveries(subscriptionID int, status string, limit int) ([]models.WebhookDelivery, error) {
+	if _, err := r.GetByID(subscriptionID); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(
+		`SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries
+		 WHERE subscription_id = $1 AND ($2 = '' OR status = $2)
+		 ORDER BY id DESC LIMIT $3`,
+		subscriptionID, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *WebhookRepository) GetDelivery(id int) (*models.WebhookDelivery, error) {
+	return scanWebhookDelivery(r.db.QueryRow("SELECT "+webhookDeliveryColumns+" FROM webhook_deliveries WHERE id = $1", id))
+}
+
+// CreateDeliveries queues an event for every active subscription to its type, or for subscriptionID
+// alone when it is not 0. The deliveries are created claimed, ready for their first attempt.
+// Subscriptions the event was queued for before are skipped.
+func (r *WebhookRepository) CreateDeliveries(subscriptionID int, eventID, eventType string, payload []byte) ([]int, error) {
+	rows, err := r.db.Query(
+		`INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, status)
+		 SELECT id, $1, $2, $3, $4 FROM webhook_subscriptions
+		 WHERE CASE WHEN $5 = 0 THEN active AND (cardinality(event_types) = 0 OR $2 = ANY(event_types)) ELSE id = $5 END
+		 ON CONFLICT (subscription_id, event_id) DO NOTHING
+		 RETURNING id`,
+		eventID, eventType, string(payload), models.WebhookPending, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ClaimDue claims up to limit pending deliveries whose next attempt is due, so that only one caller
+// retries each. A claimed delivery has no next attempt time until its attempt is recorded.
+func (r *WebhookRepository) ClaimDue(limit int) ([]int, error) {
+	rows, err := r.db.Query(
+		`UPDATE webhook_deliveries SET next_attempt_at = NULL
+		 WHERE id IN (SELECT id FROM webhook_deliveries
+		              WHERE status = $1 AND next_attempt_at <= LOCALTIMESTAMP
+		              ORDER BY next_attempt_at LIMIT $2
+		              FOR UPDATE SKIP LOCKED)
+		 RETURNING id`,
+		models.WebhookPending, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ClaimRedelivery claims a delivery of any status for one more attempt now.
+// It returns sql.ErrNoRows when the delivery does not exist or is being attempted.
+func (r *WebhookRepository) ClaimRedelivery(id int) error {
+	result, err := r.db.Exec(
+		`UPDATE webhook_deliveries SET status = $1, next_attempt_at = NULL
+		 WHERE id = $2 AND NOT (status = $1 AND next_attempt_at IS NULL)`,
+		models.WebhookPending, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RecordAttempt stores the outcome of an attempt. retryIn schedules the next attempt of a delivery
+// left pending.
+func (r *WebhookRepository) RecordAttempt(id int, status string, statusCode int, attemptErr string, retryIn time.Duration) error {
+	_, err := r.db.Exec(
+		`UPDATE webhook_deliveries SET status = $1, attempts = attempts + 1,
+		 last_status_code = NULLIF($2, 0), last_error = NULLIF($3, ''),
+		 next_attempt_at = CASE WHEN $1 = $4 THEN LOCALTIMESTAMP + make_interval(secs => $5) END,
+		 delivered_at = CASE WHEN $1 = $6 THEN LOCALTIMESTAMP ELSE delivered_at END
+		 WHERE id = $7`,
+		status, statusCode, attemptErr, models.WebhookPending, retryIn.Seconds(), models.WebhookDelivered, id)
+	return err
+}
+
+func scanWebhookSubscription(row rowScanner) (*models.WebhookSubscription, error) {
+	var s models.WebhookSubscription
+	var eventTypes string
+	if err := row.Scan(&s.ID, &s.URL, &s.Description, &eventTypes, &s.Secret, &s.Active, &s.CreatedAt); err != nil {
+		return nil, err
+	}
+	s.EventTypes = []string{}
+	if eventTypes != "" {
+		s.EventTypes = strings.Split(eventTypes, ",")
+	}
+	return &s, nil
+}
+
+func scanWebhookDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	var payload []byte
+	err := row.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts,
+		&d.LastStatusCode, &d.LastError, &d.NextAttemptAt, &d.CreatedAt, &d.DeliveredAt)
+	if err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+	return &d, nil
+}
+
+// nonNilStrings keeps an empty list from being stored as NULL
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
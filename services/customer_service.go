@@ -0,0 +1,42 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+type CustomerService struct {
+	repo *repositories.CustomerRepository
+}
+
+func NewCustomerService(repo *repositories.CustomerRepository) *CustomerService {
+	return &CustomerService{repo: repo}
+}
+
+func (s *CustomerService) GetAll(search string) ([]models.Customer, error) {
+	return s.repo.GetAll(search)
+}
+
+func (s *CustomerService) GetByID(id int) (*models.Customer, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *CustomerService) GetByPhone(phone string) (*models.Customer, error) {
+	return s.repo.GetByPhone(phone)
+}
+
+func (s *CustomerService) Create(req models.CustomerRequest) (*models.Customer, error) {
+	return s.repo.Create(req)
+}
+
+func (s *CustomerService) Update(id int, req models.CustomerRequest) (*models.Customer, error) {
+	return s.repo.Update(id, req)
+}
+
+func (s *CustomerService) Delete(id int) error {
+	return s.repo.Delete(id)
+}
+
+func (s *CustomerService) GetProfile(id int) (*models.CustomerProfile, error) {
+	return s.repo.GetProfile(id)
+}
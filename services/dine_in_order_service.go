@@ -0,0 +1,75 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+type DineInOrderService struct {
+	repo         *repositories.DineInOrderRepository
+	transactions *TransactionService
+}
+
+func NewDineInOrderService(repo *repositories.DineInOrderRepository, transactions *TransactionService) *DineInOrderService {
+	return &DineInOrderService{repo: repo, transactions: transactions}
+}
+
+func (s *DineInOrderService) GetAll(status string, outletID int) ([]models.DineInOrder, error) {
+	return s.repo.GetAll(status, outletID)
+}
+
+func (s *DineInOrderService) GetByID(id int) (*models.DineInOrder, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *DineInOrderService) Open(req models.DineInOrderRequest) (*models.DineInOrder, error) {
+	return s.repo.Open(req)
+}
+
+func (s *DineInOrderService) AddItems(id int, req models.DineInItemsRequest) (*models.DineInOrder, error) {
+	return s.repo.AddItems(id, req)
+}
+
+// SendToKitchen sends the order's new round to the kitchen stations
+func (s *DineInOrderService) SendToKitchen(id int) (*models.DineInOrder, error) {
+	order, kitchen, err := s.repo.SendToKitchen(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.transactions.publishKitchen(kitchen)
+	return order, nil
+}
+
+func (s *DineInOrderService) Serve(id int) (*models.DineInOrder, error) {
+	return s.repo.Serve(id)
+}
+
+func (s *DineInOrderService) MoveTables(id int, req models.DineInTablesRequest) (*models.DineInOrder, error) {
+	return s.repo.MoveTables(id, req)
+}
+
+func (s *DineInOrderService) Merge(id int, req models.DineInMergeRequest) (*models.DineInOrder, error) {
+	return s.repo.Merge(id, req)
+}
+
+// Bill checks out the order's bills, which are sales like any other checkout
+func (s *DineInOrderService) Bill(id int, req models.DineInBillRequest) (*models.DineInOrder, error) {
+	order, err := s.repo.Bill(id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range order.Bills {
+		s.transactions.publishSale(&order.Bills[i])
+	}
+	return order, nil
+}
+
+func (s *DineInOrderService) Pay(id int, req models.DineInPaymentRequest) (*models.DineInOrder, error) {
+	return s.repo.Pay(id, req)
+}
+
+func (s *DineInOrderService) Cancel(id int, actor string) (*models.DineInOrder, error) {
+	return s.repo.Cancel(id, actor)
+}
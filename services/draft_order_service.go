@@ -0,0 +1,41 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+type DraftOrderService struct {
+	repo         *repositories.DraftOrderRepository
+	transactions *TransactionService
+}
+
+func NewDraftOrderService(repo *repositories.DraftOrderRepository, transactions *TransactionService) *DraftOrderService {
+	return &DraftOrderService{repo: repo, transactions: transactions}
+}
+
+func (s *DraftOrderService) GetAll(status string, outletID int) ([]models.DraftOrder, error) {
+	return s.repo.GetAll(status, outletID)
+}
+
+func (s *DraftOrderService) GetByID(id int) (*models.DraftOrder, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *DraftOrderService) Create(req models.DraftOrderRequest) (*models.DraftOrder, error) {
+	return s.repo.Create(req)
+}
+
+func (s *DraftOrderService) Update(id int, req models.DraftOrderRequest) (*models.DraftOrder, error) {
+	return s.repo.Update(id, req)
+}
+
+func (s *DraftOrderService) Delete(id int) error {
+	return s.repo.Delete(id)
+}
+
+// Checkout converts a draft order into a transaction through the regular checkout
+func (s *DraftOrderService) Checkout(id int, req models.CheckoutRequest) (*models.Transaction, error) {
+	req.DraftOrderID = id
+	return s.transactions.Create(req)
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+type GiftCardService struct {
+	repo *repositories.GiftCardRepository
+}
+
+func NewGiftCardService(repo *repositories.GiftCardRepository) *GiftCardService {
+	return &GiftCardService{repo: repo}
+}
+
+func (s *GiftCardService) GetAll(status string) ([]models.GiftCard, error) {
+	return s.repo.GetAll(status)
+}
+
+func (s *GiftCardService) GetByCode(code string) (*models.GiftCard, error) {
+	return s.repo.GetByCode(code)
+}
+
+// GetBalance reports what is left on a gift card and whether it can pay a checkout now
+func (s *GiftCardService) GetBalance(code string) (*models.GiftCardBalance, error) {
+	card, err := s.repo.GetByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	return &models.GiftCardBalance{
+		Code:       card.Code,
+		Type:       card.Type,
+		Balance:    card.Balance,
+		Status:     card.Status,
+		ExpiryDate: card.ExpiryDate,
+		Expired:    card.Expired,
+		Usable:     card.Status == models.GiftCardActive && !card.Expired && card.Balance > 0,
+	}, nil
+}
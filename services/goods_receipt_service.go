@@ -0,0 +1,26 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+type GoodsReceiptService struct {
+	repo *repositories.GoodsReceiptRepository
+}
+
+func NewGoodsReceiptService(repo *repositories.GoodsReceiptRepository) *GoodsReceiptService {
+	return &GoodsReceiptService{repo: repo}
+}
+
+func (s *GoodsReceiptService) GetAll(supplierID, productID int) ([]models.GoodsReceipt, error) {
+	return s.repo.GetAll(supplierID, productID)
+}
+
+func (s *GoodsReceiptService) GetByID(id int) (*models.GoodsReceipt, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *GoodsReceiptService) Create(req models.GoodsReceiptRequest) (*models.GoodsReceipt, error) {
+	return s.repo.Create(req)
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"kasir-api/events"
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+type KitchenService struct {
+	repo *repositories.KitchenRepository
+	bus  *events.Bus
+}
+
+func NewKitchenService(repo *repositories.KitchenRepository, bus *events.Bus) *KitchenService {
+	return &KitchenService{repo: repo, bus: bus}
+}
+
+func (s *KitchenService) GetStations() ([]models.KitchenStation, error) {
+	return s.repo.GetStations()
+}
+
+func (s *KitchenService) GetStation(id int) (*models.KitchenStation, error) {
+	return s.repo.GetStation(id)
+}
+
+func (s *KitchenService) CreateStation(req models.KitchenStationRequest) (*models.KitchenStation, error) {
+	return s.repo.CreateStation(req)
+}
+
+func (s *KitchenService) UpdateStation(id int, req models.KitchenStationRequest) (*models.KitchenStation, error) {
+	return s.repo.UpdateStation(id, req)
+}
+
+func (s *KitchenService) DeleteStation(id int) error {
+	return s.repo.DeleteStation(id)
+}
+
+func (s *KitchenService) GetQueue(stationID, outletID int, status string) ([]models.KitchenItem, error) {
+	return s.repo.GetQueue(stationID, outletID, status)
+}
+
+// Start marks a new item as being prepared
+func (s *KitchenService) Start(id int) (*models.KitchenItem, error) {
+	return s.setStatus(id, models.KitchenItemPreparing, models.KitchenItemNew)
+}
+
+// Ready marks an item ready to be picked up
+func (s *KitchenService) Ready(id int) (*models.KitchenItem, error) {
+	return s.setStatus(id, models.KitchenItemReady, models.KitchenItemNew, models.KitchenItemPreparing)
+}
+
+// Bump takes an item off the station's screen
+func (s *KitchenService) Bump(id int) (*models.KitchenItem, error) {
+	return s.setStatus(id, models.KitchenItemBumped, models.KitchenItemNew, models.KitchenItemPreparing, models.KitchenItemReady)
+}
+
+// Recall brings a bumped item back on the screen as ready
+func (s *KitchenService) Recall(id int) (*models.KitchenItem, error) {
+	return s.setStatus(id, models.KitchenItemReady, models.KitchenItemBumped)
+}
+
+func (s *KitchenService) setStatus(id int, status string, from ...string) (*models.KitchenItem, error) {
+	item, err := s.repo.SetItemStatus(id, status, from...)
+	if err != nil {
+		return nil, err
+	}
+
+	s.bus.Publish(events.TypeKitchenItem, *item)
+	return item, nil
+}
@@ -0,0 +1,18 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+type LoyaltyService struct {
+	repo *repositories.LoyaltyRepository
+}
+
+func NewLoyaltyService(repo *repositories.LoyaltyRepository) *LoyaltyService {
+	return &LoyaltyService{repo: repo}
+}
+
+func (s *LoyaltyService) GetAccount(customerID int) (*models.LoyaltyAccount, error) {
+	return s.repo.GetAccount(customerID)
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"encoding/json"
+	"kasir-api/events"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// outboxBatch is how many outbox events are claimed at once
+const outboxBatch = 100
+
+// outboxMaxBackoff caps the wait before an event a sink failed is relayed again
+const outboxMaxBackoff = 5 * time.Minute
+
+// outboxRetention is how long dispatched events stay in the outbox
+const outboxRetention = 7 * 24 * time.Hour
+
+// OutboxDispatcher relays the events recorded in the outbox to its sinks, at least once each.
+// An event stays in the outbox until every sink has taken it, a sink that fails gets it again
+// with a growing delay while the others are not sent it twice.
+type OutboxDispatcher struct {
+	repo *repositories.OutboxRepository
+	// interval is how often the outbox is checked without being notified, and the first retry delay
+	interval time.Duration
+	sinks    []outboxSink
+	wake     chan struct{}
+}
+
+type outboxSink struct {
+	name    string
+	consume events.Consumer
+}
+
+func NewOutboxDispatcher(repo *repositories.OutboxRepository, interval time.Duration) *OutboxDispatcher {
+	return &OutboxDispatcher{repo: repo, interval: interval, wake: make(chan struct{}, 1)}
+}
+
+// AddSink registers a sink under a name that identifies it in the outbox, so the name must not
+// change between releases. Sinks are added before Start.
+func (d *OutboxDispatcher) AddSink(name string, consume events.Consumer) {
+	d.sinks = append(d.sinks, outboxSink{name: name, consume: consume})
+}
+
+// Start relays events in the background until stop is called: when notified of new events and
+// every interval, for retries and for events left by a dispatcher that stopped midway
+func (d *OutboxDispatcher) Start() (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+		lastPrune := time.Time{}
+		for {
+			d.dispatch(done)
+			if time.Since(lastPrune) > time.Hour {
+				if err := d.repo.Prune(outboxRetention); err != nil {
+					log.Printf("outbox: prune: %v", err)
+				}
+				lastPrune = time.Now()
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			case <-d.wake:
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// Notify tells the dispatcher that events were committed to the outbox
+func (d *OutboxDispatcher) Notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+		// A wake up is pending already and will see these events too
+	}
+}
+
+// dispatch relays the due events until there are none left or done is closed
+func (d *OutboxDispatcher) dispatch(done <-chan struct{}) {
+	for {
+		claimed, err := d.repo.Claim(outboxBatch)
+		if err != nil {
+			log.Printf("outbox: %v", err)
+			return
+		}
+		for _, e := range claimed {
+			select {
+			case <-done:
+				// Claimed events left here are claimed again once their lease runs out
+				return
+			default:
+			}
+			d.relay(e)
+		}
+		if len(claimed) < outboxBatch {
+			return
+		}
+	}
+}
+
+// relay sends an event to the sinks that have not taken it yet and records the outcome
+func (d *OutboxDispatcher) relay(oe models.OutboxEvent) {
+	e := events.Event{ID: oe.EventID, Type: oe.EventType, OccurredAt: oe.OccurredAt}
+	doneSinks := oe.DoneSinks
+	var errs []string
+	data, err := eventData(oe.EventType, oe.Payload)
+	if err != nil {
+		errs = append(errs, err.Error())
+	} else {
+		e.Data = data
+		for _, sink := range d.sinks {
+			if slices.Contains(doneSinks, sink.name) {
+				continue
+			}
+			if err := sink.consume(e); err != nil {
+				errs = append(errs, sink.name+": "+err.Error())
+				continue
+			}
+			doneSinks = append(doneSinks, sink.name)
+		}
+	}
+
+	retryIn := d.interval << min(oe.Attempts, 16)
+	if retryIn > outboxMaxBackoff {
+		retryIn = outboxMaxBackoff
+	}
+	attemptErr := strings.Join(errs, "; ")
+	if attemptErr != "" {
+		log.Printf("outbox: event %s %s: %s", e.Type, e.ID, attemptErr)
+	}
+	if err := d.repo.RecordAttempt(oe.ID, doneSinks, attemptErr, retryIn); err != nil {
+		log.Printf("outbox: event %s %s: %v", e.Type, e.ID, err)
+	}
+}
+
+// eventData decodes an outbox payload into the value the event was recorded with, so sinks and
+// subscribers see the same data as for events published directly
+func eventData(eventType string, payload json.RawMessage) (interface{}, error) {
+	switch eventType {
+	case events.TypeTransactionCreated, events.TypeTransactionRefunded:
+		var t models.Transaction
+		err := json.Unmarshal(payload, &t)
+		return t, err
+	case events.TypeStockLow:
+		var item models.LowStockItem
+		err := json.Unmarshal(payload, &item)
+		return item, err
+	case events.TypeProductCreated, events.TypeProductUpdated:
+		var p models.Product
+		err := json.Unmarshal(payload, &p)
+		return p, err
+	case events.TypeProductDeleted:
+		var ref map[string]int
+		err := json.Unmarshal(payload, &ref)
+		return ref, err
+	default:
+		return payload, nil
+	}
+}
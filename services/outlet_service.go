@@ -0,0 +1,58 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"time"
+)
+
+type OutletService struct {
+	repo *repositories.OutletRepository
+}
+
+func NewOutletService(repo *repositories.OutletRepository) *OutletService {
+	return &OutletService{repo: repo}
+}
+
+func (s *OutletService) GetAll() ([]models.Outlet, error) {
+	return s.repo.GetAll()
+}
+
+func (s *OutletService) GetByID(id int) (*models.Outlet, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *OutletService) Create(req models.OutletRequest) (*models.Outlet, error) {
+	return s.repo.Create(req)
+}
+
+func (s *OutletService) Update(id int, req models.OutletRequest) (*models.Outlet, error) {
+	return s.repo.Update(id, req)
+}
+
+func (s *OutletService) Delete(id int) error {
+	return s.repo.Delete(id)
+}
+
+// GetStock lists the outlet's stock and prices, failing for an unknown outlet
+func (s *OutletService) GetStock(id int) ([]models.OutletStock, error) {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, err
+	}
+	return s.repo.GetStock(id)
+}
+
+// SetPrices applies the price overrides and returns the outlet's updated stock and price list
+func (s *OutletService) SetPrices(id int, prices []models.OutletPriceRequest) ([]models.OutletStock, error) {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetPrices(id, prices); err != nil {
+		return nil, err
+	}
+	return s.repo.GetStock(id)
+}
+
+func (s *OutletService) GetSales(startDate, endDate time.Time) ([]models.OutletSales, error) {
+	return s.repo.GetSales(startDate, endDate)
+}
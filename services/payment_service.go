@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/payments"
+	"kasir-api/qr"
+	"kasir-api/repositories"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// paymentSweepInterval is how often expired QRIS payments are looked for
+const paymentSweepInterval = 30 * time.Second
+
+// PaymentService takes QRIS payments of sales through a gateway. The sale is recorded pending with
+// its stock reserved, it completes when the gateway notifies the payment and is given back when
+// the QRIS expires unpaid or the payment is cancelled.
+type PaymentService struct {
+	repo         *repositories.PaymentRepository
+	gateway      payments.Gateway
+	transactions *TransactionService
+	// expiry is how long a QRIS can be paid
+	expiry time.Duration
+}
+
+func NewPaymentService(repo *repositories.PaymentRepository, gateway payments.Gateway, transactions *TransactionService, expiry time.Duration) *PaymentService {
+	return &PaymentService{repo: repo, gateway: gateway, transactions: transactions, expiry: expiry}
+}
+
+func (s *PaymentService) GetAll(status string) ([]models.Payment, error) {
+	return s.repo.GetAll(status)
+}
+
+func (s *PaymentService) GetByID(id int) (*models.Payment, error) {
+	return s.repo.GetByID(id)
+}
+
+// CreateQRIS records the sale of req pending and creates a QRIS at the gateway for what is left to pay.
+// When the gateway cannot create it, the payment fails and the sale is given back.
+func (s *PaymentService) CreateQRIS(req models.CheckoutRequest) (*models.Payment, error) {
+	payment, err := s.repo.Create(req, s.gateway.Name(), s.expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := s.gateway.CreateQRIS(context.Background(), payments.QRISRequest{
+		OrderID:   "PAY-" + strconv.Itoa(payment.ID),
+		Amount:    payment.Amount,
+		ExpiresAt: payment.ExpiresAt,
+	})
+	if err != nil {
+		if _, releaseErr := s.repo.Release(payment.ID, models.PaymentFailed, "QRIS could not be created", req.Actor); releaseErr != nil {
+			log.Printf("payments: release payment %d: %v", payment.ID, releaseErr)
+		}
+		return nil, fmt.Errorf("%s gateway: %w", s.gateway.Name(), err)
+	}
+	return s.repo.SetQRIS(payment.ID, code.Reference, code.Payload)
+}
+
+// QRCode renders the QRIS of a pending payment as a PNG image with modules of scale pixels
+func (s *PaymentService) QRCode(id, scale int) ([]byte, error) {
+	payment, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != models.PaymentPending || payment.QRISPayload == "" {
+		return nil, fmt.Errorf("payment %d is %s and has no QRIS to pay", id, payment.Status)
+	}
+	code, err := qr.Encode(payment.QRISPayload)
+	if err != nil {
+		return nil, err
+	}
+	return code.PNG(scale)
+}
+
+// Cancel cancels a pending payment at the gateway and gives back its sale
+func (s *PaymentService) Cancel(id int, actor string) (*models.Payment, error) {
+	payment, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != models.PaymentPending {
+		return nil, fmt.Errorf("payment %d is %s already", id, payment.Status)
+	}
+	if payment.Reference != "" {
+		if err := s.gateway.CancelQRIS(context.Background(), payment.Reference); err != nil {
+			return nil, fmt.Errorf("%s gateway: %w", s.gateway.Name(), err)
+		}
+	}
+	return s.repo.Release(id, models.PaymentCancelled, "QRIS payment cancelled", actor)
+}
+
+// ParseNotification authenticates and reads a notification the gateway posted
+func (s *PaymentService) ParseNotification(r *http.Request) (*payments.Notification, error) {
+	return s.gateway.ParseNotification(r)
+}
+
+// Notify applies a gateway notification: a paid payment completes its sale, an expired or failed
+// one gives it back
+func (s *PaymentService) Notify(n *payments.Notification) (*models.Payment, error) {
+	payment, err := s.repo.GetByReference(s.gateway.Name(), n.Reference)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.Status {
+	case payments.StatusPaid:
+		if n.Amount != payment.Amount {
+			return nil, fmt.Errorf("payment %d is for %d, not %d", payment.ID, payment.Amount, n.Amount)
+		}
+		paid, completed, err := s.repo.MarkPaid(payment.ID)
+		if err != nil {
+			// The customer paid a QRIS whose sale was given back, the money has to be returned by hand
+			log.Printf("payments: %s paid payment %d: %v", s.gateway.Name(), payment.ID, err)
+			return nil, err
+		}
+		if completed {
+			s.transactions.publishSale(paid.Transaction)
+		}
+		return paid, nil
+	case payments.StatusExpired:
+		return s.release(payment, models.PaymentExpired, "QRIS payment expired")
+	default:
+		return s.release(payment, models.PaymentFailed, "QRIS payment failed")
+	}
+}
+
+// release gives back the sale of a payment that is still pending, a payment that is not is returned as is
+func (s *PaymentService) release(payment *models.Payment, status, reason string) (*models.Payment, error) {
+	if payment.Status != models.PaymentPending {
+		return payment, nil
+	}
+	return s.repo.Release(payment.ID, status, reason, "")
+}
+
+// Start expires unpaid QRIS payments in the background until stop is called. The QRIS is cancelled
+// at the gateway first, so a payment made at the last moment is not lost.
+func (s *PaymentService) Start() (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(paymentSweepInterval)
+		defer ticker.Stop()
+		for {
+			s.expireDue()
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// expireDue releases the pending payments whose QRIS has expired
+func (s *PaymentService) expireDue() {
+	due, err := s.repo.GetDue()
+	if err != nil {
+		log.Printf("payments: %v", err)
+		return
+	}
+	for _, payment := range due {
+		if payment.Reference != "" {
+			if err := s.gateway.CancelQRIS(context.Background(), payment.Reference); err != nil {
+				// Left pending, the gateway may still notify the payment
+				log.Printf("payments: cancel payment %d: %v", payment.ID, err)
+				continue
+			}
+		}
+		if _, err := s.repo.Release(payment.ID, models.PaymentExpired, "QRIS payment expired", ""); err != nil {
+			log.Printf("payments: expire payment %d: %v", payment.ID, err)
+		}
+	}
+}
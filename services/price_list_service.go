@@ -0,0 +1,34 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+type PriceListService struct {
+	repo *repositories.PriceListRepository
+}
+
+func NewPriceListService(repo *repositories.PriceListRepository) *PriceListService {
+	return &PriceListService{repo: repo}
+}
+
+func (s *PriceListService) GetAll() ([]models.PriceList, error) {
+	return s.repo.GetAll()
+}
+
+func (s *PriceListService) GetByID(id int) (*models.PriceList, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *PriceListService) Create(req models.PriceListRequest) (*models.PriceList, error) {
+	return s.repo.Create(req)
+}
+
+func (s *PriceListService) Update(id int, req models.PriceListRequest) (*models.PriceList, error) {
+	return s.repo.Update(id, req)
+}
+
+func (s *PriceListService) Delete(id int) error {
+	return s.repo.Delete(id)
+}
@@ -6,29 +6,74 @@ import (
 )
 
 type ProductService struct {
-	repo *repositories.ProductRepository
+	repo   *repositories.ProductRepository
+	outbox *OutboxDispatcher
 }
 
-func NewProductService(repo *repositories.ProductRepository) *ProductService {
-	return &ProductService{repo: repo}
+func NewProductService(repo *repositories.ProductRepository, outbox *OutboxDispatcher) *ProductService {
+	return &ProductService{repo: repo, outbox: outbox}
 }
 
-func (s *ProductService) GetAll(name string) ([]models.Product, error) {
-	return s.repo.GetAll(name)
+func (s *ProductService) GetAll(name string, outletID int) ([]models.Product, error) {
+	return s.repo.GetAll(name, outletID)
 }
 
-func (s *ProductService) GetByID(id int) (*models.Product, error) {
-	return s.repo.GetByID(id)
+func (s *ProductService) GetByID(id, outletID int) (*models.Product, error) {
+	return s.repo.GetByID(id, outletID)
 }
 
 func (s *ProductService) Create(req models.ProductRequest) (*models.Product, error) {
-	return s.repo.Create(req)
+	applyUnitDefaults(&req)
+	product, err := s.repo.Create(req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.outbox.Notify()
+	return product, nil
 }
 
 func (s *ProductService) Update(id int, req models.ProductRequest) (*models.Product, error) {
-	return s.repo.Update(id, req)
+	applyUnitDefaults(&req)
+	product, err := s.repo.Update(id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.outbox.Notify()
+	return product, nil
 }
 
 func (s *ProductService) Delete(id int) error {
-	return s.repo.Delete(id)
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+
+	s.outbox.Notify()
+	return nil
+}
+
+func (s *ProductService) GetCostHistory(id int) ([]models.ProductCost, error) {
+	return s.repo.GetCostHistory(id)
+}
+
+func (s *ProductService) GetLowStock(outletID int) ([]models.LowStockItem, error) {
+	return s.repo.GetLowStock(outletID)
+}
+
+func (s *ProductService) GetBatches(id, outletID int, all bool) ([]models.ProductBatch, error) {
+	return s.repo.GetBatches(id, outletID, all)
+}
+
+// applyUnitDefaults sells and purchases in the base unit unless told otherwise
+func applyUnitDefaults(req *models.ProductRequest) {
+	if req.Unit == "" {
+		req.Unit = models.DefaultUnit
+	}
+	if req.SaleUnit == "" {
+		req.SaleUnit = req.Unit
+	}
+	if req.PurchaseUnit == "" {
+		req.PurchaseUnit = req.Unit
+	}
 }
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/pdf"
+	"kasir-api/repositories"
+)
+
+type PurchaseOrderService struct {
+	repo         *repositories.PurchaseOrderRepository
+	supplierRepo *repositories.SupplierRepository
+}
+
+func NewPurchaseOrderService(repo *repositories.PurchaseOrderRepository, supplierRepo *repositories.SupplierRepository) *PurchaseOrderService {
+	return &PurchaseOrderService{repo: repo, supplierRepo: supplierRepo}
+}
+
+func (s *PurchaseOrderService) GetAll(status string) ([]models.PurchaseOrder, error) {
+	return s.repo.GetAll(status)
+}
+
+func (s *PurchaseOrderService) GetByID(id int) (*models.PurchaseOrder, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *PurchaseOrderService) Create(req models.PurchaseOrderRequest) (*models.PurchaseOrder, error) {
+	return s.repo.Create(req)
+}
+
+func (s *PurchaseOrderService) Update(id int, req models.PurchaseOrderRequest) (*models.PurchaseOrder, error) {
+	return s.repo.Update(id, req)
+}
+
+// Send marks a draft as sent to the supplier, after which it can no longer be edited
+func (s *PurchaseOrderService) Send(id int) (*models.PurchaseOrder, error) {
+	return s.repo.UpdateStatus(id, []string{models.POStatusDraft}, models.POStatusSent)
+}
+
+// Cancel cancels a purchase order that has not received any goods yet
+func (s *PurchaseOrderService) Cancel(id int) (*models.PurchaseOrder, error) {
+	return s.repo.UpdateStatus(id, []string{models.POStatusDraft, models.POStatusSent}, models.POStatusCancelled)
+}
+
+// ExportPDF renders a printable purchase order to send to the supplier
+func (s *PurchaseOrderService) ExportPDF(id int) ([]byte, error) {
+	po, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	supplier, err := s.supplierRepo.GetByID(po.SupplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := pdf.New()
+	doc.Line("PURCHASE ORDER #%d", po.ID)
+	doc.Line("Status: %s", po.Status)
+	doc.Line("Date: %s", po.CreatedAt.Format("2006-01-02"))
+	if po.ExpectedDate != "" {
+		doc.Line("Expected delivery: %s", po.ExpectedDate)
+	}
+	doc.Blank()
+	doc.Line("Supplier: %s", supplier.Name)
+	if supplier.ContactName != "" {
+		doc.Line("Contact: %s", supplier.ContactName)
+	}
+	if supplier.Phone != "" {
+		doc.Line("Phone: %s", supplier.Phone)
+	}
+	if supplier.Email != "" {
+		doc.Line("Email: %s", supplier.Email)
+	}
+	if supplier.Address != "" {
+		doc.Line("Address: %s", supplier.Address)
+	}
+	doc.Blank()
+
+	doc.Line("%-4s %-36s %10s %-8s %12s %14s", "No", "Product", "Qty", "Unit", "Unit Cost", "Subtotal")
+	doc.Rule()
+	for i, line := range po.Lines {
+		doc.Line("%-4d %-36.36s %10s %-8.8s %12d %14d", i+1, line.ProductName, line.Quantity, line.Unit, line.UnitCost, line.Subtotal)
+	}
+	doc.Rule()
+	doc.Line("%-74s %14d", "TOTAL", po.TotalAmount)
+
+	if po.Notes != "" {
+		doc.Blank()
+		doc.Line("Notes: %s", po.Notes)
+	}
+	return doc.Bytes(), nil
+}
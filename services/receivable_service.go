@@ -0,0 +1,31 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"time"
+)
+
+type ReceivableService struct {
+	repo *repositories.ReceivableRepository
+}
+
+func NewReceivableService(repo *repositories.ReceivableRepository) *ReceivableService {
+	return &ReceivableService{repo: repo}
+}
+
+func (s *ReceivableService) GetAccount(customerID int) (*models.CustomerAccount, error) {
+	return s.repo.GetAccount(customerID)
+}
+
+func (s *ReceivableService) RecordPayment(customerID int, req models.CustomerPaymentRequest) (*models.CustomerPayment, error) {
+	return s.repo.RecordPayment(customerID, req)
+}
+
+func (s *ReceivableService) GetStatement(customerID int, start, end time.Time) (*models.CustomerStatement, error) {
+	return s.repo.GetStatement(customerID, start, end)
+}
+
+func (s *ReceivableService) GetAging() (*models.ReceivablesAging, error) {
+	return s.repo.GetAging()
+}
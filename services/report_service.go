@@ -1,6 +1,7 @@
 package services
 
 import (
+	"fmt"
 	"kasir-api/models"
 	"kasir-api/repositories"
 	"time"
@@ -8,22 +9,96 @@ import (
 
 type ReportService struct {
 	repo *repositories.ReportRepository
+	// reorderDefaults fills in reorder parameters not given by the caller
+	reorderDefaults models.ReorderParams
 }
 
-func NewReportService(repo *repositories.ReportRepository) *ReportService {
-	return &ReportService{repo: repo}
+func NewReportService(repo *repositories.ReportRepository, reorderDefaults models.ReorderParams) *ReportService {
+	return &ReportService{repo: repo, reorderDefaults: reorderDefaults}
 }
 
-func (s *ReportService) GetReportToday() (*models.SalesReport, error) {
+func (s *ReportService) GetReportToday(outletID int) (*models.SalesReport, error) {
 	now := time.Now()
 	// Start of day
 	startDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	// End of day
 	endDate := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 999999999, now.Location())
 
-	return s.repo.GetSalesReport(startDate, endDate)
+	return s.repo.GetSalesReport(startDate, endDate, outletID)
 }
 
-func (s *ReportService) GetReportByDateRange(startDate, endDate time.Time) (*models.SalesReport, error) {
-	return s.repo.GetSalesReport(startDate, endDate)
+// dashboardBestSellers is how many best sellers the dashboard shows
+const dashboardBestSellers = 5
+
+// GetDashboard gives today's live sales figures of one outlet, or of all outlets when outletID is 0
+func (s *ReportService) GetDashboard(outletID int) (*models.DashboardMetrics, error) {
+	now := time.Now()
+	startDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	endDate := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 999999999, now.Location())
+
+	report, err := s.repo.GetSalesReport(startDate, endDate, outletID)
+	if err != nil {
+		return nil, err
+	}
+	products, err := s.repo.GetProductSales(startDate, endDate, outletID)
+	if err != nil {
+		return nil, err
+	}
+	if len(products) > dashboardBestSellers {
+		products = products[:dashboardBestSellers]
+	}
+	if products == nil {
+		products = []models.ProductSales{}
+	}
+
+	metrics := models.DashboardMetrics{
+		OutletID:      outletID,
+		Date:          startDate.Format("2006-01-02"),
+		Revenue:       report.TotalRevenue,
+		Transactions:  report.TotalTransactions,
+		GrossProfit:   report.GrossProfit,
+		MarginPercent: report.MarginPercent,
+		BestSellers:   products,
+		UpdatedAt:     now,
+	}
+	if report.TotalTransactions > 0 {
+		metrics.AverageBasket = report.TotalRevenue / report.TotalTransactions
+	}
+	return &metrics, nil
+}
+
+func (s *ReportService) GetReportByDateRange(startDate, endDate time.Time, outletID int) (*models.SalesReport, error) {
+	return s.repo.GetSalesReport(startDate, endDate, outletID)
+}
+
+func (s *ReportService) GetProductSales(startDate, endDate time.Time, outletID int) ([]models.ProductSales, error) {
+	return s.repo.GetProductSales(startDate, endDate, outletID)
+}
+
+func (s *ReportService) GetCategorySales(startDate, endDate time.Time, outletID int) ([]models.CategorySales, error) {
+	return s.repo.GetCategorySales(startDate, endDate, outletID)
+}
+
+func (s *ReportService) GetExpiringStock(days, outletID int) ([]models.ExpiringStock, error) {
+	return s.repo.GetExpiringStock(days, outletID)
+}
+
+// GetReorderSuggestions uses the configured defaults for any parameter left at zero
+func (s *ReportService) GetReorderSuggestions(params models.ReorderParams, outletID int, all bool) ([]models.ReorderSuggestion, error) {
+	if params.WindowDays == 0 {
+		params.WindowDays = s.reorderDefaults.WindowDays
+	}
+	if params.LeadTimeDays == 0 {
+		params.LeadTimeDays = s.reorderDefaults.LeadTimeDays
+	}
+	if params.SafetyDays == 0 {
+		params.SafetyDays = s.reorderDefaults.SafetyDays
+	}
+	if params.CoverDays == 0 {
+		params.CoverDays = s.reorderDefaults.CoverDays
+	}
+	if params.WindowDays <= 0 {
+		return nil, fmt.Errorf("window_days must be positive")
+	}
+	return s.repo.GetReorderSuggestions(params, outletID, all)
 }
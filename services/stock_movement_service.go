@@ -0,0 +1,23 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"time"
+)
+
+type StockMovementService struct {
+	repo *repositories.StockMovementRepository
+}
+
+func NewStockMovementService(repo *repositories.StockMovementRepository) *StockMovementService {
+	return &StockMovementService{repo: repo}
+}
+
+func (s *StockMovementService) GetByProduct(productID, outletID int, startDate, endDate time.Time) ([]models.StockMovement, error) {
+	return s.repo.GetByProduct(productID, outletID, startDate, endDate)
+}
+
+func (s *StockMovementService) Adjust(productID int, req models.StockAdjustmentRequest) (*models.StockMovement, error) {
+	return s.repo.Adjust(productID, req)
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+type StockTakeService struct {
+	repo *repositories.StockTakeRepository
+}
+
+func NewStockTakeService(repo *repositories.StockTakeRepository) *StockTakeService {
+	return &StockTakeService{repo: repo}
+}
+
+func (s *StockTakeService) GetAll() ([]models.StockTake, error) {
+	return s.repo.GetAll()
+}
+
+func (s *StockTakeService) GetByID(id int) (*models.StockTake, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *StockTakeService) Create(req models.StockTakeRequest) (*models.StockTake, error) {
+	return s.repo.Create(req)
+}
+
+func (s *StockTakeService) SubmitCounts(id int, req models.StockCountRequest) (*models.StockTake, error) {
+	return s.repo.SubmitCounts(id, req)
+}
+
+func (s *StockTakeService) Approve(id int, actor string) (*models.StockTake, error) {
+	return s.repo.Approve(id, actor)
+}
+
+func (s *StockTakeService) Cancel(id int) (*models.StockTake, error) {
+	return s.repo.Cancel(id)
+}
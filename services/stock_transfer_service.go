@@ -0,0 +1,42 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+type StockTransferService struct {
+	repo *repositories.StockTransferRepository
+}
+
+func NewStockTransferService(repo *repositories.StockTransferRepository) *StockTransferService {
+	return &StockTransferService{repo: repo}
+}
+
+func (s *StockTransferService) GetAll(status string, outletID, productID int) ([]models.StockTransfer, error) {
+	return s.repo.GetAll(status, outletID, productID)
+}
+
+func (s *StockTransferService) GetByID(id int) (*models.StockTransfer, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *StockTransferService) Create(req models.StockTransferRequest) (*models.StockTransfer, error) {
+	return s.repo.Create(req)
+}
+
+func (s *StockTransferService) Dispatch(id int, req models.TransferDispatchRequest) (*models.StockTransfer, error) {
+	return s.repo.Dispatch(id, req)
+}
+
+func (s *StockTransferService) Receive(id int, req models.TransferReceiveRequest) (*models.StockTransfer, error) {
+	return s.repo.Receive(id, req)
+}
+
+func (s *StockTransferService) Cancel(id int) (*models.StockTransfer, error) {
+	return s.repo.Cancel(id)
+}
+
+func (s *StockTransferService) GetInTransit(outletID int) ([]models.InTransitStock, error) {
+	return s.repo.GetInTransit(outletID)
+}
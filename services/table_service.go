@@ -0,0 +1,34 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+type TableService struct {
+	repo *repositories.TableRepository
+}
+
+func NewTableService(repo *repositories.TableRepository) *TableService {
+	return &TableService{repo: repo}
+}
+
+func (s *TableService) GetAll(outletID int, area string) ([]models.Table, error) {
+	return s.repo.GetAll(outletID, area)
+}
+
+func (s *TableService) GetByID(id int) (*models.Table, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *TableService) Create(req models.TableRequest) (*models.Table, error) {
+	return s.repo.Create(req)
+}
+
+func (s *TableService) Update(id int, req models.TableRequest) (*models.Table, error) {
+	return s.repo.Update(id, req)
+}
+
+func (s *TableService) Delete(id int) error {
+	return s.repo.Delete(id)
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+type TenantService struct {
+	repo *repositories.TenantRepository
+	// openDB returns the connection pool bound to a tenant
+	openDB func(tenantID int) (*sql.DB, error)
+}
+
+func NewTenantService(repo *repositories.TenantRepository, openDB func(tenantID int) (*sql.DB, error)) *TenantService {
+	return &TenantService{repo: repo, openDB: openDB}
+}
+
+func (s *TenantService) GetAll() ([]models.Tenant, error) {
+	return s.repo.GetAll()
+}
+
+func (s *TenantService) GetByID(id int) (*models.Tenant, error) {
+	return s.repo.GetByID(id)
+}
+
+// Create provisions a tenant with a default outlet and returns it with its API key,
+// which is only stored hashed and cannot be shown again
+func (s *TenantService) Create(req models.TenantRequest) (*models.Tenant, error) {
+	key, hash, err := generateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	tenant, err := s.repo.Create(req, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := s.openDB(tenant.ID)
+	if err == nil {
+		_, err = repositories.NewOutletRepository(db).Create(models.OutletRequest{
+			Code:      "MAIN",
+			Name:      "Main Store",
+			IsDefault: true,
+		})
+	}
+	if err != nil {
+		s.repo.Delete(tenant.ID)
+		return nil, err
+	}
+
+	tenant.APIKey = key
+	return tenant, nil
+}
+
+func (s *TenantService) Update(id int, req models.TenantRequest) (*models.Tenant, error) {
+	return s.repo.Update(id, req)
+}
+
+// RotateAPIKey issues a new API key for the tenant, the old key stops working
+func (s *TenantService) RotateAPIKey(id int) (*models.Tenant, error) {
+	key, hash, err := generateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetAPIKeyHash(id, hash); err != nil {
+		return nil, err
+	}
+	tenant, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	tenant.APIKey = key
+	return tenant, nil
+}
+
+// Authenticate returns the tenant an API key belongs to
+func (s *TenantService) Authenticate(apiKey string) (*models.Tenant, error) {
+	return s.repo.GetByAPIKeyHash(hashAPIKey(apiKey))
+}
+
+func generateAPIKey() (key, hash string, err error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	key = "kasir_" + hex.EncodeToString(b)
+	return key, hashAPIKey(key), nil
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,18 +1,50 @@
 package services
 
 import (
+	"kasir-api/events"
 	"kasir-api/models"
 	"kasir-api/repositories"
 )
 
 type TransactionService struct {
-	repo *repositories.TransactionRepository
+	repo   *repositories.TransactionRepository
+	bus    *events.Bus
+	outbox *OutboxDispatcher
 }
 
-func NewTransactionService(repo *repositories.TransactionRepository) *TransactionService {
-	return &TransactionService{repo: repo}
+func NewTransactionService(repo *repositories.TransactionRepository, bus *events.Bus, outbox *OutboxDispatcher) *TransactionService {
+	return &TransactionService{repo: repo, bus: bus, outbox: outbox}
 }
 
 func (s *TransactionService) Create(req models.CheckoutRequest) (*models.Transaction, error) {
-	return s.repo.Create(req)
+	transaction, err := s.repo.Create(req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishSale(transaction)
+	return transaction, nil
+}
+
+// publishSale relays the events a recorded sale committed to the outbox and publishes its kitchen items
+func (s *TransactionService) publishSale(transaction *models.Transaction) {
+	s.outbox.Notify()
+	s.publishKitchen(transaction.KitchenItems)
+}
+
+// publishKitchen publishes items sent to the kitchen stations
+func (s *TransactionService) publishKitchen(items []models.KitchenItem) {
+	for _, item := range items {
+		s.bus.Publish(events.TypeKitchenItem, item)
+	}
+}
+
+func (s *TransactionService) Refund(id int, req models.RefundRequest) (*models.Transaction, error) {
+	transaction, err := s.repo.Refund(id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.outbox.Notify()
+	return transaction, nil
 }
@@ -0,0 +1,364 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"kasir-api/events"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// webhookDeliveryLog is how many deliveries of a subscription the delivery log shows
+const webhookDeliveryLog = 100
+
+// webhookRetryBatch is how many due deliveries are claimed for retry at once
+const webhookRetryBatch = 50
+
+// webhookSweepInterval is how often deliveries due for a retry are looked for
+const webhookSweepInterval = 10 * time.Second
+
+// webhookMaxBackoff caps the wait before a failed delivery is retried
+const webhookMaxBackoff = 6 * time.Hour
+
+// errPrivateWebhookURL rejects webhook URLs that reach the server's own network
+var errPrivateWebhookURL = errors.New("webhook url must not point at a loopback, link-local or private address")
+
+type WebhookService struct {
+	repo   *repositories.WebhookRepository
+	client *http.Client
+	// maxAttempts is how often a delivery is tried before it fails, retries wait retryBase
+	// and then twice as long as the previous wait
+	maxAttempts int
+	retryBase   time.Duration
+	// allowPrivate lets subscriptions reach loopback, link-local and private addresses, for trying
+	// webhooks out locally
+	allowPrivate bool
+	// done is closed when the service stops, ending the retries of its consumers
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewWebhookService(repo *repositories.WebhookRepository, maxAttempts int, retryBase time.Duration, allowPrivate bool) *WebhookService {
+	s := &WebhookService{
+		repo:         repo,
+		maxAttempts:  maxAttempts,
+		retryBase:    retryBase,
+		allowPrivate: allowPrivate,
+		done:         make(chan struct{}),
+	}
+	// Addresses are checked as they are dialled, so a name resolving elsewhere after the subscription
+	// was checked or a redirect cannot reach the internal network either. No proxy is used, it would
+	// be dialled instead of the receiver.
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: s.checkDial}
+	s.client = &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: 10 * time.Second},
+	}
+	return s
+}
+
+// GetAll lists the subscriptions without their secrets
+func (s *WebhookService) GetAll() ([]models.WebhookSubscription, error) {
+	subscriptions, err := s.repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+	return subscriptions, nil
+}
+
+// GetByID returns a subscription without its secret
+func (s *WebhookService) GetByID(id int) (*models.WebhookSubscription, error) {
+	subscription, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	subscription.Secret = ""
+	return subscription, nil
+}
+
+// Create adds a subscription, generating its secret unless one is given. The secret is returned only here.
+func (s *WebhookService) Create(req models.WebhookSubscriptionRequest) (*models.WebhookSubscription, error) {
+	if req.Secret == "" {
+		b := make([]byte, 24)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		req.Secret = "whsec_" + hex.EncodeToString(b)
+	}
+	if err := s.checkURL(req.URL); err != nil {
+		return nil, err
+	}
+	return s.repo.Create(req)
+}
+
+func (s *WebhookService) Update(id int, req models.WebhookSubscriptionRequest) (*models.WebhookSubscription, error) {
+	if err := s.checkURL(req.URL); err != nil {
+		return nil, err
+	}
+	subscription, err := s.repo.Update(id, req)
+	if err != nil {
+		return nil, err
+	}
+	subscription.Secret = ""
+	return subscription, nil
+}
+
+func (s *WebhookService) Delete(id int) error {
+	return s.repo.Delete(id)
+}
+
+func (s *WebhookService) GetDeliveries(subscriptionID int, status string) ([]models.WebhookDelivery, error) {
+	return s.repo.GetDeliveries(subscriptionID, status, webhookDeliveryLog)
+}
+
+func (s *WebhookService) GetDelivery(id int) (*models.WebhookDelivery, error) {
+	return s.repo.GetDelivery(id)
+}
+
+// Test sends a webhook.test event to a subscription, active or not, and returns the delivery
+func (s *WebhookService) Test(id int) (*models.WebhookDelivery, error) {
+	ids, err := s.queue(id, events.New(events.TypeWebhookTest, map[string]int{"subscription_id": id}))
+	if err != nil {
+		return nil, err
+	}
+	return s.deliver(ids[0])
+}
+
+// Redeliver sends a delivery again now, whatever its status, and returns its outcome
+func (s *WebhookService) Redeliver(id int) (*models.WebhookDelivery, error) {
+	if err := s.repo.ClaimRedelivery(id); err != nil {
+		return nil, err
+	}
+	return s.deliver(id)
+}
+
+// Sink queues webhook events relayed from the outbox for the subscriptions to their type and sends
+// them in the background. An event relayed again is not queued twice for a subscription.
+func (s *WebhookService) Sink(e events.Event) error {
+	if !slices.Contains(events.WebhookTypes, e.Type) {
+		return nil
+	}
+
+	ids, err := s.queue(0, e)
+	if err != nil {
+		return err
+	}
+	go func() {
+		for _, id := range ids {
+			if _, err := s.deliver(id); err != nil {
+				log.Printf("webhooks: delivery %d: %v", id, err)
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *WebhookService) queue(subscriptionID int, e events.Event) ([]int, error) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := s.repo.CreateDeliveries(subscriptionID, e.ID, e.Type, payload)
+	if err != nil {
+		return nil, err
+	}
+	if subscriptionID != 0 && len(ids) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return ids, nil
+}
+
+// Consumer posts events to rawURL signed with secret, the way deliveries are posted, for a receiver
+// configured outside the subscriptions such as the low stock webhook. A failed post is retried with
+// the deliveries' backoff until it runs out of attempts or the service stops. The URL is checked as
+// a subscription's is.
+func (s *WebhookService) Consumer(rawURL, secret string) (events.Consumer, error) {
+	if err := s.checkURL(rawURL); err != nil {
+		return nil, err
+	}
+	return func(e events.Event) error {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		for attempts := 0; ; attempts++ {
+			_, err = s.post(rawURL, secret, e.Type, e.ID, payload)
+			if err == nil || attempts+1 >= s.maxAttempts {
+				return err
+			}
+			timer := time.NewTimer(webhookBackoff(s.retryBase, attempts))
+			select {
+			case <-s.done:
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+	}, nil
+}
+
+// Start retries the deliveries that are due in the background until stop is called, including
+// those left by a server that stopped before their retry. Stopping also ends the retries of the
+// service's consumers.
+func (s *WebhookService) Start() (stop func()) {
+	go func() {
+		ticker := time.NewTicker(webhookSweepInterval)
+		defer ticker.Stop()
+		for {
+			s.retryDue(s.done)
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() { s.stopOnce.Do(func() { close(s.done) }) }
+}
+
+// retryDue sends the pending deliveries whose next attempt is due until there are none left or done
+// is closed
+func (s *WebhookService) retryDue(done <-chan struct{}) {
+	for {
+		ids, err := s.repo.ClaimDue(webhookRetryBatch)
+		if err != nil {
+			log.Printf("webhooks: retry: %v", err)
+			return
+		}
+		for _, id := range ids {
+			if _, err := s.deliver(id); err != nil {
+				log.Printf("webhooks: delivery %d: %v", id, err)
+			}
+		}
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if len(ids) < webhookRetryBatch {
+			return
+		}
+	}
+}
+
+// deliver makes one attempt at a claimed delivery. A failed attempt is retried by the sweep after an
+// exponential backoff until the delivery runs out of attempts.
+func (s *WebhookService) deliver(id int) (*models.WebhookDelivery, error) {
+	delivery, err := s.repo.GetDelivery(id)
+	if err != nil {
+		return nil, err
+	}
+	subscription, err := s.repo.GetByID(delivery.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	statusCode, err := s.post(subscription.URL, subscription.Secret, delivery.EventType, strconv.Itoa(delivery.ID), delivery.Payload)
+	status, attemptErr, retryIn := models.WebhookDelivered, "", time.Duration(0)
+	if err != nil {
+		status, attemptErr = models.WebhookFailed, err.Error()
+		if delivery.Attempts+1 < s.maxAttempts {
+			status, retryIn = models.WebhookPending, webhookBackoff(s.retryBase, delivery.Attempts)
+		}
+	}
+	if err := s.repo.RecordAttempt(id, status, statusCode, attemptErr, retryIn); err != nil {
+		return nil, err
+	}
+	return s.repo.GetDelivery(id)
+}
+
+// webhookBackoff is the wait before the retry of an attempt that failed after attempts earlier ones:
+// base, then twice as long each time up to webhookMaxBackoff
+func webhookBackoff(base time.Duration, attempts int) time.Duration {
+	wait := base << min(attempts, 16)
+	if wait <= 0 || wait > webhookMaxBackoff {
+		return webhookMaxBackoff
+	}
+	return wait
+}
+
+// post sends a payload to rawURL signed with secret. Any 2xx answer delivers it.
+func (s *WebhookService) post(rawURL, secret, eventType, deliveryID string, payload []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, rawURL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(events.HeaderWebhookEvent, eventType)
+	req.Header.Set(events.HeaderWebhookDelivery, deliveryID)
+	req.Header.Set(events.HeaderWebhookTimestamp, timestamp)
+	req.Header.Set(events.HeaderWebhookSignature, events.SignPayload(secret, timestamp, payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("receiver answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// checkURL only accepts http and https URLs whose host does not resolve to an address of the server's
+// own network
+func (s *WebhookService) checkURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.New("webhook url must be an http or https URL")
+	}
+	if s.allowPrivate {
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(context.Background(), u.Hostname())
+	if err != nil {
+		return fmt.Errorf("webhook url: %w", err)
+	}
+	for _, addr := range addrs {
+		if privateIP(addr.IP) {
+			return errPrivateWebhookURL
+		}
+	}
+	return nil
+}
+
+// checkDial refuses connections to addresses of the server's own network
+func (s *WebhookService) checkDial(network, address string, _ syscall.RawConn) error {
+	if s.allowPrivate {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || privateIP(ip) {
+		return errPrivateWebhookURL
+	}
+	return nil
+}
+
+// privateIP reports whether ip is a loopback, link-local, private, unspecified or multicast address
+func privateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified()
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"kasir-api/events"
+)
+
+func TestWebhookConsumer(t *testing.T) {
+	var calls atomic.Int32
+	var signed atomic.Bool
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		want := events.SignPayload("whsec_test", r.Header.Get(events.HeaderWebhookTimestamp), body)
+		signed.Store(r.Header.Get(events.HeaderWebhookSignature) == want)
+		// The first attempt fails, the retry is accepted
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer receiver.Close()
+
+	s := NewWebhookService(nil, 3, time.Millisecond, true)
+	consume, err := s.Consumer(receiver.URL, "whsec_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := consume(events.New(events.TypeStockLow, map[string]int{"product_id": 1})); err != nil {
+		t.Fatalf("consume: %v", err)
+	}
+	if calls.Load() != 2 || !signed.Load() {
+		t.Fatalf("receiver was called %d times, signed %v, want 2 signed calls", calls.Load(), signed.Load())
+	}
+}
+
+func TestWebhookConsumerGivesUp(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	s := NewWebhookService(nil, 3, time.Millisecond, true)
+	consume, err := s.Consumer(receiver.URL, "whsec_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := consume(events.New(events.TypeStockLow, nil)); err == nil {
+		t.Fatal("consume succeeded against a failing receiver")
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("receiver was called %d times, want 3", calls.Load())
+	}
+}
+
+func TestSignPayload(t *testing.T) {
+	// Computed independently: HMAC-SHA256 of "1700000000." and the body with the key whsec_test
+	const want = "sha256=5a776ee08ccde76aac59ed5494fed92087fb6a796f47202042448c81c12ebd6a"
+	got := events.SignPayload("whsec_test", "1700000000", []byte(`{"id":"evt_1","type":"stock.low"}`))
+	if got != want {
+		t.Fatalf("SignPayload = %s, want %s", got, want)
+	}
+	if events.SignPayload("whsec_test", "1700000001", []byte(`{"id":"evt_1","type":"stock.low"}`)) == want {
+		t.Fatal("signature does not cover the timestamp")
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		base     time.Duration
+		attempts int
+		want     time.Duration
+	}{
+		{30 * time.Second, 0, 30 * time.Second},
+		{30 * time.Second, 1, time.Minute},
+		{30 * time.Second, 4, 8 * time.Minute},
+		{30 * time.Second, 9, 256 * time.Minute},
+		{30 * time.Second, 10, webhookMaxBackoff},
+		{30 * time.Second, 40, webhookMaxBackoff},
+		{webhookMaxBackoff, 0, webhookMaxBackoff},
+		{time.Duration(1) << 62, 3, webhookMaxBackoff},
+		{0, 2, webhookMaxBackoff},
+	}
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.base, tt.attempts); got != tt.want {
+			t.Errorf("webhookBackoff(%s, %d) = %s, want %s", tt.base, tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookCheckURL(t *testing.T) {
+	s := NewWebhookService(nil, 3, time.Second, false)
+
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{"https://93.184.216.34/hooks", false},
+		{"http://127.0.0.1:9000/", true},
+		{"http://localhost:9000/", true},
+		{"http://[::1]/", true},
+		{"http://10.1.2.3/", true},
+		{"http://172.16.0.1/", true},
+		{"http://192.168.1.10/", true},
+		{"http://169.254.169.254/latest/meta-data", true},
+		{"http://[fe80::1]/", true},
+		{"http://0.0.0.0/", true},
+		{"ftp://93.184.216.34/", true},
+		{"not a url", true},
+	}
+	for _, tt := range tests {
+		if err := s.checkURL(tt.url); (err != nil) != tt.wantErr {
+			t.Errorf("checkURL(%q) = %v, want error %v", tt.url, err, tt.wantErr)
+		}
+	}
+
+	if err := NewWebhookService(nil, 3, time.Second, true).checkURL("http://127.0.0.1:9000/"); err != nil {
+		t.Errorf("checkURL with private URLs allowed: %v", err)
+	}
+}
+
+func TestWebhookCheckDial(t *testing.T) {
+	s := NewWebhookService(nil, 3, time.Second, false)
+
+	tests := []struct {
+		address string
+		wantErr bool
+	}{
+		{"93.184.216.34:443", false},
+		{"[2606:2800:220:1:248:1893:25c8:1946]:443", false},
+		{"127.0.0.1:80", true},
+		{"[::1]:80", true},
+		{"10.0.0.5:80", true},
+		{"192.168.0.1:8080", true},
+		{"169.254.169.254:80", true},
+		{"[fe80::1]:80", true},
+		{"[fd00::1]:80", true},
+		{"0.0.0.0:80", true},
+		{"example.com:80", true},
+	}
+	for _, tt := range tests {
+		if err := s.checkDial("tcp", tt.address, nil); (err != nil) != tt.wantErr {
+			t.Errorf("checkDial(%q) = %v, want error %v", tt.address, err, tt.wantErr)
+		}
+	}
+}
+
+// TestWebhookDialTimeCheck posts to a receiver on loopback, as a name resolving there after its URL
+// was checked would, and expects the dial to be refused before the receiver is reached
+func TestWebhookDialTimeCheck(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer receiver.Close()
+
+	s := NewWebhookService(nil, 1, time.Millisecond, false)
+	_, err := s.post(receiver.URL, "whsec_test", events.TypeStockLow, "evt_1", []byte(`{}`))
+	if !errors.Is(err, errPrivateWebhookURL) {
+		t.Fatalf("post to loopback: %v, want errPrivateWebhookURL", err)
+	}
+	if calls.Load() != 0 {
+		t.Fatal("receiver on loopback was reached")
+	}
+}